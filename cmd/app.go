@@ -12,11 +12,14 @@ import (
 	"time"
 
 	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/jobs"
 	"github.com/labstack/echo/v4"
 )
 
 func Start(db *sql.DB, addr *string) {
-	e := InitialiseHttpRouter(db)
+	runner := jobs.NewRunner(db)
+
+	e := InitialiseHttpRouter(db, runner)
 
 	e.HTTPErrorHandler = func(err error, c echo.Context) {
 		var (
@@ -41,6 +44,10 @@ func Start(db *sql.DB, addr *string) {
 		log.Fatalf("Unable to Initialize Database %v", err)
 	}
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	runner.Start(jobsCtx)
+
 	log.Printf("Starting server at %s", *addr)
 	go func() {
 		e.Start(*addr)
@@ -49,6 +56,8 @@ func Start(db *sql.DB, addr *string) {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	stopJobs()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer func() {
 		db.Close()
@@ -63,3 +72,30 @@ func Start(db *sql.DB, addr *string) {
 
 	fmt.Println("Server exited properly.")
 }
+
+// Purge runs the retention jobs once, for use from cron or by hand with "app purge [job]"
+func Purge(db *sql.DB, job string) {
+	query := database.NewDBinstance(db)
+
+	if err := query.InitialiseDBqueries(); err != nil {
+		log.Fatalf("Unable to Initialize Database %v", err)
+	}
+
+	runs, err := jobs.NewRunner(db).RunNow(context.Background(), job, "cli")
+	if err != nil {
+		log.Fatalf("Unable to run jobs %v", err)
+	}
+
+	failed := false
+	for _, run := range runs {
+		fmt.Printf("%s: %v (%s)\n", run.Job, run.Purged, run.FinishedAt.Sub(run.StartedAt))
+		if run.Error != "" {
+			fmt.Printf("%s failed: %s\n", run.Job, run.Error)
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
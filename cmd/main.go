@@ -21,6 +21,11 @@ func main() {
 	defer db.Close()
 	log.Println("Database Status Checked")
 
+	if flag.Arg(0) == "purge" {
+		Purge(db.db, flag.Arg(1))
+		return
+	}
+
 	log.Println("Starting Server")
 	Start(db.db, addr)
 }
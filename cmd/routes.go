@@ -5,13 +5,14 @@ import (
 
 	"github.com/Hacfy/IT_INVENTORY/internals/handlers"
 	"github.com/Hacfy/IT_INVENTORY/internals/middleware"
+	"github.com/Hacfy/IT_INVENTORY/pkg/jobs"
 	// "github.com/Hacfy/IT_INVENTORY/internals/middleware"
 	"github.com/Hacfy/IT_INVENTORY/repository"
 	"github.com/labstack/echo/v4"
 	defaultMiddleware "github.com/labstack/echo/v4/middleware"
 )
 
-func InitialiseHttpRouter(db *sql.DB) *echo.Echo {
+func InitialiseHttpRouter(db *sql.DB, runner *jobs.Runner) *echo.Echo {
 	e := echo.New()
 
 	e.Use(defaultMiddleware.Logger())
@@ -21,6 +22,11 @@ func InitialiseHttpRouter(db *sql.DB) *echo.Echo {
 		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE},
 	}))
 
+	e.Use(middleware.StaffActivityMiddleware(db))
+	e.Use(middleware.DelegationActivityMiddleware(db))
+
+	detailsHandler := handlers.NewDetailsHandler(repository.NewDetailsRepo(db))
+
 	mainAdminHandler := handlers.NewMainAdmin_Handler(repository.NewMainAdminRepo(db))
 
 	e.POST("/main_admin/register", mainAdminHandler.CreateMainAdminHandler)                 //
@@ -32,6 +38,11 @@ func InitialiseHttpRouter(db *sql.DB) *echo.Echo {
 	e.GET("/main_admin/get/all/main_admins", mainAdminHandler.GetAllMainAdminsHandler)      //
 	//write main_adim middleware
 
+	jobsHandler := handlers.NewJobsHandler(repository.NewJobsRepo(db, runner))
+
+	e.POST("/main_admin/run/jobs", jobsHandler.RunJobsHandler)
+	e.GET("/main_admin/get/job/runs", jobsHandler.GetJobRunsHandler)
+
 	authHandler := handlers.NewAuthHandler(repository.NewAuthRepo(db))
 
 	e.POST("/auth/login/users", authHandler.UserLoginHandler) //
@@ -49,58 +60,66 @@ func InitialiseHttpRouter(db *sql.DB) *echo.Echo {
 	e.DELETE("/organization/delete/superAdmin", organisatoinHandler.DeleteSuperAdminHandler)            //
 	e.GET("/organization/get/all/superAdmins", organisatoinHandler.GetAllSuperAdminsHandler)            //
 	e.POST("/organization/reassign/superAdmin/branches", organisatoinHandler.ReassignSuperAdminHandler) //
-	//GET /organization/get/details
+	e.GET("/organization/get/details", detailsHandler.GetOrganizationDetailsHandler, middleware.AuthMiddleware, middleware.RoleMiddleware("organization"))
+	e.GET("/organization/get/superAdmin/details", detailsHandler.GetSuperAdminDetailsHandler, middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin"))
 
 	superAdminHandler := handlers.NewSuperAdminHandler(repository.NewSuperAdminRepo(db))
 
 	e.POST("/superAdmin/create/branch", superAdminHandler.CreateBranchHandler)        //
 	e.PUT("/superAdmin/update/branchHead", superAdminHandler.UpdateBranchHeadHandler) //
 	e.DELETE("/superAdmin/delete/branch", superAdminHandler.DeleteBranchHandler)      //
-	// GET /superAdmin/get/branch/details
+	e.GET("/superAdmin/preview/delete/branch", superAdminHandler.PreviewDeleteBranchHandler)
+	e.GET("/superAdmin/get/branch/details", detailsHandler.GetBranchDetailsHandler, middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head"))
 
 	branchHandler := handlers.NewBranchHandler(repository.NewBranchRepo(db))
 
-	e.POST("/branch/create/department", branchHandler.CreateDepartmentHandler)        //
-	e.PUT("/branch/update/departmentHead", branchHandler.UpdateDepartmentHeadHandler) //
-	e.POST("/branch/create/warehouse", branchHandler.CreateWarehouseHandler)          //
-	e.PUT("/branch/update/warehouseHead", branchHandler.UpdateWarehouseHeadHandler)   //
-	e.DELETE("/branch/delete/department", branchHandler.DeleteDepartmentHandler)      //
-	e.DELETE("/branch/delete/warehouse", branchHandler.DeleteWarehouseHandler)        //
-	// GET /branch/get/department/details
-	// GET /branch/get/warehouse/details
+	e.POST("/branch/create/department", branchHandler.CreateDepartmentHandler, middleware.AuthMiddleware)        //
+	e.PUT("/branch/update/departmentHead", branchHandler.UpdateDepartmentHeadHandler, middleware.AuthMiddleware) //
+	e.POST("/branch/create/warehouse", branchHandler.CreateWarehouseHandler, middleware.AuthMiddleware)          //
+	e.PUT("/branch/update/warehouseHead", branchHandler.UpdateWarehouseHeadHandler, middleware.AuthMiddleware)   //
+	e.DELETE("/branch/delete/department", branchHandler.DeleteDepartmentHandler, middleware.AuthMiddleware)      //
+	e.DELETE("/branch/delete/warehouse", branchHandler.DeleteWarehouseHandler, middleware.AuthMiddleware)        //
+	e.GET("/branch/preview/delete/department", branchHandler.PreviewDeleteDepartmentHandler, middleware.AuthMiddleware)
+	e.GET("/branch/preview/delete/warehouse", branchHandler.PreviewDeleteWarehouseHandler, middleware.AuthMiddleware)
+	e.GET("/branch/get/department/details", detailsHandler.GetDepartmentDetailsHandler, middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head"))
+	e.GET("/branch/get/warehouse/details", detailsHandler.GetWarehouseDetailsHandler, middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "warehouses"))
 
 	departmentHandler := handlers.NewDepartmentHandler(repository.NewDepartmentRepo(db))
 
-	e.POST("/department/create/workspace", departmentHandler.CreateWorkspaceHandler)               //
-	e.DELETE("/department/delete/workspace", departmentHandler.DeleteWorkspaceHandler)             //
-	e.POST("/department/raise/issue", departmentHandler.RaiseIssueHandler)                         //
-	e.POST("/department/request/new/units", departmentHandler.RequestNewUnitsHandler)              //
-	e.GET("/department/get/all/requests", departmentHandler.GetAllDepartmentRequestsHandler)       //
-	e.GET("/department/get/request/details", departmentHandler.GetDepartmentRequestDetailsHandler) //
-	e.DELETE("/department/delete/request", departmentHandler.DeleteRequestHandler)                 //
-	e.DELETE("/department/delete/issue", departmentHandler.DeleteIssueHandler)
+	e.POST("/department/create/workspace", departmentHandler.CreateWorkspaceHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("head"))            //
+	e.DELETE("/department/delete/workspace", departmentHandler.DeleteWorkspaceHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("head"))          //
+	e.POST("/department/raise/issue", departmentHandler.RaiseIssueHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("head", "member"))            //
+	e.POST("/department/request/new/units", departmentHandler.RequestNewUnitsHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("head", "member")) //
+	e.GET("/department/get/all/requests", departmentHandler.GetAllDepartmentRequestsHandler, middleware.AuthMiddleware)                                            //
+	e.GET("/department/get/request/details", departmentHandler.GetDepartmentRequestDetailsHandler, middleware.AuthMiddleware)                                      //
+	e.DELETE("/department/delete/request", departmentHandler.DeleteRequestHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("head"))              //
+	e.DELETE("/department/delete/issue", departmentHandler.DeleteIssueHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("head"))
 
 	warehouseHandler := handlers.NewWarehouse_Handler(repository.NewWarehouseRepo(db))
 
-	e.POST("/warehouse/create/component", warehouseHandler.CreateComponentHandler)                        //
-	e.DELETE("/warehouse/delete/component", warehouseHandler.DeleteComponentHandler)                      //
-	e.POST("/warehouse/add/component/units", warehouseHandler.AddComponentUnitsHandler)                   //
-	e.PATCH("/warehouse/assign/units", warehouseHandler.AssignUnitsHandler)                               //
-	e.GET("/warehouse/get/all/issues", warehouseHandler.GetAllIssuesHandler)                              //
-	e.GET("/warehouse/get/all/components", warehouseHandler.GetAllWarehouseComponentsHandler)             //
-	e.GET("/warehouse/get/all/component/units", warehouseHandler.GetAllWarehouseComponentUnitsHandler)    //
-	e.GET("/warehouse/get/issue/details", warehouseHandler.GetIssueDetailsHandler)                        //
-	e.GET("/warehouse/get/unit/history", warehouseHandler.GetUnitAssignmentHistoryHandler)                //
-	e.PUT("/warehouse/update/issue/status", warehouseHandler.UpdateIssueStatusHandler)                    //
-	e.PUT("/warehouse/update/component/name", warehouseHandler.UpdateComponentNameHandler)                //
-	e.GET("/warehouse/get/assigned/units", warehouseHandler.GetAssignedUnitsHandler)                      //
-	e.PUT("/warehouse/update/component/unit/maintainance", warehouseHandler.UpdateMaintenanceCostHandler) //
-	e.PUT("/warehouse/update/component/unit/status", warehouseHandler.UpdateUnitStatusHandler)            //
-	e.DELETE("/warehouse/delete/component/unit", warehouseHandler.DeleteUnitHandler)                      //
-
-	// GET /warehouse/get/component/details
-
-	detailsHandler := handlers.NewDetailsHandler(repository.NewDetailsRepo(db))
+	e.POST("/warehouse/create/component", warehouseHandler.CreateComponentHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager"))                                      //
+	e.DELETE("/warehouse/delete/component", warehouseHandler.DeleteComponentHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager"))                                    //
+	e.POST("/warehouse/add/component/units", warehouseHandler.AddComponentUnitsHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager"))                                 //
+	e.PATCH("/warehouse/assign/units", warehouseHandler.AssignUnitsHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager", "technician"))                               //
+	e.GET("/warehouse/get/all/issues", warehouseHandler.GetAllIssuesHandler)                                                                                                                  //
+	e.GET("/warehouse/get/all/components", warehouseHandler.GetAllWarehouseComponentsHandler)                                                                                                 //
+	e.GET("/warehouse/get/all/component/units", warehouseHandler.GetAllWarehouseComponentUnitsHandler)                                                                                        //
+	e.GET("/warehouse/get/issue/details", warehouseHandler.GetIssueDetailsHandler)                                                                                                            //
+	e.GET("/warehouse/get/unit/history", warehouseHandler.GetUnitAssignmentHistoryHandler)                                                                                                    //
+	e.PUT("/warehouse/update/issue/status", warehouseHandler.UpdateIssueStatusHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager", "technician"))                    //
+	e.PUT("/warehouse/update/component/name", warehouseHandler.UpdateComponentNameHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager"))                              //
+	e.GET("/warehouse/get/assigned/units", warehouseHandler.GetAssignedUnitsHandler)                                                                                                          //
+	e.PUT("/warehouse/update/component/unit/maintainance", warehouseHandler.UpdateMaintenanceCostHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager", "technician")) //
+	e.PUT("/warehouse/update/component/unit/status", warehouseHandler.UpdateUnitStatusHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager", "technician"))            //
+	e.DELETE("/warehouse/delete/component/unit", warehouseHandler.DeleteUnitHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager"))                                    //
+	e.PUT("/warehouse/update/component/unit/identifiers", warehouseHandler.UpdateUnitIdentifiersHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager"))
+	e.PUT("/warehouse/update/component/prefix", warehouseHandler.UpdateComponentPrefixHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager"))
+	e.PUT("/warehouse/update/component/catalogue", warehouseHandler.UpdateComponentCatalogueHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager"))
+	e.PUT("/warehouse/update/component/unit/attributes", warehouseHandler.UpdateUnitAttributesHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager"))
+	e.POST("/warehouse/link/units", warehouseHandler.LinkUnitsHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager"))
+	e.POST("/warehouse/unlink/unit", warehouseHandler.UnlinkUnitHandler, middleware.AuthMiddleware, middleware.StaffRoleMiddleware("manager"))
+	e.GET("/warehouse/get/unit/composition", warehouseHandler.GetUnitCompositionHandler)
+	e.GET("/warehouse/get/component/details", detailsHandler.GetComponentDetailsHandler, middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "warehouses"))
 
 	detailsGroup := e.Group("/details", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
 
@@ -112,6 +131,178 @@ func InitialiseHttpRouter(db *sql.DB) *echo.Echo {
 	detailsGroup.GET("/get/all/department/outOfWarentyUnits", detailsHandler.GetAllDepartmentOutOfWarentyUnitsHandler) //
 	detailsGroup.GET("/get/all/warehouse/outOfWarentyUnits", detailsHandler.GetAllOutOfWarentyUnitsInWarehouseHandler) //
 
+	dashboardHandler := handlers.NewDashboardHandler(repository.NewDashboardRepo(db))
+
+	e.GET("/dashboard", dashboardHandler.GetDashboardHandler, middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	searchHandler := handlers.NewSearchHandler(repository.NewSearchRepo(db))
+
+	e.GET("/search", searchHandler.SearchHandler, middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	analyticsHandler := handlers.NewAnalyticsHandler(repository.NewAnalyticsRepo(db))
+
+	analyticsGroup := e.Group("/analytics", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	analyticsGroup.GET("/get/cost/components", analyticsHandler.GetCostByComponentHandler)
+	analyticsGroup.GET("/get/cost/warehouses", analyticsHandler.GetCostByWarehouseHandler)
+	analyticsGroup.GET("/get/cost/departments", analyticsHandler.GetCostByDepartmentHandler)
+	analyticsGroup.GET("/get/cost/periods", analyticsHandler.GetCostByPeriodHandler)
+	analyticsGroup.GET("/get/top/maintenance/units", analyticsHandler.GetTopMaintenanceUnitsHandler)
+	analyticsGroup.GET("/get/cost/branches/comparison", analyticsHandler.GetBranchComponentCostComparisonHandler)
+
+	restoreHandler := handlers.NewRestoreHandler(repository.NewRestoreRepo(db))
+
+	restoreGroup := e.Group("/restore", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	restoreGroup.GET("/get/archived", restoreHandler.GetArchivedEntitiesHandler)
+	restoreGroup.POST("/department", restoreHandler.RestoreDepartmentHandler)
+	restoreGroup.POST("/workspace", restoreHandler.RestoreWorkspaceHandler)
+	restoreGroup.POST("/component", restoreHandler.RestoreComponentHandler)
+	restoreGroup.POST("/unit", restoreHandler.RestoreUnitHandler)
+	restoreGroup.POST("/issue", restoreHandler.RestoreIssueHandler)
+
+	catalogueHandler := handlers.NewCatalogueHandler(repository.NewCatalogueRepo(db))
+
+	catalogueGroup := e.Group("/catalogue", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	catalogueGroup.GET("/get/categories", catalogueHandler.GetCategoriesHandler)
+	catalogueGroup.GET("/get/models", catalogueHandler.GetCatalogueHandler)
+	catalogueGroup.GET("/get/model/stock", catalogueHandler.GetCatalogueStockHandler)
+	catalogueGroup.POST("/create/category", catalogueHandler.CreateCategoryHandler)
+	catalogueGroup.POST("/create/model", catalogueHandler.CreateCatalogueModelHandler)
+
+	consumableHandler := handlers.NewConsumableHandler(repository.NewConsumableRepo(db))
+
+	e.POST("/warehouse/consumable/stock/in", consumableHandler.StockInHandler, middleware.StaffRoleMiddleware("manager"))
+	e.POST("/warehouse/consumable/issue", consumableHandler.IssueHandler, middleware.StaffRoleMiddleware("manager"))
+	e.POST("/warehouse/consumable/adjust", consumableHandler.AdjustHandler, middleware.StaffRoleMiddleware("manager"))
+	e.GET("/warehouse/get/consumable/ledger", consumableHandler.GetConsumableLedgerHandler)
+
+	attributeHandler := handlers.NewAttributeHandler(repository.NewAttributeRepo(db))
+
+	attributeGroup := e.Group("/attributes", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	attributeGroup.GET("/get/definitions", attributeHandler.GetAttributeDefinitionsHandler)
+	attributeGroup.POST("/create/definition", attributeHandler.CreateAttributeDefinitionHandler)
+	attributeGroup.DELETE("/delete/definition", attributeHandler.DeleteAttributeDefinitionHandler)
+
+	auditHandler := handlers.NewAuditHandler(repository.NewAuditRepo(db))
+
+	auditGroup := e.Group("/audit", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	auditGroup.POST("/create/session", auditHandler.CreateAuditSessionHandler)
+	auditGroup.POST("/record/sighting", auditHandler.RecordSightingHandler)
+	auditGroup.GET("/get/sessions", auditHandler.GetAuditSessionsHandler)
+	auditGroup.GET("/get/report", auditHandler.GetAuditReportHandler)
+	auditGroup.POST("/close/session", auditHandler.CloseAuditSessionHandler)
+
+	custodianHandler := handlers.NewCustodianHandler(repository.NewCustodianRepo(db))
+
+	custodianGroup := e.Group("/custodians", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	custodianGroup.GET("/get/all", custodianHandler.GetCustodiansHandler)
+	custodianGroup.GET("/get/holdings", custodianHandler.GetCustodianHoldingsHandler)
+	custodianGroup.POST("/create", custodianHandler.CreateCustodianHandler)
+	custodianGroup.POST("/deactivate", custodianHandler.DeactivateCustodianHandler)
+	custodianGroup.POST("/assign/unit", custodianHandler.AssignCustodyHandler)
+	custodianGroup.POST("/return/unit", custodianHandler.ReturnCustodyHandler)
+
+	e.POST("/custody/acknowledge", custodianHandler.AcknowledgeCustodyHandler)
+
+	checklistHandler := handlers.NewChecklistHandler(repository.NewChecklistRepo(db))
+
+	checklistGroup := e.Group("/checklists", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	checklistGroup.GET("/get/templates", checklistHandler.GetOnboardingTemplatesHandler)
+	checklistGroup.POST("/create/template", checklistHandler.CreateOnboardingTemplateHandler)
+	checklistGroup.DELETE("/delete/template", checklistHandler.DeleteOnboardingTemplateHandler)
+	checklistGroup.POST("/start/onboarding", checklistHandler.StartOnboardingHandler)
+	checklistGroup.POST("/start/offboarding", checklistHandler.StartOffboardingHandler)
+	checklistGroup.GET("/get/all", checklistHandler.GetChecklistsHandler)
+	checklistGroup.GET("/get/details", checklistHandler.GetChecklistDetailsHandler)
+	checklistGroup.POST("/resolve/item", checklistHandler.ResolveChecklistItemHandler)
+	checklistGroup.POST("/cancel", checklistHandler.CancelChecklistHandler)
+
+	loanHandler := handlers.NewLoanHandler(repository.NewLoanRepo(db))
+
+	loanGroup := e.Group("/loans", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	loanGroup.POST("/create/reservation", loanHandler.CreateReservationHandler)
+	loanGroup.POST("/checkout", loanHandler.CheckoutLoanHandler)
+	loanGroup.POST("/return", loanHandler.ReturnLoanHandler)
+	loanGroup.POST("/cancel", loanHandler.CancelLoanHandler)
+	loanGroup.GET("/get/all", loanHandler.GetLoansHandler)
+	loanGroup.GET("/get/calendar", loanHandler.GetLoanCalendarHandler)
+
+	locationHandler := handlers.NewLocationHandler(repository.NewLocationRepo(db))
+
+	locationGroup := e.Group("/locations", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	locationGroup.POST("/create", locationHandler.CreateLocationHandler)
+	locationGroup.DELETE("/delete", locationHandler.DeleteLocationHandler)
+	locationGroup.GET("/get/tree", locationHandler.GetLocationTreeHandler)
+	locationGroup.POST("/attach/workspace", locationHandler.AttachWorkspaceLocationHandler)
+	locationGroup.POST("/create/bin", locationHandler.CreateStorageBinHandler)
+	locationGroup.GET("/get/bins", locationHandler.GetStorageBinsHandler)
+	locationGroup.POST("/store/units", locationHandler.StoreUnitsHandler)
+	locationGroup.GET("/get/units", locationHandler.GetUnitLocationsHandler)
+
+	restructureHandler := handlers.NewRestructureHandler(repository.NewRestructureRepo(db))
+
+	restructureGroup := e.Group("/restructure", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	restructureGroup.POST("/move/workspace", restructureHandler.MoveWorkspaceHandler)
+	restructureGroup.POST("/move/department", restructureHandler.MoveDepartmentHandler)
+	restructureGroup.GET("/get/moves", restructureHandler.GetMovesHandler)
+
+	hierarchyHandler := handlers.NewHierarchyHandler(repository.NewHierarchyRepo(db))
+
+	e.GET("/hierarchy/get/tree", hierarchyHandler.GetHierarchyTreeHandler, middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	staffHandler := handlers.NewStaffHandler(repository.NewStaffRepo(db))
+
+	staffGroup := e.Group("/staff", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	staffGroup.POST("/create", staffHandler.CreateStaffHandler, middleware.StaffRoleMiddleware("head", "manager"))
+	staffGroup.POST("/deactivate", staffHandler.DeactivateStaffHandler, middleware.StaffRoleMiddleware("head", "manager"))
+	staffGroup.GET("/get/all", staffHandler.GetStaffHandler)
+	staffGroup.POST("/assign/issue", staffHandler.AssignIssueHandler)
+	staffGroup.GET("/get/issues", staffHandler.GetStaffIssuesHandler)
+	staffGroup.GET("/get/activity", staffHandler.GetStaffActivityHandler, middleware.StaffRoleMiddleware("head", "manager"))
+
+	delegationHandler := handlers.NewDelegationHandler(repository.NewDelegationRepo(db))
+
+	delegationGroup := e.Group("/delegations", middleware.AuthMiddleware)
+
+	delegationGroup.POST("/create", delegationHandler.CreateDelegationHandler, middleware.RoleMiddleware("branch_head", "department_head"))
+	delegationGroup.POST("/revoke", delegationHandler.RevokeDelegationHandler)
+	delegationGroup.GET("/get/all", delegationHandler.GetDelegationsHandler)
+	delegationGroup.GET("/get/activity", delegationHandler.GetDelegationActivityHandler)
+
+	invitationHandler := handlers.NewInvitationHandler(repository.NewInvitationRepo(db))
+
+	invitationGroup := e.Group("/invitations", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	invitationGroup.GET("/get/pending", invitationHandler.GetPendingInvitationsHandler)
+	invitationGroup.POST("/resend", invitationHandler.ResendInvitationHandler)
+	invitationGroup.POST("/revoke", invitationHandler.RevokeInvitationHandler)
+
+	e.POST("/invitation/accept", invitationHandler.AcceptInvitationHandler)
+
+	roleHandler := handlers.NewRoleHandler(repository.NewRoleRepo(db))
+
+	roleGroup := e.Group("/roles", middleware.AuthMiddleware, middleware.RoleMiddleware("organization", "super_admin", "branch_head", "department_head", "warehouses"))
+
+	roleGroup.GET("/get/permissions", roleHandler.GetPermissionsHandler)
+	roleGroup.GET("/get/all", roleHandler.GetRolesHandler)
+	roleGroup.GET("/get/my/permissions", roleHandler.GetMyPermissionsHandler)
+	roleGroup.POST("/create", roleHandler.CreateRoleHandler)
+	roleGroup.PUT("/update/permissions", roleHandler.UpdateRolePermissionsHandler)
+	roleGroup.DELETE("/delete", roleHandler.DeleteRoleHandler)
+	roleGroup.POST("/bind", roleHandler.BindRoleHandler)
+	roleGroup.POST("/unbind", roleHandler.UnbindRoleHandler)
+	roleGroup.GET("/get/bindings", roleHandler.GetRoleBindingsHandler)
+
 	excelHandler := handlers.NewExcelHandler(repository.NewExcelRepo(db))
 
 	e.GET("/excel/download/component/maintainance/report", excelHandler.DownloadComponentMaintainanceReportHandler) //
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type AnalyticsHandler struct {
+	AnalyticsRepo models.AnalyticsInterface
+}
+
+func NewAnalyticsHandler(analyticsRepo models.AnalyticsInterface) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		AnalyticsRepo: analyticsRepo,
+	}
+}
+
+func (ah *AnalyticsHandler) GetCostByComponentHandler(e echo.Context) error {
+	status, costs, err := ah.AnalyticsRepo.GetCostByComponent(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"components": costs,
+		"total":      len(costs),
+	})
+}
+
+func (ah *AnalyticsHandler) GetCostByWarehouseHandler(e echo.Context) error {
+	status, costs, err := ah.AnalyticsRepo.GetCostByWarehouse(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"warehouses": costs,
+		"total":      len(costs),
+	})
+}
+
+func (ah *AnalyticsHandler) GetCostByDepartmentHandler(e echo.Context) error {
+	status, costs, err := ah.AnalyticsRepo.GetCostByDepartment(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"departments": costs,
+		"total":       len(costs),
+	})
+}
+
+func (ah *AnalyticsHandler) GetCostByPeriodHandler(e echo.Context) error {
+	status, costs, err := ah.AnalyticsRepo.GetCostByPeriod(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"periods": costs,
+		"total":   len(costs),
+	})
+}
+
+func (ah *AnalyticsHandler) GetTopMaintenanceUnitsHandler(e echo.Context) error {
+	status, units, err := ah.AnalyticsRepo.GetTopMaintenanceUnits(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"units": units,
+		"total": len(units),
+	})
+}
+
+func (ah *AnalyticsHandler) GetBranchComponentCostComparisonHandler(e echo.Context) error {
+	status, costs, err := ah.AnalyticsRepo.GetBranchComponentCostComparison(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"components": costs,
+		"total":      len(costs),
+	})
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type AttributeHandler struct {
+	AttributeRepo models.AttributeInterface
+}
+
+func NewAttributeHandler(attributeRepo models.AttributeInterface) *AttributeHandler {
+	return &AttributeHandler{
+		AttributeRepo: attributeRepo,
+	}
+}
+
+func (ah *AttributeHandler) CreateAttributeDefinitionHandler(e echo.Context) error {
+	status, id, err := ah.AttributeRepo.CreateAttributeDefinition(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":       "successfull",
+		"definition_id": id,
+	})
+}
+
+func (ah *AttributeHandler) GetAttributeDefinitionsHandler(e echo.Context) error {
+	status, definitions, err := ah.AttributeRepo.GetAttributeDefinitions(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"definitions": definitions,
+		"total":       len(definitions),
+	})
+}
+
+func (ah *AttributeHandler) DeleteAttributeDefinitionHandler(e echo.Context) error {
+	status, err := ah.AttributeRepo.DeleteAttributeDefinition(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
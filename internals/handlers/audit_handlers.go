@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type AuditHandler struct {
+	AuditRepo models.AuditInterface
+}
+
+func NewAuditHandler(auditRepo models.AuditInterface) *AuditHandler {
+	return &AuditHandler{
+		AuditRepo: auditRepo,
+	}
+}
+
+func (ah *AuditHandler) CreateAuditSessionHandler(e echo.Context) error {
+	status, id, err := ah.AuditRepo.CreateAuditSession(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":    "successfull",
+		"session_id": id,
+	})
+}
+
+func (ah *AuditHandler) RecordSightingHandler(e echo.Context) error {
+	status, sighting, err := ah.AuditRepo.RecordSighting(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":  "successfull",
+		"sighting": sighting,
+	})
+}
+
+func (ah *AuditHandler) GetAuditSessionsHandler(e echo.Context) error {
+	status, sessions, page, err := ah.AuditRepo.GetAuditSessions(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"sessions": sessions,
+		"meta":     page,
+	})
+}
+
+func (ah *AuditHandler) GetAuditReportHandler(e echo.Context) error {
+	status, report, err := ah.AuditRepo.GetAuditReport(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"report": report,
+	})
+}
+
+func (ah *AuditHandler) CloseAuditSessionHandler(e echo.Context) error {
+	status, report, err := ah.AuditRepo.CloseAuditSession(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+		"report":  report,
+	})
+}
@@ -76,3 +76,25 @@ func (bh *BranchHandler) DeleteWarehouseHandler(e echo.Context) error {
 		"message": "successfull",
 	})
 }
+
+func (bh *BranchHandler) PreviewDeleteDepartmentHandler(e echo.Context) error {
+	status, impact, err := bh.BranchRepo.PreviewDeleteDepartment(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"impact": impact,
+	})
+}
+
+func (bh *BranchHandler) PreviewDeleteWarehouseHandler(e echo.Context) error {
+	status, impact, err := bh.BranchRepo.PreviewDeleteWarehouse(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"impact": impact,
+	})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type CatalogueHandler struct {
+	CatalogueRepo models.CatalogueInterface
+}
+
+func NewCatalogueHandler(catalogueRepo models.CatalogueInterface) *CatalogueHandler {
+	return &CatalogueHandler{
+		CatalogueRepo: catalogueRepo,
+	}
+}
+
+func (ch *CatalogueHandler) CreateCategoryHandler(e echo.Context) error {
+	status, id, err := ch.CatalogueRepo.CreateCategory(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":     "successfull",
+		"category_id": id,
+	})
+}
+
+func (ch *CatalogueHandler) GetCategoriesHandler(e echo.Context) error {
+	status, categories, err := ch.CatalogueRepo.GetCategories(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"categories": categories,
+		"total":      len(categories),
+	})
+}
+
+func (ch *CatalogueHandler) CreateCatalogueModelHandler(e echo.Context) error {
+	status, id, err := ch.CatalogueRepo.CreateCatalogueModel(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":      "successfull",
+		"catalogue_id": id,
+	})
+}
+
+func (ch *CatalogueHandler) GetCatalogueHandler(e echo.Context) error {
+	status, catalogue, page, err := ch.CatalogueRepo.GetCatalogue(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"catalogue": catalogue,
+		"meta":      page,
+	})
+}
+
+func (ch *CatalogueHandler) GetCatalogueStockHandler(e echo.Context) error {
+	status, stock, err := ch.CatalogueRepo.GetCatalogueStock(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"stock": stock,
+		"total": len(stock),
+	})
+}
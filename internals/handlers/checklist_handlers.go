@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type ChecklistHandler struct {
+	ChecklistRepo models.ChecklistInterface
+}
+
+func NewChecklistHandler(checklistRepo models.ChecklistInterface) *ChecklistHandler {
+	return &ChecklistHandler{
+		ChecklistRepo: checklistRepo,
+	}
+}
+
+func (ch *ChecklistHandler) CreateOnboardingTemplateHandler(e echo.Context) error {
+	status, id, err := ch.ChecklistRepo.CreateOnboardingTemplate(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":     "successfull",
+		"template_id": id,
+	})
+}
+
+func (ch *ChecklistHandler) GetOnboardingTemplatesHandler(e echo.Context) error {
+	status, templates, err := ch.ChecklistRepo.GetOnboardingTemplates(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"templates": templates,
+	})
+}
+
+func (ch *ChecklistHandler) DeleteOnboardingTemplateHandler(e echo.Context) error {
+	status, err := ch.ChecklistRepo.DeleteOnboardingTemplate(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (ch *ChecklistHandler) StartOnboardingHandler(e echo.Context) error {
+	status, checklist, err := ch.ChecklistRepo.StartOnboarding(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":   "successfull",
+		"checklist": checklist.Checklist,
+		"items":     checklist.Items,
+	})
+}
+
+func (ch *ChecklistHandler) StartOffboardingHandler(e echo.Context) error {
+	status, checklist, err := ch.ChecklistRepo.StartOffboarding(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":   "successfull",
+		"checklist": checklist.Checklist,
+		"items":     checklist.Items,
+	})
+}
+
+func (ch *ChecklistHandler) GetChecklistsHandler(e echo.Context) error {
+	status, checklists, page, err := ch.ChecklistRepo.GetChecklists(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"checklists": checklists,
+		"meta":       page,
+	})
+}
+
+func (ch *ChecklistHandler) GetChecklistDetailsHandler(e echo.Context) error {
+	status, checklist, err := ch.ChecklistRepo.GetChecklistDetails(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"checklist": checklist.Checklist,
+		"items":     checklist.Items,
+	})
+}
+
+func (ch *ChecklistHandler) ResolveChecklistItemHandler(e echo.Context) error {
+	status, checklist, err := ch.ChecklistRepo.ResolveChecklistItem(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":   "successfull",
+		"checklist": checklist.Checklist,
+		"items":     checklist.Items,
+	})
+}
+
+func (ch *ChecklistHandler) CancelChecklistHandler(e echo.Context) error {
+	status, err := ch.ChecklistRepo.CancelChecklist(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
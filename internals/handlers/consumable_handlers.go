@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type ConsumableHandler struct {
+	ConsumableRepo models.ConsumableInterface
+}
+
+func NewConsumableHandler(consumableRepo models.ConsumableInterface) *ConsumableHandler {
+	return &ConsumableHandler{
+		ConsumableRepo: consumableRepo,
+	}
+}
+
+func (ch *ConsumableHandler) StockInHandler(e echo.Context) error {
+	status, entry, err := ch.ConsumableRepo.StockIn(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+		"entry":   entry,
+	})
+}
+
+func (ch *ConsumableHandler) IssueHandler(e echo.Context) error {
+	status, entry, err := ch.ConsumableRepo.Issue(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+		"entry":   entry,
+	})
+}
+
+func (ch *ConsumableHandler) AdjustHandler(e echo.Context) error {
+	status, entry, err := ch.ConsumableRepo.Adjust(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+		"entry":   entry,
+	})
+}
+
+func (ch *ConsumableHandler) GetConsumableLedgerHandler(e echo.Context) error {
+	status, ledger, page, err := ch.ConsumableRepo.GetConsumableLedger(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"ledger": ledger,
+		"meta":   page,
+	})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type CustodianHandler struct {
+	CustodianRepo models.CustodianInterface
+}
+
+func NewCustodianHandler(custodianRepo models.CustodianInterface) *CustodianHandler {
+	return &CustodianHandler{
+		CustodianRepo: custodianRepo,
+	}
+}
+
+func (ch *CustodianHandler) CreateCustodianHandler(e echo.Context) error {
+	status, id, err := ch.CustodianRepo.CreateCustodian(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":      "successfull",
+		"custodian_id": id,
+	})
+}
+
+func (ch *CustodianHandler) GetCustodiansHandler(e echo.Context) error {
+	status, custodians, page, err := ch.CustodianRepo.GetCustodians(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"custodians": custodians,
+		"meta":       page,
+	})
+}
+
+func (ch *CustodianHandler) DeactivateCustodianHandler(e echo.Context) error {
+	status, err := ch.CustodianRepo.DeactivateCustodian(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (ch *CustodianHandler) AssignCustodyHandler(e echo.Context) error {
+	status, id, err := ch.CustodianRepo.AssignCustody(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":    "successfull",
+		"custody_id": id,
+	})
+}
+
+func (ch *CustodianHandler) ReturnCustodyHandler(e echo.Context) error {
+	status, err := ch.CustodianRepo.ReturnCustody(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (ch *CustodianHandler) AcknowledgeCustodyHandler(e echo.Context) error {
+	status, err := ch.CustodianRepo.AcknowledgeCustody(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (ch *CustodianHandler) GetCustodianHoldingsHandler(e echo.Context) error {
+	status, holdings, err := ch.CustodianRepo.GetCustodianHoldings(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"custodian": holdings.Custodian,
+		"holdings":  holdings.Holdings,
+	})
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type DashboardHandler struct {
+	DashboardRepo models.DashboardInterface
+}
+
+func NewDashboardHandler(dashboardRepo models.DashboardInterface) *DashboardHandler {
+	return &DashboardHandler{
+		DashboardRepo: dashboardRepo,
+	}
+}
+
+func (dh *DashboardHandler) GetDashboardHandler(e echo.Context) error {
+	status, dashboard, err := dh.DashboardRepo.GetDashboard(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"dashboard": dashboard,
+	})
+}
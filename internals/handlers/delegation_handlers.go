@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type DelegationHandler struct {
+	DelegationRepo models.DelegationInterface
+}
+
+func NewDelegationHandler(delegationRepo models.DelegationInterface) *DelegationHandler {
+	return &DelegationHandler{
+		DelegationRepo: delegationRepo,
+	}
+}
+
+func (dh *DelegationHandler) CreateDelegationHandler(e echo.Context) error {
+	status, delegation_id, err := dh.DelegationRepo.CreateDelegation(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":       "successfull",
+		"delegation_id": delegation_id,
+	})
+}
+
+func (dh *DelegationHandler) RevokeDelegationHandler(e echo.Context) error {
+	status, err := dh.DelegationRepo.RevokeDelegation(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (dh *DelegationHandler) GetDelegationsHandler(e echo.Context) error {
+	status, delegations, page, err := dh.DelegationRepo.GetDelegations(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"delegations": delegations,
+		"meta":        page,
+	})
+}
+
+func (dh *DelegationHandler) GetDelegationActivityHandler(e echo.Context) error {
+	status, activity, page, err := dh.DelegationRepo.GetDelegationActivity(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"activity": activity,
+		"meta":     page,
+	})
+}
@@ -1,8 +1,6 @@
 package handlers
 
 import (
-	"math"
-
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
 	"github.com/labstack/echo/v4"
 )
@@ -18,70 +16,50 @@ func NewDetailsHandler(detailsRepo models.DetailsInterface) *DetailsHandler {
 }
 
 func (dh *DetailsHandler) GetAllDepartmentsHandler(e echo.Context) error {
-	Departments, Status, Total, Page, Limit, err := dh.DetailsRepo.GetAllDepartmentsRepo(e)
+	Departments, Status, Page, err := dh.DetailsRepo.GetAllDepartmentsRepo(e)
 	if err != nil {
 		return echo.NewHTTPError(Status, err)
 	}
 
 	return e.JSON(Status, echo.Map{
 		"departments": Departments,
-		"meta": echo.Map{
-			"total": Total,
-			"page":  Page,
-			"limit": Limit,
-			"pages": int(math.Ceil(float64(Total) / float64(Limit))),
-		},
+		"meta":        Page,
 	})
 }
 
 func (dh *DetailsHandler) GetDepartmentIssuesHandler(e echo.Context) error {
-	Status, Issues, Total, Page, Limit, err := dh.DetailsRepo.GetDepartmentIssues(e)
+	Status, Issues, Page, err := dh.DetailsRepo.GetDepartmentIssues(e)
 	if err != nil {
 		return echo.NewHTTPError(Status, err)
 	}
 
 	return e.JSON(Status, echo.Map{
 		"issues": Issues,
-		"meta": echo.Map{
-			"total": Total,
-			"page":  Page,
-			"limit": Limit,
-			"pages": int(math.Ceil(float64(Total) / float64(Limit))),
-		},
+		"meta":   Page,
 	})
 }
 
 func (dh *DetailsHandler) GetDepartmentWorkspacesHandler(e echo.Context) error {
-	Workspaces, Status, Total, Page, Limit, err := dh.DetailsRepo.GetDepartmentWorkspaces(e)
+	Workspaces, Status, Page, err := dh.DetailsRepo.GetDepartmentWorkspaces(e)
 	if err != nil {
 		return echo.NewHTTPError(Status, err)
 	}
 
 	return e.JSON(Status, echo.Map{
 		"workspaces": Workspaces,
-		"meta": echo.Map{
-			"total": Total,
-			"page":  Page,
-			"limit": Limit,
-			"pages": int(math.Ceil(float64(Total) / float64(Limit))),
-		},
+		"meta":       Page,
 	})
 }
 
 func (db *DetailsHandler) GetAllBranchesHandler(e echo.Context) error {
-	Branches, Status, Total, Page, Limit, err := db.DetailsRepo.GetAllBranchesUnderSuperAdmin(e)
+	Branches, Status, Page, err := db.DetailsRepo.GetAllBranchesUnderSuperAdmin(e)
 	if err != nil {
 		return echo.NewHTTPError(Status, err)
 	}
 
 	return e.JSON(Status, echo.Map{
 		"branches": Branches,
-		"meta": echo.Map{
-			"total": Total,
-			"page":  Page,
-			"limit": Limit,
-			"pages": int(math.Ceil(float64(Total) / float64(Limit))),
-		},
+		"meta":     Page,
 	})
 }
 
@@ -98,35 +76,91 @@ func (db *DetailsHandler) GetAllWarehousesHandler(e echo.Context) error {
 }
 
 func (detailsHandler DetailsHandler) GetAllDepartmentOutOfWarentyUnitsHandler(e echo.Context) error {
-	status, OutOfWarentyUnits, total, Limit, Page, err := detailsHandler.DetailsRepo.GetAllOutOfWarentyUnitsInDepartment(e)
+	status, OutOfWarentyUnits, Page, err := detailsHandler.DetailsRepo.GetAllOutOfWarentyUnitsInDepartment(e)
 	if err != nil {
 		return echo.NewHTTPError(status, err)
 	}
 
 	return e.JSON(status, echo.Map{
 		"outOfWarentyUnits": OutOfWarentyUnits,
-		"meta": echo.Map{
-			"total": total,
-			"page":  Page,
-			"limit": Limit,
-			"pages": int(math.Ceil(float64(total) / float64(Limit))),
-		},
+		"meta":              Page,
 	})
 }
 
 func (detailsHandler DetailsHandler) GetAllOutOfWarentyUnitsInWarehouseHandler(e echo.Context) error {
-	status, OutOfWarentyUnits, total, Limit, Page, err := detailsHandler.DetailsRepo.GetAllOutOfWarentyUnitsInWarehouse(e)
+	status, OutOfWarentyUnits, Page, err := detailsHandler.DetailsRepo.GetAllOutOfWarentyUnitsInWarehouse(e)
 	if err != nil {
 		return echo.NewHTTPError(status, err)
 	}
 
 	return e.JSON(status, echo.Map{
 		"outOfWarentyUnits": OutOfWarentyUnits,
-		"meta": echo.Map{
-			"total": total,
-			"page":  Page,
-			"limit": Limit,
-			"pages": int(math.Ceil(float64(total) / float64(Limit))),
-		},
+		"meta":              Page,
+	})
+}
+
+func (dh *DetailsHandler) GetOrganizationDetailsHandler(e echo.Context) error {
+	Status, Details, err := dh.DetailsRepo.GetOrganizationDetails(e)
+	if err != nil {
+		return echo.NewHTTPError(Status, err.Error())
+	}
+
+	return e.JSON(Status, echo.Map{
+		"organization": Details,
+	})
+}
+
+func (dh *DetailsHandler) GetSuperAdminDetailsHandler(e echo.Context) error {
+	Status, Details, err := dh.DetailsRepo.GetSuperAdminDetails(e)
+	if err != nil {
+		return echo.NewHTTPError(Status, err.Error())
+	}
+
+	return e.JSON(Status, echo.Map{
+		"super_admin": Details,
+	})
+}
+
+func (dh *DetailsHandler) GetBranchDetailsHandler(e echo.Context) error {
+	Status, Details, err := dh.DetailsRepo.GetBranchDetails(e)
+	if err != nil {
+		return echo.NewHTTPError(Status, err.Error())
+	}
+
+	return e.JSON(Status, echo.Map{
+		"branch": Details,
+	})
+}
+
+func (dh *DetailsHandler) GetDepartmentDetailsHandler(e echo.Context) error {
+	Status, Details, err := dh.DetailsRepo.GetDepartmentDetails(e)
+	if err != nil {
+		return echo.NewHTTPError(Status, err.Error())
+	}
+
+	return e.JSON(Status, echo.Map{
+		"department": Details,
+	})
+}
+
+func (dh *DetailsHandler) GetWarehouseDetailsHandler(e echo.Context) error {
+	Status, Details, err := dh.DetailsRepo.GetWarehouseDetails(e)
+	if err != nil {
+		return echo.NewHTTPError(Status, err.Error())
+	}
+
+	return e.JSON(Status, echo.Map{
+		"warehouse": Details,
+	})
+}
+
+func (dh *DetailsHandler) GetComponentDetailsHandler(e echo.Context) error {
+	Status, Details, err := dh.DetailsRepo.GetComponentDetails(e)
+	if err != nil {
+		return echo.NewHTTPError(Status, err.Error())
+	}
+
+	return e.JSON(Status, echo.Map{
+		"component": Details,
 	})
 }
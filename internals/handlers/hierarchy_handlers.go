@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type HierarchyHandler struct {
+	HierarchyRepo models.HierarchyInterface
+}
+
+func NewHierarchyHandler(hierarchyRepo models.HierarchyInterface) *HierarchyHandler {
+	return &HierarchyHandler{
+		HierarchyRepo: hierarchyRepo,
+	}
+}
+
+func (hh *HierarchyHandler) GetHierarchyTreeHandler(e echo.Context) error {
+	status, tree, delegated, err := hh.HierarchyRepo.GetHierarchyTree(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"tree":      tree,
+		"delegated": delegated,
+	})
+}
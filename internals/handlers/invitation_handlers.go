@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type InvitationHandler struct {
+	InvitationRepo models.InvitationInterface
+}
+
+func NewInvitationHandler(invitationRepo models.InvitationInterface) *InvitationHandler {
+	return &InvitationHandler{
+		InvitationRepo: invitationRepo,
+	}
+}
+
+func (ih *InvitationHandler) AcceptInvitationHandler(e echo.Context) error {
+	status, err := ih.InvitationRepo.AcceptInvitation(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (ih *InvitationHandler) ResendInvitationHandler(e echo.Context) error {
+	status, err := ih.InvitationRepo.ResendInvitation(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (ih *InvitationHandler) RevokeInvitationHandler(e echo.Context) error {
+	status, err := ih.InvitationRepo.RevokeInvitation(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (ih *InvitationHandler) GetPendingInvitationsHandler(e echo.Context) error {
+	status, invitations, page, err := ih.InvitationRepo.GetPendingInvitations(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"invitations": invitations,
+		"meta":        page,
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type JobsHandler struct {
+	JobsRepo models.JobsInterface
+}
+
+func NewJobsHandler(jobsRepo models.JobsInterface) *JobsHandler {
+	return &JobsHandler{
+		JobsRepo: jobsRepo,
+	}
+}
+
+func (jh *JobsHandler) RunJobsHandler(e echo.Context) error {
+	status, runs, err := jh.JobsRepo.RunJobs(e)
+	if err != nil {
+		if runs != nil {
+			return e.JSON(status, echo.Map{
+				"message": err.Error(),
+				"runs":    runs,
+			})
+		}
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"runs": runs,
+	})
+}
+
+func (jh *JobsHandler) GetJobRunsHandler(e echo.Context) error {
+	status, runs, err := jh.JobsRepo.GetJobRuns(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"runs":  runs,
+		"total": len(runs),
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type LoanHandler struct {
+	LoanRepo models.LoanInterface
+}
+
+func NewLoanHandler(loanRepo models.LoanInterface) *LoanHandler {
+	return &LoanHandler{
+		LoanRepo: loanRepo,
+	}
+}
+
+func (lh *LoanHandler) CreateReservationHandler(e echo.Context) error {
+	status, loan, err := lh.LoanRepo.CreateReservation(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+		"loan":    loan,
+	})
+}
+
+func (lh *LoanHandler) CheckoutLoanHandler(e echo.Context) error {
+	status, loan, err := lh.LoanRepo.CheckoutLoan(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+		"loan":    loan,
+	})
+}
+
+func (lh *LoanHandler) ReturnLoanHandler(e echo.Context) error {
+	status, err := lh.LoanRepo.ReturnLoan(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (lh *LoanHandler) CancelLoanHandler(e echo.Context) error {
+	status, err := lh.LoanRepo.CancelLoan(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (lh *LoanHandler) GetLoansHandler(e echo.Context) error {
+	status, loans, page, err := lh.LoanRepo.GetLoans(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"loans": loans,
+		"meta":  page,
+	})
+}
+
+func (lh *LoanHandler) GetLoanCalendarHandler(e echo.Context) error {
+	status, calendar, err := lh.LoanRepo.GetLoanCalendar(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"calendar": calendar,
+	})
+}
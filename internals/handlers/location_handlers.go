@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type LocationHandler struct {
+	LocationRepo models.LocationInterface
+}
+
+func NewLocationHandler(locationRepo models.LocationInterface) *LocationHandler {
+	return &LocationHandler{
+		LocationRepo: locationRepo,
+	}
+}
+
+func (lh *LocationHandler) CreateLocationHandler(e echo.Context) error {
+	status, id, err := lh.LocationRepo.CreateLocation(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":     "successfull",
+		"location_id": id,
+	})
+}
+
+func (lh *LocationHandler) DeleteLocationHandler(e echo.Context) error {
+	status, err := lh.LocationRepo.DeleteLocation(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (lh *LocationHandler) GetLocationTreeHandler(e echo.Context) error {
+	status, tree, err := lh.LocationRepo.GetLocationTree(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"locations": tree,
+	})
+}
+
+func (lh *LocationHandler) AttachWorkspaceLocationHandler(e echo.Context) error {
+	status, err := lh.LocationRepo.AttachWorkspaceLocation(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (lh *LocationHandler) CreateStorageBinHandler(e echo.Context) error {
+	status, id, err := lh.LocationRepo.CreateStorageBin(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+		"bin_id":  id,
+	})
+}
+
+func (lh *LocationHandler) GetStorageBinsHandler(e echo.Context) error {
+	status, bins, err := lh.LocationRepo.GetStorageBins(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"bins": bins,
+	})
+}
+
+func (lh *LocationHandler) StoreUnitsHandler(e echo.Context) error {
+	status, err := lh.LocationRepo.StoreUnits(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (lh *LocationHandler) GetUnitLocationsHandler(e echo.Context) error {
+	status, units, page, err := lh.LocationRepo.GetUnitLocations(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"units": units,
+		"meta":  page,
+	})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type RestoreHandler struct {
+	RestoreRepo models.RestoreInterface
+}
+
+func NewRestoreHandler(restoreRepo models.RestoreInterface) *RestoreHandler {
+	return &RestoreHandler{
+		RestoreRepo: restoreRepo,
+	}
+}
+
+func (rh *RestoreHandler) GetArchivedEntitiesHandler(e echo.Context) error {
+	status, archived, err := rh.RestoreRepo.GetArchivedEntities(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"archived": archived,
+		"total":    len(archived),
+	})
+}
+
+func (rh *RestoreHandler) restore(e echo.Context, entity_type string) error {
+	status, result, err := rh.RestoreRepo.Restore(e, entity_type)
+	if err != nil {
+		if status == http.StatusConflict {
+			return e.JSON(status, echo.Map{
+				"message": err.Error(),
+				"result":  result,
+			})
+		}
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"result": result,
+	})
+}
+
+func (rh *RestoreHandler) RestoreDepartmentHandler(e echo.Context) error {
+	return rh.restore(e, "department")
+}
+
+func (rh *RestoreHandler) RestoreWorkspaceHandler(e echo.Context) error {
+	return rh.restore(e, "workspace")
+}
+
+func (rh *RestoreHandler) RestoreComponentHandler(e echo.Context) error {
+	return rh.restore(e, "component")
+}
+
+func (rh *RestoreHandler) RestoreUnitHandler(e echo.Context) error {
+	return rh.restore(e, "unit")
+}
+
+func (rh *RestoreHandler) RestoreIssueHandler(e echo.Context) error {
+	return rh.restore(e, "issue")
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type RestructureHandler struct {
+	RestructureRepo models.RestructureInterface
+}
+
+func NewRestructureHandler(restructureRepo models.RestructureInterface) *RestructureHandler {
+	return &RestructureHandler{
+		RestructureRepo: restructureRepo,
+	}
+}
+
+func (rh *RestructureHandler) MoveWorkspaceHandler(e echo.Context) error {
+	status, move, err := rh.RestructureRepo.MoveWorkspace(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+		"move":    move,
+	})
+}
+
+func (rh *RestructureHandler) MoveDepartmentHandler(e echo.Context) error {
+	status, move, err := rh.RestructureRepo.MoveDepartment(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+		"move":    move,
+	})
+}
+
+func (rh *RestructureHandler) GetMovesHandler(e echo.Context) error {
+	status, moves, page, err := rh.RestructureRepo.GetMoves(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"moves": moves,
+		"meta":  page,
+	})
+}
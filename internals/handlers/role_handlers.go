@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type RoleHandler struct {
+	RoleRepo models.RoleInterface
+}
+
+func NewRoleHandler(roleRepo models.RoleInterface) *RoleHandler {
+	return &RoleHandler{
+		RoleRepo: roleRepo,
+	}
+}
+
+func (rh *RoleHandler) GetPermissionsHandler(e echo.Context) error {
+	status, permissions, err := rh.RoleRepo.GetPermissions(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"permissions": permissions,
+	})
+}
+
+func (rh *RoleHandler) GetRolesHandler(e echo.Context) error {
+	status, roles, err := rh.RoleRepo.GetRoles(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"roles": roles,
+	})
+}
+
+func (rh *RoleHandler) CreateRoleHandler(e echo.Context) error {
+	status, role_id, err := rh.RoleRepo.CreateRole(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+		"role_id": role_id,
+	})
+}
+
+func (rh *RoleHandler) UpdateRolePermissionsHandler(e echo.Context) error {
+	status, err := rh.RoleRepo.UpdateRolePermissions(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (rh *RoleHandler) DeleteRoleHandler(e echo.Context) error {
+	status, err := rh.RoleRepo.DeleteRole(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (rh *RoleHandler) BindRoleHandler(e echo.Context) error {
+	status, binding_id, err := rh.RoleRepo.BindRole(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":    "successfull",
+		"binding_id": binding_id,
+	})
+}
+
+func (rh *RoleHandler) UnbindRoleHandler(e echo.Context) error {
+	status, err := rh.RoleRepo.UnbindRole(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (rh *RoleHandler) GetRoleBindingsHandler(e echo.Context) error {
+	status, bindings, page, err := rh.RoleRepo.GetRoleBindings(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"bindings": bindings,
+		"meta":     page,
+	})
+}
+
+func (rh *RoleHandler) GetMyPermissionsHandler(e echo.Context) error {
+	status, permissions, err := rh.RoleRepo.GetMyPermissions(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"permissions": permissions,
+	})
+}
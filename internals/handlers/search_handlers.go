@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type SearchHandler struct {
+	SearchRepo models.SearchInterface
+}
+
+func NewSearchHandler(searchRepo models.SearchInterface) *SearchHandler {
+	return &SearchHandler{
+		SearchRepo: searchRepo,
+	}
+}
+
+func (sh *SearchHandler) SearchHandler(e echo.Context) error {
+	status, results, err := sh.SearchRepo.Search(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"results": results,
+		"total":   len(results),
+	})
+}
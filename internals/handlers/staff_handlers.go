@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/labstack/echo/v4"
+)
+
+type StaffHandler struct {
+	StaffRepo models.StaffInterface
+}
+
+func NewStaffHandler(staffRepo models.StaffInterface) *StaffHandler {
+	return &StaffHandler{
+		StaffRepo: staffRepo,
+	}
+}
+
+func (sh *StaffHandler) CreateStaffHandler(e echo.Context) error {
+	status, staff_id, err := sh.StaffRepo.CreateStaff(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message":  "successfull",
+		"staff_id": staff_id,
+	})
+}
+
+func (sh *StaffHandler) GetStaffHandler(e echo.Context) error {
+	status, staff, page, err := sh.StaffRepo.GetStaff(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"staff": staff,
+		"meta":  page,
+	})
+}
+
+func (sh *StaffHandler) DeactivateStaffHandler(e echo.Context) error {
+	status, err := sh.StaffRepo.DeactivateStaff(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (sh *StaffHandler) AssignIssueHandler(e echo.Context) error {
+	status, err := sh.StaffRepo.AssignIssue(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (sh *StaffHandler) GetStaffIssuesHandler(e echo.Context) error {
+	status, issues, page, err := sh.StaffRepo.GetStaffIssues(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"issues": issues,
+		"meta":   page,
+	})
+}
+
+func (sh *StaffHandler) GetStaffActivityHandler(e echo.Context) error {
+	status, activity, page, err := sh.StaffRepo.GetStaffActivity(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"activity": activity,
+		"meta":     page,
+	})
+}
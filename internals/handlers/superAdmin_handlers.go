@@ -46,3 +46,14 @@ func (sa *SuperAdminHandler) UpdateBranchHeadHandler(e echo.Context) error {
 		"message": "successfull",
 	})
 }
+
+func (sa *SuperAdminHandler) PreviewDeleteBranchHandler(e echo.Context) error {
+	status, impact, err := sa.SuperAdminRepo.PreviewDeleteBranch(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"impact": impact,
+	})
+}
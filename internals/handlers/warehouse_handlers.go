@@ -63,19 +63,14 @@ func (wh *WarehouseHandler) AssignUnitsHandler(e echo.Context) error {
 }
 
 func (wh *WarehouseHandler) GetAllIssuesHandler(e echo.Context) error {
-	status, issues, total, page, limit, err := wh.WarehouseRepo.GetAllWarehouseIssues(e)
+	status, issues, page, err := wh.WarehouseRepo.GetAllWarehouseIssues(e)
 	if err != nil {
 		return echo.NewHTTPError(status, err.Error())
 	}
 
 	return e.JSON(status, echo.Map{
 		"issues": issues,
-		"meta": echo.Map{
-			"total": total,
-			"page":  page,
-			"limit": limit,
-			"pages": int(math.Ceil(float64(total) / float64(limit))),
-		},
+		"meta":   page,
 	})
 }
 
@@ -92,14 +87,14 @@ func (wh *WarehouseHandler) GetAllWarehouseComponentsHandler(e echo.Context) err
 }
 
 func (wh *WarehouseHandler) GetAllWarehouseComponentUnitsHandler(e echo.Context) error {
-	status, units, err := wh.WarehouseRepo.GetAllWarehouseComponentUnits(e)
+	status, units, page, err := wh.WarehouseRepo.GetAllWarehouseComponentUnits(e)
 	if err != nil {
 		return echo.NewHTTPError(status, err.Error())
 	}
 
 	return e.JSON(status, echo.Map{
 		"units": units,
-		"total": len(units),
+		"meta":  page,
 	})
 }
 
@@ -147,6 +142,29 @@ func (wh *WarehouseHandler) UpdateComponentNameHandler(e echo.Context) error {
 	})
 }
 
+func (wh *WarehouseHandler) UpdateComponentPrefixHandler(e echo.Context) error {
+	status, token, err := wh.WarehouseRepo.UpdateComponentPrefix(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+		"token":   token,
+	})
+}
+
+func (wh *WarehouseHandler) UpdateComponentCatalogueHandler(e echo.Context) error {
+	status, err := wh.WarehouseRepo.UpdateComponentCatalogue(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
 func (wh *WarehouseHandler) GetAssignedUnitsHandler(e echo.Context) error {
 	status, units, total, limit, page, err := wh.WarehouseRepo.GetAssignedUnits(e)
 	if err != nil {
@@ -186,6 +204,61 @@ func (wh *WarehouseHandler) UpdateUnitStatusHandler(e echo.Context) error {
 	})
 }
 
+func (wh *WarehouseHandler) UpdateUnitIdentifiersHandler(e echo.Context) error {
+	status, err := wh.WarehouseRepo.UpdateUnitIdentifiers(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (wh *WarehouseHandler) UpdateUnitAttributesHandler(e echo.Context) error {
+	status, err := wh.WarehouseRepo.UpdateUnitAttributes(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (wh *WarehouseHandler) LinkUnitsHandler(e echo.Context) error {
+	status, err := wh.WarehouseRepo.LinkUnits(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (wh *WarehouseHandler) UnlinkUnitHandler(e echo.Context) error {
+	status, err := wh.WarehouseRepo.UnlinkUnit(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"message": "successfull",
+	})
+}
+
+func (wh *WarehouseHandler) GetUnitCompositionHandler(e echo.Context) error {
+	status, composition, err := wh.WarehouseRepo.GetUnitComposition(e)
+	if err != nil {
+		return echo.NewHTTPError(status, err.Error())
+	}
+
+	return e.JSON(status, echo.Map{
+		"unit": composition,
+	})
+}
+
 func (wh *WarehouseHandler) DeleteUnitHandler(e echo.Context) error {
 	status, err := wh.WarehouseRepo.DeleteUnit(e)
 	if err != nil {
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// StaffActivityMiddleware records the write requests made by staff accounts, several people can sign in
+// as the same department or warehouse so this keeps their actions attributable
+func StaffActivityMiddleware(db *sql.DB) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+
+			staffID, _ := c.Get("staffID").(int)
+			if staffID == 0 || c.Request().Method == http.MethodGet {
+				return err
+			}
+
+			if err := database.NewDBinstance(db).RecordStaffActivity(staffID, c.Request().Method, c.Request().URL.Path, responseStatus(c, err)); err != nil {
+				log.Printf("error while recording activity of staff %v: %v", staffID, err)
+			}
+
+			return err
+		}
+	}
+}
+
+// DelegationActivityMiddleware records the write requests a delegate was only authorized for through a delegation,
+// marking them as made on behalf of the delegator
+func DelegationActivityMiddleware(db *sql.DB) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+
+			delegationIDs, _ := c.Get("delegationIDs").([]int)
+			if len(delegationIDs) == 0 || c.Request().Method == http.MethodGet {
+				return err
+			}
+
+			for _, delegationID := range delegationIDs {
+				if err := database.NewDBinstance(db).RecordDelegationActivity(delegationID, c.Request().Method, c.Request().URL.Path, responseStatus(c, err)); err != nil {
+					log.Printf("error while recording activity of delegation %v: %v", delegationID, err)
+				}
+			}
+
+			return err
+		}
+	}
+}
+
+func responseStatus(c echo.Context, err error) int {
+	if he, ok := err.(*echo.HTTPError); ok {
+		return he.Code
+	} else if err != nil {
+		return http.StatusInternalServerError
+	}
+	return c.Response().Status
+}
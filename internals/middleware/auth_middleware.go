@@ -20,6 +20,8 @@ func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		c.Set("userID", claims.UserID)
 		c.Set("userType", claims.UserType)
 		c.Set("userEmail", claims.UserEmail)
+		c.Set("staffID", claims.StaffID)
+		c.Set("staffRole", claims.StaffRole)
 		return next(c)
 	}
 }
@@ -40,3 +42,33 @@ func RoleMiddleware(allowedRoles ...string) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// StaffRoleMiddleware limits staff accounts to the given staff roles, the primary account of a department
+// or warehouse is not a staff account and always passes. Routes without AuthMiddleware verify their token in
+// the handler, the staff claims are read here so the handler never sees a staff role it was not opened to
+func StaffRoleMiddleware(allowedRoles ...string) echo.MiddlewareFunc {
+	roleMap := make(map[string]bool)
+	for _, r := range allowedRoles {
+		roleMap[r] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role, ok := c.Get("staffRole").(string)
+			if !ok {
+				claims, err := utils.ParseToken(c.Request().Header.Get("Authorization"))
+				if err != nil {
+					return next(c)
+				}
+				role = claims.StaffRole
+				c.Set("staffID", claims.StaffID)
+				c.Set("staffRole", claims.StaffRole)
+			}
+
+			if role != "" && !roleMap[role] {
+				return c.JSON(403, echo.Map{"error": "Access denied"})
+			}
+			return next(c)
+		}
+	}
+}
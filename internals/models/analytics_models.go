@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CostModel struct {
+	Units           int     `json:"units"`
+	PurchaseCost    float64 `json:"purchase_cost"`
+	MaintenanceCost float64 `json:"maintenance_cost"`
+	TotalCost       float64 `json:"total_cost"`
+}
+
+type ComponentCostModel struct {
+	ComponentID   int    `json:"component_id"`
+	ComponentName string `json:"component_name"`
+	Prefix        string `json:"prefix"`
+	WarehouseID   int    `json:"warehouse_id"`
+	CostModel
+}
+
+type WarehouseCostModel struct {
+	WarehouseID   int    `json:"warehouse_id"`
+	WarehouseName string `json:"warehouse_name"`
+	CostModel
+}
+
+type DepartmentCostModel struct {
+	DepartmentID   int    `json:"department_id"`
+	DepartmentName string `json:"department_name"`
+	CostModel
+}
+
+type PeriodCostModel struct {
+	Period          time.Time `json:"period"`
+	PurchaseCost    float64   `json:"purchase_cost"`
+	MaintenanceCost float64   `json:"maintenance_cost"`
+	TotalCost       float64   `json:"total_cost"`
+}
+
+type UnitMaintenanceCostModel struct {
+	UnitID          int     `json:"unit_id"`
+	Prefix          string  `json:"prefix"`
+	ComponentID     int     `json:"component_id"`
+	ComponentName   string  `json:"component_name"`
+	WarehouseID     int     `json:"warehouse_id"`
+	DepartmentID    *int    `json:"department_id"`
+	Status          string  `json:"status"`
+	PurchaseCost    float64 `json:"purchase_cost"`
+	MaintenanceCost float64 `json:"maintenance_cost"`
+	ResolvedIssues  int     `json:"resolved_issues"`
+}
+
+type BranchComponentCostModel struct {
+	ComponentName string  `json:"component_name"`
+	BranchID      int     `json:"branch_id"`
+	BranchName    string  `json:"branch_name"`
+	CostPerUnit   float64 `json:"cost_per_unit"`
+	CostModel
+}
+
+type AnalyticsInterface interface {
+	GetCostByComponent(echo.Context) (int, []ComponentCostModel, error)
+	GetCostByWarehouse(echo.Context) (int, []WarehouseCostModel, error)
+	GetCostByDepartment(echo.Context) (int, []DepartmentCostModel, error)
+	GetCostByPeriod(echo.Context) (int, []PeriodCostModel, error)
+	GetTopMaintenanceUnits(echo.Context) (int, []UnitMaintenanceCostModel, error)
+	GetBranchComponentCostComparison(echo.Context) (int, []BranchComponentCostModel, error)
+}
@@ -0,0 +1,41 @@
+package models
+
+import "github.com/labstack/echo/v4"
+
+type AttributeDefinitionModel struct {
+	DefinitionID int      `json:"definition_id"`
+	CategoryID   *int     `json:"category_id"`
+	ComponentID  *int     `json:"component_id"`
+	Key          string   `json:"key"`
+	Label        string   `json:"label"`
+	Type         string   `json:"type"`
+	Options      []string `json:"options"`
+	Required     bool     `json:"required"`
+}
+
+type CreateAttributeDefinitionModel struct {
+	CategoryID  int      `json:"category_id"`
+	ComponentID int      `json:"component_id"`
+	Key         string   `json:"key" validate:"required,max=50"`
+	Label       string   `json:"label" validate:"required,max=100"`
+	Type        string   `json:"type" validate:"required,oneof=string number date enum"`
+	Options     []string `json:"options"`
+	Required    bool     `json:"required"`
+}
+
+type DeleteAttributeDefinitionModel struct {
+	DefinitionID int `json:"definition_id" validate:"required"`
+}
+
+type UpdateUnitAttributesModel struct {
+	WarehouseID int                    `json:"warehouse_id"`
+	UnitID      int                    `json:"unit_id" validate:"required"`
+	ComponentID int                    `json:"component_id" validate:"required"`
+	Attributes  map[string]interface{} `json:"attributes" validate:"required"`
+}
+
+type AttributeInterface interface {
+	CreateAttributeDefinition(echo.Context) (int, int, error)
+	GetAttributeDefinitions(echo.Context) (int, []AttributeDefinitionModel, error)
+	DeleteAttributeDefinition(echo.Context) (int, error)
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CreateAuditSessionModel struct {
+	WarehouseID  int    `json:"warehouse_id"`
+	DepartmentID int    `json:"department_id"`
+	Note         string `json:"note" validate:"max=255"`
+}
+
+type RecordSightingModel struct {
+	SessionID   int    `json:"session_id" validate:"required"`
+	Prefix      string `json:"prefix"`
+	UnitID      int    `json:"unit_id"`
+	Code        string `json:"code" validate:"max=100"`
+	WorkspaceID int    `json:"workspace_id"`
+}
+
+type CloseAuditSessionModel struct {
+	SessionID          int    `json:"session_id" validate:"required"`
+	MissingStatus      string `json:"missing_status" validate:"omitempty,oneof=not_working exit"`
+	RelocateMislocated bool   `json:"relocate_mislocated"`
+}
+
+type AuditSessionModel struct {
+	SessionID     int        `json:"session_id"`
+	WarehouseID   *int       `json:"warehouse_id"`
+	DepartmentID  *int       `json:"department_id"`
+	Status        string     `json:"status"`
+	Note          string     `json:"note"`
+	OpenedByRole  string     `json:"opened_by_role"`
+	OpenedBy      int        `json:"opened_by"`
+	OpenedAt      time.Time  `json:"opened_at"`
+	ClosedAt      *time.Time `json:"closed_at"`
+	Sightings     int        `json:"sightings"`
+	Discrepancies int        `json:"discrepancies"`
+}
+
+type AuditSightingModel struct {
+	SightingID  int       `json:"sighting_id"`
+	Prefix      string    `json:"prefix"`
+	UnitID      *int      `json:"unit_id"`
+	Code        string    `json:"code"`
+	WorkspaceID *int      `json:"workspace_id"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+type AuditDiscrepancyModel struct {
+	ComponentID          *int   `json:"component_id"`
+	Prefix               string `json:"prefix"`
+	UnitID               *int   `json:"unit_id"`
+	Code                 string `json:"code"`
+	Kind                 string `json:"kind"`
+	ExpectedWarehouseID  *int   `json:"expected_warehouse_id"`
+	ExpectedDepartmentID *int   `json:"expected_department_id"`
+	ExpectedWorkspaceID  *int   `json:"expected_workspace_id"`
+	FoundWorkspaceID     *int   `json:"found_workspace_id"`
+	Action               string `json:"action"`
+}
+
+type AuditReportModel struct {
+	Session       AuditSessionModel       `json:"session"`
+	Expected      int                     `json:"expected"`
+	Missing       int                     `json:"missing"`
+	Unexpected    int                     `json:"unexpected"`
+	Mislocated    int                     `json:"mislocated"`
+	Discrepancies []AuditDiscrepancyModel `json:"discrepancies"`
+}
+
+type AuditInterface interface {
+	CreateAuditSession(echo.Context) (int, int, error)
+	RecordSighting(echo.Context) (int, AuditSightingModel, error)
+	GetAuditSessions(echo.Context) (int, []AuditSessionModel, PageModel, error)
+	GetAuditReport(echo.Context) (int, AuditReportModel, error)
+	CloseAuditSession(echo.Context) (int, AuditReportModel, error)
+}
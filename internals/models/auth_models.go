@@ -21,6 +21,8 @@ type UserTokenModel struct {
 	UserEmail string `json:"user_email"`
 	UserName  string `json:"user_name"`
 	UserType  string `json:"user_type"`
+	StaffID   int    `json:"staff_id,omitempty"`
+	StaffRole string `json:"staff_role,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -26,8 +26,9 @@ type CreateBranchModel struct {
 }
 
 type DeleteBranchModel struct {
-	BranchID  int    `json:"branch_id" validate:"required"`
-	BrachName string `json:"branch_name" validate:"required"`
+	BranchID     int    `json:"branch_id" validate:"required"`
+	BrachName    string `json:"branch_name" validate:"required"`
+	PreviewToken string `json:"preview_token"`
 }
 
 type UpdateBranchHeadModel struct {
@@ -58,4 +59,6 @@ type BranchInterface interface {
 	UpdateWarehouseHead(echo.Context) (int, error)
 	DeleteDepartment(echo.Context) (int, error)
 	DeleteWarehouse(echo.Context) (int, error)
+	PreviewDeleteDepartment(echo.Context) (int, DeletionImpactModel, error)
+	PreviewDeleteWarehouse(echo.Context) (int, DeletionImpactModel, error)
 }
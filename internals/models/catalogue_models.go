@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CategoryModel struct {
+	CategoryID int    `json:"category_id"`
+	Name       string `json:"name"`
+	Global     bool   `json:"global"`
+	Models     int    `json:"models"`
+}
+
+type CreateCategoryModel struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+type CatalogueModel struct {
+	CatalogueID   int       `json:"catalogue_id"`
+	CategoryID    int       `json:"category_id"`
+	Category      string    `json:"category"`
+	Name          string    `json:"name"`
+	Manufacturer  string    `json:"manufacturer"`
+	ModelNumber   string    `json:"model_number"`
+	Warehouses    int       `json:"warehouses"`
+	TotalUnits    int       `json:"total_units"`
+	AssignedUnits int       `json:"assigned_units"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type CreateCatalogueModel struct {
+	CategoryID   int    `json:"category_id" validate:"required"`
+	Name         string `json:"name" validate:"required,max=100"`
+	Manufacturer string `json:"manufacturer" validate:"required,max=100"`
+	ModelNumber  string `json:"model_number" validate:"required,max=100"`
+}
+
+type CatalogueStockModel struct {
+	ComponentID   int    `json:"component_id"`
+	ComponentName string `json:"component_name"`
+	Prefix        string `json:"prefix"`
+	WarehouseID   int    `json:"warehouse_id"`
+	TotalUnits    int    `json:"total_units"`
+	AssignedUnits int    `json:"assigned_units"`
+}
+
+type UpdateComponentCatalogueModel struct {
+	WarehouseID int `json:"warehouse_id"`
+	ComponentID int `json:"component_id" validate:"required"`
+	CatalogueID int `json:"catalogue_id"`
+}
+
+type CatalogueInterface interface {
+	CreateCategory(echo.Context) (int, int, error)
+	GetCategories(echo.Context) (int, []CategoryModel, error)
+	CreateCatalogueModel(echo.Context) (int, int, error)
+	GetCatalogue(echo.Context) (int, []CatalogueModel, PageModel, error)
+	GetCatalogueStock(echo.Context) (int, []CatalogueStockModel, error)
+}
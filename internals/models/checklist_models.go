@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OnboardingTemplateItemModel struct {
+	CatalogueID  int    `json:"catalogue_id" validate:"required"`
+	Name         string `json:"name"`
+	Manufacturer string `json:"manufacturer"`
+	ModelNumber  string `json:"model_number"`
+	Quantity     int    `json:"quantity" validate:"required,min=1"`
+}
+
+type CreateOnboardingTemplateModel struct {
+	Name    string                        `json:"name" validate:"required,max=100"`
+	JobRole string                        `json:"job_role" validate:"max=100"`
+	Items   []OnboardingTemplateItemModel `json:"items" validate:"required,min=1,dive"`
+}
+
+type DeleteOnboardingTemplateModel struct {
+	TemplateID int `json:"template_id" validate:"required"`
+}
+
+type OnboardingTemplateModel struct {
+	TemplateID int                           `json:"template_id"`
+	Name       string                        `json:"name"`
+	JobRole    string                        `json:"job_role"`
+	CreatedAt  time.Time                     `json:"created_at"`
+	Items      []OnboardingTemplateItemModel `json:"items"`
+}
+
+type StartOnboardingModel struct {
+	CustodianID int `json:"custodian_id" validate:"required"`
+	TemplateID  int `json:"template_id" validate:"required"`
+	WarehouseID int `json:"warehouse_id" validate:"required"`
+	WorkspaceID int `json:"workspace_id" validate:"required"`
+}
+
+type StartOffboardingModel struct {
+	CustodianID int `json:"custodian_id" validate:"required"`
+}
+
+type ResolveChecklistItemModel struct {
+	ChecklistID int    `json:"checklist_id" validate:"required"`
+	ItemID      int    `json:"item_id" validate:"required"`
+	Outcome     string `json:"outcome" validate:"omitempty,oneof=returned lost"`
+}
+
+type CancelChecklistModel struct {
+	ChecklistID int `json:"checklist_id" validate:"required"`
+}
+
+type ChecklistModel struct {
+	ChecklistID   int        `json:"checklist_id"`
+	DepartmentID  int        `json:"department_id"`
+	CustodianID   int        `json:"custodian_id"`
+	CustodianName string     `json:"custodian_name"`
+	Kind          string     `json:"kind"`
+	TemplateID    *int       `json:"template_id"`
+	WarehouseID   *int       `json:"warehouse_id"`
+	WorkspaceID   *int       `json:"workspace_id"`
+	Status        string     `json:"status"`
+	CreatedByRole string     `json:"created_by_role"`
+	CreatedBy     int        `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	TotalItems    int        `json:"total_items"`
+	PendingItems  int        `json:"pending_items"`
+}
+
+type ChecklistItemModel struct {
+	ItemID        int        `json:"item_id"`
+	ComponentID   int        `json:"component_id"`
+	ComponentName string     `json:"component_name"`
+	WarehouseID   int        `json:"warehouse_id"`
+	Prefix        string     `json:"prefix"`
+	Quantity      int        `json:"quantity"`
+	Fulfilled     int        `json:"fulfilled"`
+	RequestID     *int       `json:"request_id"`
+	UnitID        *int       `json:"unit_id"`
+	CustodyID     *int       `json:"custody_id"`
+	Status        string     `json:"status"`
+	ResolvedAt    *time.Time `json:"resolved_at"`
+}
+
+type ChecklistDetailsModel struct {
+	Checklist ChecklistModel       `json:"checklist"`
+	Items     []ChecklistItemModel `json:"items"`
+}
+
+type ChecklistInterface interface {
+	CreateOnboardingTemplate(echo.Context) (int, int, error)
+	GetOnboardingTemplates(echo.Context) (int, []OnboardingTemplateModel, error)
+	DeleteOnboardingTemplate(echo.Context) (int, error)
+	StartOnboarding(echo.Context) (int, ChecklistDetailsModel, error)
+	StartOffboarding(echo.Context) (int, ChecklistDetailsModel, error)
+	GetChecklists(echo.Context) (int, []ChecklistModel, PageModel, error)
+	GetChecklistDetails(echo.Context) (int, ChecklistDetailsModel, error)
+	ResolveChecklistItem(echo.Context) (int, ChecklistDetailsModel, error)
+	CancelChecklist(echo.Context) (int, error)
+}
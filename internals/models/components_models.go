@@ -11,7 +11,11 @@ type ComponentModel struct {
 }
 
 type CreateComponentModel struct {
+	WarehouseID   int    `json:"warehouse_id"`
 	ComponentName string `json:"component_name" validate:"required"`
+	Prefix        string `json:"prefix"`
+	CatalogueID   int    `json:"catalogue_id"`
+	Kind          string `json:"kind" validate:"omitempty,oneof=unit consumable"`
 }
 
 type ComponentTokenModel struct {
@@ -22,6 +26,7 @@ type ComponentTokenModel struct {
 }
 
 type DeleteComponentModel struct {
+	WarehouseID   int    `json:"warehouse_id"`
 	ComponentID   int    `json:"component_id" validate:"required"`
 	ComponentName string `json:"component_name" validate:"required"`
 	Prefix        string `json:"prefix" validate:"required"`
@@ -31,10 +36,36 @@ type AllWarehouseComponentsModel struct {
 	ComponentID   int    `json:"component_id"`
 	ComponentName string `json:"component_name"`
 	Prefix        string `json:"prefix"`
+	Kind          string `json:"kind"`
 	Units         int    `json:"units"`
+	Quantity      int    `json:"quantity"`
 }
 
 type UpdateComponentNameModel struct {
+	WarehouseID   int    `json:"warehouse_id"`
 	ComponentID   int    `json:"component_id" validate:"required"`
 	ComponentName string `json:"component_name" validate:"required"`
 }
+
+type UpdateComponentPrefixModel struct {
+	WarehouseID int    `json:"warehouse_id"`
+	ComponentID int    `json:"component_id" validate:"required"`
+	Prefix      string `json:"prefix" validate:"required"`
+}
+
+type ComponentDetailsModel struct {
+	ComponentID          int     `json:"component_id"`
+	ComponentName        string  `json:"component_name"`
+	Prefix               string  `json:"prefix"`
+	WarehouseID          int     `json:"warehouse_id"`
+	TotalUnits           int     `json:"total_units"`
+	AssignedUnits        int     `json:"assigned_units"`
+	WorkingUnits         int     `json:"working_units"`
+	RepairUnits          int     `json:"repair_units"`
+	NotWorkingUnits      int     `json:"not_working_units"`
+	ExitUnits            int     `json:"exit_units"`
+	OutOfWarrantyUnits   int     `json:"out_of_warranty_units"`
+	TotalCost            float64 `json:"total_cost"`
+	TotalMaintenanceCost float64 `json:"total_maintenance_cost"`
+	TotalNoOfIssues      int     `json:"total_no_of_issues"`
+}
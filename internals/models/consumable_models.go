@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ConsumableStockInModel struct {
+	ComponentID int    `json:"component_id" validate:"required"`
+	Quantity    int    `json:"quantity" validate:"required,gt=0"`
+	Note        string `json:"note" validate:"max=255"`
+}
+
+type ConsumableIssueModel struct {
+	ComponentID int    `json:"component_id" validate:"required"`
+	WorkspaceID int    `json:"workspace_id"`
+	RequestID   int    `json:"request_id"`
+	Quantity    int    `json:"quantity" validate:"omitempty,gt=0"`
+	Note        string `json:"note" validate:"max=255"`
+}
+
+type ConsumableAdjustmentModel struct {
+	ComponentID int    `json:"component_id" validate:"required"`
+	Quantity    int    `json:"quantity" validate:"required"`
+	Note        string `json:"note" validate:"required,max=255"`
+}
+
+type ConsumableLedgerModel struct {
+	EntryID      int       `json:"entry_id"`
+	ComponentID  int       `json:"component_id"`
+	WarehouseID  int       `json:"warehouse_id"`
+	Type         string    `json:"type"`
+	Quantity     int       `json:"quantity"`
+	Balance      int       `json:"balance"`
+	DepartmentID *int      `json:"department_id"`
+	WorkspaceID  *int      `json:"workspace_id"`
+	RequestID    *int      `json:"request_id"`
+	Note         string    `json:"note"`
+	CreatedBy    int       `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ConsumableInterface interface {
+	StockIn(echo.Context) (int, ConsumableLedgerModel, error)
+	Issue(echo.Context) (int, ConsumableLedgerModel, error)
+	Adjust(echo.Context) (int, ConsumableLedgerModel, error)
+	GetConsumableLedger(echo.Context) (int, []ConsumableLedgerModel, PageModel, error)
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CreateCustodianModel struct {
+	DepartmentID int    `json:"department_id" validate:"required"`
+	Name         string `json:"name" validate:"required,max=100"`
+	Email        string `json:"email" validate:"required,email,max=100"`
+	EmployeeCode string `json:"employee_code" validate:"max=50"`
+}
+
+type DeactivateCustodianModel struct {
+	CustodianID int `json:"custodian_id" validate:"required"`
+}
+
+type AssignCustodyModel struct {
+	CustodianID int    `json:"custodian_id" validate:"required"`
+	ComponentID int    `json:"component_id" validate:"required"`
+	UnitID      int    `json:"unit_id" validate:"required"`
+	WorkspaceID int    `json:"workspace_id"`
+	Note        string `json:"note" validate:"max=255"`
+}
+
+type ReturnCustodyModel struct {
+	ComponentID int `json:"component_id" validate:"required"`
+	UnitID      int `json:"unit_id" validate:"required"`
+}
+
+type AcknowledgeCustodyModel struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type CustodianModel struct {
+	CustodianID   int        `json:"custodian_id"`
+	DepartmentID  int        `json:"department_id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	EmployeeCode  string     `json:"employee_code"`
+	Active        bool       `json:"active"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeactivatedAt *time.Time `json:"deactivated_at"`
+	Holdings      int        `json:"holdings"`
+}
+
+type CustodyModel struct {
+	CustodyID      int        `json:"custody_id"`
+	ComponentID    int        `json:"component_id"`
+	ComponentName  string     `json:"component_name"`
+	Prefix         string     `json:"prefix"`
+	UnitID         int        `json:"unit_id"`
+	SerialNumber   string     `json:"serial_number"`
+	AssetTag       string     `json:"asset_tag"`
+	Status         string     `json:"status"`
+	DepartmentID   *int       `json:"department_id"`
+	WorkspaceID    *int       `json:"workspace_id"`
+	Note           string     `json:"note"`
+	AssignedAt     time.Time  `json:"assigned_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	LinkedUnits    int        `json:"linked_units"`
+}
+
+type CustodianHoldingsModel struct {
+	Custodian CustodianModel `json:"custodian"`
+	Holdings  []CustodyModel `json:"holdings"`
+}
+
+type CustodianInterface interface {
+	CreateCustodian(echo.Context) (int, int, error)
+	GetCustodians(echo.Context) (int, []CustodianModel, PageModel, error)
+	DeactivateCustodian(echo.Context) (int, error)
+	AssignCustody(echo.Context) (int, int, error)
+	ReturnCustody(echo.Context) (int, error)
+	AcknowledgeCustody(echo.Context) (int, error)
+	GetCustodianHoldings(echo.Context) (int, CustodianHoldingsModel, error)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IssueAgeModel struct {
+	UnderAWeek  int `json:"under_a_week"`
+	UnderAMonth int `json:"under_a_month"`
+	OverAMonth  int `json:"over_a_month"`
+}
+
+type DashboardIssuesModel struct {
+	ByStatus map[string]int `json:"by_status"`
+	OpenAge  IssueAgeModel  `json:"open_age"`
+}
+
+type ExpiringWarrantyModel struct {
+	UnitID        int       `json:"unit_id"`
+	Prefix        string    `json:"prefix"`
+	ComponentName string    `json:"component_name"`
+	WarehouseID   int       `json:"warehouse_id"`
+	DepartmentID  *int      `json:"department_id"`
+	WarrantyDate  time.Time `json:"warranty_date"`
+}
+
+type ActivityModel struct {
+	Type        string    `json:"type"`
+	ID          int       `json:"id"`
+	Description string    `json:"description"`
+	At          time.Time `json:"at"`
+}
+
+type DashboardModel struct {
+	Role               string                  `json:"role"`
+	Counts             map[string]int          `json:"counts"`
+	Issues             DashboardIssuesModel    `json:"issues"`
+	PendingRequests    int                     `json:"pending_requests"`
+	UnitsByStatus      map[string]int          `json:"units_by_status"`
+	ExpiringWarranties []ExpiringWarrantyModel `json:"expiring_warranties"`
+	RecentActivity     []ActivityModel         `json:"recent_activity"`
+}
+
+type DashboardInterface interface {
+	GetDashboard(echo.Context) (int, DashboardModel, error)
+}
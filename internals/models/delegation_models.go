@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CreateDelegationModel hands the scope of a branch or department head to another user of the organization
+// for a date range
+type CreateDelegationModel struct {
+	DelegateEmail string    `json:"delegate_email" validate:"required,email"`
+	StartsAt      time.Time `json:"starts_at" validate:"required"`
+	EndsAt        time.Time `json:"ends_at" validate:"required"`
+	Reason        string    `json:"reason" validate:"max=200"`
+}
+
+type RevokeDelegationModel struct {
+	DelegationID int `json:"delegation_id" validate:"required"`
+}
+
+// DelegatedScopeModel is a delegation active for the signed in user
+type DelegatedScopeModel struct {
+	DelegationID   int       `json:"delegation_id"`
+	DelegatorEmail string    `json:"delegator_email"`
+	DelegatorRole  string    `json:"delegator_role"`
+	NodeType       string    `json:"node_type"`
+	NodeID         int       `json:"node_id"`
+	EndsAt         time.Time `json:"ends_at"`
+}
+
+type DelegationModel struct {
+	DelegationID   int        `json:"delegation_id"`
+	DelegatorEmail string     `json:"delegator_email"`
+	DelegatorRole  string     `json:"delegator_role"`
+	DelegateEmail  string     `json:"delegate_email"`
+	NodeType       string     `json:"node_type"`
+	NodeID         int        `json:"node_id"`
+	StartsAt       time.Time  `json:"starts_at"`
+	EndsAt         time.Time  `json:"ends_at"`
+	Reason         string     `json:"reason"`
+	Status         string     `json:"status"`
+	RevokedBy      string     `json:"revoked_by,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	ExpiredAt      *time.Time `json:"expired_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// DelegationActivityModel is a write request a delegate made on behalf of the delegator
+type DelegationActivityModel struct {
+	ActivityID     int       `json:"activity_id"`
+	DelegationID   int       `json:"delegation_id"`
+	DelegatorEmail string    `json:"on_behalf_of"`
+	DelegateEmail  string    `json:"delegate_email"`
+	Method         string    `json:"method"`
+	Path           string    `json:"path"`
+	Status         int       `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type DelegationInterface interface {
+	CreateDelegation(echo.Context) (int, int, error)
+	RevokeDelegation(echo.Context) (int, error)
+	GetDelegations(echo.Context) (int, []DelegationModel, PageModel, error)
+	GetDelegationActivity(echo.Context) (int, []DelegationActivityModel, PageModel, error)
+}
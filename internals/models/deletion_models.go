@@ -0,0 +1,23 @@
+package models
+
+type ImpactModel struct {
+	Count int   `json:"count"`
+	IDs   []int `json:"ids"`
+}
+
+type UnitImpactModel struct {
+	Prefix string `json:"prefix"`
+	Action string `json:"action"`
+	Count  int    `json:"count"`
+	IDs    []int  `json:"ids"`
+}
+
+type DeletionImpactModel struct {
+	EntityType      string                 `json:"entity_type"`
+	EntityID        int                    `json:"entity_id"`
+	Removed         map[string]ImpactModel `json:"removed"`
+	Units           []UnitImpactModel      `json:"units"`
+	OpenIssues      ImpactModel            `json:"open_issues"`
+	PendingRequests ImpactModel            `json:"pending_requests"`
+	Token           string                 `json:"token"`
+}
@@ -9,6 +9,7 @@ type DepartmentModel struct {
 }
 
 type CreateDepartmentModel struct {
+	BranchID            int    `json:"branch_id"`
 	DepartmentName      string `json:"department_name" validate:"required"`
 	DepartmentHeadName  string `json:"department_head_name" validate:"required"`
 	DepartmentHeadEmail string `json:"department_head_email" validate:"required,email"`
@@ -43,6 +44,7 @@ type GetDepartmentWorkspacesModel struct {
 type DeleteDepartmentModel struct {
 	DepartmentID       int    `json:"department_id" validate:"required"`
 	BranchHeadPassword string `json:"branch_head_password" validate:"required"`
+	PreviewToken       string `json:"preview_token"`
 }
 
 type AllRequestsModel struct {
@@ -87,5 +89,19 @@ type GetRequestDetailsModel struct {
 }
 
 type DeleteRequestModel struct {
-	RequestID int `json:"request_id" validate:"required"`
+	RequestID    int `json:"request_id" validate:"required"`
+	DepartmentID int `json:"department_id"`
+}
+
+type DepartmentDetailsModel struct {
+	DepartmentID        int    `json:"department_id"`
+	DepartmentName      string `json:"department_name"`
+	BranchID            int    `json:"branch_id"`
+	DepartmentHeadName  string `json:"department_head_name"`
+	DepartmentHeadEmail string `json:"department_head_email"`
+	NoOfWorkspaces      int    `json:"no_of_workspaces"`
+	TotalAssignedUnits  int    `json:"total_assigned_units"`
+	TotalNoOfIssues     int    `json:"total_no_of_issues"`
+	OpenIssues          int    `json:"open_issues"`
+	PendingRequests     int    `json:"pending_requests"`
 }
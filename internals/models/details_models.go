@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/labstack/echo/v4"
 )
 
@@ -17,13 +19,19 @@ type GetAllDepartmentsModel struct {
 }
 
 type DetailsInterface interface {
-	GetAllDepartmentsRepo(echo.Context) ([]AllDepartmentsModel, int, int, int, int, error)
-	GetDepartmentIssues(echo.Context) (int, []DepartmentIssuesModel, int, int, int, error)
-	GetDepartmentWorkspaces(echo.Context) ([]DepartmentWorkspaceModel, int, int, int, int, error)
-	GetAllBranchesUnderSuperAdmin(echo.Context) ([]AllBranchesModel, int, int, int, int, error)
+	GetAllDepartmentsRepo(echo.Context) ([]AllDepartmentsModel, int, PageModel, error)
+	GetDepartmentIssues(echo.Context) (int, []DepartmentIssuesModel, PageModel, error)
+	GetDepartmentWorkspaces(echo.Context) ([]DepartmentWorkspaceModel, int, PageModel, error)
+	GetAllBranchesUnderSuperAdmin(echo.Context) ([]AllBranchesModel, int, PageModel, error)
 	GetAllWarehouses(echo.Context) ([]AllWarehousesModel, int, error)
-	GetAllOutOfWarentyUnitsInDepartment(echo.Context) (int, []AllOutOfWarentyUnitsModel, int, int, int, error)
-	GetAllOutOfWarentyUnitsInWarehouse(echo.Context) (int, []AllOutOfWarentyWarehouseModel, int, int, int, error)
+	GetAllOutOfWarentyUnitsInDepartment(echo.Context) (int, []AllOutOfWarentyUnitsModel, PageModel, error)
+	GetAllOutOfWarentyUnitsInWarehouse(echo.Context) (int, []AllOutOfWarentyWarehouseModel, PageModel, error)
+	GetOrganizationDetails(echo.Context) (int, OrganizationDetailsModel, error)
+	GetSuperAdminDetails(echo.Context) (int, SuperAdminDetailsModel, error)
+	GetBranchDetails(echo.Context) (int, BranchDetailsModel, error)
+	GetDepartmentDetails(echo.Context) (int, DepartmentDetailsModel, error)
+	GetWarehouseDetails(echo.Context) (int, WarehouseDetailsModel, error)
+	GetComponentDetails(echo.Context) (int, ComponentDetailsModel, error)
 }
 
 type DepartmentWorkspaceModel struct {
@@ -62,13 +70,16 @@ type AllWarehousesModel struct {
 }
 
 type AllComponentUnitsModel struct {
-	UnitID          int     `json:"unit_id"`
-	WarehouseID     int     `json:"warehouse_id"`
-	WarrantyDate    int     `json:"warranty_date"`
-	Status          string  `json:"status"`
-	Assigned        bool    `json:"assigned"`
-	Cost            float32 `json:"cost"`
-	MaintenanceCost float32 `json:"maintenance_cost"`
+	UnitID          int                    `json:"unit_id"`
+	WarehouseID     int                    `json:"warehouse_id"`
+	WarrantyDate    time.Time              `json:"warranty_date"`
+	Status          string                 `json:"status"`
+	Assigned        bool                   `json:"assigned"`
+	Cost            float32                `json:"cost"`
+	MaintenanceCost float32                `json:"maintenance_cost"`
+	SerialNumber    string                 `json:"serial_number"`
+	AssetTag        string                 `json:"asset_tag"`
+	Attributes      map[string]interface{} `json:"attributes"`
 }
 
 type GetAllOutOfWarentyUnitsModel struct {
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/labstack/echo/v4"
 	"github.com/xuri/excelize/v2"
 )
@@ -10,14 +12,15 @@ type DownloadComponentMaintainanceReportRequest struct {
 }
 
 type ExcelMaintenanceReportModel struct {
-	UnitID              int    `json:"unit_id"`
-	WarrantyDate        int64  `json:"warranty_date"`
-	Status              string `json:"status"`
-	Cost                int    `json:"cost"`
-	MaintenanceCost     int    `json:"maintainance_cost"`
-	LastMaintenanceDate int64  `json:"last_maintenance_date"`
-	DepartmentID        string `json:"department_id"`
-	WorkspaceID         string `json:"workspace_id"`
+	UnitID              int                    `json:"unit_id"`
+	WarrantyDate        time.Time              `json:"warranty_date"`
+	Status              string                 `json:"status"`
+	Cost                float64                `json:"cost"`
+	MaintenanceCost     float64                `json:"maintainance_cost"`
+	LastMaintenanceDate *time.Time             `json:"last_maintenance_date"`
+	DepartmentID        string                 `json:"department_id"`
+	WorkspaceID         string                 `json:"workspace_id"`
+	Attributes          map[string]interface{} `json:"attributes"`
 }
 
 type ExcelInterface interface {
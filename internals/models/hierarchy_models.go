@@ -0,0 +1,24 @@
+package models
+
+import "github.com/labstack/echo/v4"
+
+type HierarchyHeadModel struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// HierarchyNodeModel is one node of the organization chart, units counts the units held in the node's
+// subtree: assigned ones where they are assigned and the rest in their warehouse
+type HierarchyNodeModel struct {
+	Type     string               `json:"type"`
+	ID       int                  `json:"id"`
+	Name     string               `json:"name"`
+	Head     *HierarchyHeadModel  `json:"head"`
+	Units    int                  `json:"units"`
+	Children []HierarchyNodeModel `json:"children,omitempty"`
+}
+
+type HierarchyInterface interface {
+	GetHierarchyTree(echo.Context) (int, HierarchyNodeModel, []HierarchyNodeModel, error)
+}
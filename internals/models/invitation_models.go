@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AcceptInvitationModel sets the first password of an invited account, the token comes from the invitation link
+type AcceptInvitationModel struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ResendInvitationModel struct {
+	InvitationID int `json:"invitation_id" validate:"required"`
+}
+
+type RevokeInvitationModel struct {
+	InvitationID int `json:"invitation_id" validate:"required"`
+}
+
+// InvitationModel is an invitation not yet accepted or revoked, status is pending or expired
+type InvitationModel struct {
+	InvitationID int       `json:"invitation_id"`
+	UserEmail    string    `json:"user_email"`
+	Name         string    `json:"name"`
+	UserLevel    string    `json:"user_level"`
+	Status       string    `json:"status"`
+	SentCount    int       `json:"sent_count"`
+	LastSentAt   time.Time `json:"last_sent_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// NewInvitationModel is stored in the same transaction as the account it invites, TokenHash is the hash of the
+// emailed token
+type NewInvitationModel struct {
+	Name          string
+	UserLevel     string
+	CreatedByRole string
+	CreatedBy     int
+	TokenHash     string
+	ExpiresAt     time.Time
+}
+
+type InvitationInterface interface {
+	AcceptInvitation(echo.Context) (int, error)
+	ResendInvitation(echo.Context) (int, error)
+	RevokeInvitation(echo.Context) (int, error)
+	GetPendingInvitations(echo.Context) (int, []InvitationModel, PageModel, error)
+}
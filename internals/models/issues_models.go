@@ -41,10 +41,12 @@ type IssueDetailsModel struct {
 }
 
 type UpdateIssueStatusModel struct {
-	IssueID int    `json:"issue_id" validate:"required"`
-	Status  string `json:"status" validate:"required"`
+	WarehouseID int    `json:"warehouse_id"`
+	IssueID     int    `json:"issue_id" validate:"required"`
+	Status      string `json:"status" validate:"required"`
 }
 
 type DeleteIssueModel struct {
-	IssueID int `json:"issue_id" validate:"required"`
+	IssueID      int `json:"issue_id" validate:"required"`
+	DepartmentID int `json:"department_id"`
 }
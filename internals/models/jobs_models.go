@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type JobRunModel struct {
+	ID         int              `json:"id"`
+	Job        string           `json:"job"`
+	Trigger    string           `json:"trigger"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+	Purged     map[string]int64 `json:"purged"`
+	Error      string           `json:"error,omitempty"`
+}
+
+type JobsInterface interface {
+	RunJobs(echo.Context) (int, []JobRunModel, error)
+	GetJobRuns(echo.Context) (int, []JobRunModel, error)
+}
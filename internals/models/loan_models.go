@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CreateReservationModel struct {
+	ComponentID int       `json:"component_id" validate:"required"`
+	UnitID      int       `json:"unit_id"`
+	Quantity    int       `json:"quantity" validate:"min=0"`
+	CustodianID int       `json:"custodian_id" validate:"required"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Note        string    `json:"note" validate:"max=255"`
+}
+
+type CheckoutLoanModel struct {
+	LoanID  int   `json:"loan_id" validate:"required"`
+	UnitIDs []int `json:"unit_ids"`
+}
+
+type LoanActionModel struct {
+	LoanID int `json:"loan_id" validate:"required"`
+}
+
+type LoanModel struct {
+	LoanID        int        `json:"loan_id"`
+	WarehouseID   int        `json:"warehouse_id"`
+	ComponentID   int        `json:"component_id"`
+	ComponentName string     `json:"component_name"`
+	Prefix        string     `json:"prefix"`
+	UnitID        *int       `json:"unit_id"`
+	Quantity      int        `json:"quantity"`
+	CustodianID   int        `json:"custodian_id"`
+	CustodianName string     `json:"custodian_name"`
+	StartsAt      time.Time  `json:"starts_at"`
+	EndsAt        time.Time  `json:"ends_at"`
+	Status        string     `json:"status"`
+	Overdue       bool       `json:"overdue"`
+	Note          string     `json:"note"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CheckedOutAt  *time.Time `json:"checked_out_at"`
+	ReturnedAt    *time.Time `json:"returned_at"`
+	Reminders     int        `json:"reminders"`
+	UnitIDs       []int      `json:"unit_ids"`
+}
+
+type LoanDayModel struct {
+	Date      string `json:"date"`
+	Reserved  int    `json:"reserved"`
+	Available int    `json:"available"`
+}
+
+type LoanCalendarComponentModel struct {
+	ComponentID   int            `json:"component_id"`
+	ComponentName string         `json:"component_name"`
+	Prefix        string         `json:"prefix"`
+	Pool          int            `json:"pool"`
+	Days          []LoanDayModel `json:"days"`
+	Loans         []LoanModel    `json:"loans"`
+}
+
+type LoanReminderModel struct {
+	LoanID         int
+	CustodianName  string
+	CustodianEmail string
+	ComponentName  string
+	Prefix         string
+	Quantity       int
+	DueAt          time.Time
+}
+
+type LoanInterface interface {
+	CreateReservation(echo.Context) (int, LoanModel, error)
+	CheckoutLoan(echo.Context) (int, LoanModel, error)
+	ReturnLoan(echo.Context) (int, error)
+	CancelLoan(echo.Context) (int, error)
+	GetLoans(echo.Context) (int, []LoanModel, PageModel, error)
+	GetLoanCalendar(echo.Context) (int, []LoanCalendarComponentModel, error)
+}
@@ -0,0 +1,83 @@
+package models
+
+import "github.com/labstack/echo/v4"
+
+type CreateLocationModel struct {
+	BranchID int    `json:"branch_id" validate:"required"`
+	ParentID int    `json:"parent_id"`
+	Kind     string `json:"kind" validate:"required,oneof=site building floor room"`
+	Name     string `json:"name" validate:"required,max=100"`
+}
+
+type DeleteLocationModel struct {
+	LocationID int `json:"location_id" validate:"required"`
+}
+
+type AttachWorkspaceLocationModel struct {
+	WorkspaceID int `json:"workspace_id" validate:"required"`
+	LocationID  int `json:"location_id"`
+}
+
+type CreateStorageBinModel struct {
+	WarehouseID int    `json:"warehouse_id"`
+	LocationID  int    `json:"location_id"`
+	Name        string `json:"name" validate:"required,max=50"`
+}
+
+type StoreUnitsModel struct {
+	BinID       int   `json:"bin_id" validate:"required"`
+	ComponentID int   `json:"component_id" validate:"required"`
+	UnitIDs     []int `json:"unit_ids" validate:"required,min=1"`
+}
+
+type LocationNodeModel struct {
+	LocationID int                 `json:"location_id"`
+	ParentID   *int                `json:"parent_id"`
+	Kind       string              `json:"kind"`
+	Name       string              `json:"name"`
+	Workspaces int                 `json:"workspaces"`
+	Bins       int                 `json:"bins"`
+	Units      int                 `json:"units"`
+	TotalUnits int                 `json:"total_units"`
+	Children   []LocationNodeModel `json:"children"`
+}
+
+type LocationTreeModel struct {
+	BranchID int                 `json:"branch_id"`
+	Units    int                 `json:"units"`
+	Unplaced int                 `json:"unplaced"`
+	Tree     []LocationNodeModel `json:"tree"`
+}
+
+type StorageBinModel struct {
+	BinID        int    `json:"bin_id"`
+	WarehouseID  int    `json:"warehouse_id"`
+	LocationID   *int   `json:"location_id"`
+	LocationPath string `json:"location_path"`
+	Name         string `json:"name"`
+	Units        int    `json:"units"`
+}
+
+type UnitLocationModel struct {
+	ComponentID   int    `json:"component_id"`
+	ComponentName string `json:"component_name"`
+	Prefix        string `json:"prefix"`
+	UnitID        int    `json:"unit_id"`
+	WarehouseID   int    `json:"warehouse_id"`
+	DepartmentID  *int   `json:"department_id"`
+	WorkspaceID   *int   `json:"workspace_id"`
+	BinID         *int   `json:"bin_id"`
+	LocationID    *int   `json:"location_id"`
+	LocationPath  string `json:"location_path"`
+}
+
+type LocationInterface interface {
+	CreateLocation(echo.Context) (int, int, error)
+	DeleteLocation(echo.Context) (int, error)
+	GetLocationTree(echo.Context) (int, LocationTreeModel, error)
+	AttachWorkspaceLocation(echo.Context) (int, error)
+	CreateStorageBin(echo.Context) (int, int, error)
+	GetStorageBins(echo.Context) (int, []StorageBinModel, error)
+	StoreUnits(echo.Context) (int, error)
+	GetUnitLocations(echo.Context) (int, []UnitLocationModel, PageModel, error)
+}
@@ -49,3 +49,18 @@ type OrganizationInterface interface {
 // 	NoOfWorkspaces     int    `json:"no_of_workspaces" validate:"required"`
 // 	Issues             int    `json:"issues" validate:"required"`
 // }
+
+type OrganizationDetailsModel struct {
+	OrganizationID          int    `json:"organization_id"`
+	OrganizationName        string `json:"organization_name"`
+	OrganizationEmail       string `json:"organization_email"`
+	OrganizationPhoneNumber string `json:"organization_phone_number"`
+	NoOfSuperAdmins         int    `json:"no_of_super_admins"`
+	NoOfBranches            int    `json:"no_of_branches"`
+	NoOfDepartments         int    `json:"no_of_departments"`
+	NoOfWarehouses          int    `json:"no_of_warehouses"`
+	NoOfWorkspaces          int    `json:"no_of_workspaces"`
+	TotalTypesOfComponents  int    `json:"total_types_of_components"`
+	TotalUnitsOfComponents  int    `json:"total_units_of_components"`
+	TotalNoOfIssues         int    `json:"total_no_of_issues"`
+}
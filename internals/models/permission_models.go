@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PermissionModel struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// RoleModel is a named set of permissions, built-in roles are shared by every organization and named after
+// the account type or staff role they apply to
+type RoleModel struct {
+	RoleID      int       `json:"role_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	BuiltIn     bool      `json:"built_in"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CreateRoleModel struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description" validate:"max=200"`
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,required"`
+}
+
+type UpdateRolePermissionsModel struct {
+	RoleID      int      `json:"role_id" validate:"required"`
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,required"`
+}
+
+type DeleteRoleModel struct {
+	RoleID int `json:"role_id" validate:"required"`
+}
+
+// BindRoleModel grants a role to a user on an organization, branch, department or warehouse and everything below it
+type BindRoleModel struct {
+	RoleID    int    `json:"role_id" validate:"required"`
+	UserEmail string `json:"user_email" validate:"required,email"`
+	NodeType  string `json:"node_type" validate:"required,oneof=organization branch department warehouse"`
+	NodeID    int    `json:"node_id" validate:"required"`
+}
+
+type UnbindRoleModel struct {
+	BindingID int `json:"binding_id" validate:"required"`
+}
+
+type RoleBindingModel struct {
+	BindingID     int       `json:"binding_id"`
+	RoleID        int       `json:"role_id"`
+	RoleName      string    `json:"role_name"`
+	UserEmail     string    `json:"user_email"`
+	NodeType      string    `json:"node_type"`
+	NodeID        int       `json:"node_id"`
+	CreatedByRole string    `json:"created_by_role"`
+	CreatedBy     int       `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// EffectivePermissionsModel lists what a user holds through the role of their account and their bindings
+type EffectivePermissionsModel struct {
+	Role        string                `json:"role"`
+	Permissions []string              `json:"permissions"`
+	Bindings    []RoleBindingModel    `json:"bindings"`
+	Delegations []DelegatedScopeModel `json:"delegations"`
+}
+
+type RoleInterface interface {
+	GetPermissions(echo.Context) (int, []PermissionModel, error)
+	GetRoles(echo.Context) (int, []RoleModel, error)
+	CreateRole(echo.Context) (int, int, error)
+	UpdateRolePermissions(echo.Context) (int, error)
+	DeleteRole(echo.Context) (int, error)
+	BindRole(echo.Context) (int, int, error)
+	UnbindRole(echo.Context) (int, error)
+	GetRoleBindings(echo.Context) (int, []RoleBindingModel, PageModel, error)
+	GetMyPermissions(echo.Context) (int, EffectivePermissionsModel, error)
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RestoreModel struct {
+	ID     int    `json:"id" validate:"required"`
+	Prefix string `json:"prefix"`
+}
+
+type RestoreConflictModel struct {
+	Table  string `json:"table"`
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+type RestoreResultModel struct {
+	EntityType string                 `json:"entity_type"`
+	EntityID   int                    `json:"entity_id"`
+	Restored   map[string]int         `json:"restored"`
+	Conflicts  []RestoreConflictModel `json:"conflicts"`
+}
+
+type ArchivedEntityModel struct {
+	EntityType string          `json:"entity_type"`
+	ID         int             `json:"id"`
+	Prefix     string          `json:"prefix,omitempty"`
+	ArchivedAt time.Time       `json:"archived_at"`
+	ExpiresAt  time.Time       `json:"expires_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+type RestoreInterface interface {
+	GetArchivedEntities(echo.Context) (int, []ArchivedEntityModel, error)
+	Restore(echo.Context, string) (int, RestoreResultModel, error)
+}
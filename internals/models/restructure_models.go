@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MoveWorkspaceModel struct {
+	WorkspaceID  int `json:"workspace_id" validate:"required"`
+	DepartmentID int `json:"department_id" validate:"required"`
+}
+
+type MoveDepartmentModel struct {
+	DepartmentID int `json:"department_id" validate:"required"`
+	BranchID     int `json:"branch_id" validate:"required"`
+}
+
+// MoveModel records a re-parented workspace or department, from_id and to_id are departments
+// for a workspace and branches for a department
+type MoveModel struct {
+	MoveID      int       `json:"move_id"`
+	Entity      string    `json:"entity"`
+	EntityID    int       `json:"entity_id"`
+	FromID      int       `json:"from_id"`
+	ToID        int       `json:"to_id"`
+	Units       int       `json:"units"`
+	Issues      int       `json:"issues"`
+	Requests    int       `json:"requests"`
+	MovedByRole string    `json:"moved_by_role"`
+	MovedBy     int       `json:"moved_by"`
+	MovedAt     time.Time `json:"moved_at"`
+}
+
+type RestructureInterface interface {
+	MoveWorkspace(echo.Context) (int, MoveModel, error)
+	MoveDepartment(echo.Context) (int, MoveModel, error)
+	GetMoves(echo.Context) (int, []MoveModel, PageModel, error)
+}
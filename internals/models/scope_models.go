@@ -0,0 +1,18 @@
+package models
+
+// ScopeModel holds the ids a user is allowed to see, resolved from their role
+type ScopeModel struct {
+	Role          string `json:"role"`
+	UserID        int    `json:"user_id"`
+	UserEmail     string `json:"user_email"`
+	OrgID         int    `json:"org_id"`
+	BranchIDs     []int  `json:"branch_ids"`
+	DepartmentIDs []int  `json:"department_ids"`
+	WarehouseIDs  []int  `json:"warehouse_ids"`
+	StaffID       int    `json:"staff_id,omitempty"`
+	StaffRole     string `json:"staff_role,omitempty"`
+	// Delegations are the active delegations to the user, their nodes are part of the ids above
+	Delegations []DelegatedScopeModel `json:"delegations,omitempty"`
+	// OnBehalfOf lists the delegations an authorized scope relies on
+	OnBehalfOf []int `json:"on_behalf_of,omitempty"`
+}
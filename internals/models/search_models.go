@@ -0,0 +1,17 @@
+package models
+
+import "github.com/labstack/echo/v4"
+
+type SearchResultModel struct {
+	Type     string  `json:"type"`
+	ID       int     `json:"id"`
+	Prefix   string  `json:"prefix,omitempty"`
+	UserType string  `json:"user_type,omitempty"`
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle"`
+	Rank     float64 `json:"rank"`
+}
+
+type SearchInterface interface {
+	Search(echo.Context) (int, []SearchResultModel, error)
+}
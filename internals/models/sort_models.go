@@ -8,3 +8,11 @@ type SortModel struct {
 	Search string `json:"search"`
 	Offset int    `json:"offset"`
 }
+
+type PageModel struct {
+	Total      int    `json:"total"`
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Pages      int    `json:"pages"`
+	NextCursor string `json:"next_cursor"`
+}
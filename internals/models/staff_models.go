@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CreateStaffModel adds a staff login to a department (head or member) or a warehouse (manager or technician),
+// department and warehouse heads may leave the ids out to add staff to their own unit
+type CreateStaffModel struct {
+	Name         string `json:"name" validate:"required,max=50"`
+	Email        string `json:"email" validate:"required,email,max=50"`
+	DepartmentID int    `json:"department_id"`
+	WarehouseID  int    `json:"warehouse_id"`
+	StaffRole    string `json:"staff_role" validate:"required,oneof=head member manager technician"`
+}
+
+type DeactivateStaffModel struct {
+	StaffID int `json:"staff_id" validate:"required"`
+}
+
+type StaffModel struct {
+	StaffID       int        `json:"staff_id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	StaffRole     string     `json:"staff_role"`
+	DepartmentID  int        `json:"department_id,omitempty"`
+	WarehouseID   int        `json:"warehouse_id,omitempty"`
+	Active        bool       `json:"active"`
+	CreatedByRole string     `json:"created_by_role"`
+	CreatedBy     int        `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+}
+
+type AssignIssueModel struct {
+	IssueID int `json:"issue_id" validate:"required"`
+	StaffID int `json:"staff_id" validate:"required"`
+}
+
+type StaffIssueModel struct {
+	IssueID         int        `json:"issue_id"`
+	Issue           string     `json:"issue"`
+	Status          string     `json:"status"`
+	DepartmentID    int        `json:"department_id"`
+	WarehouseID     int        `json:"warehouse_id"`
+	WorkspaceID     int        `json:"workspace_id"`
+	UnitID          int        `json:"unit_id"`
+	UnitPrefix      string     `json:"unit_prefix"`
+	AssignedStaffID int        `json:"assigned_staff_id,omitempty"`
+	AssignedTo      string     `json:"assigned_to,omitempty"`
+	AssignedAt      *time.Time `json:"assigned_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// StaffActivityModel is a write request made by a staff account
+type StaffActivityModel struct {
+	ActivityID int       `json:"activity_id"`
+	StaffID    int       `json:"staff_id"`
+	StaffName  string    `json:"staff_name"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Status     int       `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type StaffInterface interface {
+	CreateStaff(echo.Context) (int, int, error)
+	GetStaff(echo.Context) (int, []StaffModel, PageModel, error)
+	DeactivateStaff(echo.Context) (int, error)
+	AssignIssue(echo.Context) (int, error)
+	GetStaffIssues(echo.Context) (int, []StaffIssueModel, PageModel, error)
+	GetStaffActivity(echo.Context) (int, []StaffActivityModel, PageModel, error)
+}
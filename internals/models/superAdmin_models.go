@@ -25,6 +25,7 @@ type SuperAdminInterface interface {
 	CreateBranch(echo.Context) (int, error)
 	DeleteBranch(echo.Context) (int, error)
 	UpdateBranchHead(echo.Context) (int, error)
+	PreviewDeleteBranch(echo.Context) (int, DeletionImpactModel, error)
 }
 
 type GetAllSuperAdminsModel struct {
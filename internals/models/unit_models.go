@@ -29,13 +29,16 @@ type UnitModel struct {
 }
 
 type AddUnitModel struct {
-	Number_of_units int       `json:"number_of_units" validate:"required"`
-	ComponentID     int       `json:"component_id" validate:"required"`
-	Warenty_Date    time.Time `json:"warenty_date" validate:"required"`
-	Cost            float64   `json:"cost" validate:"required"`
+	WarehouseID     int                    `json:"warehouse_id"`
+	Number_of_units int                    `json:"number_of_units" validate:"required"`
+	ComponentID     int                    `json:"component_id" validate:"required"`
+	Warenty_Date    time.Time              `json:"warenty_date" validate:"required"`
+	Cost            float64                `json:"cost" validate:"required"`
+	Attributes      map[string]interface{} `json:"attributes"`
 }
 
 type AssignUnitModel struct {
+	WarehouseID int    `json:"warehouse_id"`
 	WorkspaceID int    `json:"workspace_id" validate:"required"`
 	ComponentID int    `json:"component_id" validate:"required"`
 	UnitIDs     []int  `json:"unit_ids" validate:"required"`
@@ -72,13 +75,57 @@ type GetAssignedUnitsModel struct {
 }
 
 type UpdateUnitStatusModel struct {
-	UnitID int    `json:"unit_id" validate:"required"`
-	Prefix string `json:"prefix" validate:"required"`
-	Status string `json:"status" validate:"required"`
+	WarehouseID int    `json:"warehouse_id"`
+	UnitID      int    `json:"unit_id" validate:"required"`
+	Prefix      string `json:"prefix" validate:"required"`
+	Status      string `json:"status" validate:"required"`
+}
+
+type UpdateUnitIdentifiersModel struct {
+	WarehouseID  int    `json:"warehouse_id"`
+	UnitID       int    `json:"unit_id" validate:"required"`
+	Prefix       string `json:"prefix" validate:"required"`
+	SerialNumber string `json:"serial_number" validate:"max=100"`
+	AssetTag     string `json:"asset_tag" validate:"max=100"`
 }
 
 type DeleteUnitModel struct {
-	UnitID    int    `json:"unit_id" validate:"required"`
-	Prefix    string `json:"prefix" validate:"required"`
-	Component int    `json:"component" validate:"required"`
+	WarehouseID int    `json:"warehouse_id"`
+	UnitID      int    `json:"unit_id" validate:"required"`
+	Prefix      string `json:"prefix" validate:"required"`
+	Component   int    `json:"component" validate:"required"`
+}
+
+type LinkUnitsModel struct {
+	WarehouseID       int    `json:"warehouse_id"`
+	ParentComponentID int    `json:"parent_component_id" validate:"required"`
+	ParentUnitID      int    `json:"parent_unit_id" validate:"required"`
+	ChildComponentID  int    `json:"child_component_id" validate:"required"`
+	ChildUnitID       int    `json:"child_unit_id" validate:"required"`
+	Relation          string `json:"relation" validate:"required,oneof=installed_in kit"`
+}
+
+type UnlinkUnitModel struct {
+	WarehouseID int `json:"warehouse_id"`
+	ComponentID int `json:"component_id" validate:"required"`
+	UnitID      int `json:"unit_id" validate:"required"`
+}
+
+type UnitRefModel struct {
+	ComponentID int    `json:"component_id"`
+	Prefix      string `json:"prefix"`
+	UnitID      int    `json:"unit_id"`
+}
+
+type UnitCompositionModel struct {
+	ComponentID   int                     `json:"component_id"`
+	Prefix        string                  `json:"prefix"`
+	UnitID        int                     `json:"unit_id"`
+	Relation      string                  `json:"relation,omitempty"`
+	Status        string                  `json:"status"`
+	WorkspaceID   *int                    `json:"workspace_id"`
+	OpenIssues    int                     `json:"open_issues"`
+	SubtreeIssues int                     `json:"subtree_issues"`
+	Parent        *UnitRefModel           `json:"parent,omitempty"`
+	Children      []*UnitCompositionModel `json:"children"`
 }
@@ -12,6 +12,7 @@ type WarehouseModel struct {
 }
 
 type CreateWarehouseModel struct {
+	BranchID           int    `json:"branch_id"`
 	WarehouseUserName  string `json:"warehouse_user_name" validate:"required"`
 	WarehouseUserEmail string `json:"warehouse_user_email" validate:"required,email"`
 }
@@ -26,6 +27,7 @@ type UpdateWarehouseHeadModel struct {
 type DeleteWarehouseModel struct {
 	WarehouseID        int    `json:"warehouse_id" validate:"required"`
 	BranchHeadPassword string `json:"branch_head_password" validate:"required"`
+	PreviewToken       string `json:"preview_token"`
 }
 
 type WarehouseInterface interface {
@@ -33,16 +35,23 @@ type WarehouseInterface interface {
 	DeleteComponent(echo.Context) (int, error)
 	AddComponentUnits(echo.Context) (int, error)
 	AssignUnits(echo.Context) (int, error)
-	GetAllWarehouseIssues(echo.Context) (int, []IssueModel, int, int, int, error)
+	GetAllWarehouseIssues(echo.Context) (int, []IssueModel, PageModel, error)
 	GetAllWarehouseComponents(echo.Context) (int, []AllWarehouseComponentsModel, error)
-	GetAllWarehouseComponentUnits(echo.Context) (int, []AllComponentUnitsModel, error)
+	GetAllWarehouseComponentUnits(echo.Context) (int, []AllComponentUnitsModel, PageModel, error)
 	GetIssueDetails(echo.Context) (int, IssueDetailsModel, error)
 	GetUnitAssignmentHistory(echo.Context) (int, UnitAssignmentHistoryModel, error)
 	UpdateIssueStatus(echo.Context) (int, error)
 	UpdateComponentName(echo.Context) (int, error)
+	UpdateComponentPrefix(echo.Context) (int, string, error)
+	UpdateComponentCatalogue(echo.Context) (int, error)
 	GetAssignedUnits(echo.Context) (int, []AssignedUnitsModel, int, int, int, error)
 	UpdateMaintenanceCost(echo.Context) (int, error)
 	UpdateUnitStatus(echo.Context) (int, error)
+	UpdateUnitIdentifiers(echo.Context) (int, error)
+	UpdateUnitAttributes(echo.Context) (int, error)
+	LinkUnits(echo.Context) (int, error)
+	UnlinkUnit(echo.Context) (int, error)
+	GetUnitComposition(echo.Context) (int, UnitCompositionModel, error)
 	DeleteUnit(echo.Context) (int, error)
 }
 
@@ -53,12 +62,22 @@ type GetAllIssuesModel struct {
 	WorkspaceID  int    `json:"workspace_id" validate:"required"`
 }
 
-type GetAllComponentUnitsModel struct {
-	ComponentID int `json:"component_id" validate:"required"`
-}
-
 type UpdateMaintenanceCostModel struct {
+	WarehouseID     int     `json:"warehouse_id"`
 	UnitID          int     `json:"unit_id" validate:"required"`
 	ComponentID     int     `json:"component_id" validate:"required"`
 	MaintenanceCost float32 `json:"maintenance_cost" validate:"required"`
 }
+
+type WarehouseDetailsModel struct {
+	WarehouseID            int    `json:"warehouse_id"`
+	WarehouseName          string `json:"warehouse_name"`
+	WarehouseEmail         string `json:"warehouse_email"`
+	BranchID               int    `json:"branch_id"`
+	TotalTypesOfComponents int    `json:"total_types_of_components"`
+	TotalUnitsOfComponents int    `json:"total_units_of_components"`
+	TotalAssignedUnits     int    `json:"total_assigned_units"`
+	TotalNoOfIssues        int    `json:"total_no_of_issues"`
+	OpenIssues             int    `json:"open_issues"`
+	PendingRequests        int    `json:"pending_requests"`
+}
@@ -8,9 +8,11 @@ type WorkspaceModel struct {
 
 type CreateWorkspaceModel struct {
 	WorkspaceName string `json:"workspace_name" validate:"required"`
+	DepartmentID  int    `json:"department_id"`
 }
 
 type DeleteWorkspaceModel struct {
 	WorkspaceName string `json:"workspace_name" validate:"required"`
 	WorkspaceID   int    `json:"workspace_id" validate:"required"`
+	DepartmentID  int    `json:"department_id"`
 }
@@ -0,0 +1,308 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/lib/pq"
+)
+
+// costedUnitsQuery extends the scoped units with the cost of every resolved issue raised against them
+func costedUnitsQuery(components []models.ComponentModel) string {
+	return scopedUnitsQuery(components) + `,
+	repairs AS (
+		SELECT i.unit_prefix, i.unit_id, SUM(r.cost) AS cost, COUNT(r.id) AS resolved
+		FROM resolved_issues r
+		JOIN issues i ON i.id = r.issue_id
+		GROUP BY i.unit_prefix, i.unit_id
+	),
+	costed AS (
+		SELECT s.*, s.maintainance_cost + COALESCE(rp.cost, 0) AS total_maintenance, COALESCE(rp.resolved, 0) AS resolved_issues
+		FROM scoped s
+		LEFT JOIN repairs rp ON rp.unit_prefix = s.prefix AND rp.unit_id = s.unit_id
+	)`
+}
+
+func setTotalCost(cost *models.CostModel) {
+	cost.TotalCost = cost.PurchaseCost + cost.MaintenanceCost
+}
+
+func (q *Query) GetCostByComponent(scope models.ScopeModel) (int, []models.ComponentCostModel, error) {
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	costs := []models.ComponentCostModel{}
+	if len(components) == 0 {
+		return http.StatusOK, costs, nil
+	}
+
+	query := costedUnitsQuery(components) + `
+	SELECT c.id, c.name, c.prefix, c.warehouse_id, COUNT(u.unit_id), COALESCE(SUM(u.cost), 0), COALESCE(SUM(u.total_maintenance), 0)
+	FROM costed u
+	JOIN components c ON c.id = u.component_id
+	GROUP BY c.id, c.name, c.prefix, c.warehouse_id
+	ORDER BY SUM(u.cost + u.total_maintenance) DESC`
+
+	rows, err := q.db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs))
+	if err != nil {
+		log.Printf("error while getting component costs: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cost models.ComponentCostModel
+		if err := rows.Scan(&cost.ComponentID, &cost.ComponentName, &cost.Prefix, &cost.WarehouseID, &cost.Units, &cost.PurchaseCost, &cost.MaintenanceCost); err != nil {
+			log.Printf("error while scanning component costs: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		setTotalCost(&cost.CostModel)
+		costs = append(costs, cost)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("error while getting component costs: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, costs, nil
+}
+
+func (q *Query) GetCostByWarehouse(scope models.ScopeModel) (int, []models.WarehouseCostModel, error) {
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	costs := []models.WarehouseCostModel{}
+	if len(components) == 0 {
+		return http.StatusOK, costs, nil
+	}
+
+	query := costedUnitsQuery(components) + `
+	SELECT w.id, w.name, COUNT(u.unit_id), COALESCE(SUM(u.cost), 0), COALESCE(SUM(u.total_maintenance), 0)
+	FROM costed u
+	JOIN warehouses w ON w.id = u.warehouse_id
+	GROUP BY w.id, w.name
+	ORDER BY SUM(u.cost + u.total_maintenance) DESC`
+
+	rows, err := q.db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs))
+	if err != nil {
+		log.Printf("error while getting warehouse costs: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cost models.WarehouseCostModel
+		if err := rows.Scan(&cost.WarehouseID, &cost.WarehouseName, &cost.Units, &cost.PurchaseCost, &cost.MaintenanceCost); err != nil {
+			log.Printf("error while scanning warehouse costs: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		setTotalCost(&cost.CostModel)
+		costs = append(costs, cost)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("error while getting warehouse costs: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, costs, nil
+}
+
+func (q *Query) GetCostByDepartment(scope models.ScopeModel) (int, []models.DepartmentCostModel, error) {
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	costs := []models.DepartmentCostModel{}
+	if len(components) == 0 {
+		return http.StatusOK, costs, nil
+	}
+
+	query := costedUnitsQuery(components) + `
+	SELECT d.department_id, d.department_name, COUNT(u.unit_id), COALESCE(SUM(u.cost), 0), COALESCE(SUM(u.total_maintenance), 0)
+	FROM costed u
+	JOIN departments d ON d.department_id = u.department_id
+	WHERE u.department_id = ANY($2)
+	GROUP BY d.department_id, d.department_name
+	ORDER BY SUM(u.cost + u.total_maintenance) DESC`
+
+	rows, err := q.db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs))
+	if err != nil {
+		log.Printf("error while getting department costs: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cost models.DepartmentCostModel
+		if err := rows.Scan(&cost.DepartmentID, &cost.DepartmentName, &cost.Units, &cost.PurchaseCost, &cost.MaintenanceCost); err != nil {
+			log.Printf("error while scanning department costs: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		setTotalCost(&cost.CostModel)
+		costs = append(costs, cost)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("error while getting department costs: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, costs, nil
+}
+
+// GetCostByPeriod buckets purchases by unit creation, maintenance by its last update and repairs by resolution date
+func (q *Query) GetCostByPeriod(scope models.ScopeModel, interval string, from, to time.Time) (int, []models.PeriodCostModel, error) {
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	costs := []models.PeriodCostModel{}
+	if len(components) == 0 {
+		return http.StatusOK, costs, nil
+	}
+
+	query := scopedUnitsQuery(components) + fmt.Sprintf(`,
+	events AS (
+		SELECT created_at AS at, cost AS purchase, 0::NUMERIC AS maintenance FROM scoped
+		UNION ALL
+		SELECT COALESCE(last_maintenance_date, created_at), 0, maintainance_cost FROM scoped WHERE maintainance_cost > 0
+		UNION ALL
+		SELECT r.resolved_at, 0, r.cost
+		FROM resolved_issues r
+		JOIN issues i ON i.id = r.issue_id
+		JOIN scoped s ON s.prefix = i.unit_prefix AND s.unit_id = i.unit_id
+	)
+	SELECT date_trunc('%s', at) AS period, COALESCE(SUM(purchase), 0), COALESCE(SUM(maintenance), 0)
+	FROM events
+	WHERE at >= $3 AND at < $4
+	GROUP BY period
+	ORDER BY period`, interval)
+
+	rows, err := q.db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs), from, to)
+	if err != nil {
+		log.Printf("error while getting period costs: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cost models.PeriodCostModel
+		if err := rows.Scan(&cost.Period, &cost.PurchaseCost, &cost.MaintenanceCost); err != nil {
+			log.Printf("error while scanning period costs: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		cost.TotalCost = cost.PurchaseCost + cost.MaintenanceCost
+		costs = append(costs, cost)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("error while getting period costs: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, costs, nil
+}
+
+func (q *Query) GetTopMaintenanceUnits(scope models.ScopeModel, limit int) (int, []models.UnitMaintenanceCostModel, error) {
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	units := []models.UnitMaintenanceCostModel{}
+	if len(components) == 0 {
+		return http.StatusOK, units, nil
+	}
+
+	query := costedUnitsQuery(components) + `
+	SELECT u.unit_id, u.prefix, c.id, c.name, u.warehouse_id, u.department_id, u.status, u.cost, u.total_maintenance, u.resolved_issues
+	FROM costed u
+	JOIN components c ON c.id = u.component_id
+	WHERE u.total_maintenance > 0
+	ORDER BY u.total_maintenance DESC
+	LIMIT $3`
+
+	rows, err := q.db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs), limit)
+	if err != nil {
+		log.Printf("error while getting top maintenance units: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var unit models.UnitMaintenanceCostModel
+		var department_id sql.NullInt64
+		if err := rows.Scan(&unit.UnitID, &unit.Prefix, &unit.ComponentID, &unit.ComponentName, &unit.WarehouseID, &department_id, &unit.Status, &unit.PurchaseCost, &unit.MaintenanceCost, &unit.ResolvedIssues); err != nil {
+			log.Printf("error while scanning top maintenance units: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		if department_id.Valid {
+			id := int(department_id.Int64)
+			unit.DepartmentID = &id
+		}
+		units = append(units, unit)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("error while getting top maintenance units: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, units, nil
+}
+
+// GetBranchComponentCostComparison matches components across branches by name since each warehouse owns its own components
+func (q *Query) GetBranchComponentCostComparison(scope models.ScopeModel, component_name string) (int, []models.BranchComponentCostModel, error) {
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	costs := []models.BranchComponentCostModel{}
+	if len(components) == 0 {
+		return http.StatusOK, costs, nil
+	}
+
+	query := costedUnitsQuery(components) + `
+	SELECT LOWER(c.name) AS component_name, b.branch_id, b.branch_name, COUNT(u.unit_id), COALESCE(SUM(u.cost), 0), COALESCE(SUM(u.total_maintenance), 0)
+	FROM costed u
+	JOIN components c ON c.id = u.component_id
+	JOIN warehouses w ON w.id = u.warehouse_id
+	JOIN branches b ON b.branch_id = w.branch_id
+	WHERE $3 = '' OR c.name ILIKE $3
+	GROUP BY LOWER(c.name), b.branch_id, b.branch_name
+	ORDER BY component_name, b.branch_id`
+
+	rows, err := q.db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs), component_name)
+	if err != nil {
+		log.Printf("error while getting branch component costs: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cost models.BranchComponentCostModel
+		if err := rows.Scan(&cost.ComponentName, &cost.BranchID, &cost.BranchName, &cost.Units, &cost.PurchaseCost, &cost.MaintenanceCost); err != nil {
+			log.Printf("error while scanning branch component costs: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		setTotalCost(&cost.CostModel)
+		if cost.Units > 0 {
+			cost.CostPerUnit = cost.TotalCost / float64(cost.Units)
+		}
+		costs = append(costs, cost)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("error while getting branch component costs: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, costs, nil
+}
@@ -0,0 +1,310 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
+)
+
+var unitFields = map[string]queryspec.Field{
+	"id":                    {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true, Searchable: true},
+	"status":                {Column: "status", Type: queryspec.Enum, Values: []string{"working", "repair", "not_working", "exit"}, Filterable: true, Sortable: true},
+	"cost":                  {Column: "cost", Type: queryspec.Number, Filterable: true, Sortable: true},
+	"maintainance_cost":     {Column: "maintainance_cost", Type: queryspec.Number, Filterable: true, Sortable: true},
+	"warranty_date":         {Column: "warranty_date", Type: queryspec.Time, Filterable: true, Sortable: true},
+	"created_at":            {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	"serial_number":         {Column: "serial_number", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+	"asset_tag":             {Column: "asset_tag", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+	"assigned_workspace_id": {Column: "workspace_id", Type: queryspec.Int, Filterable: true},
+}
+
+// UnitsResource is the unit list resource of a component, every attribute definition
+// becomes a filterable and sortable attr.<key> field
+func UnitsResource(definitions []models.AttributeDefinitionModel) queryspec.Resource {
+	resource := queryspec.Resource{
+		IDColumn:    "id",
+		DefaultSort: "id",
+		Fields:      make(map[string]queryspec.Field, len(unitFields)+len(definitions)),
+	}
+
+	for name, field := range unitFields {
+		resource.Fields[name] = field
+	}
+
+	for _, definition := range definitions {
+		key := pq.QuoteLiteral(definition.Key)
+		field := queryspec.Field{Column: fmt.Sprintf("(attributes->>%s)", key), Filterable: true, Sortable: true, JSONColumn: "attributes", JSONKey: definition.Key}
+		// values that do not fit the type read as NULL instead of failing the cast
+		switch definition.Type {
+		case "number":
+			field.Type = queryspec.Number
+			field.Column = fmt.Sprintf("(CASE WHEN jsonb_typeof(attributes->%s) = 'number' THEN (attributes->>%s)::NUMERIC END)", key, key)
+		case "date":
+			field.Type = queryspec.Time
+			field.Column = fmt.Sprintf("(CASE WHEN (attributes->>%s) ~ '^\\d{4}-\\d{2}-\\d{2}$' THEN (attributes->>%s)::DATE END)", key, key)
+		case "enum":
+			field.Type = queryspec.Enum
+			field.Values = definition.Options
+		default:
+			field.Type = queryspec.String
+			field.Searchable = true
+		}
+		resource.Fields["attr."+definition.Key] = field
+	}
+
+	return resource
+}
+
+func scanAttributeDefinitions(rows *sql.Rows) ([]models.AttributeDefinitionModel, error) {
+	defer rows.Close()
+
+	definitions := []models.AttributeDefinitionModel{}
+	for rows.Next() {
+		var definition models.AttributeDefinitionModel
+		var category_id, component_id sql.NullInt64
+		if err := rows.Scan(&definition.DefinitionID, &category_id, &component_id, &definition.Key, &definition.Label, &definition.Type, pq.Array(&definition.Options), &definition.Required); err != nil {
+			return nil, err
+		}
+		if category_id.Valid {
+			id := int(category_id.Int64)
+			definition.CategoryID = &id
+		}
+		if component_id.Valid {
+			id := int(component_id.Int64)
+			definition.ComponentID = &id
+		}
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, rows.Err()
+}
+
+func (q *Query) CreateAttributeDefinition(org_id int, definition models.CreateAttributeDefinitionModel) (int, int, error) {
+	var exists bool
+	var err error
+
+	if definition.CategoryID > 0 {
+		err = q.db.QueryRow("SELECT EXISTS(SELECT 1 FROM component_categories WHERE id = $1 AND (org_id = $2 OR org_id IS NULL))", definition.CategoryID, org_id).Scan(&exists)
+	} else {
+		exists, err = q.componentInOrg(definition.ComponentID, org_id)
+	}
+
+	if err != nil {
+		log.Printf("error while checking attribute target: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	} else if !exists {
+		return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+	}
+
+	query := `INSERT INTO attribute_definitions(org_id, category_id, component_id, key, label, type, options, required)
+		VALUES($1, NULLIF($2, 0), NULLIF($3, 0), $4, $5, $6, $7, $8) RETURNING id`
+
+	var id int
+	if err := q.db.QueryRow(query, org_id, definition.CategoryID, definition.ComponentID, definition.Key, definition.Label, definition.Type, pq.Array(definition.Options), definition.Required).Scan(&id); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, -1, fmt.Errorf("attribute %v is already defined", definition.Key)
+		}
+		log.Printf("error while creating attribute definition: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, id, nil
+}
+
+// GetAttributeDefinitions lists the definitions of the organization, narrowed to a category when category_id is set
+func (q *Query) GetAttributeDefinitions(org_id, category_id int) (int, []models.AttributeDefinitionModel, error) {
+	query := `SELECT id, category_id, component_id, key, label, type, options, required
+		FROM attribute_definitions
+		WHERE org_id = $1 AND ($2 = 0 OR category_id = $2)
+		ORDER BY category_id NULLS LAST, component_id, key`
+
+	rows, err := q.db.Query(query, org_id, category_id)
+	if err != nil {
+		log.Printf("error while getting attribute definitions: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	definitions, err := scanAttributeDefinitions(rows)
+	if err != nil {
+		log.Printf("error while scanning attribute definitions: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, definitions, nil
+}
+
+// GetComponentAttributeDefinitions returns the definitions that apply to a component, those set on the
+// component itself override the ones of its catalogue category with the same key
+func (q *Query) GetComponentAttributeDefinitions(component_id int) ([]models.AttributeDefinitionModel, error) {
+	query := `SELECT DISTINCT ON (ad.key) ad.id, ad.category_id, ad.component_id, ad.key, ad.label, ad.type, ad.options, ad.required
+		FROM components c
+		JOIN warehouses w ON w.id = c.warehouse_id
+		JOIN branches b ON b.branch_id = w.branch_id
+		LEFT JOIN catalogue_models cm ON cm.id = c.catalogue_id
+		JOIN attribute_definitions ad ON ad.org_id = b.org_id AND (ad.component_id = c.id OR ad.category_id = cm.category_id)
+		WHERE c.id = $1
+		ORDER BY ad.key, ad.component_id NULLS LAST`
+
+	rows, err := q.db.Query(query, component_id)
+	if err != nil {
+		log.Printf("error while getting attribute definitions of component %v: %v", component_id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	definitions, err := scanAttributeDefinitions(rows)
+	if err != nil {
+		log.Printf("error while scanning attribute definitions: %v", err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return definitions, nil
+}
+
+func (q *Query) componentInOrg(component_id, org_id int) (bool, error) {
+	var exists bool
+	err := q.db.QueryRow(`SELECT EXISTS(
+		SELECT 1 FROM components c
+		JOIN warehouses w ON w.id = c.warehouse_id
+		JOIN branches b ON b.branch_id = w.branch_id
+		WHERE c.id = $1 AND b.org_id = $2
+	)`, component_id, org_id).Scan(&exists)
+	return exists, err
+}
+
+// GetOrgComponentAttributeDefinitions returns the effective definitions of a component of the organization
+func (q *Query) GetOrgComponentAttributeDefinitions(org_id, component_id int) (int, []models.AttributeDefinitionModel, error) {
+	exists, err := q.componentInOrg(component_id, org_id)
+	if err != nil {
+		log.Printf("error while checking component %v: %v", component_id, err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	} else if !exists {
+		return http.StatusNotFound, nil, fmt.Errorf("no matching data found")
+	}
+
+	definitions, err := q.GetComponentAttributeDefinitions(component_id)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	return http.StatusOK, definitions, nil
+}
+
+// DeleteAttributeDefinition removes the definition and the values it left on units, unless another definition
+// with the same key still applies to their component. A key redefined with another type never meets stale values
+func (q *Query) DeleteAttributeDefinition(org_id, definition_id int) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var key string
+	var category_id, component_id sql.NullInt64
+	if err := tx.QueryRow("DELETE FROM attribute_definitions WHERE id = $1 AND org_id = $2 RETURNING key, category_id, component_id",
+		definition_id, org_id).Scan(&key, &category_id, &component_id); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while deleting attribute definition: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	rows, err := tx.Query(`SELECT c.id, c.prefix FROM components c
+		JOIN warehouses w ON w.id = c.warehouse_id
+		JOIN branches b ON b.branch_id = w.branch_id
+		LEFT JOIN catalogue_models cm ON cm.id = c.catalogue_id
+		WHERE b.org_id = $1 AND (c.id = $2 OR cm.category_id = $3)
+		AND EXISTS(SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = lower(c.prefix || '_units'))
+		AND NOT EXISTS(SELECT 1 FROM attribute_definitions ad WHERE ad.org_id = $1 AND ad.key = $4 AND (ad.component_id = c.id OR ad.category_id = cm.category_id))`,
+		org_id, component_id, category_id, key)
+	if err != nil {
+		log.Printf("error while getting components of attribute %v: %v", key, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	prefixes := map[int]string{}
+	for rows.Next() {
+		var id int
+		var prefix string
+		if err := rows.Scan(&id, &prefix); err != nil {
+			rows.Close()
+			log.Printf("error while scanning components of attribute %v: %v", key, err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		}
+		prefixes[id] = prefix
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("error while getting components of attribute %v: %v", key, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	for id, prefix := range prefixes {
+		if _, err := tx.Exec(fmt.Sprintf("UPDATE %s_units SET attributes = attributes - $1 WHERE component_id = $2 AND attributes ? $1", prefix), key, id); err != nil {
+			log.Printf("error while removing attribute %v from units of component %v: %v", key, id, err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing attribute definition deletion: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+// UpdateUnitAttributes locks the unit and replaces its attributes with the result of merge
+func (q *Query) UpdateUnitAttributes(prefix string, unit_id, component_id, warehouse_id int, merge func(map[string]interface{}) (map[string]interface{}, error)) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var data []byte
+	query1 := fmt.Sprintf("SELECT COALESCE(attributes, '{}') FROM %s_units WHERE id = $1 AND component_id = $2 AND warehouse_id = $3 FOR UPDATE", prefix)
+	if err := tx.QueryRow(query1, unit_id, component_id, warehouse_id).Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while locking unit %v: %v", unit_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	current := map[string]interface{}{}
+	if err := json.Unmarshal(data, &current); err != nil {
+		log.Printf("error while decoding attributes of unit %v: %v", unit_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	attributes, err := merge(current)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if data, err = json.Marshal(attributes); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	query2 := fmt.Sprintf("UPDATE %s_units SET attributes = $1 WHERE id = $2", prefix)
+	if _, err := tx.Exec(query2, data, unit_id); err != nil {
+		log.Printf("error while updating attributes of unit %v: %v", unit_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing unit attributes: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
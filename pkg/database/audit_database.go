@@ -0,0 +1,586 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
+)
+
+var AuditSessionsResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "opened_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":            {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"warehouse_id":  {Column: "warehouse_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"department_id": {Column: "department_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"status":        {Column: "status", Type: queryspec.Enum, Values: []string{"open", "closed"}, Filterable: true, Sortable: true},
+		"note":          {Column: "note", Type: queryspec.String, Filterable: true, Searchable: true},
+		"opened_at":     {Column: "opened_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+		"closed_at":     {Column: "closed_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+		"sightings":     {Column: "sightings", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"discrepancies": {Column: "discrepancies", Type: queryspec.Int, Filterable: true, Sortable: true},
+	},
+}
+
+const auditSessionColumns = `s.id, s.warehouse_id, s.department_id, s.status, COALESCE(s.note, '') AS note, s.opened_by_role, s.opened_by, s.opened_at, s.closed_at,
+	(SELECT COUNT(*) FROM audit_sightings a WHERE a.session_id = s.id) AS sightings,
+	(SELECT COUNT(*) FROM audit_discrepancies d WHERE d.session_id = s.id) AS discrepancies`
+
+type auditUnit struct {
+	ComponentID  int
+	Prefix       string
+	UnitID       int
+	WarehouseID  int
+	DepartmentID sql.NullInt64
+	WorkspaceID  sql.NullInt64
+}
+
+type auditSighting struct {
+	ComponentID sql.NullInt64
+	Prefix      string
+	UnitID      sql.NullInt64
+	Code        string
+	WorkspaceID sql.NullInt64
+}
+
+func nullIntPointer(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	v := int(value.Int64)
+	return &v
+}
+
+func auditSessionInScope(session models.AuditSessionModel, scope models.ScopeModel) bool {
+	if session.WarehouseID != nil {
+		return slices.Contains(scope.WarehouseIDs, *session.WarehouseID)
+	}
+	return session.DepartmentID != nil && slices.Contains(scope.DepartmentIDs, *session.DepartmentID)
+}
+
+func getAuditSession(tx *sql.Tx, session_id int, lock bool) (models.AuditSessionModel, error) {
+	query := fmt.Sprintf("SELECT %s FROM audit_sessions s WHERE s.id = $1", auditSessionColumns)
+	if lock {
+		query += " FOR UPDATE OF s"
+	}
+
+	var session models.AuditSessionModel
+	err := tx.QueryRow(query, session_id).Scan(&session.SessionID, &session.WarehouseID, &session.DepartmentID, &session.Status, &session.Note, &session.OpenedByRole, &session.OpenedBy, &session.OpenedAt, &session.ClosedAt, &session.Sightings, &session.Discrepancies)
+	return session, err
+}
+
+// beginAuditSession starts a transaction holding the session, it is only returned when the session is in the scope
+func (q *Query) beginAuditSession(scope models.ScopeModel, session_id int, lock bool) (*sql.Tx, models.AuditSessionModel, int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return nil, models.AuditSessionModel{}, http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	session, err := getAuditSession(tx, session_id, lock)
+	if err == nil && !auditSessionInScope(session, scope) {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, models.AuditSessionModel{}, http.StatusNotFound, fmt.Errorf("no matching audit session found")
+		}
+		log.Printf("error while getting audit session %v: %v", session_id, err)
+		return nil, models.AuditSessionModel{}, http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return tx, session, http.StatusOK, nil
+}
+
+func (q *Query) CreateAuditSession(scope models.ScopeModel, warehouse_id, department_id int, note string) (int, int, error) {
+	if warehouse_id > 0 && !slices.Contains(scope.WarehouseIDs, warehouse_id) {
+		return http.StatusNotFound, -1, fmt.Errorf("no matching warehouse found")
+	} else if department_id > 0 && !slices.Contains(scope.DepartmentIDs, department_id) {
+		return http.StatusNotFound, -1, fmt.Errorf("no matching department found")
+	}
+
+	query := `INSERT INTO audit_sessions(org_id, warehouse_id, department_id, note, opened_by_role, opened_by)
+		VALUES($1, NULLIF($2, 0), NULLIF($3, 0), NULLIF($4, ''), $5, $6) RETURNING id`
+
+	var id int
+	if err := q.db.QueryRow(query, scope.OrgID, warehouse_id, department_id, note, scope.Role, scope.UserID).Scan(&id); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, -1, fmt.Errorf("an audit session is already open here")
+		}
+		log.Printf("error while creating audit session: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, id, nil
+}
+
+// findUnitByCode looks a scanned asset tag or serial number up across the components of the organization
+func findUnitByCode(tx *sql.Tx, org_id int, code string) (auditSighting, error) {
+	rows, err := tx.Query(`SELECT c.id, c.prefix FROM components c
+		JOIN warehouses w ON w.id = c.warehouse_id
+		JOIN branches b ON b.branch_id = w.branch_id
+		WHERE b.org_id = $1 AND c.kind = 'unit'
+		AND EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = lower(c.prefix || '_units'))`, org_id)
+	if err != nil {
+		return auditSighting{}, err
+	}
+
+	var parts []string
+	for rows.Next() {
+		var component_id int
+		var prefix string
+		if err := rows.Scan(&component_id, &prefix); err != nil {
+			rows.Close()
+			return auditSighting{}, err
+		}
+		parts = append(parts, fmt.Sprintf("SELECT %d AS component_id, '%s'::TEXT AS prefix, id FROM %s_units WHERE lower(asset_tag) = lower($1) OR lower(serial_number) = lower($1)", component_id, prefix, prefix))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return auditSighting{}, err
+	}
+
+	sighting := auditSighting{Code: code}
+	if len(parts) == 0 {
+		return sighting, nil
+	}
+
+	rows, err = tx.Query(strings.Join(parts, " UNION ALL ")+" LIMIT 2", code)
+	if err != nil {
+		return auditSighting{}, err
+	}
+	defer rows.Close()
+
+	matches := 0
+	for rows.Next() {
+		matches++
+		if err := rows.Scan(&sighting.ComponentID, &sighting.Prefix, &sighting.UnitID); err != nil {
+			return auditSighting{}, err
+		}
+	}
+	if matches > 1 {
+		return auditSighting{}, errAmbiguousCode
+	}
+
+	return sighting, rows.Err()
+}
+
+var errAmbiguousCode = fmt.Errorf("code matches more than one unit, record it by prefix and unit id")
+
+// RecordSighting stores a unit seen during the audit, by prefix and unit id or by a scanned code.
+// Sightings that do not resolve to a unit are kept and reported as unexpected
+func (q *Query) RecordSighting(scope models.ScopeModel, record models.RecordSightingModel) (int, models.AuditSightingModel, error) {
+	tx, session, status, err := q.beginAuditSession(scope, record.SessionID, true)
+	if err != nil {
+		return status, models.AuditSightingModel{}, err
+	}
+	defer tx.Rollback()
+
+	if session.Status != "open" {
+		return http.StatusConflict, models.AuditSightingModel{}, fmt.Errorf("audit session is closed")
+	}
+
+	if record.WorkspaceID > 0 {
+		if session.DepartmentID == nil {
+			return http.StatusBadRequest, models.AuditSightingModel{}, fmt.Errorf("workspaces can only be recorded in department audits")
+		}
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM workspaces WHERE id = $1 AND department_id = $2)", record.WorkspaceID, *session.DepartmentID).Scan(&exists); err != nil {
+			log.Printf("error while checking workspace: %v", err)
+			return http.StatusInternalServerError, models.AuditSightingModel{}, fmt.Errorf("database error")
+		} else if !exists {
+			return http.StatusNotFound, models.AuditSightingModel{}, fmt.Errorf("no matching workspace found")
+		}
+	}
+
+	var sighting auditSighting
+	if record.UnitID > 0 {
+		sighting = auditSighting{Prefix: record.Prefix, UnitID: sql.NullInt64{Int64: int64(record.UnitID), Valid: true}, Code: record.Code}
+		var component_id int
+		err := tx.QueryRow(`SELECT c.id, c.prefix FROM components c
+			JOIN warehouses w ON w.id = c.warehouse_id
+			JOIN branches b ON b.branch_id = w.branch_id
+			WHERE lower(c.prefix) = $1 AND b.org_id = $2 AND c.kind = 'unit'`, record.Prefix, scope.OrgID).Scan(&component_id, &sighting.Prefix)
+		if err != nil && err != sql.ErrNoRows {
+			log.Printf("error while resolving prefix %v: %v", record.Prefix, err)
+			return http.StatusInternalServerError, models.AuditSightingModel{}, fmt.Errorf("database error")
+		} else if err == nil {
+			sighting.ComponentID = sql.NullInt64{Int64: int64(component_id), Valid: true}
+		}
+	} else {
+		if sighting, err = findUnitByCode(tx, scope.OrgID, record.Code); err == errAmbiguousCode {
+			return http.StatusConflict, models.AuditSightingModel{}, err
+		} else if err != nil {
+			log.Printf("error while resolving code %v: %v", record.Code, err)
+			return http.StatusInternalServerError, models.AuditSightingModel{}, fmt.Errorf("database error")
+		}
+	}
+
+	result := models.AuditSightingModel{
+		Prefix:      sighting.Prefix,
+		UnitID:      nullIntPointer(sighting.UnitID),
+		Code:        sighting.Code,
+		WorkspaceID: nullIntPointer(sql.NullInt64{Int64: int64(record.WorkspaceID), Valid: record.WorkspaceID > 0}),
+	}
+
+	query := `INSERT INTO audit_sightings(session_id, component_id, prefix, unit_id, code, workspace_id, recorded_by_role, recorded_by)
+		VALUES($1, $2, NULLIF($3, ''), $4, NULLIF($5, ''), NULLIF($6, 0), $7, $8) RETURNING id, recorded_at`
+	if err := tx.QueryRow(query, record.SessionID, sighting.ComponentID, sighting.Prefix, sighting.UnitID, sighting.Code, record.WorkspaceID, scope.Role, scope.UserID).Scan(&result.SightingID, &result.RecordedAt); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, models.AuditSightingModel{}, fmt.Errorf("unit is already recorded in this session")
+		}
+		log.Printf("error while recording sighting: %v", err)
+		return http.StatusInternalServerError, models.AuditSightingModel{}, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing sighting: %v", err)
+		return http.StatusInternalServerError, models.AuditSightingModel{}, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, result, nil
+}
+
+func (q *Query) GetAuditSessions(scope models.ScopeModel, spec queryspec.Spec) (int, []models.AuditSessionModel, models.PageModel, error) {
+	base := fmt.Sprintf("SELECT %s FROM audit_sessions s WHERE s.warehouse_id = ANY($1) OR s.department_id = ANY($2)", auditSessionColumns)
+
+	sessions := []models.AuditSessionModel{}
+
+	page, err := q.listPage(spec, base, "id, warehouse_id, department_id, status, note, opened_by_role, opened_by, opened_at, closed_at, sightings, discrepancies", []interface{}{pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs)}, func(rows *sql.Rows, extra ...interface{}) error {
+		var session models.AuditSessionModel
+		dest := []interface{}{&session.SessionID, &session.WarehouseID, &session.DepartmentID, &session.Status, &session.Note, &session.OpenedByRole, &session.OpenedBy, &session.OpenedAt, &session.ClosedAt, &session.Sightings, &session.Discrepancies}
+		if err := rows.Scan(append(dest, extra...)...); err != nil {
+			return err
+		}
+		sessions = append(sessions, session)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, sessions, page, nil
+}
+
+// expectedAuditUnits lists the units that should be found by the session: the unassigned units of an
+// audited warehouse, or the units assigned to an audited department. Retired units are not expected
+func (q *Query) expectedAuditUnits(tx *sql.Tx, session models.AuditSessionModel) ([]auditUnit, error) {
+	scope := models.ScopeModel{WarehouseIDs: []int{}, DepartmentIDs: []int{}}
+	condition := "department_id = ANY($2)"
+	if session.WarehouseID != nil {
+		scope.WarehouseIDs = []int{*session.WarehouseID}
+		condition = "warehouse_id = ANY($1) AND department_id IS NULL"
+	} else {
+		scope.DepartmentIDs = []int{*session.DepartmentID}
+	}
+
+	components, err := q.GetScopeComponents(scope)
+	if err != nil || len(components) == 0 {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`%s
+	SELECT s.component_id, s.prefix, s.unit_id, s.warehouse_id, s.department_id, s.workspace_id
+	FROM scoped s
+	JOIN components c ON c.id = s.component_id
+	WHERE %s AND s.status <> 'exit' AND c.kind = 'unit'
+	ORDER BY s.prefix, s.unit_id`, scopedUnitsQuery(components), condition)
+
+	rows, err := tx.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var units []auditUnit
+	for rows.Next() {
+		var unit auditUnit
+		if err := rows.Scan(&unit.ComponentID, &unit.Prefix, &unit.UnitID, &unit.WarehouseID, &unit.DepartmentID, &unit.WorkspaceID); err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+
+	return units, rows.Err()
+}
+
+// compareAudit reconciles the sightings of a session against the expected units
+func (q *Query) compareAudit(tx *sql.Tx, session models.AuditSessionModel) (int, []models.AuditDiscrepancyModel, []auditUnit, error) {
+	expected, err := q.expectedAuditUnits(tx, session)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	rows, err := tx.Query("SELECT component_id, COALESCE(prefix, ''), unit_id, COALESCE(code, ''), workspace_id FROM audit_sightings WHERE session_id = $1 ORDER BY id", session.SessionID)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	var sightings []auditSighting
+	for rows.Next() {
+		var sighting auditSighting
+		if err := rows.Scan(&sighting.ComponentID, &sighting.Prefix, &sighting.UnitID, &sighting.Code, &sighting.WorkspaceID); err != nil {
+			rows.Close()
+			return 0, nil, nil, err
+		}
+		sightings = append(sightings, sighting)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, nil, err
+	}
+
+	expectedUnits := make(map[[2]int]auditUnit, len(expected))
+	for _, unit := range expected {
+		expectedUnits[[2]int{unit.ComponentID, unit.UnitID}] = unit
+	}
+
+	discrepancies := []models.AuditDiscrepancyModel{}
+	var mislocated []auditUnit
+	seen := make(map[[2]int]bool)
+
+	for _, sighting := range sightings {
+		discrepancy := models.AuditDiscrepancyModel{
+			ComponentID:      nullIntPointer(sighting.ComponentID),
+			Prefix:           sighting.Prefix,
+			UnitID:           nullIntPointer(sighting.UnitID),
+			Code:             sighting.Code,
+			FoundWorkspaceID: nullIntPointer(sighting.WorkspaceID),
+			Action:           "none",
+		}
+
+		if !sighting.ComponentID.Valid || !sighting.UnitID.Valid {
+			discrepancy.Kind = "unexpected"
+			discrepancies = append(discrepancies, discrepancy)
+			continue
+		}
+
+		key := [2]int{int(sighting.ComponentID.Int64), int(sighting.UnitID.Int64)}
+		seen[key] = true
+
+		unit, ok := expectedUnits[key]
+		if !ok {
+			unit = auditUnit{ComponentID: key[0], Prefix: sighting.Prefix, UnitID: key[1]}
+			query := fmt.Sprintf(`SELECT u.warehouse_id, a.department_id, a.workspace_id
+				FROM %[1]s_units u
+				LEFT JOIN %[1]s_units_assigned a ON a.unit_id = u.id
+				WHERE u.id = $1`, sighting.Prefix)
+			if err := tx.QueryRow(query, unit.UnitID).Scan(&unit.WarehouseID, &unit.DepartmentID, &unit.WorkspaceID); err == sql.ErrNoRows {
+				discrepancy.Kind = "unexpected"
+				discrepancies = append(discrepancies, discrepancy)
+				continue
+			} else if err != nil {
+				return 0, nil, nil, err
+			}
+		} else if !sighting.WorkspaceID.Valid || !unit.WorkspaceID.Valid || sighting.WorkspaceID.Int64 == unit.WorkspaceID.Int64 {
+			continue
+		}
+
+		discrepancy.Kind = "mislocated"
+		discrepancy.ExpectedWarehouseID = &unit.WarehouseID
+		discrepancy.ExpectedDepartmentID = nullIntPointer(unit.DepartmentID)
+		discrepancy.ExpectedWorkspaceID = nullIntPointer(unit.WorkspaceID)
+		discrepancies = append(discrepancies, discrepancy)
+		unit.WorkspaceID = sighting.WorkspaceID
+		mislocated = append(mislocated, unit)
+	}
+
+	for _, unit := range expected {
+		if seen[[2]int{unit.ComponentID, unit.UnitID}] {
+			continue
+		}
+		component_id := unit.ComponentID
+		unit_id := unit.UnitID
+		warehouse_id := unit.WarehouseID
+		discrepancies = append(discrepancies, models.AuditDiscrepancyModel{
+			ComponentID:          &component_id,
+			Prefix:               unit.Prefix,
+			UnitID:               &unit_id,
+			Kind:                 "missing",
+			ExpectedWarehouseID:  &warehouse_id,
+			ExpectedDepartmentID: nullIntPointer(unit.DepartmentID),
+			ExpectedWorkspaceID:  nullIntPointer(unit.WorkspaceID),
+			Action:               "none",
+		})
+	}
+
+	return len(expected), discrepancies, mislocated, nil
+}
+
+func auditReport(session models.AuditSessionModel, expected int, discrepancies []models.AuditDiscrepancyModel) models.AuditReportModel {
+	report := models.AuditReportModel{Session: session, Expected: expected, Discrepancies: discrepancies}
+	for _, discrepancy := range discrepancies {
+		switch discrepancy.Kind {
+		case "missing":
+			report.Missing++
+		case "unexpected":
+			report.Unexpected++
+		case "mislocated":
+			report.Mislocated++
+		}
+	}
+	return report
+}
+
+// GetAuditReport returns the stored report of a closed session, or a preview computed from the current
+// records while the session is open
+func (q *Query) GetAuditReport(scope models.ScopeModel, session_id int) (int, models.AuditReportModel, error) {
+	tx, session, status, err := q.beginAuditSession(scope, session_id, false)
+	if err != nil {
+		return status, models.AuditReportModel{}, err
+	}
+	defer tx.Rollback()
+
+	if session.Status == "open" {
+		expected, discrepancies, _, err := q.compareAudit(tx, session)
+		if err != nil {
+			log.Printf("error while comparing audit session %v: %v", session_id, err)
+			return http.StatusInternalServerError, models.AuditReportModel{}, fmt.Errorf("error occured while retrieving data")
+		}
+		return http.StatusOK, auditReport(session, expected, discrepancies), nil
+	}
+
+	var expected int
+	if err := tx.QueryRow("SELECT COALESCE(expected, 0) FROM audit_sessions WHERE id = $1", session_id).Scan(&expected); err != nil {
+		log.Printf("error while getting audit session %v: %v", session_id, err)
+		return http.StatusInternalServerError, models.AuditReportModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	rows, err := tx.Query(`SELECT component_id, COALESCE(prefix, ''), unit_id, COALESCE(code, ''), kind, expected_warehouse_id, expected_department_id, expected_workspace_id, found_workspace_id, action
+		FROM audit_discrepancies WHERE session_id = $1 ORDER BY id`, session_id)
+	if err != nil {
+		log.Printf("error while getting audit discrepancies: %v", err)
+		return http.StatusInternalServerError, models.AuditReportModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	discrepancies := []models.AuditDiscrepancyModel{}
+	for rows.Next() {
+		var discrepancy models.AuditDiscrepancyModel
+		if err := rows.Scan(&discrepancy.ComponentID, &discrepancy.Prefix, &discrepancy.UnitID, &discrepancy.Code, &discrepancy.Kind, &discrepancy.ExpectedWarehouseID, &discrepancy.ExpectedDepartmentID, &discrepancy.ExpectedWorkspaceID, &discrepancy.FoundWorkspaceID, &discrepancy.Action); err != nil {
+			log.Printf("error while scanning audit discrepancies: %v", err)
+			return http.StatusInternalServerError, models.AuditReportModel{}, fmt.Errorf("error occured while retrieving data")
+		}
+		discrepancies = append(discrepancies, discrepancy)
+	}
+
+	return http.StatusOK, auditReport(session, expected, discrepancies), nil
+}
+
+// applyAuditActions runs the corrective actions requested on close and records what was done per discrepancy.
+// Missing units get missing_status; mislocated units are moved to where they were found when they belong to the
+// audited location, units linked into another unit are left for their parent
+func (q *Query) applyAuditActions(tx *sql.Tx, session models.AuditSessionModel, closeSession models.CloseAuditSessionModel, discrepancies []models.AuditDiscrepancyModel, mislocated []auditUnit) error {
+	moves := make(map[[2]int]auditUnit, len(mislocated))
+	for _, unit := range mislocated {
+		moves[[2]int{unit.ComponentID, unit.UnitID}] = unit
+	}
+
+	for i := range discrepancies {
+		discrepancy := &discrepancies[i]
+		if discrepancy.ComponentID == nil || discrepancy.UnitID == nil {
+			continue
+		}
+
+		switch {
+		case discrepancy.Kind == "missing" && closeSession.MissingStatus != "":
+			query := fmt.Sprintf("UPDATE %s_units SET status = $1 WHERE id = $2", discrepancy.Prefix)
+			if _, err := tx.Exec(query, closeSession.MissingStatus, *discrepancy.UnitID); err != nil {
+				return err
+			}
+			discrepancy.Action = "status_" + closeSession.MissingStatus
+		case discrepancy.Kind == "mislocated" && closeSession.RelocateMislocated:
+			unit := moves[[2]int{*discrepancy.ComponentID, *discrepancy.UnitID}]
+			parent, err := getUnitParent(tx, unit.ComponentID, unit.UnitID)
+			if err != nil {
+				return err
+			} else if parent != nil {
+				discrepancy.Action = "skipped_linked"
+				continue
+			}
+
+			linked, err := getUnitDescendants(tx, unit.ComponentID, unit.UnitID)
+			if err != nil {
+				return err
+			}
+			moved := append([]linkedUnit{{ComponentID: unit.ComponentID, Prefix: unit.Prefix, UnitID: unit.UnitID}}, linked...)
+
+			if session.DepartmentID != nil && unit.WorkspaceID.Valid {
+				for _, m := range moved {
+					if err := moveUnitAssignment(tx, m.Prefix, m.UnitID, *session.DepartmentID, int(unit.WorkspaceID.Int64), session.OpenedBy); err != nil {
+						return err
+					}
+				}
+				discrepancy.Action = "relocated"
+			} else if session.WarehouseID != nil && unit.WarehouseID == *session.WarehouseID {
+				for _, m := range moved {
+					if err := unassignUnit(tx, m.Prefix, m.UnitID, session.OpenedBy); err != nil {
+						return err
+					}
+				}
+				discrepancy.Action = "returned_to_warehouse"
+			} else {
+				discrepancy.Action = "skipped_other_location"
+			}
+		}
+	}
+
+	return nil
+}
+
+// CloseAuditSession computes the discrepancy report, applies the requested corrective actions and stores
+// the report with the session in one transaction
+func (q *Query) CloseAuditSession(scope models.ScopeModel, closeSession models.CloseAuditSessionModel) (int, models.AuditReportModel, error) {
+	tx, session, status, err := q.beginAuditSession(scope, closeSession.SessionID, true)
+	if err != nil {
+		return status, models.AuditReportModel{}, err
+	}
+	defer tx.Rollback()
+
+	if session.Status != "open" {
+		return http.StatusConflict, models.AuditReportModel{}, fmt.Errorf("audit session is already closed")
+	}
+
+	expected, discrepancies, mislocated, err := q.compareAudit(tx, session)
+	if err != nil {
+		log.Printf("error while comparing audit session %v: %v", closeSession.SessionID, err)
+		return http.StatusInternalServerError, models.AuditReportModel{}, fmt.Errorf("database error")
+	}
+
+	if err := q.applyAuditActions(tx, session, closeSession, discrepancies, mislocated); err != nil {
+		log.Printf("error while applying audit actions for session %v: %v", closeSession.SessionID, err)
+		return http.StatusInternalServerError, models.AuditReportModel{}, fmt.Errorf("database error")
+	}
+
+	query := `INSERT INTO audit_discrepancies(session_id, component_id, prefix, unit_id, code, kind, expected_warehouse_id, expected_department_id, expected_workspace_id, found_workspace_id, action)
+		VALUES($1, $2, NULLIF($3, ''), $4, NULLIF($5, ''), $6, $7, $8, $9, $10, $11)`
+	for _, discrepancy := range discrepancies {
+		if _, err := tx.Exec(query, closeSession.SessionID, discrepancy.ComponentID, discrepancy.Prefix, discrepancy.UnitID, discrepancy.Code, discrepancy.Kind, discrepancy.ExpectedWarehouseID, discrepancy.ExpectedDepartmentID, discrepancy.ExpectedWorkspaceID, discrepancy.FoundWorkspaceID, discrepancy.Action); err != nil {
+			log.Printf("error while storing audit discrepancy: %v", err)
+			return http.StatusInternalServerError, models.AuditReportModel{}, fmt.Errorf("database error")
+		}
+	}
+
+	if err := tx.QueryRow("UPDATE audit_sessions SET status = 'closed', expected = $2, closed_by_role = $3, closed_by = $4, closed_at = NOW() WHERE id = $1 RETURNING closed_at", closeSession.SessionID, expected, scope.Role, scope.UserID).Scan(&session.ClosedAt); err != nil {
+		log.Printf("error while closing audit session %v: %v", closeSession.SessionID, err)
+		return http.StatusInternalServerError, models.AuditReportModel{}, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing audit session %v: %v", closeSession.SessionID, err)
+		return http.StatusInternalServerError, models.AuditReportModel{}, fmt.Errorf("database error")
+	}
+
+	session.Status = "closed"
+	session.Discrepancies = len(discrepancies)
+
+	return http.StatusOK, auditReport(session, expected, discrepancies), nil
+}
@@ -47,12 +47,37 @@ func (q *Query) UpdateUserTokenTimestamp(email string, T time.Time) error {
 	return nil
 }
 
+// staffAccountsQuery selects the active staff accounts with the role and id of the department head or
+// warehouse account they sign in as
+const staffAccountsQuery = `SELECT s.id, s.user_email, s.name, s.password, s.staff_role,
+	CASE WHEN s.department_id IS NULL THEN 'warehouses' ELSE 'department_head' END AS account_type,
+	COALESCE(dh.id, s.warehouse_id) AS account_id
+	FROM staff s LEFT JOIN department_head dh ON dh.department_id = s.department_id
+	WHERE s.active`
+
+// GetStaffAccount returns the staff id and role of an email, ok is false for the primary account of a unit
+func (q *Query) GetStaffAccount(userEmail string) (int, string, bool, error) {
+	var staff_id int
+	var staff_role string
+	if err := q.db.QueryRow("SELECT id, staff_role FROM ("+staffAccountsQuery+") a WHERE user_email = $1", userEmail).Scan(&staff_id, &staff_role); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, "", false, nil
+		}
+		return 0, "", false, err
+	}
+	return staff_id, staff_role, true, nil
+}
+
 func (q *Query) GetUserPasswordID(userEmail, userType string) (string, string, int, bool, error) {
 	var db_password, db_name string
 	var db_id int
 
 	query := fmt.Sprintf("SELECT password, id, name FROM %s WHERE email = $1", userType)
-	if err := q.db.QueryRow(query, userEmail).Scan(&db_password, &db_id, &db_name); err != nil {
+	err := q.db.QueryRow(query, userEmail).Scan(&db_password, &db_id, &db_name)
+	if err == sql.ErrNoRows {
+		err = q.db.QueryRow("SELECT password, account_id, name FROM ("+staffAccountsQuery+") a WHERE user_email = $1 AND account_type = $2", userEmail, userType).Scan(&db_password, &db_id, &db_name)
+	}
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", "", -1, false, nil
 		}
@@ -65,7 +90,11 @@ func (q *Query) VerifyUser(userEmail, userType string, userID int) (bool, error)
 	var exists int
 	log.Println(userType, userEmail, userID)
 	query := fmt.Sprintf("SELECT 1 FROM %s WHERE email = $1 AND id = $2", userType)
-	if err := q.db.QueryRow(query, userEmail, userID).Scan(&exists); err != nil {
+	err := q.db.QueryRow(query, userEmail, userID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		err = q.db.QueryRow("SELECT 1 FROM ("+staffAccountsQuery+") a WHERE user_email = $1 AND account_type = $2 AND account_id = $3", userEmail, userType, userID).Scan(&exists)
+	}
+	if err != nil {
 		log.Println(err)
 		if err == sql.ErrNoRows {
 			return false, nil
@@ -90,9 +119,17 @@ func (q *Query) GetLatestTokenTime(userEmail, userType string) (time.Time, error
 
 func (q *Query) ChangeUserPassword(newPassword, userEmail, userType string) (int, error) {
 	query := fmt.Sprintf("UPDATE %s SET password = $1 WHERE email = $2", userType)
-	if _, err := q.db.Exec(query, newPassword, userEmail); err != nil {
+	result, err := q.db.Exec(query, newPassword, userEmail)
+	if err != nil {
 		return http.StatusInternalServerError, err
 	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return http.StatusInternalServerError, err
+	} else if rows == 0 {
+		if _, err := q.db.Exec("UPDATE staff SET password = $1 WHERE user_email = $2 AND active", newPassword, userEmail); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
 	return http.StatusOK, nil
 }
 
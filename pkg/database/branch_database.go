@@ -9,8 +9,7 @@ import (
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
 )
 
-func (q *Query) CreateDepartment(department models.CreateDepartmentModel, branch_head_id int, password string) error {
-	query0 := "SELECT branch_id FROM branch_head WHERE id = $1"
+func (q *Query) CreateDepartment(department models.CreateDepartmentModel, branch_id int, password string, invitation models.NewInvitationModel) error {
 	query1 := "INSERT INTO departments(branch_id, department_name) VALUES($1, $2) RETURNING department_id"
 	query2 := "INSERT INTO users(user_email, user_level) VALUES($1, $2)"
 	query3 := "INSERT INTO department_head(department_id, name, email, password) VALUES($1, $2, $3, $4)"
@@ -29,11 +28,7 @@ func (q *Query) CreateDepartment(department models.CreateDepartmentModel, branch
 		}
 	}()
 
-	var department_id, branch_id int
-
-	if err = tx.QueryRow(query0, branch_head_id).Scan(&branch_id); err != nil {
-		return err
-	}
+	var department_id int
 
 	if err = tx.QueryRow(query1, branch_id, department.DepartmentName).Scan(&department_id); err != nil {
 		return err
@@ -47,16 +42,14 @@ func (q *Query) CreateDepartment(department models.CreateDepartmentModel, branch
 		return err
 	}
 
+	if err = createInvitation(tx, department.DepartmentHeadEmail, invitation); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func (q *Query) CreateWarehouse(warehouse models.CreateWarehouseModel, branchHeadID int, password string) (int, error) {
-	query0 := `
-    SELECT branch_id 
-    FROM branch_head 
-    WHERE id = $1
-`
-
+func (q *Query) CreateWarehouse(warehouse models.CreateWarehouseModel, branch_id int, password string, invitation models.NewInvitationModel) (int, error) {
 	query1 := `
     INSERT INTO users(user_email, user_level) 
     VALUES($1, $2)
@@ -69,7 +62,6 @@ func (q *Query) CreateWarehouse(warehouse models.CreateWarehouseModel, branchHea
 `
 
 	var (
-		branch_id int
 		// user_id      int
 		warehouse_id int
 	)
@@ -89,14 +81,6 @@ func (q *Query) CreateWarehouse(warehouse models.CreateWarehouseModel, branchHea
 		}
 	}()
 
-	if err = tx.QueryRow(query0, branchHeadID).Scan(&branch_id); err != nil {
-		log.Println("query0")
-		if err == sql.ErrNoRows {
-			return http.StatusNotFound, err
-		}
-		return http.StatusInternalServerError, err
-	}
-
 	if _, err = tx.Exec(query1, warehouse.WarehouseUserEmail, "warehouses"); err != nil {
 		log.Println("query1")
 		return http.StatusInternalServerError, err
@@ -107,18 +91,22 @@ func (q *Query) CreateWarehouse(warehouse models.CreateWarehouseModel, branchHea
 		return http.StatusInternalServerError, err
 	}
 
+	if err = createInvitation(tx, warehouse.WarehouseUserEmail, invitation); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
 	log.Printf("New warehouse created: warehouse_id=%d, user_email=%s, branch_id=%d", warehouse_id, warehouse.WarehouseUserEmail, branch_id)
 
 	return http.StatusCreated, nil
 
 }
 
-func (q *Query) UpdateDepartmentHead(department_head models.UpdateDepartmentHeadModel, branch_head_id int, password string) (int, error) {
-	query1 := "DELETE FROM department_heads WHERE email =$1 RETURNING department_id, id"
+func (q *Query) UpdateDepartmentHead(department_head models.UpdateDepartmentHeadModel, deleted_by int, password string, invitation models.NewInvitationModel) (int, error) {
+	query1 := "DELETE FROM department_head WHERE email = $1 AND department_id = $2 RETURNING department_id, id"
 	query2 := "DELETE FROM users WHERE user_email = $1"
-	query3 := "INSERT INTO deleted_department_heads(department_id, department_head_id, email, deleted_by) VALUES($1, $2, $3, $4)"
-	query4 := "INSERT INTO users(user_email, user_level) VALUES($1, 2)"
-	query5 := "INSERT INTO department_heads(department_id, name, email, password) VALUES($1, $2, $3, $4)"
+	query3 := "INSERT INTO deleted_department_head(department_id, department_head_id, email, deleted_by) VALUES($1, $2, $3, $4)"
+	query4 := "INSERT INTO users(user_email, user_level) VALUES($1, $2)"
+	query5 := "INSERT INTO department_head(department_id, name, email, password) VALUES($1, $2, $3, $4)"
 
 	tx, err := q.db.Begin()
 	if err != nil {
@@ -137,7 +125,7 @@ func (q *Query) UpdateDepartmentHead(department_head models.UpdateDepartmentHead
 
 	var department_id, department_head_id int
 
-	if err = tx.QueryRow(query1, department_head.DepartmentHeadEmail).Scan(&department_id, &department_head_id); err != nil {
+	if err = tx.QueryRow(query1, department_head.DepartmentHeadEmail, department_head.DepartmentID).Scan(&department_id, &department_head_id); err != nil {
 		if err == sql.ErrNoRows {
 			return http.StatusNotFound, fmt.Errorf("no matching data found")
 		}
@@ -148,11 +136,11 @@ func (q *Query) UpdateDepartmentHead(department_head models.UpdateDepartmentHead
 		return http.StatusInternalServerError, err
 	}
 
-	if _, err = tx.Exec(query3, department_id, department_head_id, department_head.DepartmentHeadEmail, branch_head_id); err != nil {
+	if _, err = tx.Exec(query3, department_id, department_head_id, department_head.DepartmentHeadEmail, deleted_by); err != nil {
 		return http.StatusInternalServerError, err
 	}
 
-	if _, err = tx.Exec(query4, department_head.NewDepartmentHeadEmail, "department_heads"); err != nil {
+	if _, err = tx.Exec(query4, department_head.NewDepartmentHeadEmail, "department_head"); err != nil {
 		return http.StatusInternalServerError, err
 	}
 
@@ -160,12 +148,16 @@ func (q *Query) UpdateDepartmentHead(department_head models.UpdateDepartmentHead
 		return http.StatusInternalServerError, err
 	}
 
+	if err = createInvitation(tx, department_head.NewDepartmentHeadEmail, invitation); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
 	return http.StatusOK, nil
 }
 
-func (q *Query) UpdateWarehouseHead(warehouse_head models.UpdateWarehouseHeadModel, branch_head_id int, password string) (int, error) {
-	query1 := "INSERT INTO users(user_email, user_level) VALUES($1, 2)"
-	query2 := "UPDATE warehouses SET name = $1, email = $2, password = $3 WHERE id = $4"
+func (q *Query) UpdateWarehouseHead(warehouse_head models.UpdateWarehouseHeadModel, deleted_by int, password string, invitation models.NewInvitationModel) (int, error) {
+	query1 := "INSERT INTO users(user_email, user_level) VALUES($1, $2)"
+	query2 := "UPDATE warehouses SET name = $1, email = $2, password = $3 WHERE id = $4 AND email = $5 RETURNING id"
 	query3 := "INSERT INTO deleted_warehouse_heads(warehouse_id, email, deleted_by) VALUES($1, $2, $3)"
 	query4 := "DELETE FROM users WHERE user_email = $1"
 
@@ -190,14 +182,14 @@ func (q *Query) UpdateWarehouseHead(warehouse_head models.UpdateWarehouseHeadMod
 		return http.StatusInternalServerError, err
 	}
 
-	if err = tx.QueryRow(query2, warehouse_head.NewWarehouseHeadName, warehouse_head.NewWarehouseHeadEmail, password, warehouse_head.WarehouseID).Scan(&warehouse_id); err != nil {
+	if err = tx.QueryRow(query2, warehouse_head.NewWarehouseHeadName, warehouse_head.NewWarehouseHeadEmail, password, warehouse_head.WarehouseID, warehouse_head.WarehouseHeadEmail).Scan(&warehouse_id); err != nil {
 		if err == sql.ErrNoRows {
 			return http.StatusNotFound, fmt.Errorf("no matching data found")
 		}
 		return http.StatusInternalServerError, err
 	}
 
-	if _, err = tx.Exec(query3, warehouse_id, warehouse_head.NewWarehouseHeadEmail, branch_head_id); err != nil {
+	if _, err = tx.Exec(query3, warehouse_id, warehouse_head.WarehouseHeadEmail, deleted_by); err != nil {
 		return http.StatusInternalServerError, err
 	}
 
@@ -205,27 +197,59 @@ func (q *Query) UpdateWarehouseHead(warehouse_head models.UpdateWarehouseHeadMod
 		return http.StatusInternalServerError, err
 	}
 
+	if err = createInvitation(tx, warehouse_head.NewWarehouseHeadEmail, invitation); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
 	return http.StatusOK, nil
 }
 
-func (q *Query) DeleteDepartment(department_id, deleted_by int) (int, error) {
+func (q *Query) DeleteDepartment(department_id, deleted_by int, preview_token string) (int, error) {
 	query := "CALL delete_department($1, $2);"
 
-	_, err := q.db.Exec(query, department_id, deleted_by)
+	tx, err := q.beginDeletion()
 	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
 		return http.StatusInternalServerError, err
 	}
+	defer tx.Rollback()
+
+	if status, err := q.checkDeletionToken(tx, "department", department_id, preview_token); err != nil {
+		return status, err
+	}
+
+	if _, err := tx.Exec(query, department_id, deleted_by); err != nil {
+		return deletionFailed(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return deletionFailed(err)
+	}
 
 	return http.StatusNoContent, nil
 }
 
-func (q *Query) DeleteWarehouse(warehouse_id, deleted_by int) (int, error) {
+func (q *Query) DeleteWarehouse(warehouse_id, deleted_by int, preview_token string) (int, error) {
 	query := "CALL delete_warehouse($1, $2);"
 
-	_, err := q.db.Exec(query, warehouse_id, deleted_by)
+	tx, err := q.beginDeletion()
 	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
 		return http.StatusInternalServerError, err
 	}
+	defer tx.Rollback()
+
+	if status, err := q.checkDeletionToken(tx, "warehouse", warehouse_id, preview_token); err != nil {
+		return status, err
+	}
+
+	if _, err := tx.Exec(query, warehouse_id, deleted_by); err != nil {
+		return deletionFailed(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return deletionFailed(err)
+	}
 
 	return http.StatusNoContent, nil
 }
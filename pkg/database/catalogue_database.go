@@ -0,0 +1,279 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
+)
+
+var CatalogueResource = queryspec.Resource{
+	IDColumn:    "id",
+	DefaultSort: "id",
+	Fields: map[string]queryspec.Field{
+		"id":             {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true, Searchable: true},
+		"category_id":    {Column: "category_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"category":       {Column: "category", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"name":           {Column: "name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"manufacturer":   {Column: "manufacturer", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"model_number":   {Column: "model_number", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"warehouses":     {Column: "warehouses", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"total_units":    {Column: "total_units", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"assigned_units": {Column: "assigned_units", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"created_at":     {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+func (q *Query) CreateCategory(org_id int, name string) (int, int, error) {
+	var exists bool
+	if err := q.db.QueryRow("SELECT EXISTS(SELECT 1 FROM component_categories WHERE (org_id = $1 OR org_id IS NULL) AND lower(name) = lower($2))", org_id, name).Scan(&exists); err != nil {
+		log.Printf("error while checking category: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	} else if exists {
+		return http.StatusConflict, -1, fmt.Errorf("category already exists")
+	}
+
+	var id int
+	if err := q.db.QueryRow("INSERT INTO component_categories(org_id, name) VALUES($1, $2) RETURNING id", org_id, name).Scan(&id); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, -1, fmt.Errorf("category already exists")
+		}
+		log.Printf("error while creating category: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, id, nil
+}
+
+// GetCategories lists the default categories together with the ones added by the organization
+func (q *Query) GetCategories(org_id int) (int, []models.CategoryModel, error) {
+	query := `SELECT cc.id, cc.name, cc.org_id IS NULL, COUNT(cm.id)
+		FROM component_categories cc
+		LEFT JOIN catalogue_models cm ON cm.category_id = cc.id AND cm.org_id = $1
+		WHERE cc.org_id = $1 OR cc.org_id IS NULL
+		GROUP BY cc.id
+		ORDER BY cc.org_id NULLS FIRST, cc.name`
+
+	rows, err := q.db.Query(query, org_id)
+	if err != nil {
+		log.Printf("error while getting categories: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	categories := []models.CategoryModel{}
+	for rows.Next() {
+		var category models.CategoryModel
+		if err := rows.Scan(&category.CategoryID, &category.Name, &category.Global, &category.Models); err != nil {
+			log.Printf("error while scanning categories: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		categories = append(categories, category)
+	}
+
+	return http.StatusOK, categories, nil
+}
+
+func (q *Query) CreateCatalogueModel(org_id int, model models.CreateCatalogueModel) (int, int, error) {
+	var exists bool
+	if err := q.db.QueryRow("SELECT EXISTS(SELECT 1 FROM component_categories WHERE id = $1 AND (org_id = $2 OR org_id IS NULL))", model.CategoryID, org_id).Scan(&exists); err != nil {
+		log.Printf("error while checking category: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	} else if !exists {
+		return http.StatusNotFound, -1, fmt.Errorf("category not found")
+	}
+
+	query := "INSERT INTO catalogue_models(org_id, category_id, name, manufacturer, model_number) VALUES($1, $2, $3, $4, $5) RETURNING id"
+
+	var id int
+	if err := q.db.QueryRow(query, org_id, model.CategoryID, model.Name, model.Manufacturer, model.ModelNumber).Scan(&id); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, -1, fmt.Errorf("model already exists in the catalogue")
+		}
+		log.Printf("error while creating catalogue model: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, id, nil
+}
+
+// GetCatalogue lists the organization catalogue with how many units of each model are stocked in the scope
+func (q *Query) GetCatalogue(scope models.ScopeModel, spec queryspec.Spec) (int, []models.CatalogueModel, models.PageModel, error) {
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, err
+	}
+
+	args := []interface{}{pq.Array(scope.WarehouseIDs)}
+	with := "WITH "
+	stock := "SELECT NULL::INTEGER AS catalogue_id, 0::BIGINT AS total_units, 0::BIGINT AS assigned_units WHERE FALSE"
+
+	if len(components) > 0 {
+		args = append(args, pq.Array(scope.DepartmentIDs))
+		with = scopedUnitsQuery(components) + ",\n"
+		stock = `SELECT c.catalogue_id, COUNT(*) AS total_units, COUNT(s.department_id) AS assigned_units
+			FROM scoped s
+			JOIN components c ON c.id = s.component_id
+			WHERE c.catalogue_id IS NOT NULL
+			GROUP BY c.catalogue_id`
+	}
+	args = append(args, scope.OrgID)
+
+	base := fmt.Sprintf(`%sstock AS (%s)
+		SELECT cm.id, cm.category_id, cc.name AS category, cm.name, cm.manufacturer, cm.model_number, cm.created_at,
+			(SELECT COUNT(*) FROM components c WHERE c.catalogue_id = cm.id AND c.warehouse_id = ANY($1)) AS warehouses,
+			COALESCE(st.total_units, 0) AS total_units,
+			COALESCE(st.assigned_units, 0) AS assigned_units
+		FROM catalogue_models cm
+		JOIN component_categories cc ON cc.id = cm.category_id
+		LEFT JOIN stock st ON st.catalogue_id = cm.id
+		WHERE cm.org_id = $%d`, with, stock, len(args))
+
+	catalogue := []models.CatalogueModel{}
+
+	page, err := q.listPage(spec, base, "id, category_id, category, name, manufacturer, model_number, warehouses, total_units, assigned_units, created_at", args, func(rows *sql.Rows, extra ...interface{}) error {
+		var model models.CatalogueModel
+		dest := []interface{}{&model.CatalogueID, &model.CategoryID, &model.Category, &model.Name, &model.Manufacturer, &model.ModelNumber, &model.Warehouses, &model.TotalUnits, &model.AssignedUnits, &model.CreatedAt}
+		if err := rows.Scan(append(dest, extra...)...); err != nil {
+			return err
+		}
+		catalogue = append(catalogue, model)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, catalogue, page, nil
+}
+
+// GetCatalogueStock breaks the stock of one catalogue model down by the components stocking it in the scope
+func (q *Query) GetCatalogueStock(scope models.ScopeModel, catalogue_id int) (int, []models.CatalogueStockModel, error) {
+	var exists bool
+	if err := q.db.QueryRow("SELECT EXISTS(SELECT 1 FROM catalogue_models WHERE id = $1 AND org_id = $2)", catalogue_id, scope.OrgID).Scan(&exists); err != nil {
+		log.Printf("error while checking catalogue model: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	} else if !exists {
+		return http.StatusNotFound, nil, fmt.Errorf("no matching data found")
+	}
+
+	scoped, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	ids := make([]int, 0, len(scoped))
+	for _, component := range scoped {
+		ids = append(ids, component.ComponentID)
+	}
+
+	stocking, err := q.collectIDs("SELECT id FROM components WHERE catalogue_id = $1 AND id = ANY($2)", catalogue_id, pq.Array(ids))
+	if err != nil {
+		log.Printf("error while getting catalogue components: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	}
+
+	components := []models.ComponentModel{}
+	for _, component := range scoped {
+		for _, id := range stocking {
+			if component.ComponentID == id {
+				components = append(components, component)
+			}
+		}
+	}
+
+	stock := []models.CatalogueStockModel{}
+	if len(components) == 0 {
+		return http.StatusOK, stock, nil
+	}
+
+	query := scopedUnitsQuery(components) + `
+	SELECT c.id, c.name, c.prefix, c.warehouse_id, COUNT(s.unit_id), COUNT(s.department_id)
+	FROM components c
+	LEFT JOIN scoped s ON s.component_id = c.id
+	WHERE c.id = ANY($3)
+	GROUP BY c.id
+	ORDER BY c.warehouse_id, c.id`
+
+	rows, err := q.db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs), pq.Array(stocking))
+	if err != nil {
+		log.Printf("error while getting catalogue stock: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.CatalogueStockModel
+		if err := rows.Scan(&item.ComponentID, &item.ComponentName, &item.Prefix, &item.WarehouseID, &item.TotalUnits, &item.AssignedUnits); err != nil {
+			log.Printf("error while scanning catalogue stock: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		stock = append(stock, item)
+	}
+
+	return http.StatusOK, stock, nil
+}
+
+// checkCatalogueInOrg reports whether a catalogue model belongs to the organization of the warehouse
+func checkCatalogueInOrg(tx *sql.Tx, catalogue_id, warehouse_id int) (bool, error) {
+	query := `SELECT EXISTS(
+		SELECT 1 FROM catalogue_models cm
+		JOIN branches b ON b.org_id = cm.org_id
+		JOIN warehouses w ON w.branch_id = b.branch_id
+		WHERE cm.id = $1 AND w.id = $2
+	)`
+
+	var exists bool
+	err := tx.QueryRow(query, catalogue_id, warehouse_id).Scan(&exists)
+	return exists, err
+}
+
+// UpdateComponentCatalogue links a component to a catalogue model, a catalogue id of 0 unlinks it
+func (q *Query) UpdateComponentCatalogue(component_id, warehouse_id, catalogue_id int) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var locked int
+	if err := tx.QueryRow("SELECT id FROM warehouses WHERE id = $1 FOR UPDATE", warehouse_id).Scan(&locked); err != nil {
+		log.Printf("error while locking warehouse %v: %v", warehouse_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if catalogue_id > 0 {
+		ok, err := checkCatalogueInOrg(tx, catalogue_id, warehouse_id)
+		if err != nil {
+			log.Printf("error while checking catalogue model: %v", err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		} else if !ok {
+			return http.StatusNotFound, fmt.Errorf("catalogue model not found")
+		}
+	}
+
+	result, err := tx.Exec("UPDATE components SET catalogue_id = NULLIF($1, 0) WHERE id = $2 AND warehouse_id = $3", catalogue_id, component_id, warehouse_id)
+	if err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, fmt.Errorf("the warehouse already stocks this model in another component")
+		}
+		log.Printf("error while updating component catalogue: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing component catalogue: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
@@ -0,0 +1,619 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
+)
+
+var ChecklistsResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":             {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"department_id":  {Column: "department_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"custodian_id":   {Column: "custodian_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"custodian_name": {Column: "custodian_name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"kind":           {Column: "kind", Type: queryspec.Enum, Values: []string{"onboarding", "offboarding"}, Filterable: true, Sortable: true},
+		"status":         {Column: "status", Type: queryspec.Enum, Values: []string{"open", "completed", "cancelled"}, Filterable: true, Sortable: true},
+		"created_at":     {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+		"completed_at":   {Column: "completed_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+		"pending_items":  {Column: "pending_items", Type: queryspec.Int, Filterable: true, Sortable: true},
+	},
+}
+
+const checklistColumns = `c.id, c.department_id, c.custodian_id, cu.name AS custodian_name, c.kind, c.template_id, c.warehouse_id, c.workspace_id, c.status,
+	c.created_by_role, c.created_by, c.created_at, c.completed_at,
+	(SELECT COUNT(*) FROM staff_checklist_items i WHERE i.checklist_id = c.id) AS total_items,
+	(SELECT COUNT(*) FROM staff_checklist_items i WHERE i.checklist_id = c.id AND i.status = 'pending') AS pending_items`
+
+// checklistVisible lets the department see its checklists and a warehouse see the ones holding its components,
+// $1 and $2 are the scope department and warehouse ids
+const checklistVisible = `(c.department_id = ANY($1) OR EXISTS (
+	SELECT 1 FROM staff_checklist_items i JOIN components co ON co.id = i.component_id
+	WHERE i.checklist_id = c.id AND co.warehouse_id = ANY($2)
+))`
+
+func (q *Query) CreateOnboardingTemplate(org_id int, template models.CreateOnboardingTemplateModel) (int, int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var id int
+	if err := tx.QueryRow("INSERT INTO onboarding_templates(org_id, name, job_role) VALUES($1, $2, NULLIF($3, '')) RETURNING id", org_id, template.Name, template.JobRole).Scan(&id); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, -1, fmt.Errorf("template %v already exists", template.Name)
+		}
+		log.Printf("error while creating onboarding template: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	for _, item := range template.Items {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM catalogue_models WHERE id = $1 AND org_id = $2)", item.CatalogueID, org_id).Scan(&exists); err != nil {
+			log.Printf("error while checking catalogue model %v: %v", item.CatalogueID, err)
+			return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+		} else if !exists {
+			return http.StatusNotFound, -1, fmt.Errorf("catalogue model %v not found", item.CatalogueID)
+		}
+
+		if _, err := tx.Exec("INSERT INTO onboarding_template_items(template_id, catalogue_id, quantity) VALUES($1, $2, $3)", id, item.CatalogueID, item.Quantity); err != nil {
+			log.Printf("error while adding onboarding template item: %v", err)
+			return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing onboarding template: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, id, nil
+}
+
+func (q *Query) GetOnboardingTemplates(org_id int) (int, []models.OnboardingTemplateModel, error) {
+	query := `SELECT t.id, t.name, COALESCE(t.job_role, ''), t.created_at, cm.id, cm.name, cm.manufacturer, cm.model_number, ti.quantity
+		FROM onboarding_templates t
+		JOIN onboarding_template_items ti ON ti.template_id = t.id
+		JOIN catalogue_models cm ON cm.id = ti.catalogue_id
+		WHERE t.org_id = $1
+		ORDER BY t.name, t.id, cm.name`
+
+	rows, err := q.db.Query(query, org_id)
+	if err != nil {
+		log.Printf("error while getting onboarding templates: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	templates := []models.OnboardingTemplateModel{}
+	for rows.Next() {
+		var template models.OnboardingTemplateModel
+		var item models.OnboardingTemplateItemModel
+		if err := rows.Scan(&template.TemplateID, &template.Name, &template.JobRole, &template.CreatedAt, &item.CatalogueID, &item.Name, &item.Manufacturer, &item.ModelNumber, &item.Quantity); err != nil {
+			log.Printf("error while scanning onboarding templates: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+
+		if n := len(templates); n > 0 && templates[n-1].TemplateID == template.TemplateID {
+			templates[n-1].Items = append(templates[n-1].Items, item)
+			continue
+		}
+		template.Items = []models.OnboardingTemplateItemModel{item}
+		templates = append(templates, template)
+	}
+
+	return http.StatusOK, templates, nil
+}
+
+func (q *Query) DeleteOnboardingTemplate(org_id, template_id int) (int, error) {
+	result, err := q.db.Exec("DELETE FROM onboarding_templates WHERE id = $1 AND org_id = $2", template_id, org_id)
+	if err != nil {
+		log.Printf("error while deleting onboarding template: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
+	}
+
+	return http.StatusOK, nil
+}
+
+// lockChecklistCustodian locks an active custodian of the scope so checklists and custody of the person cannot race
+func lockChecklistCustodian(tx *sql.Tx, scope models.ScopeModel, custodian_id int) (int, int, error) {
+	var department_id int
+	var active bool
+	if err := tx.QueryRow("SELECT department_id, active FROM custodians WHERE id = $1 AND department_id = ANY($2) FOR UPDATE", custodian_id, pq.Array(scope.DepartmentIDs)).Scan(&department_id, &active); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, -1, fmt.Errorf("custodian not found")
+		}
+		log.Printf("error while getting custodian %v: %v", custodian_id, err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	} else if !active {
+		return http.StatusConflict, -1, fmt.Errorf("custodian is deactivated")
+	}
+
+	return http.StatusOK, department_id, nil
+}
+
+func insertChecklist(tx *sql.Tx, scope models.ScopeModel, custodian_id, department_id int, kind string, template_id, warehouse_id, workspace_id int) (int, error) {
+	var id int
+	err := tx.QueryRow(`INSERT INTO staff_checklists(org_id, department_id, custodian_id, kind, template_id, warehouse_id, workspace_id, created_by_role, created_by)
+		SELECT org_id, $2, $1, $3, NULLIF($4, 0), NULLIF($5, 0), NULLIF($6, 0), $7, $8 FROM custodians WHERE id = $1 RETURNING id`,
+		custodian_id, department_id, kind, template_id, warehouse_id, workspace_id, scope.Role, scope.UserID).Scan(&id)
+	return id, err
+}
+
+// StartOnboarding opens an onboarding checklist for the custodian and raises a unit request to the warehouse
+// for every item of the template. Items are fulfilled as units are handed to the custodian
+func (q *Query) StartOnboarding(scope models.ScopeModel, onboarding models.StartOnboardingModel) (int, models.ChecklistDetailsModel, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	status, department_id, err := lockChecklistCustodian(tx, scope, onboarding.CustodianID)
+	if err != nil {
+		return status, models.ChecklistDetailsModel{}, err
+	}
+
+	var exists bool
+	if err := tx.QueryRow(`SELECT EXISTS(
+		SELECT 1 FROM warehouses w JOIN departments d ON d.branch_id = w.branch_id
+		WHERE w.id = $1 AND d.department_id = $2
+	)`, onboarding.WarehouseID, department_id).Scan(&exists); err != nil {
+		log.Printf("error while checking warehouse %v: %v", onboarding.WarehouseID, err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	} else if !exists {
+		return http.StatusBadRequest, models.ChecklistDetailsModel{}, fmt.Errorf("warehouse is not in the custodian's branch")
+	}
+
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM workspaces WHERE id = $1 AND department_id = $2)", onboarding.WorkspaceID, department_id).Scan(&exists); err != nil {
+		log.Printf("error while checking workspace %v: %v", onboarding.WorkspaceID, err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	} else if !exists {
+		return http.StatusBadRequest, models.ChecklistDetailsModel{}, fmt.Errorf("workspace does not belong to the custodian's department")
+	}
+
+	type templateItem struct {
+		name        string
+		quantity    int
+		componentID sql.NullInt64
+		prefix      sql.NullString
+	}
+
+	rows, err := tx.Query(`SELECT cm.name, ti.quantity, co.id, co.prefix
+		FROM onboarding_templates t
+		JOIN custodians cu ON cu.org_id = t.org_id
+		JOIN onboarding_template_items ti ON ti.template_id = t.id
+		JOIN catalogue_models cm ON cm.id = ti.catalogue_id
+		LEFT JOIN components co ON co.catalogue_id = ti.catalogue_id AND co.warehouse_id = $3
+		WHERE t.id = $1 AND cu.id = $2
+		ORDER BY ti.id`, onboarding.TemplateID, onboarding.CustodianID, onboarding.WarehouseID)
+	if err != nil {
+		log.Printf("error while getting onboarding template %v: %v", onboarding.TemplateID, err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	var items []templateItem
+	for rows.Next() {
+		var item templateItem
+		if err := rows.Scan(&item.name, &item.quantity, &item.componentID, &item.prefix); err != nil {
+			rows.Close()
+			log.Printf("error while scanning onboarding template: %v", err)
+			return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+
+	if len(items) == 0 {
+		return http.StatusNotFound, models.ChecklistDetailsModel{}, fmt.Errorf("onboarding template not found")
+	}
+
+	checklist_id, err := insertChecklist(tx, scope, onboarding.CustodianID, department_id, "onboarding", onboarding.TemplateID, onboarding.WarehouseID, onboarding.WorkspaceID)
+	if err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, models.ChecklistDetailsModel{}, fmt.Errorf("custodian already has an open onboarding checklist")
+		}
+		log.Printf("error while creating onboarding checklist: %v", err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	for _, item := range items {
+		if !item.componentID.Valid {
+			return http.StatusBadRequest, models.ChecklistDetailsModel{}, fmt.Errorf("%s is not stocked in warehouse %v", item.name, onboarding.WarehouseID)
+		}
+
+		status, request_id, err := requestNewUnits(tx, department_id, onboarding.WorkspaceID, onboarding.WarehouseID, int(item.componentID.Int64), item.quantity, item.prefix.String, scope.UserID)
+		if err != nil {
+			if status == http.StatusBadRequest {
+				return status, models.ChecklistDetailsModel{}, fmt.Errorf("not enough %s available", item.name)
+			}
+			return status, models.ChecklistDetailsModel{}, err
+		}
+
+		if _, err := tx.Exec("INSERT INTO staff_checklist_items(checklist_id, component_id, prefix, quantity, request_id) VALUES($1, $2, $3, $4, $5)",
+			checklist_id, item.componentID.Int64, item.prefix.String, item.quantity, request_id); err != nil {
+			log.Printf("error while adding onboarding checklist item: %v", err)
+			return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+		}
+	}
+
+	status, details, err := getChecklistDetails(tx, scope, checklist_id)
+	if err != nil {
+		return status, models.ChecklistDetailsModel{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing onboarding checklist: %v", err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, details, nil
+}
+
+// StartOffboarding opens an offboarding checklist listing every unit the custodian holds. A pending onboarding
+// of the custodian is cancelled, the checklist completes and deactivates the custodian once all units are back
+func (q *Query) StartOffboarding(scope models.ScopeModel, custodian_id int) (int, models.ChecklistDetailsModel, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	status, department_id, err := lockChecklistCustodian(tx, scope, custodian_id)
+	if err != nil {
+		return status, models.ChecklistDetailsModel{}, err
+	}
+
+	var onboarding_id int
+	err = tx.QueryRow("SELECT id FROM staff_checklists WHERE custodian_id = $1 AND kind = 'onboarding' AND status = 'open'", custodian_id).Scan(&onboarding_id)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("error while getting onboarding of custodian %v: %v", custodian_id, err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	} else if err == nil {
+		if err := cancelChecklist(tx, onboarding_id); err != nil {
+			log.Printf("error while cancelling onboarding checklist %v: %v", onboarding_id, err)
+			return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+		}
+	}
+
+	checklist_id, err := insertChecklist(tx, scope, custodian_id, department_id, "offboarding", 0, 0, 0)
+	if err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, models.ChecklistDetailsModel{}, fmt.Errorf("custodian already has an open offboarding checklist")
+		}
+		log.Printf("error while creating offboarding checklist: %v", err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	if _, err := tx.Exec(`INSERT INTO staff_checklist_items(checklist_id, component_id, prefix, unit_id, custody_id)
+		SELECT $1, component_id, prefix, unit_id, id FROM unit_custody
+		WHERE custodian_id = $2 AND returned_at IS NULL
+		ORDER BY assigned_at`, checklist_id, custodian_id); err != nil {
+		log.Printf("error while adding offboarding checklist items: %v", err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	if err := completeChecklist(tx, checklist_id); err != nil {
+		log.Printf("error while completing offboarding checklist %v: %v", checklist_id, err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	status, details, err := getChecklistDetails(tx, scope, checklist_id)
+	if err != nil {
+		return status, models.ChecklistDetailsModel{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing offboarding checklist: %v", err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, details, nil
+}
+
+func (q *Query) GetChecklists(scope models.ScopeModel, spec queryspec.Spec) (int, []models.ChecklistModel, models.PageModel, error) {
+	base := fmt.Sprintf("SELECT %s FROM staff_checklists c JOIN custodians cu ON cu.id = c.custodian_id WHERE %s", checklistColumns, checklistVisible)
+
+	checklists := []models.ChecklistModel{}
+
+	page, err := q.listPage(spec, base, "id, department_id, custodian_id, custodian_name, kind, template_id, warehouse_id, workspace_id, status, created_by_role, created_by, created_at, completed_at, total_items, pending_items", []interface{}{pq.Array(scope.DepartmentIDs), pq.Array(scope.WarehouseIDs)}, func(rows *sql.Rows, extra ...interface{}) error {
+		var checklist models.ChecklistModel
+		if err := scanChecklist(rows, &checklist, extra...); err != nil {
+			return err
+		}
+		checklists = append(checklists, checklist)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, checklists, page, nil
+}
+
+func scanChecklist(row interface{ Scan(...interface{}) error }, checklist *models.ChecklistModel, extra ...interface{}) error {
+	dest := []interface{}{&checklist.ChecklistID, &checklist.DepartmentID, &checklist.CustodianID, &checklist.CustodianName, &checklist.Kind, &checklist.TemplateID, &checklist.WarehouseID, &checklist.WorkspaceID, &checklist.Status,
+		&checklist.CreatedByRole, &checklist.CreatedBy, &checklist.CreatedAt, &checklist.CompletedAt, &checklist.TotalItems, &checklist.PendingItems}
+	return row.Scan(append(dest, extra...)...)
+}
+
+func getChecklistDetails(tx *sql.Tx, scope models.ScopeModel, checklist_id int) (int, models.ChecklistDetailsModel, error) {
+	details := models.ChecklistDetailsModel{Items: []models.ChecklistItemModel{}}
+
+	query1 := fmt.Sprintf("SELECT %s FROM staff_checklists c JOIN custodians cu ON cu.id = c.custodian_id WHERE c.id = $3 AND %s", checklistColumns, checklistVisible)
+	if err := scanChecklist(tx.QueryRow(query1, pq.Array(scope.DepartmentIDs), pq.Array(scope.WarehouseIDs), checklist_id), &details.Checklist); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.ChecklistDetailsModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting checklist %v: %v", checklist_id, err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	query2 := `SELECT i.id, i.component_id, co.name, co.warehouse_id, i.prefix, i.quantity, i.fulfilled, i.request_id, i.unit_id, i.custody_id, i.status, i.resolved_at
+		FROM staff_checklist_items i
+		JOIN components co ON co.id = i.component_id
+		WHERE i.checklist_id = $1
+		ORDER BY i.id`
+
+	rows, err := tx.Query(query2, checklist_id)
+	if err != nil {
+		log.Printf("error while getting items of checklist %v: %v", checklist_id, err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.ChecklistItemModel
+		if err := rows.Scan(&item.ItemID, &item.ComponentID, &item.ComponentName, &item.WarehouseID, &item.Prefix, &item.Quantity, &item.Fulfilled, &item.RequestID, &item.UnitID, &item.CustodyID, &item.Status, &item.ResolvedAt); err != nil {
+			log.Printf("error while scanning checklist items: %v", err)
+			return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("error occured while retrieving data")
+		}
+		details.Items = append(details.Items, item)
+	}
+
+	return http.StatusOK, details, nil
+}
+
+func (q *Query) GetChecklistDetails(scope models.ScopeModel, checklist_id int) (int, models.ChecklistDetailsModel, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	return getChecklistDetails(tx, scope, checklist_id)
+}
+
+// ResolveChecklistItem records the return of an offboarding unit to its warehouse, which only the warehouse
+// can do, or writes it off as lost. Either way the custody of the unit ends
+func (q *Query) ResolveChecklistItem(scope models.ScopeModel, resolve models.ResolveChecklistItemModel) (int, models.ChecklistDetailsModel, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var department_id int
+	var kind, checklist_status string
+	if err := tx.QueryRow("SELECT department_id, kind, status FROM staff_checklists WHERE id = $1 FOR UPDATE", resolve.ChecklistID).Scan(&department_id, &kind, &checklist_status); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.ChecklistDetailsModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while locking checklist %v: %v", resolve.ChecklistID, err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	var item models.ChecklistItemModel
+	if err := tx.QueryRow(`SELECT i.component_id, i.prefix, i.unit_id, i.custody_id, i.status, co.warehouse_id
+		FROM staff_checklist_items i
+		JOIN components co ON co.id = i.component_id
+		WHERE i.id = $1 AND i.checklist_id = $2
+		FOR UPDATE OF i`, resolve.ItemID, resolve.ChecklistID).Scan(&item.ComponentID, &item.Prefix, &item.UnitID, &item.CustodyID, &item.Status, &item.WarehouseID); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.ChecklistDetailsModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while locking checklist item %v: %v", resolve.ItemID, err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	manages_warehouse := slices.Contains(scope.WarehouseIDs, item.WarehouseID)
+	if !manages_warehouse && !slices.Contains(scope.DepartmentIDs, department_id) {
+		return http.StatusNotFound, models.ChecklistDetailsModel{}, fmt.Errorf("no matching data found")
+	} else if kind != "offboarding" {
+		return http.StatusBadRequest, models.ChecklistDetailsModel{}, fmt.Errorf("onboarding items are fulfilled by assigning units to the custodian")
+	} else if checklist_status != "open" {
+		return http.StatusConflict, models.ChecklistDetailsModel{}, fmt.Errorf("checklist is already %v", checklist_status)
+	} else if item.Status != "pending" {
+		return http.StatusConflict, models.ChecklistDetailsModel{}, fmt.Errorf("item is already resolved")
+	}
+
+	outcome := "done"
+	if resolve.Outcome == "lost" {
+		outcome = "lost"
+	} else {
+		if !manages_warehouse {
+			return http.StatusForbidden, models.ChecklistDetailsModel{}, fmt.Errorf("only the warehouse can receive the unit")
+		}
+
+		if parent, err := getUnitParent(tx, item.ComponentID, *item.UnitID); err != nil {
+			log.Printf("error while getting parent of unit: %v", err)
+			return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+		} else if parent != nil {
+			return http.StatusConflict, models.ChecklistDetailsModel{}, fmt.Errorf("unit is linked to %s unit %v, return that unit instead", parent.Prefix, parent.UnitID)
+		}
+
+		linked, err := getUnitDescendants(tx, item.ComponentID, *item.UnitID)
+		if err != nil {
+			log.Printf("error while getting linked units: %v", err)
+			return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+		}
+
+		returned := append([]linkedUnit{{ComponentID: item.ComponentID, Prefix: item.Prefix, UnitID: *item.UnitID}}, linked...)
+		for _, unit := range returned {
+			if err := unassignUnit(tx, unit.Prefix, unit.UnitID, scope.UserID); err != nil {
+				log.Printf("error while returning %s unit %v: %v", unit.Prefix, unit.UnitID, err)
+				return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+			}
+		}
+	}
+
+	if item.CustodyID != nil {
+		if _, err := tx.Exec("UPDATE unit_custody SET returned_at = NOW(), returned_by_role = $2, returned_by = $3, ack_token = NULL WHERE id = $1 AND returned_at IS NULL", *item.CustodyID, scope.Role, scope.UserID); err != nil {
+			log.Printf("error while ending custody %v: %v", *item.CustodyID, err)
+			return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE staff_checklist_items SET status = $2, fulfilled = quantity, resolved_by_role = $3, resolved_by = $4, resolved_at = NOW() WHERE id = $1", resolve.ItemID, outcome, scope.Role, scope.UserID); err != nil {
+		log.Printf("error while resolving checklist item %v: %v", resolve.ItemID, err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	if err := completeChecklist(tx, resolve.ChecklistID); err != nil {
+		log.Printf("error while completing checklist %v: %v", resolve.ChecklistID, err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	status, details, err := getChecklistDetails(tx, scope, resolve.ChecklistID)
+	if err != nil {
+		return status, models.ChecklistDetailsModel{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing checklist item: %v", err)
+		return http.StatusInternalServerError, models.ChecklistDetailsModel{}, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, details, nil
+}
+
+func (q *Query) CancelChecklist(scope models.ScopeModel, checklist_id int) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRow("SELECT status FROM staff_checklists WHERE id = $1 AND department_id = ANY($2) FOR UPDATE", checklist_id, pq.Array(scope.DepartmentIDs)).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while locking checklist %v: %v", checklist_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if status != "open" {
+		return http.StatusConflict, fmt.Errorf("checklist is already %v", status)
+	}
+
+	if err := cancelChecklist(tx, checklist_id); err != nil {
+		log.Printf("error while cancelling checklist %v: %v", checklist_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing checklist cancellation: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+// cancelChecklist cancels an open checklist and declines the requests it raised that the warehouse has not handled
+func cancelChecklist(tx *sql.Tx, checklist_id int) error {
+	if _, err := tx.Exec("UPDATE staff_checklists SET status = 'cancelled', completed_at = NOW() WHERE id = $1 AND status = 'open'", checklist_id); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`UPDATE requests SET status = 'declined'
+		WHERE status = 'raised' AND id IN (SELECT request_id FROM staff_checklist_items WHERE checklist_id = $1 AND status = 'pending')`, checklist_id)
+	return err
+}
+
+// completeChecklist closes an open checklist once none of its items are pending, a completed offboarding
+// deactivates the custodian
+func completeChecklist(tx *sql.Tx, checklist_id int) error {
+	var custodian_id int
+	var kind string
+	err := tx.QueryRow(`UPDATE staff_checklists c SET status = 'completed', completed_at = NOW()
+		WHERE c.id = $1 AND c.status = 'open'
+		AND NOT EXISTS (SELECT 1 FROM staff_checklist_items i WHERE i.checklist_id = c.id AND i.status = 'pending')
+		RETURNING c.custodian_id, c.kind`, checklist_id).Scan(&custodian_id, &kind)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if kind == "offboarding" {
+		_, err = tx.Exec(`UPDATE custodians SET active = FALSE, deactivated_at = NOW()
+			WHERE id = $1 AND active AND NOT EXISTS (SELECT 1 FROM unit_custody WHERE custodian_id = $1 AND returned_at IS NULL)`, custodian_id)
+	}
+
+	return err
+}
+
+// fulfilOnboardingItem counts a unit handed to the custodian against their open onboarding checklist, the
+// item's request is accepted once all of its units are handed over
+func fulfilOnboardingItem(tx *sql.Tx, custodian_id, component_id int) error {
+	var checklist_id int
+	var request_id sql.NullInt64
+	var status string
+	err := tx.QueryRow(`UPDATE staff_checklist_items i SET fulfilled = i.fulfilled + 1,
+			status = CASE WHEN i.fulfilled + 1 >= i.quantity THEN 'done' ELSE i.status END,
+			resolved_at = CASE WHEN i.fulfilled + 1 >= i.quantity THEN NOW() END
+		WHERE i.id = (
+			SELECT i2.id FROM staff_checklist_items i2
+			JOIN staff_checklists c ON c.id = i2.checklist_id
+			WHERE c.custodian_id = $1 AND c.kind = 'onboarding' AND c.status = 'open'
+			AND i2.component_id = $2 AND i2.status = 'pending'
+			ORDER BY i2.id LIMIT 1
+		)
+		RETURNING i.checklist_id, i.request_id, i.status`, custodian_id, component_id).Scan(&checklist_id, &request_id, &status)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if status == "done" && request_id.Valid {
+		if _, err := tx.Exec("UPDATE requests SET status = 'accepted' WHERE id = $1 AND status = 'raised'", request_id.Int64); err != nil {
+			return err
+		}
+	}
+
+	return completeChecklist(tx, checklist_id)
+}
+
+// fulfilRequestItem marks the onboarding item of an accepted request as done
+func fulfilRequestItem(tx *sql.Tx, request_id int) error {
+	var checklist_id int
+	err := tx.QueryRow(`UPDATE staff_checklist_items SET fulfilled = quantity, status = 'done', resolved_at = NOW()
+		WHERE request_id = $1 AND status = 'pending' RETURNING checklist_id`, request_id).Scan(&checklist_id)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return completeChecklist(tx, checklist_id)
+}
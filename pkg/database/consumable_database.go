@@ -0,0 +1,181 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+)
+
+var ConsumableLedgerResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":            {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"type":          {Column: "type", Type: queryspec.Enum, Values: []string{"stock_in", "issue", "adjustment"}, Filterable: true, Sortable: true},
+		"quantity":      {Column: "quantity", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"balance":       {Column: "balance", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"department_id": {Column: "department_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"workspace_id":  {Column: "workspace_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"request_id":    {Column: "request_id", Type: queryspec.Int, Filterable: true},
+		"note":          {Column: "note", Type: queryspec.String, Filterable: true, Searchable: true},
+		"created_at":    {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+// RecordConsumableTransaction applies a stock_in, issue or adjustment to the quantity on hand and
+// writes the matching ledger entry in the same transaction. quantity is the signed change for
+// adjustments and a positive amount otherwise; an issue against a request takes its workspace and,
+// when quantity is 0, its outstanding units from the request. Requests can be issued in parts and are
+// accepted once their full number of units was issued
+func (q *Query) RecordConsumableTransaction(warehouse_id, user_id int, entry_type string, component_id, quantity, workspace_id, request_id int, note string) (int, models.ConsumableLedgerModel, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.ConsumableLedgerModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var on_hand int
+	if err := tx.QueryRow("SELECT quantity FROM consumable_stock WHERE component_id = $1 AND warehouse_id = $2 FOR UPDATE", component_id, warehouse_id).Scan(&on_hand); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.ConsumableLedgerModel{}, fmt.Errorf("no consumable found")
+		}
+		log.Printf("error while locking consumable stock: %v", err)
+		return http.StatusInternalServerError, models.ConsumableLedgerModel{}, fmt.Errorf("database error")
+	}
+
+	var department_id sql.NullInt64
+	delta := quantity
+
+	if entry_type == "issue" {
+		if request_id > 0 {
+			var request_workspace_id, number_of_units int
+			var status string
+			if err := tx.QueryRow("SELECT workspace_id, department_id, number_of_units, status::TEXT FROM requests WHERE id = $1 AND component_id = $2 AND warehouse_id = $3 FOR UPDATE", request_id, component_id, warehouse_id).Scan(&request_workspace_id, &department_id, &number_of_units, &status); err != nil {
+				if err == sql.ErrNoRows {
+					return http.StatusNotFound, models.ConsumableLedgerModel{}, fmt.Errorf("no matching request found")
+				}
+				log.Printf("error while getting request %v: %v", request_id, err)
+				return http.StatusInternalServerError, models.ConsumableLedgerModel{}, fmt.Errorf("database error")
+			}
+
+			if status != "raised" {
+				return http.StatusConflict, models.ConsumableLedgerModel{}, fmt.Errorf("request is already %v", status)
+			} else if workspace_id > 0 && workspace_id != request_workspace_id {
+				return http.StatusBadRequest, models.ConsumableLedgerModel{}, fmt.Errorf("workspace does not match the request")
+			}
+
+			var issued int
+			if err := tx.QueryRow("SELECT COALESCE(-SUM(quantity), 0) FROM consumable_ledger WHERE request_id = $1 AND type = 'issue'", request_id).Scan(&issued); err != nil {
+				log.Printf("error while getting issued quantity of request %v: %v", request_id, err)
+				return http.StatusInternalServerError, models.ConsumableLedgerModel{}, fmt.Errorf("database error")
+			}
+
+			remaining := number_of_units - issued
+			workspace_id = request_workspace_id
+			if remaining <= 0 {
+				return http.StatusConflict, models.ConsumableLedgerModel{}, fmt.Errorf("request was already issued in full")
+			} else if quantity == 0 {
+				quantity = remaining
+			} else if quantity > remaining {
+				return http.StatusBadRequest, models.ConsumableLedgerModel{}, fmt.Errorf("only %v units of the request are still to be issued", remaining)
+			}
+
+			// a request stays raised until its full number of units has been issued
+			if quantity == remaining {
+				if _, err := tx.Exec("UPDATE requests SET status = 'accepted' WHERE id = $1", request_id); err != nil {
+					log.Printf("error while accepting request %v: %v", request_id, err)
+					return http.StatusInternalServerError, models.ConsumableLedgerModel{}, fmt.Errorf("database error")
+				}
+
+				if err := fulfilRequestItem(tx, request_id); err != nil {
+					log.Printf("error while updating onboarding item of request %v: %v", request_id, err)
+					return http.StatusInternalServerError, models.ConsumableLedgerModel{}, fmt.Errorf("database error")
+				}
+			}
+		} else {
+			query := `SELECT w.department_id FROM workspaces w
+				JOIN departments d ON d.department_id = w.department_id
+				JOIN warehouses wh ON wh.branch_id = d.branch_id
+				WHERE w.id = $1 AND wh.id = $2`
+			if err := tx.QueryRow(query, workspace_id, warehouse_id).Scan(&department_id); err != nil {
+				if err == sql.ErrNoRows {
+					return http.StatusNotFound, models.ConsumableLedgerModel{}, fmt.Errorf("no matching workspace found")
+				}
+				log.Printf("error while getting workspace %v: %v", workspace_id, err)
+				return http.StatusInternalServerError, models.ConsumableLedgerModel{}, fmt.Errorf("database error")
+			}
+		}
+		delta = -quantity
+	}
+
+	balance := on_hand + delta
+	if balance < 0 {
+		return http.StatusConflict, models.ConsumableLedgerModel{}, fmt.Errorf("not enough stock, %v on hand", on_hand)
+	}
+
+	if _, err := tx.Exec("UPDATE consumable_stock SET quantity = $1, updated_at = NOW() WHERE component_id = $2", balance, component_id); err != nil {
+		log.Printf("error while updating consumable stock: %v", err)
+		return http.StatusInternalServerError, models.ConsumableLedgerModel{}, fmt.Errorf("database error")
+	}
+
+	entry := models.ConsumableLedgerModel{
+		ComponentID: component_id,
+		WarehouseID: warehouse_id,
+		Type:        entry_type,
+		Quantity:    delta,
+		Balance:     balance,
+		Note:        note,
+		CreatedBy:   user_id,
+	}
+	if department_id.Valid {
+		id := int(department_id.Int64)
+		entry.DepartmentID = &id
+		entry.WorkspaceID = &workspace_id
+	}
+	if request_id > 0 {
+		entry.RequestID = &request_id
+	}
+
+	query := `INSERT INTO consumable_ledger(component_id, warehouse_id, type, quantity, balance, department_id, workspace_id, request_id, note, created_by)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10) RETURNING id, created_at`
+	if err := tx.QueryRow(query, component_id, warehouse_id, entry_type, delta, balance, entry.DepartmentID, entry.WorkspaceID, entry.RequestID, note, user_id).Scan(&entry.EntryID, &entry.CreatedAt); err != nil {
+		log.Printf("error while writing consumable ledger: %v", err)
+		return http.StatusInternalServerError, models.ConsumableLedgerModel{}, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing consumable transaction: %v", err)
+		return http.StatusInternalServerError, models.ConsumableLedgerModel{}, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, entry, nil
+}
+
+func (q *Query) GetConsumableLedger(component_id, warehouse_id int, spec queryspec.Spec) (int, []models.ConsumableLedgerModel, models.PageModel, error) {
+	base := `SELECT id, component_id, warehouse_id, type, quantity, balance, department_id, workspace_id, request_id, COALESCE(note, '') AS note, created_by, created_at
+		FROM consumable_ledger
+		WHERE component_id = $1 AND warehouse_id = $2`
+
+	ledger := []models.ConsumableLedgerModel{}
+
+	page, err := q.listPage(spec, base, "id, component_id, warehouse_id, type, quantity, balance, department_id, workspace_id, request_id, note, created_by, created_at", []interface{}{component_id, warehouse_id}, func(rows *sql.Rows, extra ...interface{}) error {
+		var entry models.ConsumableLedgerModel
+		dest := []interface{}{&entry.EntryID, &entry.ComponentID, &entry.WarehouseID, &entry.Type, &entry.Quantity, &entry.Balance, &entry.DepartmentID, &entry.WorkspaceID, &entry.RequestID, &entry.Note, &entry.CreatedBy, &entry.CreatedAt}
+		if err := rows.Scan(append(dest, extra...)...); err != nil {
+			return err
+		}
+		ledger = append(ledger, entry)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, ledger, page, nil
+}
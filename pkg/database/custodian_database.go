@@ -0,0 +1,350 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
+)
+
+var CustodiansResource = queryspec.Resource{
+	IDColumn:    "id",
+	DefaultSort: "name",
+	Fields: map[string]queryspec.Field{
+		"id":            {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"department_id": {Column: "department_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"name":          {Column: "name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"email":         {Column: "email", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"employee_code": {Column: "employee_code", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"active":        {Column: "active::TEXT", Type: queryspec.Enum, Values: []string{"true", "false"}, Filterable: true},
+		"created_at":    {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+		"holdings":      {Column: "holdings", Type: queryspec.Int, Filterable: true, Sortable: true},
+	},
+}
+
+const custodianColumns = `c.id, c.department_id, c.name, c.email, COALESCE(c.employee_code, '') AS employee_code, c.active, c.created_at, c.deactivated_at,
+	(SELECT COUNT(*) FROM unit_custody uc WHERE uc.custodian_id = c.id AND uc.returned_at IS NULL) AS holdings`
+
+func (q *Query) CreateCustodian(scope models.ScopeModel, custodian models.CreateCustodianModel) (int, int, error) {
+	if !slices.Contains(scope.DepartmentIDs, custodian.DepartmentID) {
+		return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+	}
+
+	query := `INSERT INTO custodians(org_id, department_id, name, email, employee_code)
+		VALUES($1, $2, $3, $4, NULLIF($5, '')) RETURNING id`
+
+	var id int
+	if err := q.db.QueryRow(query, scope.OrgID, custodian.DepartmentID, custodian.Name, custodian.Email, custodian.EmployeeCode).Scan(&id); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, -1, fmt.Errorf("a custodian with this email or employee code already exists")
+		}
+		log.Printf("error while creating custodian: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, id, nil
+}
+
+func scanCustodian(row interface{ Scan(...interface{}) error }, custodian *models.CustodianModel, extra ...interface{}) error {
+	dest := []interface{}{&custodian.CustodianID, &custodian.DepartmentID, &custodian.Name, &custodian.Email, &custodian.EmployeeCode, &custodian.Active, &custodian.CreatedAt, &custodian.DeactivatedAt, &custodian.Holdings}
+	return row.Scan(append(dest, extra...)...)
+}
+
+func (q *Query) GetCustodians(scope models.ScopeModel, spec queryspec.Spec) (int, []models.CustodianModel, models.PageModel, error) {
+	base := fmt.Sprintf("SELECT %s FROM custodians c WHERE c.department_id = ANY($1)", custodianColumns)
+
+	custodians := []models.CustodianModel{}
+
+	page, err := q.listPage(spec, base, "id, department_id, name, email, employee_code, active, created_at, deactivated_at, holdings", []interface{}{pq.Array(scope.DepartmentIDs)}, func(rows *sql.Rows, extra ...interface{}) error {
+		var custodian models.CustodianModel
+		if err := scanCustodian(rows, &custodian, extra...); err != nil {
+			return err
+		}
+		custodians = append(custodians, custodian)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, custodians, page, nil
+}
+
+// DeactivateCustodian marks a custodian as having left, they must have returned everything they hold first
+func (q *Query) DeactivateCustodian(scope models.ScopeModel, custodian_id int) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var active bool
+	if err := tx.QueryRow("SELECT active FROM custodians WHERE id = $1 AND department_id = ANY($2) FOR UPDATE", custodian_id, pq.Array(scope.DepartmentIDs)).Scan(&active); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting custodian %v: %v", custodian_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if !active {
+		return http.StatusConflict, fmt.Errorf("custodian is already deactivated")
+	}
+
+	var holdings int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM unit_custody WHERE custodian_id = $1 AND returned_at IS NULL", custodian_id).Scan(&holdings); err != nil {
+		log.Printf("error while counting holdings of custodian %v: %v", custodian_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if holdings > 0 {
+		return http.StatusConflict, fmt.Errorf("custodian still holds %v units", holdings)
+	}
+
+	if _, err := tx.Exec("UPDATE custodians SET active = FALSE, deactivated_at = NOW() WHERE id = $1", custodian_id); err != nil {
+		log.Printf("error while deactivating custodian %v: %v", custodian_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing custodian deactivation: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+// AssignCustody hands a unit to a custodian. The unit has to be assigned to the custodian's department,
+// when workspace_id is set the unit and everything linked below it is moved to that workspace first
+func (q *Query) AssignCustody(scope models.ScopeModel, custody models.AssignCustodyModel, token_hash string) (int, models.CustodianModel, models.CustodyModel, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var custodian models.CustodianModel
+	if err := tx.QueryRow("SELECT id, department_id, name, email, active FROM custodians WHERE id = $1 AND department_id = ANY($2) FOR UPDATE", custody.CustodianID, pq.Array(scope.DepartmentIDs)).Scan(&custodian.CustodianID, &custodian.DepartmentID, &custodian.Name, &custodian.Email, &custodian.Active); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("custodian not found")
+		}
+		log.Printf("error while getting custodian %v: %v", custody.CustodianID, err)
+		return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+	} else if !custodian.Active {
+		return http.StatusConflict, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("custodian is deactivated")
+	}
+
+	var offboarding bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM staff_checklists WHERE custodian_id = $1 AND kind = 'offboarding' AND status = 'open')", custodian.CustodianID).Scan(&offboarding); err != nil {
+		log.Printf("error while checking offboarding of custodian %v: %v", custodian.CustodianID, err)
+		return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+	} else if offboarding {
+		return http.StatusConflict, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("custodian is being offboarded")
+	}
+
+	holding := models.CustodyModel{ComponentID: custody.ComponentID, UnitID: custody.UnitID, Note: custody.Note}
+
+	var warehouse_id int
+	var kind string
+	if err := tx.QueryRow("SELECT name, prefix, warehouse_id, kind FROM components WHERE id = $1", custody.ComponentID).Scan(&holding.ComponentName, &holding.Prefix, &warehouse_id, &kind); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting component %v: %v", custody.ComponentID, err)
+		return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+	} else if kind != "unit" {
+		return http.StatusBadRequest, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("consumables cannot be held by a custodian")
+	}
+
+	unit, err := getUnitState(tx, custody.ComponentID, custody.UnitID, warehouse_id)
+	if err == sql.ErrNoRows {
+		return http.StatusNotFound, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("no matching data found")
+	} else if err != nil {
+		log.Printf("error while getting unit %v: %v", custody.UnitID, err)
+		return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+	}
+
+	manages_warehouse := slices.Contains(scope.WarehouseIDs, warehouse_id)
+	in_department := unit.DepartmentID.Valid && int(unit.DepartmentID.Int64) == custodian.DepartmentID
+
+	if !manages_warehouse && !(unit.DepartmentID.Valid && slices.Contains(scope.DepartmentIDs, int(unit.DepartmentID.Int64))) {
+		return http.StatusNotFound, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("no matching data found")
+	} else if unit.Status == "exit" {
+		return http.StatusConflict, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("unit has been retired")
+	}
+
+	if parent, err := getUnitParent(tx, custody.ComponentID, custody.UnitID); err != nil {
+		log.Printf("error while getting parent of unit: %v", err)
+		return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+	} else if parent != nil {
+		return http.StatusBadRequest, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("unit is linked to %s unit %v, assign that unit instead", parent.Prefix, parent.UnitID)
+	}
+
+	if custody.WorkspaceID > 0 {
+		var department_id int
+		if err := tx.QueryRow("SELECT department_id FROM workspaces WHERE id = $1", custody.WorkspaceID).Scan(&department_id); err != nil {
+			if err == sql.ErrNoRows {
+				return http.StatusNotFound, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("workspace not found")
+			}
+			log.Printf("error while getting workspace %v: %v", custody.WorkspaceID, err)
+			return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+		} else if department_id != custodian.DepartmentID {
+			return http.StatusBadRequest, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("workspace does not belong to the custodian's department")
+		}
+
+		// only the warehouse can move a unit between departments
+		if !in_department && !manages_warehouse {
+			return http.StatusForbidden, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("unit is assigned to another department")
+		}
+
+		if !unit.WorkspaceID.Valid || int(unit.WorkspaceID.Int64) != custody.WorkspaceID {
+			descendants, err := getUnitDescendants(tx, custody.ComponentID, custody.UnitID)
+			if err != nil {
+				log.Printf("error while getting linked units: %v", err)
+				return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+			}
+
+			moved := append([]linkedUnit{{ComponentID: custody.ComponentID, Prefix: holding.Prefix, UnitID: custody.UnitID}}, descendants...)
+			for _, linked := range moved {
+				if err := moveUnitAssignment(tx, linked.Prefix, linked.UnitID, custodian.DepartmentID, custody.WorkspaceID, scope.UserID); err != nil {
+					log.Printf("error while moving %s unit %v: %v", linked.Prefix, linked.UnitID, err)
+					return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+				}
+			}
+		}
+	} else if !in_department {
+		return http.StatusBadRequest, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("unit is not assigned to the custodian's department, workspace_id is required")
+	}
+
+	query := fmt.Sprintf("SELECT COALESCE(serial_number, ''), COALESCE(asset_tag, '') FROM %s_units WHERE id = $1", holding.Prefix)
+	if err := tx.QueryRow(query, custody.UnitID).Scan(&holding.SerialNumber, &holding.AssetTag); err != nil {
+		log.Printf("error while getting unit %v: %v", custody.UnitID, err)
+		return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+	}
+
+	if err := tx.QueryRow(`INSERT INTO unit_custody(custodian_id, component_id, prefix, unit_id, note, assigned_by_role, assigned_by, ack_token)
+		VALUES($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8) RETURNING id, assigned_at`, custodian.CustodianID, custody.ComponentID, holding.Prefix, custody.UnitID, custody.Note, scope.Role, scope.UserID, token_hash).Scan(&holding.CustodyID, &holding.AssignedAt); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("unit is already held by a custodian")
+		}
+		log.Printf("error while assigning custody: %v", err)
+		return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+	}
+
+	if err := fulfilOnboardingItem(tx, custodian.CustodianID, custody.ComponentID); err != nil {
+		log.Printf("error while updating onboarding of custodian %v: %v", custodian.CustodianID, err)
+		return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing custody: %v", err)
+		return http.StatusInternalServerError, models.CustodianModel{}, models.CustodyModel{}, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, custodian, holding, nil
+}
+
+// ReturnCustody ends the custody of a unit, the unit keeps its workspace assignment
+func (q *Query) ReturnCustody(scope models.ScopeModel, component_id, unit_id int) (int, error) {
+	query := `UPDATE unit_custody uc SET returned_at = NOW(), returned_by_role = $3, returned_by = $4, ack_token = NULL
+		FROM custodians c, components co
+		WHERE c.id = uc.custodian_id AND co.id = uc.component_id
+		AND uc.component_id = $1 AND uc.unit_id = $2 AND uc.returned_at IS NULL
+		AND (c.department_id = ANY($5) OR co.warehouse_id = ANY($6))`
+
+	result, err := q.db.Exec(query, component_id, unit_id, scope.Role, scope.UserID, pq.Array(scope.DepartmentIDs), pq.Array(scope.WarehouseIDs))
+	if err != nil {
+		log.Printf("error while returning custody of unit %v: %v", unit_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return http.StatusNotFound, fmt.Errorf("unit is not held by a custodian")
+	}
+
+	return http.StatusOK, nil
+}
+
+// AcknowledgeCustody records the custodian's confirmation of receipt, each token can be used once
+func (q *Query) AcknowledgeCustody(token_hash string) (int, error) {
+	result, err := q.db.Exec("UPDATE unit_custody SET acknowledged_at = NOW(), ack_token = NULL WHERE ack_token = $1 AND returned_at IS NULL", token_hash)
+	if err != nil {
+		log.Printf("error while acknowledging custody: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return http.StatusNotFound, fmt.Errorf("invalid or already used token")
+	}
+
+	return http.StatusOK, nil
+}
+
+// GetCustodianHoldings lists every unit the custodian currently holds with its live status and assignment
+func (q *Query) GetCustodianHoldings(scope models.ScopeModel, custodian_id int) (int, models.CustodianHoldingsModel, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.CustodianHoldingsModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	holdings := models.CustodianHoldingsModel{Holdings: []models.CustodyModel{}}
+
+	query1 := fmt.Sprintf("SELECT %s FROM custodians c WHERE c.id = $1 AND c.department_id = ANY($2)", custodianColumns)
+	if err := scanCustodian(tx.QueryRow(query1, custodian_id, pq.Array(scope.DepartmentIDs)), &holdings.Custodian); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.CustodianHoldingsModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting custodian %v: %v", custodian_id, err)
+		return http.StatusInternalServerError, models.CustodianHoldingsModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	query2 := `SELECT uc.id, uc.component_id, c.name, uc.prefix, uc.unit_id, COALESCE(uc.note, ''), uc.assigned_at, uc.acknowledged_at
+		FROM unit_custody uc
+		JOIN components c ON c.id = uc.component_id
+		WHERE uc.custodian_id = $1 AND uc.returned_at IS NULL
+		ORDER BY uc.assigned_at`
+
+	rows, err := tx.Query(query2, custodian_id)
+	if err != nil {
+		log.Printf("error while getting holdings of custodian %v: %v", custodian_id, err)
+		return http.StatusInternalServerError, models.CustodianHoldingsModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	for rows.Next() {
+		var holding models.CustodyModel
+		if err := rows.Scan(&holding.CustodyID, &holding.ComponentID, &holding.ComponentName, &holding.Prefix, &holding.UnitID, &holding.Note, &holding.AssignedAt, &holding.AcknowledgedAt); err != nil {
+			rows.Close()
+			log.Printf("error while scanning holdings: %v", err)
+			return http.StatusInternalServerError, models.CustodianHoldingsModel{}, fmt.Errorf("error occured while retrieving data")
+		}
+		holdings.Holdings = append(holdings.Holdings, holding)
+	}
+	rows.Close()
+
+	for i := range holdings.Holdings {
+		holding := &holdings.Holdings[i]
+
+		query3 := fmt.Sprintf(`SELECT COALESCE(u.serial_number, ''), COALESCE(u.asset_tag, ''), u.status::TEXT, a.department_id, a.workspace_id
+			FROM %[1]s_units u
+			LEFT JOIN %[1]s_units_assigned a ON a.unit_id = u.id
+			WHERE u.id = $1`, holding.Prefix)
+		if err := tx.QueryRow(query3, holding.UnitID).Scan(&holding.SerialNumber, &holding.AssetTag, &holding.Status, &holding.DepartmentID, &holding.WorkspaceID); err != nil {
+			log.Printf("error while getting %s unit %v: %v", holding.Prefix, holding.UnitID, err)
+			return http.StatusInternalServerError, models.CustodianHoldingsModel{}, fmt.Errorf("error occured while retrieving data")
+		}
+
+		linked, err := getUnitDescendants(tx, holding.ComponentID, holding.UnitID)
+		if err != nil {
+			log.Printf("error while getting linked units: %v", err)
+			return http.StatusInternalServerError, models.CustodianHoldingsModel{}, fmt.Errorf("error occured while retrieving data")
+		}
+		holding.LinkedUnits = len(linked)
+	}
+
+	return http.StatusOK, holdings, nil
+}
@@ -0,0 +1,222 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/lib/pq"
+)
+
+func (q *Query) GetDashboard(scope models.ScopeModel, warranty_days int) (int, models.DashboardModel, error) {
+	dashboard := models.DashboardModel{
+		Role:               scope.Role,
+		Counts:             map[string]int{},
+		UnitsByStatus:      map[string]int{},
+		ExpiringWarranties: []models.ExpiringWarrantyModel{},
+		RecentActivity:     []models.ActivityModel{},
+	}
+
+	if err := q.getDashboardCounts(scope, dashboard.Counts); err != nil {
+		return http.StatusInternalServerError, models.DashboardModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	issues, err := q.getDashboardIssues(scope)
+	if err != nil {
+		return http.StatusInternalServerError, models.DashboardModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+	dashboard.Issues = issues
+
+	query := `SELECT COUNT(*) FROM requests WHERE status = 'raised' AND (warehouse_id = ANY($1) OR department_id = ANY($2))`
+	if err := q.db.QueryRow(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs)).Scan(&dashboard.PendingRequests); err != nil {
+		log.Printf("error while counting pending requests: %v", err)
+		return http.StatusInternalServerError, models.DashboardModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return http.StatusInternalServerError, models.DashboardModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	if len(components) > 0 {
+		if err := q.getDashboardUnits(components, scope, warranty_days, &dashboard); err != nil {
+			return http.StatusInternalServerError, models.DashboardModel{}, fmt.Errorf("internal server error, please try again later")
+		}
+	}
+
+	activity, err := q.getRecentActivity(components, scope, 10)
+	if err != nil {
+		return http.StatusInternalServerError, models.DashboardModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+	dashboard.RecentActivity = activity
+
+	return http.StatusOK, dashboard, nil
+}
+
+func (q *Query) getDashboardCounts(scope models.ScopeModel, counts map[string]int) error {
+	var err error
+
+	switch scope.Role {
+	case "organization", "super_admin":
+		counts["branches"] = len(scope.BranchIDs)
+		counts["departments"] = len(scope.DepartmentIDs)
+		counts["warehouses"] = len(scope.WarehouseIDs)
+		if scope.Role == "organization" {
+			var super_admins int
+			err = q.db.QueryRow("SELECT COUNT(*) FROM super_admin WHERE org_id = $1", scope.OrgID).Scan(&super_admins)
+			counts["super_admins"] = super_admins
+		}
+	case "branch_head":
+		counts["departments"] = len(scope.DepartmentIDs)
+		counts["warehouses"] = len(scope.WarehouseIDs)
+	case "department_head":
+		var workspaces int
+		err = q.db.QueryRow("SELECT COUNT(*) FROM workspaces WHERE department_id = ANY($1)", pq.Array(scope.DepartmentIDs)).Scan(&workspaces)
+		counts["workspaces"] = workspaces
+	case "warehouses":
+		var components int
+		err = q.db.QueryRow("SELECT COUNT(*) FROM components WHERE warehouse_id = ANY($1)", pq.Array(scope.WarehouseIDs)).Scan(&components)
+		counts["components"] = components
+	}
+
+	if err != nil {
+		log.Printf("error while getting dashboard counts: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (q *Query) getDashboardIssues(scope models.ScopeModel) (models.DashboardIssuesModel, error) {
+	issues := models.DashboardIssuesModel{ByStatus: map[string]int{}}
+
+	query1 := `SELECT status, COUNT(*) FROM issues WHERE warehouse_id = ANY($1) OR department_id = ANY($2) GROUP BY status`
+	query2 := `SELECT
+		COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days'),
+		COUNT(*) FILTER (WHERE created_at < NOW() - INTERVAL '7 days' AND created_at >= NOW() - INTERVAL '30 days'),
+		COUNT(*) FILTER (WHERE created_at < NOW() - INTERVAL '30 days')
+	FROM issues
+	WHERE status <> 'resolved' AND (warehouse_id = ANY($1) OR department_id = ANY($2))`
+
+	rows, err := q.db.Query(query1, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs))
+	if err != nil {
+		log.Printf("error while getting issues by status: %v", err)
+		return issues, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			log.Printf("error while scanning issues by status: %v", err)
+			return issues, err
+		}
+		issues.ByStatus[status] = count
+	}
+
+	if err := q.db.QueryRow(query2, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs)).Scan(
+		&issues.OpenAge.UnderAWeek,
+		&issues.OpenAge.UnderAMonth,
+		&issues.OpenAge.OverAMonth,
+	); err != nil {
+		log.Printf("error while getting open issue age: %v", err)
+		return issues, err
+	}
+
+	return issues, nil
+}
+
+func (q *Query) getDashboardUnits(components []models.ComponentModel, scope models.ScopeModel, warranty_days int, dashboard *models.DashboardModel) error {
+	query1 := scopedUnitsQuery(components) + `
+	SELECT status, COUNT(*) FROM scoped GROUP BY status`
+	query2 := scopedUnitsQuery(components) + `
+	SELECT s.unit_id, s.prefix, c.name, s.warehouse_id, s.department_id, s.warranty_date
+	FROM scoped s
+	JOIN components c ON c.id = s.component_id
+	WHERE s.status <> 'exit' AND s.warranty_date >= NOW() AND s.warranty_date < NOW() + make_interval(days => $3)
+	ORDER BY s.warranty_date
+	LIMIT 20`
+
+	rows, err := q.db.Query(query1, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs))
+	if err != nil {
+		log.Printf("error while getting units by status: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			log.Printf("error while scanning units by status: %v", err)
+			return err
+		}
+		dashboard.UnitsByStatus[status] = count
+	}
+
+	rows2, err := q.db.Query(query2, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs), warranty_days)
+	if err != nil {
+		log.Printf("error while getting expiring warranties: %v", err)
+		return err
+	}
+	defer rows2.Close()
+
+	for rows2.Next() {
+		var unit models.ExpiringWarrantyModel
+		var department_id sql.NullInt64
+		if err := rows2.Scan(&unit.UnitID, &unit.Prefix, &unit.ComponentName, &unit.WarehouseID, &department_id, &unit.WarrantyDate); err != nil {
+			log.Printf("error while scanning expiring warranties: %v", err)
+			return err
+		}
+		if department_id.Valid {
+			id := int(department_id.Int64)
+			unit.DepartmentID = &id
+		}
+		dashboard.ExpiringWarranties = append(dashboard.ExpiringWarranties, unit)
+	}
+
+	return nil
+}
+
+func (q *Query) getRecentActivity(components []models.ComponentModel, scope models.ScopeModel, limit int) ([]models.ActivityModel, error) {
+	activity := []models.ActivityModel{}
+
+	parts := []string{
+		"SELECT 'issue_raised' AS type, id, issue AS description, created_at AS at FROM issues WHERE warehouse_id = ANY($1) OR department_id = ANY($2)",
+		"SELECT 'issue_resolved', i.id, r.solution, r.resolved_at FROM resolved_issues r JOIN issues i ON i.id = r.issue_id WHERE i.warehouse_id = ANY($1) OR i.department_id = ANY($2)",
+		"SELECT 'request_raised', id, number_of_units || ' units of ' || prefix, created_at FROM requests WHERE warehouse_id = ANY($1) OR department_id = ANY($2)",
+	}
+
+	with := "WITH "
+	if len(components) > 0 {
+		with = scopedUnitsQuery(components) + ",\n"
+		parts = append(parts, "SELECT 'unit_assigned', unit_id, prefix || ' unit assigned to workspace ' || workspace_id, assigned_at FROM scoped WHERE department_id IS NOT NULL")
+	}
+
+	query := with + fmt.Sprintf(`activity AS (
+		%s
+	)
+	SELECT type, id, description, at FROM activity WHERE at IS NOT NULL ORDER BY at DESC LIMIT $3`, strings.Join(parts, " UNION ALL "))
+
+	rows, err := q.db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs), limit)
+	if err != nil {
+		log.Printf("error while getting recent activity: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.ActivityModel
+		if err := rows.Scan(&a.Type, &a.ID, &a.Description, &a.At); err != nil {
+			log.Printf("error while scanning recent activity: %v", err)
+			return nil, err
+		}
+		activity = append(activity, a)
+	}
+
+	return activity, nil
+}
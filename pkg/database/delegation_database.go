@@ -0,0 +1,277 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
+)
+
+// maxDelegationPeriod caps how long a head can hand over their scope at once
+const maxDelegationPeriod = 90 * 24 * time.Hour
+
+const activeDelegation = "d.revoked_at IS NULL AND d.starts_at <= NOW() AND d.ends_at > NOW()"
+
+// ApplyDelegations adds the nodes delegated to the user and still active to the scope
+func (q *Query) ApplyDelegations(scope *models.ScopeModel) error {
+	rows, err := q.db.Query(`SELECT d.id, d.delegator_email, d.delegator_role, d.node_type, d.node_id, d.ends_at
+		FROM delegations d WHERE d.delegate_email = $1 AND d.org_id = $2 AND `+activeDelegation+` ORDER BY d.id`, scope.UserEmail, scope.OrgID)
+	if err != nil {
+		log.Printf("error while getting delegations: %v", err)
+		return fmt.Errorf("database error")
+	}
+	defer rows.Close()
+
+	nodes := map[string][]int{}
+	for rows.Next() {
+		var delegation models.DelegatedScopeModel
+		if err := rows.Scan(&delegation.DelegationID, &delegation.DelegatorEmail, &delegation.DelegatorRole, &delegation.NodeType, &delegation.NodeID, &delegation.EndsAt); err != nil {
+			log.Printf("error while scanning delegations: %v", err)
+			return fmt.Errorf("database error")
+		}
+		scope.Delegations = append(scope.Delegations, delegation)
+		nodes[delegation.NodeType] = append(nodes[delegation.NodeType], delegation.NodeID)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("error while getting delegations: %v", err)
+		return fmt.Errorf("database error")
+	}
+
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	delegated, err := q.expandNodes(scope.OrgID, nodes)
+	if err != nil {
+		return err
+	}
+
+	scope.BranchIDs = mergeIDs(scope.BranchIDs, delegated.BranchIDs)
+	scope.DepartmentIDs = mergeIDs(scope.DepartmentIDs, delegated.DepartmentIDs)
+	scope.WarehouseIDs = mergeIDs(scope.WarehouseIDs, delegated.WarehouseIDs)
+
+	return nil
+}
+
+// DelegationsOnNode narrows the delegations an authorized scope relies on to the ones covering the node acted on
+func (q *Query) DelegationsOnNode(scope models.ScopeModel, node_type string, node_id int) ([]int, error) {
+	delegations := []int{}
+	for _, delegation := range scope.Delegations {
+		if !slices.Contains(scope.OnBehalfOf, delegation.DelegationID) {
+			continue
+		}
+
+		node, err := q.expandNodes(scope.OrgID, map[string][]int{delegation.NodeType: {delegation.NodeID}})
+		if err != nil {
+			return nil, err
+		}
+
+		if nodeInScope(node, node_type, node_id) {
+			delegations = append(delegations, delegation.DelegationID)
+		}
+	}
+
+	return delegations, nil
+}
+
+// CreateDelegation hands the branch or department of the head to the delegate for the period, delegate_org is
+// the organization of the delegate. Only what the head holds through their own account is delegated
+func (q *Query) CreateDelegation(scope models.ScopeModel, delegation models.CreateDelegationModel, delegate_org int) (int, int, error) {
+	if scope.StaffRole != "" && scope.StaffRole != "head" {
+		return http.StatusForbidden, -1, fmt.Errorf("only branch and department heads can delegate their authority")
+	}
+
+	own, err := q.GetUserScope(scope.Role, scope.UserID)
+	if err != nil {
+		return http.StatusInternalServerError, -1, err
+	}
+
+	var node_type string
+	var node_id int
+	switch {
+	case scope.Role == "branch_head" && len(own.BranchIDs) == 1:
+		node_type, node_id = "branch", own.BranchIDs[0]
+	case scope.Role == "department_head" && len(own.DepartmentIDs) == 1:
+		node_type, node_id = "department", own.DepartmentIDs[0]
+	default:
+		return http.StatusForbidden, -1, fmt.Errorf("only branch and department heads can delegate their authority")
+	}
+
+	if delegate_org != scope.OrgID {
+		return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+	} else if delegation.DelegateEmail == scope.UserEmail {
+		return http.StatusBadRequest, -1, fmt.Errorf("you cannot delegate to yourself")
+	} else if !delegation.EndsAt.After(delegation.StartsAt) || !delegation.EndsAt.After(time.Now()) {
+		return http.StatusBadRequest, -1, fmt.Errorf("ends_at must be after starts_at and in the future")
+	} else if delegation.EndsAt.Sub(delegation.StartsAt) > maxDelegationPeriod {
+		return http.StatusBadRequest, -1, fmt.Errorf("a delegation can last at most %v days", int(maxDelegationPeriod.Hours()/24))
+	}
+
+	var overlapping bool
+	if err := q.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM delegations d WHERE d.delegator_email = $1 AND d.delegate_email = $2
+		AND d.node_type = $3 AND d.node_id = $4 AND d.revoked_at IS NULL AND d.starts_at < $6 AND d.ends_at > $5)`,
+		scope.UserEmail, delegation.DelegateEmail, node_type, node_id, delegation.StartsAt, delegation.EndsAt).Scan(&overlapping); err != nil {
+		log.Printf("error while checking overlapping delegations: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	} else if overlapping {
+		return http.StatusConflict, -1, fmt.Errorf("an overlapping delegation to this user already exists")
+	}
+
+	var delegation_id int
+	if err := q.db.QueryRow(`INSERT INTO delegations(org_id, delegator_email, delegator_role, delegate_email, node_type, node_id, starts_at, ends_at, reason)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`, scope.OrgID, scope.UserEmail, accountRole(scope), delegation.DelegateEmail,
+		node_type, node_id, delegation.StartsAt, delegation.EndsAt, delegation.Reason).Scan(&delegation_id); err != nil {
+		log.Printf("error while creating delegation: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, delegation_id, nil
+}
+
+// RevokeDelegation ends a delegation early, the delegator and anyone whose scope covers the delegated node can revoke it
+func (q *Query) RevokeDelegation(scope models.ScopeModel, delegation_id int) (int, error) {
+	var delegator_email, node_type string
+	var node_id int
+	if err := q.db.QueryRow("SELECT delegator_email, node_type, node_id FROM delegations WHERE id = $1 AND org_id = $2",
+		delegation_id, scope.OrgID).Scan(&delegator_email, &node_type, &node_id); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting delegation %v: %v", delegation_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if delegator_email != scope.UserEmail && !nodeInScope(scope, node_type, node_id) {
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
+	}
+
+	result, err := q.db.Exec("UPDATE delegations SET revoked_at = NOW(), revoked_by = $2 WHERE id = $1 AND revoked_at IS NULL AND ends_at > NOW()",
+		delegation_id, scope.UserEmail)
+	if err != nil {
+		log.Printf("error while revoking delegation %v: %v", delegation_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		log.Printf("error while revoking delegation %v: %v", delegation_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if n == 0 {
+		return http.StatusConflict, fmt.Errorf("delegation has already ended")
+	}
+
+	return http.StatusOK, nil
+}
+
+// ExpireDelegations stamps the delegations whose period ended, they stop applying at ends_at on their own
+func (q *Query) ExpireDelegations() (int64, error) {
+	result, err := q.db.Exec("UPDATE delegations SET expired_at = NOW() WHERE expired_at IS NULL AND revoked_at IS NULL AND ends_at <= NOW()")
+	if err != nil {
+		log.Printf("error while expiring delegations: %v", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+var DelegationsResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":              {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"delegator_email": {Column: "delegator_email", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"delegate_email":  {Column: "delegate_email", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"node_type":       {Column: "node_type", Type: queryspec.Enum, Values: []string{"branch", "department"}, Filterable: true, Sortable: true},
+		"node_id":         {Column: "node_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"status":          {Column: "status", Type: queryspec.Enum, Values: []string{"scheduled", "active", "expired", "revoked"}, Filterable: true, Sortable: true},
+		"starts_at":       {Column: "starts_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+		"ends_at":         {Column: "ends_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+		"created_at":      {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+// visibleDelegations matches the delegations the user made, received or that lie within their scope
+const visibleDelegations = `d.org_id = $1 AND (d.delegator_email = $2 OR d.delegate_email = $2
+	OR (d.node_type = 'branch' AND d.node_id = ANY($3)) OR (d.node_type = 'department' AND d.node_id = ANY($4)))`
+
+func (q *Query) GetDelegations(scope models.ScopeModel, spec queryspec.Spec) (int, []models.DelegationModel, models.PageModel, error) {
+	base := `SELECT d.id, d.delegator_email, d.delegator_role, d.delegate_email, d.node_type, d.node_id, d.starts_at, d.ends_at, d.reason,
+		CASE WHEN d.revoked_at IS NOT NULL THEN 'revoked' WHEN d.ends_at <= NOW() THEN 'expired'
+			WHEN d.starts_at > NOW() THEN 'scheduled' ELSE 'active' END AS status,
+		COALESCE(d.revoked_by, '') AS revoked_by, d.revoked_at, d.expired_at, d.created_at
+		FROM delegations d WHERE ` + visibleDelegations
+
+	delegations := []models.DelegationModel{}
+
+	page, err := q.listPage(spec, base, "id, delegator_email, delegator_role, delegate_email, node_type, node_id, starts_at, ends_at, reason, status, revoked_by, revoked_at, expired_at, created_at",
+		[]interface{}{scope.OrgID, scope.UserEmail, pq.Array(scope.BranchIDs), pq.Array(scope.DepartmentIDs)}, func(rows *sql.Rows, extra ...interface{}) error {
+			var delegation models.DelegationModel
+			dest := []interface{}{&delegation.DelegationID, &delegation.DelegatorEmail, &delegation.DelegatorRole, &delegation.DelegateEmail,
+				&delegation.NodeType, &delegation.NodeID, &delegation.StartsAt, &delegation.EndsAt, &delegation.Reason, &delegation.Status,
+				&delegation.RevokedBy, &delegation.RevokedAt, &delegation.ExpiredAt, &delegation.CreatedAt}
+			if err := rows.Scan(append(dest, extra...)...); err != nil {
+				return err
+			}
+			delegations = append(delegations, delegation)
+			return nil
+		})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, delegations, page, nil
+}
+
+func (q *Query) RecordDelegationActivity(delegation_id int, method, path string, status int) error {
+	if len(path) > 200 {
+		path = path[:200]
+	}
+	_, err := q.db.Exec("INSERT INTO delegation_activity(delegation_id, method, path, status) VALUES($1, $2, $3, $4)", delegation_id, method, path, status)
+	return err
+}
+
+var DelegationActivityResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":              {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"delegation_id":   {Column: "delegation_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"delegator_email": {Column: "delegator_email", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"delegate_email":  {Column: "delegate_email", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"method":          {Column: "method", Type: queryspec.Enum, Values: []string{"POST", "PUT", "PATCH", "DELETE"}, Filterable: true},
+		"path":            {Column: "path", Type: queryspec.String, Filterable: true, Searchable: true},
+		"status":          {Column: "status", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"created_at":      {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+func (q *Query) GetDelegationActivity(scope models.ScopeModel, spec queryspec.Spec) (int, []models.DelegationActivityModel, models.PageModel, error) {
+	base := `SELECT a.id, a.delegation_id, d.delegator_email, d.delegate_email, a.method, a.path, a.status, a.created_at
+		FROM delegation_activity a JOIN delegations d ON d.id = a.delegation_id WHERE ` + visibleDelegations
+
+	activity := []models.DelegationActivityModel{}
+
+	page, err := q.listPage(spec, base, "id, delegation_id, delegator_email, delegate_email, method, path, status, created_at",
+		[]interface{}{scope.OrgID, scope.UserEmail, pq.Array(scope.BranchIDs), pq.Array(scope.DepartmentIDs)}, func(rows *sql.Rows, extra ...interface{}) error {
+			var entry models.DelegationActivityModel
+			dest := []interface{}{&entry.ActivityID, &entry.DelegationID, &entry.DelegatorEmail, &entry.DelegateEmail, &entry.Method, &entry.Path,
+				&entry.Status, &entry.CreatedAt}
+			if err := rows.Scan(append(dest, extra...)...); err != nil {
+				return err
+			}
+			activity = append(activity, entry)
+			return nil
+		})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, activity, page, nil
+}
@@ -0,0 +1,218 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/lib/pq"
+)
+
+func newImpact(ids []int) models.ImpactModel {
+	return models.ImpactModel{Count: len(ids), IDs: ids}
+}
+
+// GetDeletionImpact lists everything the delete_branch, delete_department or delete_warehouse
+// procedures would remove or unassign, the token fingerprints that state
+func (q *Query) GetDeletionImpact(entity_type string, entity_id int) (int, models.DeletionImpactModel, error) {
+	return deletionImpact(q.db, entity_type, entity_id)
+}
+
+func deletionImpact(db querier, entity_type string, entity_id int) (int, models.DeletionImpactModel, error) {
+	impact := models.DeletionImpactModel{
+		EntityType: entity_type,
+		EntityID:   entity_id,
+		Removed:    map[string]models.ImpactModel{},
+		Units:      []models.UnitImpactModel{},
+	}
+
+	var exists bool
+	var err error
+	scope := models.ScopeModel{DepartmentIDs: []int{}, WarehouseIDs: []int{}}
+
+	switch entity_type {
+	case "branch":
+		err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM branches WHERE branch_id = $1)", entity_id).Scan(&exists)
+		if err == nil && exists {
+			var heads []int
+			if heads, err = collectIDsOn(db, "SELECT id FROM branch_head WHERE branch_id = $1 ORDER BY id", entity_id); err == nil {
+				impact.Removed["branches"] = newImpact([]int{entity_id})
+				impact.Removed["branch_heads"] = newImpact(heads)
+			}
+			if err == nil {
+				scope.DepartmentIDs, err = collectIDsOn(db, "SELECT department_id FROM departments WHERE branch_id = $1 ORDER BY department_id", entity_id)
+			}
+			if err == nil {
+				scope.WarehouseIDs, err = collectIDsOn(db, "SELECT id FROM warehouses WHERE branch_id = $1 ORDER BY id", entity_id)
+			}
+		}
+	case "department":
+		err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM departments WHERE department_id = $1)", entity_id).Scan(&exists)
+		scope.DepartmentIDs = []int{entity_id}
+	case "warehouse":
+		err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM warehouses WHERE id = $1)", entity_id).Scan(&exists)
+		scope.WarehouseIDs = []int{entity_id}
+	default:
+		return http.StatusBadRequest, models.DeletionImpactModel{}, fmt.Errorf("invalid entity type")
+	}
+
+	if err != nil {
+		log.Printf("error while resolving %v %v for deletion: %v", entity_type, entity_id, err)
+		return http.StatusInternalServerError, models.DeletionImpactModel{}, fmt.Errorf("internal server error, please try again later")
+	} else if !exists {
+		return http.StatusNotFound, models.DeletionImpactModel{}, fmt.Errorf("no matching data found")
+	}
+
+	departments := pq.Array(scope.DepartmentIDs)
+	warehouses := pq.Array(scope.WarehouseIDs)
+
+	lists := []struct {
+		key   string
+		query string
+		arg   interface{}
+	}{
+		{"department_heads", "SELECT id FROM department_head WHERE department_id = ANY($1) ORDER BY id", departments},
+		{"workspaces", "SELECT id FROM workspaces WHERE department_id = ANY($1) ORDER BY id", departments},
+		{"components", "SELECT id FROM components WHERE warehouse_id = ANY($1) ORDER BY id", warehouses},
+	}
+
+	impact.Removed["departments"] = newImpact(scope.DepartmentIDs)
+	impact.Removed["warehouses"] = newImpact(scope.WarehouseIDs)
+
+	for _, list := range lists {
+		ids, err := collectIDsOn(db, list.query, list.arg)
+		if err != nil {
+			log.Printf("error while collecting %v for deletion: %v", list.key, err)
+			return http.StatusInternalServerError, models.DeletionImpactModel{}, fmt.Errorf("internal server error, please try again later")
+		}
+		impact.Removed[list.key] = newImpact(ids)
+	}
+
+	issues, err := collectIDsOn(db, "SELECT id FROM issues WHERE (department_id = ANY($1) OR warehouse_id = ANY($2)) AND status <> 'resolved' ORDER BY id", departments, warehouses)
+	if err != nil {
+		log.Printf("error while collecting open issues for deletion: %v", err)
+		return http.StatusInternalServerError, models.DeletionImpactModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+	impact.OpenIssues = newImpact(issues)
+
+	requests, err := collectIDsOn(db, "SELECT id FROM requests WHERE (department_id = ANY($1) OR warehouse_id = ANY($2)) AND status = 'raised' ORDER BY id", departments, warehouses)
+	if err != nil {
+		log.Printf("error while collecting pending requests for deletion: %v", err)
+		return http.StatusInternalServerError, models.DeletionImpactModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+	impact.PendingRequests = newImpact(requests)
+
+	if impact.Units, err = getUnitsImpact(db, scope); err != nil {
+		return http.StatusInternalServerError, models.DeletionImpactModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	data, err := json.Marshal(impact)
+	if err != nil {
+		log.Printf("error while encoding deletion impact: %v", err)
+		return http.StatusInternalServerError, models.DeletionImpactModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+	sum := sha256.Sum256(data)
+	impact.Token = hex.EncodeToString(sum[:])
+
+	return http.StatusOK, impact, nil
+}
+
+// getUnitsImpact groups units stored in the deleted warehouses as removed and
+// units assigned to the deleted departments from other warehouses as unassigned
+func getUnitsImpact(db querier, scope models.ScopeModel) ([]models.UnitImpactModel, error) {
+	units := []models.UnitImpactModel{}
+
+	components, err := getScopeComponents(db, scope)
+	if err != nil || len(components) == 0 {
+		return units, err
+	}
+
+	query := scopedUnitsQuery(components) + `
+	SELECT prefix, CASE WHEN warehouse_id = ANY($1) THEN 'removed' ELSE 'unassigned' END AS action, unit_id
+	FROM scoped
+	ORDER BY prefix, action, unit_id`
+
+	rows, err := db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs))
+	if err != nil {
+		log.Printf("error while collecting units for deletion: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var prefix, action string
+		var id int
+		if err := rows.Scan(&prefix, &action, &id); err != nil {
+			log.Printf("error while scanning units for deletion: %v", err)
+			return nil, err
+		}
+
+		if n := len(units); n == 0 || units[n-1].Prefix != prefix || units[n-1].Action != action {
+			units = append(units, models.UnitImpactModel{Prefix: prefix, Action: action, IDs: []int{}})
+		}
+		last := &units[len(units)-1]
+		last.IDs = append(last.IDs, id)
+		last.Count++
+	}
+
+	return units, rows.Err()
+}
+
+// beginDeletion starts the transaction of a cascading delete, REPEATABLE READ keeps the impact checked
+// against the preview token and the rows the delete procedure removes on the same snapshot
+func (q *Query) beginDeletion() (*sql.Tx, error) {
+	return q.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+}
+
+// deletionFailed reports a concurrent change that broke the snapshot of a deletion as a conflict
+func deletionFailed(err error) (int, error) {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "40001" {
+		return http.StatusConflict, fmt.Errorf("data changed since the deletion preview, please preview again")
+	}
+	return http.StatusInternalServerError, err
+}
+
+// checkDeletionToken locks the row being deleted and makes sure the impact computed inside tx
+// still matches the preview the token was issued for, a cascading delete always needs a preview
+func (q *Query) checkDeletionToken(tx *sql.Tx, entity_type string, entity_id int, token string) (int, error) {
+	if token == "" {
+		return http.StatusPreconditionRequired, fmt.Errorf("preview the deletion first and send its preview_token")
+	}
+
+	locks := map[string]string{
+		"branch":     "SELECT branch_id FROM branches WHERE branch_id = $1 FOR UPDATE",
+		"department": "SELECT department_id FROM departments WHERE department_id = $1 FOR UPDATE",
+		"warehouse":  "SELECT id FROM warehouses WHERE id = $1 FOR UPDATE",
+	}
+
+	var id int
+	if err := tx.QueryRow(locks[entity_type], entity_id).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while locking %v %v: %v", entity_type, entity_id, err)
+		if status, err := deletionFailed(err); status == http.StatusConflict {
+			return status, err
+		}
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	status, impact, err := deletionImpact(tx, entity_type, entity_id)
+	if err != nil {
+		return status, err
+	}
+
+	if impact.Token != token {
+		return http.StatusConflict, fmt.Errorf("data changed since the deletion preview, please preview again")
+	}
+
+	return http.StatusOK, nil
+}
@@ -5,13 +5,13 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
 )
 
-func (q *Query) CreateWorkspace(workspace models.CreateWorkspaceModel, departmentHeadID int) (int, int, error) {
-	query1 := "SELECT department_id FROM department_heads WHERE id = $1"
-	query2 := "INSERT INTO workspaces(department_id, workspace_name) VALUES($1, $2) RETURNING id"
+func (q *Query) CreateWorkspace(workspace models.CreateWorkspaceModel, department_id int) (int, int, error) {
+	query1 := "INSERT INTO workspaces(department_id, workspace_name) VALUES($1, $2) RETURNING id"
 
 	tx, err := q.db.Begin()
 	if err != nil {
@@ -28,22 +28,18 @@ func (q *Query) CreateWorkspace(workspace models.CreateWorkspaceModel, departmen
 		}
 	}()
 
-	var department_id, workspace_id int
-
-	if err = tx.QueryRow(query1, departmentHeadID).Scan(&department_id); err != nil {
-		return http.StatusInternalServerError, -1, err
-	}
+	var workspace_id int
 
-	if err = tx.QueryRow(query2, department_id, workspace.WorkspaceName).Scan(&workspace_id); err != nil {
+	if err = tx.QueryRow(query1, department_id, workspace.WorkspaceName).Scan(&workspace_id); err != nil {
 		return http.StatusInternalServerError, -1, err
 	}
 
 	return http.StatusCreated, workspace_id, nil
 }
 
-func (q *Query) DeleteWorkspace(workspace models.DeleteWorkspaceModel, departmentHeadID int) (int, error) {
-	query1 := "DELETE FROM workspaces WHERE workspace_name = $1 AND workspace_id = $2"
-	query2 := "INSERT INTO deleted_workspaces(workspace_id, department_id, deleted_by) VALUES($1, $2, $3)"
+func (q *Query) DeleteWorkspace(workspace models.DeleteWorkspaceModel, department_id, deleted_by int) (int, error) {
+	query1 := "DELETE FROM workspaces WHERE workspace_name = $1 AND id = $2 AND department_id = $3 RETURNING department_id"
+	query2 := "INSERT INTO deleted_workpaces(workspace_id, department_id, deleted_by) VALUES($1, $2, $3)"
 
 	tx, err := q.db.Begin()
 	if err != nil {
@@ -60,16 +56,14 @@ func (q *Query) DeleteWorkspace(workspace models.DeleteWorkspaceModel, departmen
 		}
 	}()
 
-	var workspace_id int
-
-	if _, err = tx.Exec(query1, workspace.WorkspaceName, workspace.WorkspaceID); err != nil {
+	if err = tx.QueryRow(query1, workspace.WorkspaceName, workspace.WorkspaceID, department_id).Scan(&department_id); err != nil {
 		if err == sql.ErrNoRows {
 			return http.StatusNotFound, fmt.Errorf("no matching data found")
 		}
 		return http.StatusInternalServerError, err
 	}
 
-	if _, err = tx.Exec(query2, workspace_id, departmentHeadID); err != nil {
+	if _, err = tx.Exec(query2, workspace.WorkspaceID, department_id, deleted_by); err != nil {
 		return http.StatusInternalServerError, err
 	}
 
@@ -77,9 +71,9 @@ func (q *Query) DeleteWorkspace(workspace models.DeleteWorkspaceModel, departmen
 }
 
 func (q *Query) RaiseIssue(issue models.IssueModel) (int, int, error) {
-	query1 := fmt.Sprintf("SELECT workspace_id FROM %s_units_assigned WHERE unit_id = $1", issue.UnitPrefix)
-	query2 := `INSERT INTO issues (department_id, warehouse_id, workspace_id, unit_id, issue) VALUES($1, $2, $3, $4, $5) RETURNING id`
-	query3 := fmt.Sprintf("UPDATE %s_units SET status = repair WHERE id = $1", issue.UnitPrefix)
+	query1 := fmt.Sprintf("SELECT workspace_id FROM %s_units_assigned WHERE unit_id = $1 AND department_id = $2", issue.UnitPrefix)
+	query2 := `INSERT INTO issues (department_id, warehouse_id, workspace_id, unit_id, unit_prefix, issue) VALUES($1, $2, $3, $4, $5, $6) RETURNING id`
+	query3 := fmt.Sprintf("UPDATE %s_units SET status = 'repair' WHERE id = $1", issue.UnitPrefix)
 
 	tx, err := q.db.Begin()
 	if err != nil {
@@ -99,7 +93,7 @@ func (q *Query) RaiseIssue(issue models.IssueModel) (int, int, error) {
 	var issue_id int
 	var workspace_id int
 
-	if err = tx.QueryRow(query1, issue.UnitID).Scan(&workspace_id); err != nil {
+	if err = tx.QueryRow(query1, issue.UnitID, issue.DepartmentID).Scan(&workspace_id); err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("no matching unit found :%v", err)
 			return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
@@ -107,7 +101,7 @@ func (q *Query) RaiseIssue(issue models.IssueModel) (int, int, error) {
 		return http.StatusInternalServerError, -1, err
 	}
 
-	if err = tx.QueryRow(query2, issue.DepartmentID, issue.WarehouseID, issue.WorkspaceID, issue.UnitID, issue.Issue).Scan(&issue_id); err != nil {
+	if err = tx.QueryRow(query2, issue.DepartmentID, issue.WarehouseID, issue.WorkspaceID, issue.UnitID, issue.UnitPrefix, issue.Issue).Scan(&issue_id); err != nil {
 		return http.StatusInternalServerError, -1, err
 	}
 
@@ -123,9 +117,6 @@ func (q *Query) RaiseIssue(issue models.IssueModel) (int, int, error) {
 }
 
 func (q *Query) RequestNewUnits(department_id int, workspace_id int, warehouse_id int, component_id int, number_of_units int, prefix string, user_id int) (int, int, error) {
-	query1 := fmt.Sprintf("SELECT id FROM %s_units WHERE component_id = $1 AND warehouse_id = $2 AND id NOT IN (SELECT unit_id FROM %s_units_assigned )", prefix, prefix)
-	query2 := "INSERT INTO requests(department_id, workspace_id, warehouse_id, component_id, number_of_units, prefix, created_by) VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id"
-
 	tx, err := q.db.Begin()
 	if err != nil {
 		log.Printf("error while initialising DB: %v", err)
@@ -141,10 +132,37 @@ func (q *Query) RequestNewUnits(department_id int, workspace_id int, warehouse_i
 		}
 	}()
 
+	var status, Request_id int
+
+	status, Request_id, err = requestNewUnits(tx, department_id, workspace_id, warehouse_id, component_id, number_of_units, prefix, user_id)
+
+	return status, Request_id, err
+}
+
+// requestNewUnits raises a request for units of a component after checking the warehouse has enough of them
+func requestNewUnits(tx *sql.Tx, department_id int, workspace_id int, warehouse_id int, component_id int, number_of_units int, prefix string, user_id int) (int, int, error) {
+	query1 := fmt.Sprintf("SELECT COUNT(*) FROM %s_units WHERE component_id = $1 AND warehouse_id = $2 AND id NOT IN (SELECT unit_id FROM %s_units_assigned )", prefix, prefix)
+	query2 := "INSERT INTO requests(department_id, workspace_id, warehouse_id, component_id, number_of_units, prefix, created_by) VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+
 	var Request_id int
 	var num int
+	var kind string
+
+	if err := tx.QueryRow("SELECT kind FROM components WHERE id = $1", component_id).Scan(&kind); err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no matching component found :%v", err)
+			return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting component kind: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	// consumables have no unit rows, their availability is the quantity on hand
+	if kind == "consumable" {
+		query1 = "SELECT quantity FROM consumable_stock WHERE component_id = $1 AND warehouse_id = $2"
+	}
 
-	if err = tx.QueryRow(query1, component_id, warehouse_id).Scan(&num); err != nil {
+	if err := tx.QueryRow(query1, component_id, warehouse_id).Scan(&num); err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("no matching unit found :%v", err)
 			return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
@@ -158,7 +176,7 @@ func (q *Query) RequestNewUnits(department_id int, workspace_id int, warehouse_i
 		return http.StatusBadRequest, -1, fmt.Errorf("not enough units available")
 	}
 
-	if err = tx.QueryRow(query2, department_id, workspace_id, warehouse_id, component_id, number_of_units, prefix, user_id).Scan(&Request_id); err != nil {
+	if err := tx.QueryRow(query2, department_id, workspace_id, warehouse_id, component_id, number_of_units, prefix, user_id).Scan(&Request_id); err != nil {
 		log.Printf("error while requesting new units: %v", err)
 		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
 	}
@@ -215,11 +233,11 @@ func (q *Query) GetAllRequests(department_id int) ([]models.AllRequestsModel, er
 }
 
 func (q *Query) GetRequestDetails(getRequestDetails models.GetRequestDetailsModel) (models.RequestDetailsModel, error) {
-	query := "SELECT id, workspace_id, warehouse_id, component_id, number_of_units, prefix, created_by, created_at, status FROM requests WHERE id = $1"
+	query := "SELECT id, workspace_id, warehouse_id, component_id, number_of_units, prefix, created_by, created_at, status FROM requests WHERE id = $1 AND department_id = $2"
 
 	var Request models.RequestDetailsModel
 
-	err := q.db.QueryRow(query, getRequestDetails.RequestID).Scan(&Request.RequestID, &Request.WorkspaceID, &Request.WarehouseID, &Request.ComponentID, &Request.NumberOfUnits, &Request.Prefix, &Request.CreatedBy, &Request.CreatedAt, &Request.Status)
+	err := q.db.QueryRow(query, getRequestDetails.RequestID, getRequestDetails.DepartmentID).Scan(&Request.RequestID, &Request.WorkspaceID, &Request.WarehouseID, &Request.ComponentID, &Request.NumberOfUnits, &Request.Prefix, &Request.CreatedBy, &Request.CreatedAt, &Request.Status)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("no matching data found : %v", err)
@@ -257,7 +275,7 @@ func (q *Query) DeleteIssue(issue int, userID int) (int, error) {
 	var unit_id int
 	var unit_prefix string
 	var Issue string
-	var created_at int64
+	var created_at time.Time
 	var status string
 
 	if err = tx.QueryRow(query1, issue).Scan(&issue_id, &department_id, &workspace_id, &unit_id, &unit_prefix, &Issue, &created_at, &status); err != nil {
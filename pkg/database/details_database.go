@@ -5,398 +5,124 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
 
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
 )
 
-func (q *Query) GetAllDepartments(branch_id int, sort models.SortModel) (int, []models.AllDepartmentsModel, int, error) {
-
-	tx, err := q.db.Begin()
-	if err != nil {
-		log.Printf("error while initialising DB: %v", err)
-		return http.StatusInternalServerError, []models.AllDepartmentsModel{}, -1, err
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
-
-	whereClause := fmt.Sprintf("WHERE d.branch_id = %d ", branch_id)
-
-	if sort.Search != "" {
-		var id int
-		id, err = strconv.Atoi(sort.Search)
-		if err == nil {
-			whereClause += fmt.Sprintf("AND (d.department_id = %d OR  dh.id = %d OR w.id = %d )", id, id, id)
-		} else {
-			whereClause += fmt.Sprintf("AND (d.department_name LIKE '%%%s%%' OR dh.name LIKE '%%%s%%' OR w.workspace_name LIKE '%%%s%%')", sort.Search, sort.Search, sort.Search)
-		}
-	}
-
-	query1 := fmt.Sprintf(`SELECT 
+func (q *Query) GetAllDepartments(branch_id int, spec queryspec.Spec) (int, []models.AllDepartmentsModel, models.PageModel, error) {
+	base := `SELECT
 		d.department_id,
 		d.department_name,
 		COALESCE(dh.name, '') AS department_head_name,
-		COUNT(DISTINCT w.id) AS no_of_workspaces,
-		COUNT(DISTINCT i.id) AS issues
-	FROM 
-		departments d
+		(SELECT COUNT(*) FROM workspaces w WHERE w.department_id = d.department_id) AS no_of_workspaces,
+		(SELECT COUNT(*) FROM issues i WHERE i.department_id = d.department_id) AS issues
+	FROM departments d
 	LEFT JOIN department_head dh ON d.department_id = dh.department_id
-	LEFT JOIN workspaces w ON d.department_id = w.department_id
-	LEFT JOIN issues i ON d.department_id = i.department_id
-	%s
-	GROUP BY 
-		d.department_id, d.department_name, dh.name
-	ORDER BY 
-		%s %s
-	LIMIT $1 OFFSET $2;
-		`, whereClause, sort.SortBy, sort.Order)
-	query2 := `SELECT COUNT(*) AS total_departments 
-		FROM departments d
-		WHERE branch_id = $1`
-
-	var rows *sql.Rows
-
-	rows, err = tx.Query(query1, sort.Limit, sort.Offset)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no departments with branch_id %v found: %v", branch_id, err)
-			return http.StatusNotFound, []models.AllDepartmentsModel{}, 0, fmt.Errorf("no departments found")
-		}
-		log.Printf("error while getting department data: %v", err)
-		return http.StatusInternalServerError, []models.AllDepartmentsModel{}, -1, fmt.Errorf("internal server error, please try again later")
-	}
-	defer rows.Close()
+	WHERE d.branch_id = $1`
 
-	var Departments []models.AllDepartmentsModel
+	Departments := []models.AllDepartmentsModel{}
 
-	for rows.Next() {
+	page, err := q.listPage(spec, base, "department_id, department_name, department_head_name, no_of_workspaces, issues", []interface{}{branch_id}, func(rows *sql.Rows, extra ...interface{}) error {
 		var dept models.AllDepartmentsModel
-		if err = rows.Scan(
-			&dept.DepartmentID,
-			&dept.DepartmentName,
-			&dept.DepartmentHeadName,
-			&dept.NoOfWorkspaces,
-			&dept.Issues,
-		); err != nil {
-			log.Printf("error while row iteration: %v", err)
-			return http.StatusInternalServerError, []models.AllDepartmentsModel{}, -1, fmt.Errorf("internal server error, please try again later")
+		if err := rows.Scan(append([]interface{}{&dept.DepartmentID, &dept.DepartmentName, &dept.DepartmentHeadName, &dept.NoOfWorkspaces, &dept.Issues}, extra...)...); err != nil {
+			return err
 		}
 		Departments = append(Departments, dept)
-	}
-
-	var Total_departments int
-
-	if err = tx.QueryRow(query2, branch_id).Scan(&Total_departments); err != nil {
-		log.Printf("errro while getting total no. of departments in branch %v: %v", branch_id, err)
-		return http.StatusInternalServerError, []models.AllDepartmentsModel{}, -1, fmt.Errorf("internal server error, please try again later")
-	}
-
-	return http.StatusOK, Departments, Total_departments, nil
-
-}
-
-func (q *Query) GetDepartmentIssues(department_id int, sort models.SortModel) (int, []models.DepartmentIssuesModel, int, error) {
-
-	tx, err := q.db.Begin()
+		return nil
+	})
 	if err != nil {
-		log.Printf("error while initialising DB: %v", err)
-		return http.StatusInternalServerError, nil, -1, err
+		log.Printf("error while getting departments of branch %v: %v", branch_id, err)
+		return http.StatusInternalServerError, []models.AllDepartmentsModel{}, models.PageModel{}, fmt.Errorf("internal server error, please try again later")
 	}
 
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
-
-	whereClause := fmt.Sprintf("WHERE d.department_id = %d ", department_id)
-
-	if sort.Search != "" {
-		var id int
-
-		id, err = strconv.Atoi(sort.Search)
-		if err == nil {
-			whereClause += fmt.Sprintf("AND (d.department_id = %d OR  i.id = %d OR w.id = %d )", id, id, id)
-		} else {
-			whereClause += fmt.Sprintf("AND (i.issue LIKE '%%%s%%' OR w.workspace_name LIKE '%%%s%%')", sort.Search, sort.Search)
-		}
-	}
-
-	query1 := fmt.Sprintf(`SELECT
-	i.id, 
-	i.issue, 
-	i.created_at, 
-	i.status, 
-	i.unit_id,
-	i.unit_prefix,
-	w.id
-	FROM 
-		issues i
-	LEFT JOIN workspaces w ON i.id = w.id
-	LEFT JOIN departments d ON i.department_id = d.department_id
-	LEFT JOIN department_head dh ON d.department_id = dh.department_id
-	%s
-	ORDER BY i.%s %s
-	LIMIT $1 OFFSET $2;`,
-		whereClause, sort.SortBy, sort.Order)
+	return http.StatusOK, Departments, page, nil
+}
 
-	query2 := `SELECT COUNT(*) AS total_issues 
-		FROM issues i
-		WHERE i.department_id = $1`
+func (q *Query) GetDepartmentIssues(department_id int, spec queryspec.Spec) (int, []models.DepartmentIssuesModel, models.PageModel, error) {
+	base := `SELECT id, issue, created_at, status, unit_id, unit_prefix, workspace_id
+	FROM issues
+	WHERE department_id = $1`
 
-	var rows *sql.Rows
+	Issues := []models.DepartmentIssuesModel{}
 
-	rows, err = tx.Query(query1, sort.Limit, sort.Offset)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no issues with department_id %v found: %v", department_id, err)
-			return http.StatusNotFound, nil, 0, fmt.Errorf("no issues found")
-		}
-		log.Printf("error while getting issue data: %v", err)
-		return http.StatusInternalServerError, nil, -1, fmt.Errorf("internal server error, please try again later")
-	}
-	defer rows.Close()
-
-	var Issues []models.DepartmentIssuesModel
-
-	for rows.Next() {
+	page, err := q.listPage(spec, base, "id, issue, created_at, status, unit_id, unit_prefix, workspace_id", []interface{}{department_id}, func(rows *sql.Rows, extra ...interface{}) error {
 		var issue models.DepartmentIssuesModel
-		if err := rows.Scan(
-			&issue.IssueID,
-			&issue.Issue,
-			&issue.CreatedAt,
-			&issue.Status,
-			&issue.UnitID,
-			&issue.UnitPrefix,
-			&issue.WorkspaceID,
-		); err != nil {
-			log.Printf("error while row iteration: %v", err)
-			return http.StatusInternalServerError, nil, -1, fmt.Errorf("internal server error, please try again later")
+		if err := rows.Scan(append([]interface{}{&issue.IssueID, &issue.Issue, &issue.CreatedAt, &issue.Status, &issue.UnitID, &issue.UnitPrefix, &issue.WorkspaceID}, extra...)...); err != nil {
+			return err
 		}
 		Issues = append(Issues, issue)
-	}
-
-	var Total_issues int
-
-	if err = tx.QueryRow(query2, department_id).Scan(&Total_issues); err != nil {
-		log.Printf("errro while getting total no. of issues in department %v: %v", department_id, err)
-		return http.StatusInternalServerError, nil, -1, fmt.Errorf("internal server error, please try again later")
-	}
-
-	return http.StatusOK, Issues, Total_issues, nil
-}
-
-func (q *Query) GetAllWorkspaces(department_id int, sort models.SortModel) (int, []models.DepartmentWorkspaceModel, int, error) {
-
-	tx, err := q.db.Begin()
+		return nil
+	})
 	if err != nil {
-		log.Printf("error while initialising DB: %v", err)
-		return http.StatusInternalServerError, nil, -1, err
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
-
-	whereClause := fmt.Sprintf("WHERE w.department_id = %d ", department_id)
-
-	if sort.Search != "" {
-		var id int
-
-		id, err = strconv.Atoi(sort.Search)
-		if err == nil {
-			whereClause += fmt.Sprintf("AND (w.id = %d OR  w.department_id = %d )", id, id)
-		} else {
-			whereClause += fmt.Sprintf("AND (w.workspace_name LIKE '%%%s%%' )", sort.Search)
-		}
+		log.Printf("error while getting issues of department %v: %v", department_id, err)
+		return http.StatusInternalServerError, []models.DepartmentIssuesModel{}, models.PageModel{}, fmt.Errorf("internal server error, please try again later")
 	}
 
-	query1 := fmt.Sprintf(`
-			SELECT
-	        w.id,
-	        w.workspace_name
-			FROM
-				workspaces w
-			%s
-			ORDER BY w.%s %s
-			LIMIT $1 OFFSET $2;
-		`,
-		whereClause, sort.SortBy, sort.Order)
+	return http.StatusOK, Issues, page, nil
+}
 
-	query2 := `SELECT COUNT(*) AS total_workspaces
-		FROM workspaces w
-		WHERE w.department_id = $1`
+func (q *Query) GetAllWorkspaces(department_id int, spec queryspec.Spec) (int, []models.DepartmentWorkspaceModel, models.PageModel, error) {
+	base := `SELECT id, workspace_name FROM workspaces WHERE department_id = $1`
 
-	var rows *sql.Rows
+	Workspaces := []models.DepartmentWorkspaceModel{}
 
-	rows, err = tx.Query(query1, sort.Limit, sort.Offset)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no workspaces with department_id %v found: %v", department_id, err)
-			return http.StatusNotFound, nil, 0, fmt.Errorf("no workspaces found")
-		}
-		log.Printf("error while getting workspace data: %v", err)
-		return http.StatusInternalServerError, nil, -1, fmt.Errorf("internal server error, please try again later")
-	}
-	defer rows.Close()
-
-	var Workspaces []models.DepartmentWorkspaceModel
-	for rows.Next() {
+	page, err := q.listPage(spec, base, "id, workspace_name", []interface{}{department_id}, func(rows *sql.Rows, extra ...interface{}) error {
 		var workspace models.DepartmentWorkspaceModel
-		if err = rows.Scan(
-			&workspace.WorkspaceID,
-			&workspace.WorkspaceName,
-		); err != nil {
-			log.Printf("error while row iteration: %v", err)
-			return http.StatusInternalServerError, nil, -1, fmt.Errorf("internal server error, please try again later")
+		if err := rows.Scan(append([]interface{}{&workspace.WorkspaceID, &workspace.WorkspaceName}, extra...)...); err != nil {
+			return err
 		}
 		Workspaces = append(Workspaces, workspace)
-	}
-
-	var Total_workspaces int
-
-	if err = tx.QueryRow(query2, department_id).Scan(&Total_workspaces); err != nil {
-		log.Printf("errro while getting total no. of workspaces in department %v: %v", department_id, err)
-		return http.StatusInternalServerError, nil, -1, fmt.Errorf("internal server error, please try again later")
-	}
-
-	return http.StatusOK, Workspaces, Total_workspaces, nil
-}
-
-func (q *Query) GetAllBranches(super_admin_id int, sort models.SortModel) (int, []models.AllBranchesModel, int, error) {
-
-	tx, err := q.db.Begin()
+		return nil
+	})
 	if err != nil {
-		log.Printf("error while initialising DB: %v", err)
-		return http.StatusInternalServerError, nil, -1, err
+		log.Printf("error while getting workspaces of department %v: %v", department_id, err)
+		return http.StatusInternalServerError, []models.DepartmentWorkspaceModel{}, models.PageModel{}, fmt.Errorf("internal server error, please try again later")
 	}
 
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
-
-	whereClause := fmt.Sprintf("WHERE b.super_admin_id = %d ", super_admin_id)
+	return http.StatusOK, Workspaces, page, nil
+}
 
-	if sort.Search != "" {
-		var id int
+func (q *Query) GetAllBranches(branch_ids []int, spec queryspec.Spec) (int, []models.AllBranchesModel, models.PageModel, error) {
+	base := `SELECT
+		b.branch_id,
+		b.branch_name,
+		b.branch_location,
+		COALESCE(bh.name, '') AS branch_head_name
+	FROM branches b
+	LEFT JOIN branch_head bh ON b.branch_id = bh.branch_id
+	WHERE b.branch_id = ANY($1)`
 
-		id, err = strconv.Atoi(sort.Search)
-		if err == nil {
-			whereClause += fmt.Sprintf("AND (b.branch_id = %d )", id)
-		} else {
-			whereClause += fmt.Sprintf("AND (b.branch_location LIKE '%%%s%%' OR b.branch_name LIKE '%%%s%%')", sort.Search, sort.Search)
-		}
-	}
+	Branches := []models.AllBranchesModel{}
 
-	query1 := fmt.Sprintf(`
-			SELECT
-				b.branch_id,
-				b.branch_name,
-				b.branch_location,
-				bh.name AS branch_head_name
-			FROM
-				branches b
-			LEFT JOIN branch_head bh ON b.branch_id = bh.branch_id
-			%s
-			ORDER BY b.%s %s
-			LIMIT $1 OFFSET $2;
-		`,
-		whereClause, sort.SortBy, sort.Order)
-
-	query2 := `SELECT COUNT(*) AS total_branches
-			FROM branches b
-			WHERE b.super_admin_id = $1`
-
-	var rows *sql.Rows
-
-	rows, err = tx.Query(query1, sort.Limit, sort.Offset)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no branches with super_admin_id %v found: %v", super_admin_id, err)
-			return http.StatusNotFound, nil, 0, fmt.Errorf("no branches found")
-		}
-		log.Printf("error while getting branch data: %v", err)
-		return http.StatusInternalServerError, nil, -1, fmt.Errorf("internal server error, please try again later")
-	}
-	defer rows.Close()
-
-	var Branches []models.AllBranchesModel
-
-	for rows.Next() {
+	page, err := q.listPage(spec, base, "branch_id, branch_name, branch_location, branch_head_name", []interface{}{pq.Array(branch_ids)}, func(rows *sql.Rows, extra ...interface{}) error {
 		var branch models.AllBranchesModel
-		if err = rows.Scan(
-			&branch.BranchID,
-			&branch.BranchName,
-			&branch.BranchLocation,
-			&branch.BranchHeadName,
-		); err != nil {
-			log.Printf("error while row iteration: %v", err)
-			return http.StatusInternalServerError, nil, -1, fmt.Errorf("internal server error, please try again later")
+		if err := rows.Scan(append([]interface{}{&branch.BranchID, &branch.BranchName, &branch.BranchLocation, &branch.BranchHeadName}, extra...)...); err != nil {
+			return err
 		}
 		Branches = append(Branches, branch)
-	}
-
-	var Total_branches int
-
-	if err = tx.QueryRow(query2, super_admin_id).Scan(&Total_branches); err != nil {
-		log.Printf("errro while getting total no. of branches in super_admin %v: %v", super_admin_id, err)
-		return http.StatusInternalServerError, nil, -1, fmt.Errorf("internal server error, please try again later")
-	}
-	return http.StatusOK, Branches, Total_branches, nil
-}
-
-func (q *Query) CheckIfDepartmentUnderBranchHead(department_id, user_id int) (bool, error) {
-	query := "SELECT EXISTS(SELECT 1 FROM departments WHERE department_id = $1 AND branch_id = (SELECT branch_id FROM branch_head WHERE id = $2))"
-	var exists bool
-	err := q.db.QueryRow(query, department_id, user_id).Scan(&exists)
+		return nil
+	})
 	if err != nil {
-		return false, err
+		log.Printf("error while getting branches: %v", err)
+		return http.StatusInternalServerError, []models.AllBranchesModel{}, models.PageModel{}, fmt.Errorf("internal server error, please try again later")
 	}
-	return exists, nil
-}
 
-func (q *Query) CheckIfWarehouseUnderBranchHead(warehouse_id, user_id int) (bool, error) {
-	query := "SELECT EXISTS(SELECT 1 FROM warehouses WHERE warehouse_id = $1 AND branch_id = (SELECT branch_id FROM branch_head WHERE id = $2))"
-	var exists bool
-	err := q.db.QueryRow(query, warehouse_id, user_id).Scan(&exists)
-	if err != nil {
-		return false, err
-	}
-	return exists, nil
+	return http.StatusOK, Branches, page, nil
 }
 
-func (q *Query) CheckIfWarehouseIDExistsInTheDepartmentsBranch(warehouse_id, user_id int) (bool, error) {
+func (q *Query) CheckIfWarehouseIDExistsInTheDepartmentsBranch(warehouse_id, department_id int) (bool, error) {
 	query := `SELECT EXISTS (
 				SELECT 1
 				FROM warehouses w
-				JOIN departments d ON d.department_id = dh.department_id
-				JOIN department_heads dh ON dh.department_id = d.department_id
-				WHERE w.id = $1 AND dh.id = $2 AND w.branch_id = d.branch_id
+				JOIN departments d ON d.branch_id = w.branch_id
+				WHERE w.id = $1 AND d.department_id = $2
 			) AS same_branch;`
 
 	var same_branch bool
 
-	err := q.db.QueryRow(query, warehouse_id, user_id).Scan(&same_branch)
+	err := q.db.QueryRow(query, warehouse_id, department_id).Scan(&same_branch)
 
 	return same_branch, err
 }
@@ -520,130 +246,47 @@ func (q *Query) CheckWarehouseHead(user_id, component_id int) (bool, error) {
 	return exists, nil
 }
 
-func (q *Query) GetAllOutOfWarehouseUnitsInWarehouse(warehouse_id, limit, offset int, prefix string) (int, []models.AllOutOfWarentyWarehouseModel, int, error) {
-	query := fmt.Sprintf("SELECT id, warranty_date FROM %s_units WHERE warenty_date < NOW() LIMIT $1 OFFSET $2", prefix)
-	query1 := "SELECT COUNT(*) FROM %s_units WHERE warenty_date < NOW() "
-
-	tx, err := q.db.Begin()
-	if err != nil {
-		log.Printf("error while initialising DB: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, -1, fmt.Errorf("database error")
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
+func (q *Query) GetAllOutOfWarehouseUnitsInWarehouse(warehouse_id, component_id int, prefix string, spec queryspec.Spec) (int, []models.AllOutOfWarentyWarehouseModel, models.PageModel, error) {
+	base := fmt.Sprintf(`SELECT id, warehouse_id, warranty_date AS expired_on FROM %s_units
+		WHERE warehouse_id = $1 AND component_id = $2 AND warranty_date < NOW()`, prefix)
 
-	var rows *sql.Rows
+	units := []models.AllOutOfWarentyWarehouseModel{}
 
-	rows, err = tx.Query(query, limit, offset)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no units found for component id %v", prefix)
-			return http.StatusNotFound, []models.AllOutOfWarentyWarehouseModel{}, 0, fmt.Errorf("no units found")
-		}
-		log.Printf("error while querying data: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, -1, fmt.Errorf("error occured while retrieving data")
-	}
-	defer rows.Close()
-
-	var OutOfWarehouseUnits []models.AllOutOfWarentyWarehouseModel
-
-	for rows.Next() {
+	page, err := q.listPage(spec, base, "id, expired_on", []interface{}{warehouse_id, component_id}, func(rows *sql.Rows, extra ...interface{}) error {
 		var unit models.AllOutOfWarentyWarehouseModel
-		if err := rows.Scan(&unit.UnitID, &unit.ExpiredOn); err != nil {
-			log.Printf("error while scanning data: %v", err)
-			return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, -1, fmt.Errorf("error occured while retrieving data")
+		if err := rows.Scan(append([]interface{}{&unit.UnitID, &unit.ExpiredOn}, extra...)...); err != nil {
+			return err
 		}
-		OutOfWarehouseUnits = append(OutOfWarehouseUnits, unit)
-	}
-
-	if err = rows.Err(); err != nil {
-		log.Printf("row iteration error: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, -1, fmt.Errorf("internal server error, please try again later")
-	}
-
-	countQuery := fmt.Sprintf(query1, prefix)
-	var total int
-	if err = tx.QueryRow(countQuery).Scan(&total); err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no units found for component id %v", prefix)
-			return http.StatusNotFound, []models.AllOutOfWarentyWarehouseModel{}, 0, fmt.Errorf("no units found")
-		}
-		log.Printf("error while scanning data: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, -1, fmt.Errorf("error occured while retrieving data")
-	}
-
-	return http.StatusOK, OutOfWarehouseUnits, total, nil
-
-}
-
-func (q *Query) GetAllOutOfWarentyUnitsInDepartment(DepartmentID, ComponentID int, prefix string, limit, offset int) (int, []models.AllOutOfWarentyUnitsModel, int, error) {
-	query := fmt.Sprintf("SELECT id, warehouse_id, warranty_date FROM %s_units WHERE warenty_date < NOW() AND id IN (SELECT unit_id FROM %s_units_assigned WHERE department_id = $1) LIMIT $2 OFFSET $3", prefix, prefix)
-	query1 := "SELECT COUNT(*) FROM %s_units WHERE warenty_date < NOW() AND id IN (SELECT unit_id FROM %s_units_assigned WHERE department_id = $1)"
-
-	tx, err := q.db.Begin()
+		units = append(units, unit)
+		return nil
+	})
 	if err != nil {
-		log.Printf("error while initialising DB: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyUnitsModel{}, -1, fmt.Errorf("database error")
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
 	}
 
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
-
-	var rows *sql.Rows
+	return http.StatusOK, units, page, nil
+}
 
-	rows, err = tx.Query(query, DepartmentID, limit, offset)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no units found for component id %v", prefix)
-			return http.StatusNotFound, []models.AllOutOfWarentyUnitsModel{}, 0, fmt.Errorf("no units found")
-		}
-		log.Printf("error while querying data: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyUnitsModel{}, -1, fmt.Errorf("error occured while retrieving data")
-	}
-	defer rows.Close()
+func (q *Query) GetAllOutOfWarentyUnitsInDepartment(DepartmentID, ComponentID int, prefix string, spec queryspec.Spec) (int, []models.AllOutOfWarentyUnitsModel, models.PageModel, error) {
+	base := fmt.Sprintf(`SELECT u.id, u.warehouse_id, u.warranty_date AS expired_on FROM %[1]s_units u
+		JOIN %[1]s_units_assigned a ON a.unit_id = u.id
+		WHERE a.department_id = $1 AND u.component_id = $2 AND u.warranty_date < NOW()`, prefix)
 
-	var OutOfWarentyUnits []models.AllOutOfWarentyUnitsModel
+	units := []models.AllOutOfWarentyUnitsModel{}
 
-	for rows.Next() {
+	page, err := q.listPage(spec, base, "id, warehouse_id, expired_on", []interface{}{DepartmentID, ComponentID}, func(rows *sql.Rows, extra ...interface{}) error {
 		var unit models.AllOutOfWarentyUnitsModel
-		if err = rows.Scan(&unit.UnitID, &unit.WarehouseID, &unit.ExpiredOn); err != nil {
-			log.Printf("error while scanning data: %v", err)
-			return http.StatusInternalServerError, []models.AllOutOfWarentyUnitsModel{}, -1, fmt.Errorf("error occured while retrieving data")
+		if err := rows.Scan(append([]interface{}{&unit.UnitID, &unit.WarehouseID, &unit.ExpiredOn}, extra...)...); err != nil {
+			return err
 		}
-		OutOfWarentyUnits = append(OutOfWarentyUnits, unit)
-	}
-
-	if err = rows.Err(); err != nil {
-		log.Printf("row iteration error: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyUnitsModel{}, -1, fmt.Errorf("internal server error, please try again later")
-	}
-
-	countQuery := fmt.Sprintf(query1, prefix, prefix)
-	var total int
-	if err = tx.QueryRow(countQuery, DepartmentID).Scan(&total); err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no units found for component id %v", prefix)
-			return http.StatusNotFound, []models.AllOutOfWarentyUnitsModel{}, 0, fmt.Errorf("no units found")
-		}
-		log.Printf("error while scanning data: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyUnitsModel{}, -1, fmt.Errorf("error occured while retrieving data")
+		units = append(units, unit)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
 	}
 
-	return http.StatusOK, OutOfWarentyUnits, total, nil
-
+	return http.StatusOK, units, page, nil
 }
 
 func (q *Query) CheckIfComponentBelongsToWarehouse(component_id, user_id int) (bool, error) {
@@ -690,6 +333,9 @@ func (q *Query) CheckIfSuperAdminExists(super_admin_id, user_id int) error {
 	if err != nil {
 		return err
 	}
+	if !exists {
+		return sql.ErrNoRows
+	}
 	return nil
 }
 
@@ -702,3 +348,293 @@ func (q *Query) CheckIfUnitExists(unit_id int, prefix string, user_id int) (bool
 	}
 	return exists, nil
 }
+
+func (q *Query) GetOrganizationDetails(org_id int) (int, models.OrganizationDetailsModel, error) {
+	query := `SELECT
+		o.id,
+		o.name,
+		o.email,
+		o.phone_number,
+		(SELECT COUNT(*) FROM super_admin sa WHERE sa.org_id = o.id),
+		(SELECT COUNT(*) FROM branches b WHERE b.org_id = o.id),
+		(SELECT COUNT(*) FROM departments d JOIN branches b ON b.branch_id = d.branch_id WHERE b.org_id = o.id),
+		(SELECT COUNT(*) FROM warehouses w JOIN branches b ON b.branch_id = w.branch_id WHERE b.org_id = o.id),
+		(SELECT COUNT(*) FROM workspaces ws JOIN departments d ON d.department_id = ws.department_id JOIN branches b ON b.branch_id = d.branch_id WHERE b.org_id = o.id),
+		(SELECT COUNT(*) FROM components c JOIN warehouses w ON w.id = c.warehouse_id JOIN branches b ON b.branch_id = w.branch_id WHERE b.org_id = o.id),
+		(SELECT COUNT(*) FROM issues i JOIN departments d ON d.department_id = i.department_id JOIN branches b ON b.branch_id = d.branch_id WHERE b.org_id = o.id)
+	FROM organization o
+	WHERE o.id = $1`
+
+	var details models.OrganizationDetailsModel
+
+	if err := q.db.QueryRow(query, org_id).Scan(
+		&details.OrganizationID,
+		&details.OrganizationName,
+		&details.OrganizationEmail,
+		&details.OrganizationPhoneNumber,
+		&details.NoOfSuperAdmins,
+		&details.NoOfBranches,
+		&details.NoOfDepartments,
+		&details.NoOfWarehouses,
+		&details.NoOfWorkspaces,
+		&details.TotalTypesOfComponents,
+		&details.TotalNoOfIssues,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no organization with id %v found", org_id)
+			return http.StatusNotFound, models.OrganizationDetailsModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting organization details: %v", err)
+		return http.StatusInternalServerError, models.OrganizationDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	scope, err := q.GetUserScope("organization", org_id)
+	if err != nil {
+		return http.StatusInternalServerError, models.OrganizationDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	total, _, err := q.countScopeUnits(scope)
+	if err != nil {
+		return http.StatusInternalServerError, models.OrganizationDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+	details.TotalUnitsOfComponents = total
+
+	return http.StatusOK, details, nil
+}
+
+func (q *Query) GetSuperAdminDetails(super_admin_id int) (int, models.SuperAdminDetailsModel, error) {
+	query := `SELECT
+		sa.id,
+		sa.name,
+		sa.email,
+		(SELECT COUNT(*) FROM branches b WHERE b.super_admin_id = sa.id),
+		(SELECT COUNT(*) FROM departments d JOIN branches b ON b.branch_id = d.branch_id WHERE b.super_admin_id = sa.id),
+		(SELECT COUNT(*) FROM warehouses w JOIN branches b ON b.branch_id = w.branch_id WHERE b.super_admin_id = sa.id),
+		(SELECT COUNT(*) FROM workspaces ws JOIN departments d ON d.department_id = ws.department_id JOIN branches b ON b.branch_id = d.branch_id WHERE b.super_admin_id = sa.id),
+		(SELECT COUNT(*) FROM components c JOIN warehouses w ON w.id = c.warehouse_id JOIN branches b ON b.branch_id = w.branch_id WHERE b.super_admin_id = sa.id),
+		(SELECT COUNT(*) FROM issues i JOIN departments d ON d.department_id = i.department_id JOIN branches b ON b.branch_id = d.branch_id WHERE b.super_admin_id = sa.id)
+	FROM super_admin sa
+	WHERE sa.id = $1`
+
+	var details models.SuperAdminDetailsModel
+
+	if err := q.db.QueryRow(query, super_admin_id).Scan(
+		&details.SuperAdminID,
+		&details.SuperAdminName,
+		&details.SuperAdminEmail,
+		&details.NoOfBranches,
+		&details.NoOfDepartments,
+		&details.NoOfWarehouses,
+		&details.NoOfWorkspaces,
+		&details.TotalTypesOfComponents,
+		&details.TotalNoOfIssues,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no super admin with id %v found", super_admin_id)
+			return http.StatusNotFound, models.SuperAdminDetailsModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting super admin details: %v", err)
+		return http.StatusInternalServerError, models.SuperAdminDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	scope, err := q.GetUserScope("super_admin", super_admin_id)
+	if err != nil {
+		return http.StatusInternalServerError, models.SuperAdminDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	total, _, err := q.countScopeUnits(scope)
+	if err != nil {
+		return http.StatusInternalServerError, models.SuperAdminDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+	details.TotalUnitsOfComponents = total
+
+	return http.StatusOK, details, nil
+}
+
+func (q *Query) GetBranchDetails(branch_id int) (int, models.BranchDetailsModel, error) {
+	query := `SELECT
+		b.branch_id,
+		b.branch_name,
+		b.branch_location,
+		COALESCE(bh.name, ''),
+		COALESCE(bh.email, ''),
+		(SELECT COUNT(*) FROM departments d WHERE d.branch_id = b.branch_id),
+		(SELECT COUNT(*) FROM workspaces ws JOIN departments d ON d.department_id = ws.department_id WHERE d.branch_id = b.branch_id),
+		(SELECT COUNT(*) FROM components c JOIN warehouses w ON w.id = c.warehouse_id WHERE w.branch_id = b.branch_id),
+		(SELECT COUNT(*) FROM issues i JOIN departments d ON d.department_id = i.department_id WHERE d.branch_id = b.branch_id)
+	FROM branches b
+	LEFT JOIN branch_head bh ON bh.branch_id = b.branch_id
+	WHERE b.branch_id = $1`
+
+	var details models.BranchDetailsModel
+
+	if err := q.db.QueryRow(query, branch_id).Scan(
+		&details.BranchID,
+		&details.BranchName,
+		&details.BranchLocation,
+		&details.BranchHeadName,
+		&details.BranchHeadEmail,
+		&details.NoOfDepartments,
+		&details.NoOfWorkspaces,
+		&details.TotalTypesOfComponents,
+		&details.TotalNoOfIssues,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no branch with id %v found", branch_id)
+			return http.StatusNotFound, models.BranchDetailsModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting branch details: %v", err)
+		return http.StatusInternalServerError, models.BranchDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	scope := models.ScopeModel{BranchIDs: []int{branch_id}}
+	if err := q.expandBranchScope(&scope); err != nil {
+		return http.StatusInternalServerError, models.BranchDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	total, _, err := q.countScopeUnits(scope)
+	if err != nil {
+		return http.StatusInternalServerError, models.BranchDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+	details.TotalUnitsOfComponents = total
+
+	return http.StatusOK, details, nil
+}
+
+func (q *Query) GetDepartmentDetails(department_id int) (int, models.DepartmentDetailsModel, error) {
+	query := `SELECT
+		d.department_id,
+		d.department_name,
+		d.branch_id,
+		COALESCE(dh.name, ''),
+		COALESCE(dh.email, ''),
+		(SELECT COUNT(*) FROM workspaces ws WHERE ws.department_id = d.department_id),
+		(SELECT COUNT(*) FROM issues i WHERE i.department_id = d.department_id),
+		(SELECT COUNT(*) FROM issues i WHERE i.department_id = d.department_id AND i.status <> 'resolved'),
+		(SELECT COUNT(*) FROM requests r WHERE r.department_id = d.department_id AND r.status = 'raised')
+	FROM departments d
+	LEFT JOIN department_head dh ON dh.department_id = d.department_id
+	WHERE d.department_id = $1`
+
+	var details models.DepartmentDetailsModel
+
+	if err := q.db.QueryRow(query, department_id).Scan(
+		&details.DepartmentID,
+		&details.DepartmentName,
+		&details.BranchID,
+		&details.DepartmentHeadName,
+		&details.DepartmentHeadEmail,
+		&details.NoOfWorkspaces,
+		&details.TotalNoOfIssues,
+		&details.OpenIssues,
+		&details.PendingRequests,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no department with id %v found", department_id)
+			return http.StatusNotFound, models.DepartmentDetailsModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting department details: %v", err)
+		return http.StatusInternalServerError, models.DepartmentDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	_, assigned, err := q.countScopeUnits(models.ScopeModel{DepartmentIDs: []int{department_id}})
+	if err != nil {
+		return http.StatusInternalServerError, models.DepartmentDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+	details.TotalAssignedUnits = assigned
+
+	return http.StatusOK, details, nil
+}
+
+func (q *Query) GetWarehouseDetails(warehouse_id int) (int, models.WarehouseDetailsModel, error) {
+	query := `SELECT
+		w.id,
+		w.name,
+		w.email,
+		w.branch_id,
+		(SELECT COUNT(*) FROM components c WHERE c.warehouse_id = w.id),
+		(SELECT COUNT(*) FROM issues i WHERE i.warehouse_id = w.id),
+		(SELECT COUNT(*) FROM issues i WHERE i.warehouse_id = w.id AND i.status <> 'resolved'),
+		(SELECT COUNT(*) FROM requests r WHERE r.warehouse_id = w.id AND r.status = 'raised')
+	FROM warehouses w
+	WHERE w.id = $1`
+
+	var details models.WarehouseDetailsModel
+
+	if err := q.db.QueryRow(query, warehouse_id).Scan(
+		&details.WarehouseID,
+		&details.WarehouseName,
+		&details.WarehouseEmail,
+		&details.BranchID,
+		&details.TotalTypesOfComponents,
+		&details.TotalNoOfIssues,
+		&details.OpenIssues,
+		&details.PendingRequests,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no warehouse with id %v found", warehouse_id)
+			return http.StatusNotFound, models.WarehouseDetailsModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting warehouse details: %v", err)
+		return http.StatusInternalServerError, models.WarehouseDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	total, assigned, err := q.countScopeUnits(models.ScopeModel{WarehouseIDs: []int{warehouse_id}})
+	if err != nil {
+		return http.StatusInternalServerError, models.WarehouseDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+	details.TotalUnitsOfComponents = total
+	details.TotalAssignedUnits = assigned
+
+	return http.StatusOK, details, nil
+}
+
+func (q *Query) GetComponentDetails(component_id int) (int, models.ComponentDetailsModel, error) {
+	var details models.ComponentDetailsModel
+
+	if err := q.db.QueryRow("SELECT id, name, prefix, warehouse_id FROM components WHERE id = $1", component_id).Scan(
+		&details.ComponentID,
+		&details.ComponentName,
+		&details.Prefix,
+		&details.WarehouseID,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no component with id %v found", component_id)
+			return http.StatusNotFound, models.ComponentDetailsModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting component details: %v", err)
+		return http.StatusInternalServerError, models.ComponentDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	query := fmt.Sprintf(`SELECT
+		COUNT(u.id),
+		COUNT(a.unit_id),
+		COUNT(*) FILTER (WHERE u.status = 'working'),
+		COUNT(*) FILTER (WHERE u.status = 'repair'),
+		COUNT(*) FILTER (WHERE u.status = 'not_working'),
+		COUNT(*) FILTER (WHERE u.status = 'exit'),
+		COUNT(*) FILTER (WHERE u.warranty_date < NOW()),
+		COALESCE(SUM(u.cost), 0),
+		COALESCE(SUM(u.maintainance_cost), 0),
+		(SELECT COUNT(*) FROM issues i WHERE i.unit_prefix = $1)
+	FROM %[1]s_units u
+	LEFT JOIN %[1]s_units_assigned a ON a.unit_id = u.id`, details.Prefix)
+
+	if err := q.db.QueryRow(query, details.Prefix).Scan(
+		&details.TotalUnits,
+		&details.AssignedUnits,
+		&details.WorkingUnits,
+		&details.RepairUnits,
+		&details.NotWorkingUnits,
+		&details.ExitUnits,
+		&details.OutOfWarrantyUnits,
+		&details.TotalCost,
+		&details.TotalMaintenanceCost,
+		&details.TotalNoOfIssues,
+	); err != nil {
+		log.Printf("error while getting component unit details: %v", err)
+		return http.StatusInternalServerError, models.ComponentDetailsModel{}, fmt.Errorf("internal server error, please try again later")
+	}
+
+	return http.StatusOK, details, nil
+}
@@ -2,62 +2,52 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"log"
 	"strconv"
 
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
 )
 
-func (q *Query) GetAllComponentUnits(prefix string) ([]models.ExcelMaintenanceReportModel, error) {
-	query := "SELECT id, warranty_date, status, cost, maintainance_cost, last_maintenance_date FROM %s_units "
+func (q *Query) GetAllComponentUnits(prefix string, component_id int) ([]models.ExcelMaintenanceReportModel, error) {
+	query := fmt.Sprintf(`SELECT u.id, u.warranty_date, u.status::TEXT, u.cost, COALESCE(u.maintainance_cost, 0), u.last_maintenance_date,
+		a.department_id, a.workspace_id, COALESCE(u.attributes, '{}')
+		FROM %[1]s_units u
+		LEFT JOIN %[1]s_units_assigned a ON a.unit_id = u.id
+		WHERE u.component_id = $1
+		ORDER BY u.id`, prefix)
 
-	var units []models.ExcelMaintenanceReportModel
-
-	tx, err := q.db.Begin()
-	if err != nil {
-		log.Printf("error while initialising DB: %v", err)
-		return nil, err
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
-
-	var rows *sql.Rows
-
-	rows, err = tx.Query(query, prefix)
+	rows, err := q.db.Query(query, component_id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no units found for component id %v", prefix)
-			return nil, err
-		}
 		log.Printf("error while querying data: %v", err)
 		return nil, err
 	}
 	defer rows.Close()
 
+	var units []models.ExcelMaintenanceReportModel
+
 	for rows.Next() {
 		var unit models.ExcelMaintenanceReportModel
-		if err = rows.Scan(&unit.UnitID, &unit.WarrantyDate, &unit.Status, &unit.Cost, &unit.MaintenanceCost, &unit.LastMaintenanceDate); err != nil {
+		var last_maintenance_date sql.NullTime
+		var department_id, workspace_id sql.NullInt64
+		var attributes []byte
+		if err = rows.Scan(&unit.UnitID, &unit.WarrantyDate, &unit.Status, &unit.Cost, &unit.MaintenanceCost, &last_maintenance_date, &department_id, &workspace_id, &attributes); err != nil {
 			log.Printf("error while scanning data: %v", err)
 			return nil, err
 		}
-		query1 := "SELECT department_id, workspace_id FROM %s_units_assigned WHERE unit_id = $1"
-		var department_id, workspace_id int
-		if err = tx.QueryRow(query1, prefix).Scan(&department_id, &workspace_id); err != nil {
-			log.Printf("unit not assigned to any department or workspace: %v", unit.UnitID)
-			unit.DepartmentID = "N/A"
-			unit.WorkspaceID = "N/A"
-		} else {
-			department_id_str := strconv.Itoa(department_id)
-			workspace_id_str := strconv.Itoa(workspace_id)
-			unit.DepartmentID = department_id_str
-			unit.WorkspaceID = workspace_id_str
+		if last_maintenance_date.Valid {
+			unit.LastMaintenanceDate = &last_maintenance_date.Time
+		}
+		unit.DepartmentID = "N/A"
+		unit.WorkspaceID = "N/A"
+		if department_id.Valid {
+			unit.DepartmentID = strconv.FormatInt(department_id.Int64, 10)
+			unit.WorkspaceID = strconv.FormatInt(workspace_id.Int64, 10)
+		}
+		if err = json.Unmarshal(attributes, &unit.Attributes); err != nil {
+			log.Printf("error while decoding attributes of unit %v: %v", unit.UnitID, err)
+			return nil, err
 		}
 		units = append(units, unit)
 	}
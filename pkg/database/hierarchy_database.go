@@ -0,0 +1,247 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/lib/pq"
+)
+
+// hierarchyNodes runs a query selecting parent id, id, name and the head's id, name and email, and groups
+// the nodes by parent
+func (q *Query) hierarchyNodes(node_type, query string, args ...interface{}) (map[int][]models.HierarchyNodeModel, error) {
+	rows, err := q.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := map[int][]models.HierarchyNodeModel{}
+	for rows.Next() {
+		var parent_id int
+		var head_id sql.NullInt64
+		var head_name, head_email sql.NullString
+		node := models.HierarchyNodeModel{Type: node_type}
+		if err := rows.Scan(&parent_id, &node.ID, &node.Name, &head_id, &head_name, &head_email); err != nil {
+			return nil, err
+		}
+		if head_id.Valid {
+			node.Head = &models.HierarchyHeadModel{ID: int(head_id.Int64), Name: head_name.String, Email: head_email.String}
+		}
+		nodes[parent_id] = append(nodes[parent_id], node)
+	}
+
+	return nodes, rows.Err()
+}
+
+// GetHierarchyTree returns the organization chart below the caller: the organization, a super admin, a branch,
+// a department or a warehouse depending on the role, and a tree for each node delegated to the caller. depth limits
+// the levels returned below the roots, -1 returns all
+func (q *Query) GetHierarchyTree(scope models.ScopeModel, depth int) (int, models.HierarchyNodeModel, []models.HierarchyNodeModel, error) {
+	departments, warehouses := pq.Array(scope.DepartmentIDs), pq.Array(scope.WarehouseIDs)
+
+	workspaces, err := q.hierarchyNodes("workspace", `SELECT department_id, id, workspace_name, NULL::INTEGER, NULL, NULL
+		FROM workspaces WHERE department_id = ANY($1) ORDER BY workspace_name, id`, departments)
+	if err != nil {
+		log.Printf("error while getting workspaces: %v", err)
+		return http.StatusInternalServerError, models.HierarchyNodeModel{}, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	branch_departments, err := q.hierarchyNodes("department", `SELECT d.branch_id, d.department_id, d.department_name, dh.id, dh.name, dh.email
+		FROM departments d LEFT JOIN department_head dh ON dh.department_id = d.department_id
+		WHERE d.department_id = ANY($1) ORDER BY d.department_name, d.department_id`, departments)
+	if err != nil {
+		log.Printf("error while getting departments: %v", err)
+		return http.StatusInternalServerError, models.HierarchyNodeModel{}, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	branch_warehouses, err := q.hierarchyNodes("warehouse", `SELECT branch_id, id, name, id, name, email
+		FROM warehouses WHERE id = ANY($1) ORDER BY name, id`, warehouses)
+	if err != nil {
+		log.Printf("error while getting warehouses: %v", err)
+		return http.StatusInternalServerError, models.HierarchyNodeModel{}, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	branches, err := q.hierarchyNodes("branch", `SELECT b.super_admin_id, b.branch_id, b.branch_name, bh.id, bh.name, bh.email
+		FROM branches b LEFT JOIN branch_head bh ON bh.branch_id = b.branch_id
+		WHERE b.branch_id = ANY($1) ORDER BY b.branch_name, b.branch_id`, pq.Array(scope.BranchIDs))
+	if err != nil {
+		log.Printf("error while getting branches: %v", err)
+		return http.StatusInternalServerError, models.HierarchyNodeModel{}, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	super_admins, err := q.hierarchyNodes("super_admin", `SELECT org_id, id, name, id, name, email
+		FROM super_admin WHERE org_id = $1 AND ($2 = 'organization' OR id = $3) ORDER BY name, id`, scope.OrgID, scope.Role, scope.UserID)
+	if err != nil {
+		log.Printf("error while getting super admins: %v", err)
+		return http.StatusInternalServerError, models.HierarchyNodeModel{}, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	organizations, err := q.hierarchyNodes("organization", `SELECT 0, id, name, NULL::INTEGER, NULL, NULL FROM organization WHERE id = $1`, scope.OrgID)
+	if err != nil {
+		log.Printf("error while getting organization: %v", err)
+		return http.StatusInternalServerError, models.HierarchyNodeModel{}, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	workspace_units, department_units, warehouse_units, err := q.countHierarchyUnits(scope)
+	if err != nil {
+		return http.StatusInternalServerError, models.HierarchyNodeModel{}, nil, err
+	}
+
+	var attach func(node *models.HierarchyNodeModel)
+	attach = func(node *models.HierarchyNodeModel) {
+		switch node.Type {
+		case "organization":
+			node.Children = super_admins[node.ID]
+		case "super_admin":
+			node.Children = branches[node.ID]
+		case "branch":
+			node.Children = append(append([]models.HierarchyNodeModel{}, branch_departments[node.ID]...), branch_warehouses[node.ID]...)
+		case "department":
+			node.Children = workspaces[node.ID]
+			node.Units = department_units[node.ID]
+		case "warehouse":
+			node.Units = warehouse_units[node.ID]
+		case "workspace":
+			node.Units = workspace_units[node.ID]
+		}
+
+		for i := range node.Children {
+			attach(&node.Children[i])
+			if node.Type != "department" {
+				node.Units += node.Children[i].Units
+			}
+		}
+	}
+
+	// delegated nodes are part of the scope but not of the caller's own tree, the root is picked from the
+	// caller's own nodes and every delegated node becomes a tree of its own
+	own := scope
+	if len(scope.Delegations) > 0 {
+		if own, err = q.GetUserScope(scope.Role, scope.UserID); err != nil {
+			return http.StatusInternalServerError, models.HierarchyNodeModel{}, nil, err
+		}
+	}
+
+	var roots []models.HierarchyNodeModel
+	switch scope.Role {
+	case "organization":
+		roots = organizations[0]
+	case "super_admin":
+		roots = super_admins[scope.OrgID]
+	case "branch_head":
+		roots = pickHierarchyNodes(branches, own.BranchIDs)
+	case "department_head":
+		roots = pickHierarchyNodes(branch_departments, own.DepartmentIDs)
+	case "warehouses":
+		roots = pickHierarchyNodes(branch_warehouses, own.WarehouseIDs)
+	}
+
+	if len(roots) != 1 {
+		return http.StatusNotFound, models.HierarchyNodeModel{}, nil, fmt.Errorf("no matching data found")
+	}
+
+	root := roots[0]
+	attach(&root)
+	pruneHierarchy(&root, depth)
+
+	delegated := []models.HierarchyNodeModel{}
+	for _, delegation := range scope.Delegations {
+		groups := map[string]map[int][]models.HierarchyNodeModel{"branch": branches, "department": branch_departments, "warehouse": branch_warehouses}
+		nodes := pickHierarchyNodes(groups[delegation.NodeType], []int{delegation.NodeID})
+		if len(nodes) != 1 || hierarchyContains(root, nodes[0].Type, nodes[0].ID) {
+			continue
+		}
+
+		node := nodes[0]
+		attach(&node)
+		pruneHierarchy(&node, depth)
+		delegated = append(delegated, node)
+	}
+
+	return http.StatusOK, root, delegated, nil
+}
+
+// pickHierarchyNodes returns the nodes of the groups with one of the ids
+func pickHierarchyNodes(groups map[int][]models.HierarchyNodeModel, ids []int) []models.HierarchyNodeModel {
+	picked := []models.HierarchyNodeModel{}
+	for _, nodes := range groups {
+		for _, node := range nodes {
+			if slices.Contains(ids, node.ID) {
+				picked = append(picked, node)
+			}
+		}
+	}
+	return picked
+}
+
+// hierarchyContains reports whether the node of the type and id is in the tree, pruned levels are not searched
+func hierarchyContains(node models.HierarchyNodeModel, node_type string, id int) bool {
+	if node.Type == node_type && node.ID == id {
+		return true
+	}
+	for _, child := range node.Children {
+		if hierarchyContains(child, node_type, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// countHierarchyUnits counts the units visible in the scope per workspace and department they are assigned to,
+// and per warehouse for the ones in stock
+func (q *Query) countHierarchyUnits(scope models.ScopeModel) (map[int]int, map[int]int, map[int]int, error) {
+	workspace_units, department_units, warehouse_units := map[int]int{}, map[int]int{}, map[int]int{}
+
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if len(components) == 0 {
+		return workspace_units, department_units, warehouse_units, nil
+	}
+
+	query := scopedUnitsQuery(components) + `
+	SELECT warehouse_id, COALESCE(department_id, 0), COALESCE(workspace_id, 0), COUNT(*) FROM scoped GROUP BY 1, 2, 3`
+
+	rows, err := q.db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs))
+	if err != nil {
+		log.Printf("error while counting hierarchy units: %v", err)
+		return nil, nil, nil, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var warehouse_id, department_id, workspace_id, count int
+		if err := rows.Scan(&warehouse_id, &department_id, &workspace_id, &count); err != nil {
+			log.Printf("error while scanning hierarchy units: %v", err)
+			return nil, nil, nil, fmt.Errorf("error occured while retrieving data")
+		}
+
+		if department_id == 0 {
+			warehouse_units[warehouse_id] += count
+			continue
+		}
+		department_units[department_id] += count
+		workspace_units[workspace_id] += count
+	}
+
+	return workspace_units, department_units, warehouse_units, rows.Err()
+}
+
+// pruneHierarchy drops the nodes deeper than depth below node, their units stay counted in their ancestors
+func pruneHierarchy(node *models.HierarchyNodeModel, depth int) {
+	if depth == 0 {
+		node.Children = nil
+		return
+	}
+
+	for i := range node.Children {
+		pruneHierarchy(&node.Children[i], depth-1)
+	}
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+)
+
+// invitationTables maps the user level of an invited account to the table holding its password
+var invitationTables = map[string]string{
+	"super_admin":     "super_admin",
+	"branch_head":     "branch_head",
+	"department_head": "department_head",
+	"warehouses":      "warehouses",
+}
+
+// createInvitation stores the invitation of an account created without a usable password, it runs in the
+// transaction creating the account so neither exists without the other. Only the hash of the token sent by email
+// is kept
+func createInvitation(db querier, email string, invitation models.NewInvitationModel) error {
+	var invitation_id int
+	if err := db.QueryRow(`INSERT INTO invitations(user_email, name, user_level, token_hash, created_by_role, created_by, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`, email, invitation.Name, invitation.UserLevel, invitation.TokenHash,
+		invitation.CreatedByRole, invitation.CreatedBy, invitation.ExpiresAt).Scan(&invitation_id); err != nil {
+		log.Printf("error while creating invitation for %v: %v", email, err)
+		return err
+	}
+
+	return nil
+}
+
+// AcceptInvitation sets the password of the invited account and uses the invitation up
+func (q *Query) AcceptInvitation(token_hash, password string) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var invitation_id int
+	var email, user_level string
+	var expires_at time.Time
+	if err := tx.QueryRow(`SELECT id, user_email, user_level, expires_at FROM invitations
+		WHERE token_hash = $1 AND accepted_at IS NULL AND revoked_at IS NULL FOR UPDATE`, token_hash).Scan(&invitation_id, &email, &user_level, &expires_at); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("invitation is invalid or was already used")
+		}
+		log.Printf("error while getting invitation: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if !expires_at.After(time.Now()) {
+		return http.StatusGone, fmt.Errorf("invitation has expired, ask for a new one")
+	}
+
+	table, ok := invitationTables[user_level]
+	if !ok {
+		log.Printf("invitation %v has unknown user level %v", invitation_id, user_level)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	result, err := tx.Exec(fmt.Sprintf("UPDATE %s SET password = $1 WHERE email = $2", table), password, email)
+	if err != nil {
+		log.Printf("error while setting password of %v: %v", email, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		log.Printf("error while setting password of %v: %v", email, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if n == 0 {
+		// staff sign in with the level of their unit but keep their password on the staff row
+		if result, err = tx.Exec("UPDATE staff SET password = $1 WHERE user_email = $2 AND active", password, email); err != nil {
+			log.Printf("error while setting password of %v: %v", email, err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			log.Printf("error while setting password of %v: %v", email, err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		} else if n == 0 {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE invitations SET accepted_at = NOW() WHERE id = $1", invitation_id); err != nil {
+		log.Printf("error while accepting invitation %v: %v", invitation_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing transaction: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+// ResendInvitation replaces the token of a pending or expired invitation of the creator, the previous link stops working
+func (q *Query) ResendInvitation(created_by_role string, created_by, invitation_id int, token_hash string, expires_at time.Time) (int, models.InvitationModel, error) {
+	var invitation models.InvitationModel
+	if err := q.db.QueryRow(`UPDATE invitations SET token_hash = $4, expires_at = $5, sent_count = sent_count + 1, last_sent_at = NOW()
+		WHERE id = $1 AND created_by_role = $2 AND created_by = $3 AND accepted_at IS NULL AND revoked_at IS NULL
+		RETURNING id, user_email, name, user_level, sent_count, last_sent_at, expires_at, created_at`,
+		invitation_id, created_by_role, created_by, token_hash, expires_at).Scan(&invitation.InvitationID, &invitation.UserEmail, &invitation.Name,
+		&invitation.UserLevel, &invitation.SentCount, &invitation.LastSentAt, &invitation.ExpiresAt, &invitation.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.InvitationModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while resending invitation %v: %v", invitation_id, err)
+		return http.StatusInternalServerError, models.InvitationModel{}, fmt.Errorf("database error")
+	}
+	invitation.Status = "pending"
+
+	return http.StatusOK, invitation, nil
+}
+
+// RevokeInvitation withdraws a pending invitation of the creator, the account keeps no usable password until it is
+// deleted or its password is reset
+func (q *Query) RevokeInvitation(created_by_role string, created_by, invitation_id int) (int, error) {
+	result, err := q.db.Exec(`UPDATE invitations SET revoked_at = NOW()
+		WHERE id = $1 AND created_by_role = $2 AND created_by = $3 AND accepted_at IS NULL AND revoked_at IS NULL`,
+		invitation_id, created_by_role, created_by)
+	if err != nil {
+		log.Printf("error while revoking invitation %v: %v", invitation_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		log.Printf("error while revoking invitation %v: %v", invitation_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if n == 0 {
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
+	}
+
+	return http.StatusOK, nil
+}
+
+var InvitationsResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":           {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"user_email":   {Column: "user_email", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"name":         {Column: "name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"user_level":   {Column: "user_level", Type: queryspec.Enum, Values: []string{"super_admin", "branch_head", "department_head", "warehouses"}, Filterable: true, Sortable: true},
+		"status":       {Column: "status", Type: queryspec.Enum, Values: []string{"pending", "expired"}, Filterable: true, Sortable: true},
+		"last_sent_at": {Column: "last_sent_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+		"expires_at":   {Column: "expires_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+		"created_at":   {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+// GetPendingInvitations lists the invitations of the creator that were neither accepted nor revoked
+func (q *Query) GetPendingInvitations(created_by_role string, created_by int, spec queryspec.Spec) (int, []models.InvitationModel, models.PageModel, error) {
+	base := `SELECT id, user_email, name, user_level, CASE WHEN expires_at <= NOW() THEN 'expired' ELSE 'pending' END AS status,
+		sent_count, last_sent_at, expires_at, created_at
+		FROM invitations WHERE created_by_role = $1 AND created_by = $2 AND accepted_at IS NULL AND revoked_at IS NULL`
+
+	invitations := []models.InvitationModel{}
+
+	page, err := q.listPage(spec, base, "id, user_email, name, user_level, status, sent_count, last_sent_at, expires_at, created_at",
+		[]interface{}{created_by_role, created_by}, func(rows *sql.Rows, extra ...interface{}) error {
+			var invitation models.InvitationModel
+			dest := []interface{}{&invitation.InvitationID, &invitation.UserEmail, &invitation.Name, &invitation.UserLevel, &invitation.Status,
+				&invitation.SentCount, &invitation.LastSentAt, &invitation.ExpiresAt, &invitation.CreatedAt}
+			if err := rows.Scan(append(dest, extra...)...); err != nil {
+				return err
+			}
+			invitations = append(invitations, invitation)
+			return nil
+		})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, invitations, page, nil
+}
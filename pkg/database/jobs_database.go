@@ -0,0 +1,110 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/lib/pq"
+)
+
+// PurgeOlderThan deletes rows of table whose column is older than days
+func (q *Query) PurgeOlderThan(table, column string, days int) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s < NOW() - make_interval(days => $1)", pq.QuoteIdentifier(table), pq.QuoteIdentifier(column))
+
+	result, err := q.db.Exec(query, days)
+	if err != nil {
+		log.Printf("error while purging %v: %v", table, err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (q *Query) PurgeExpiredOtps(validity time.Duration) (int64, error) {
+	result, err := q.db.Exec("DELETE FROM otps WHERE time < $1", time.Now().Add(-validity).UTC())
+	if err != nil {
+		log.Printf("error while purging expired otps: %v", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetTablesWithSuffix lists existing per-prefix tables such as <prefix>_deleted_units
+func (q *Query) GetTablesWithSuffix(suffix string) ([]string, error) {
+	query := `SELECT t.table_name FROM information_schema.tables t
+		JOIN components c ON t.table_name = lower(c.prefix || $1)
+		WHERE t.table_schema = current_schema()
+		ORDER BY t.table_name`
+
+	rows, err := q.db.Query(query, suffix)
+	if err != nil {
+		log.Printf("error while listing %v tables: %v", suffix, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []string{}
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
+}
+
+func (q *Query) SaveJobRun(run models.JobRunModel) (int, error) {
+	query := "INSERT INTO job_runs(job, trigger, started_at, finished_at, purged, error) VALUES($1, $2, $3, $4, $5, NULLIF($6, '')) RETURNING id"
+
+	purged, err := json.Marshal(run.Purged)
+	if err != nil {
+		return -1, err
+	}
+
+	var id int
+	if err := q.db.QueryRow(query, run.Job, run.Trigger, run.StartedAt, run.FinishedAt, purged, run.Error).Scan(&id); err != nil {
+		log.Printf("error while saving job run: %v", err)
+		return -1, err
+	}
+
+	return id, nil
+}
+
+func (q *Query) GetJobRuns(job string, limit int) (int, []models.JobRunModel, error) {
+	query := `SELECT id, job, trigger, started_at, finished_at, purged, COALESCE(error, '')
+		FROM job_runs
+		WHERE $1 = '' OR job = $1
+		ORDER BY started_at DESC
+		LIMIT $2`
+
+	rows, err := q.db.Query(query, job, limit)
+	if err != nil {
+		log.Printf("error while getting job runs: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	runs := []models.JobRunModel{}
+	for rows.Next() {
+		var run models.JobRunModel
+		var purged []byte
+		if err := rows.Scan(&run.ID, &run.Job, &run.Trigger, &run.StartedAt, &run.FinishedAt, &purged, &run.Error); err != nil {
+			log.Printf("error while scanning job runs: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		if err := json.Unmarshal(purged, &run.Purged); err != nil {
+			log.Printf("error while decoding job run report: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		runs = append(runs, run)
+	}
+
+	return http.StatusOK, runs, nil
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+)
+
+var IssuesResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":            {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true, Searchable: true},
+		"department_id": {Column: "department_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"workspace_id":  {Column: "workspace_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"unit_id":       {Column: "unit_id", Type: queryspec.Int, Filterable: true, Searchable: true},
+		"unit_prefix":   {Column: "unit_prefix", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"issue":         {Column: "issue", Type: queryspec.String, Filterable: true, Searchable: true},
+		"status":        {Column: "status", Type: queryspec.Enum, Values: []string{"raised", "accepted", "resolved"}, Filterable: true, Sortable: true, Searchable: true},
+		"created_at":    {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+var DepartmentsResource = queryspec.Resource{
+	IDColumn:    "department_id",
+	DefaultSort: "department_id",
+	Fields: map[string]queryspec.Field{
+		"department_id":        {Column: "department_id", Type: queryspec.Int, Filterable: true, Sortable: true, Searchable: true},
+		"department_name":      {Column: "department_name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"department_head_name": {Column: "department_head_name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"no_of_workspaces":     {Column: "no_of_workspaces", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"issues":               {Column: "issues", Type: queryspec.Int, Filterable: true, Sortable: true},
+	},
+}
+
+var DepartmentIssuesResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":           {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true, Searchable: true},
+		"workspace_id": {Column: "workspace_id", Type: queryspec.Int, Filterable: true, Sortable: true, Searchable: true},
+		"unit_id":      {Column: "unit_id", Type: queryspec.Int, Filterable: true},
+		"unit_prefix":  {Column: "unit_prefix", Type: queryspec.String, Filterable: true, Sortable: true},
+		"issue":        {Column: "issue", Type: queryspec.String, Filterable: true, Searchable: true},
+		"status":       {Column: "status", Type: queryspec.Enum, Values: []string{"raised", "accepted", "resolved"}, Filterable: true, Sortable: true},
+		"created_at":   {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+var WorkspacesResource = queryspec.Resource{
+	IDColumn:    "id",
+	DefaultSort: "id",
+	Fields: map[string]queryspec.Field{
+		"id":             {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true, Searchable: true},
+		"workspace_name": {Column: "workspace_name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+	},
+}
+
+var BranchesResource = queryspec.Resource{
+	IDColumn:    "branch_id",
+	DefaultSort: "branch_id",
+	Fields: map[string]queryspec.Field{
+		"branch_id":        {Column: "branch_id", Type: queryspec.Int, Filterable: true, Sortable: true, Searchable: true},
+		"branch_name":      {Column: "branch_name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"branch_location":  {Column: "branch_location", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"branch_head_name": {Column: "branch_head_name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+	},
+}
+
+// OutOfWarrantyUnitsResource lists the units of a component whose warranty has ended
+var OutOfWarrantyUnitsResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "expired_on",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"unit_id":      {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"warehouse_id": {Column: "warehouse_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"expired_on":   {Column: "expired_on", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+// listPage runs a list query through the spec, wrapping base so filters can reference any of its output columns;
+// scan is called once per returned row and must append extra to the values it scans
+func (q *Query) listPage(spec queryspec.Spec, base string, columns string, args []interface{}, scan func(rows *sql.Rows, extra ...interface{}) error) (models.PageModel, error) {
+	where, args := spec.Where(args)
+	countArgs := args
+	keyset, tail, args := spec.Paginate(args)
+
+	query1 := fmt.Sprintf("SELECT %s, %s FROM (%s) t WHERE TRUE%s%s%s", columns, spec.CursorColumns(), base, where, keyset, tail)
+	query2 := fmt.Sprintf("SELECT COUNT(*) FROM (%s) t WHERE TRUE%s", base, where)
+
+	rows, err := q.db.Query(query1, args...)
+	if err != nil {
+		log.Printf("error while querying list: %v", err)
+		return models.PageModel{}, err
+	}
+	defer rows.Close()
+
+	var n, lastID int
+	var lastValue sql.NullString
+
+	for rows.Next() {
+		n++
+		if n > spec.Limit {
+			break
+		}
+		if err := scan(rows, &lastValue, &lastID); err != nil {
+			log.Printf("error while scanning list: %v", err)
+			return models.PageModel{}, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("row iteration error: %v", err)
+		return models.PageModel{}, err
+	}
+
+	var total int
+	if err := q.db.QueryRow(query2, countArgs...).Scan(&total); err != nil {
+		log.Printf("error while counting list: %v", err)
+		return models.PageModel{}, err
+	}
+
+	var cursorValue *string
+	if lastValue.Valid {
+		cursorValue = &lastValue.String
+	}
+
+	return spec.PageModel(total, spec.NextCursor(n, cursorValue, lastID)), nil
+}
@@ -0,0 +1,576 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
+)
+
+var LoansResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "starts_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":             {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"warehouse_id":   {Column: "warehouse_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"component_id":   {Column: "component_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"custodian_id":   {Column: "custodian_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"custodian_name": {Column: "custodian_name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"status":         {Column: "status", Type: queryspec.Enum, Values: []string{"reserved", "checked_out", "returned", "cancelled"}, Filterable: true, Sortable: true},
+		"overdue":        {Column: "overdue::TEXT", Type: queryspec.Enum, Values: []string{"true", "false"}, Filterable: true},
+		"starts_at":      {Column: "starts_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+		"ends_at":        {Column: "ends_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+		"note":           {Column: "note", Type: queryspec.String, Filterable: true, Searchable: true},
+	},
+}
+
+const loanColumns = `l.id, l.warehouse_id, l.component_id, c.name AS component_name, l.prefix, l.unit_id, l.quantity, l.custodian_id, cu.name AS custodian_name,
+	l.starts_at, l.ends_at, l.status, l.status = 'checked_out' AND l.ends_at < NOW() AS overdue, COALESCE(l.note, '') AS note, l.created_at,
+	l.checked_out_at, l.returned_at, l.reminders,
+	ARRAY(SELECT lu.unit_id FROM loan_units lu WHERE lu.loan_id = l.id ORDER BY lu.unit_id) AS unit_ids`
+
+const loanFrom = `FROM loans l
+	JOIN components c ON c.id = l.component_id
+	JOIN custodians cu ON cu.id = l.custodian_id`
+
+// loanActive matches the loans that hold units in a window, a checked out loan keeps them until it is returned
+const loanActive = `l.status IN ('reserved', 'checked_out') AND l.starts_at < $3
+	AND (CASE WHEN l.status = 'checked_out' THEN GREATEST(l.ends_at, NOW()) ELSE l.ends_at END) > $2`
+
+func scanLoan(row interface{ Scan(...interface{}) error }, loan *models.LoanModel, extra ...interface{}) error {
+	var unit_ids []int64
+	dest := []interface{}{&loan.LoanID, &loan.WarehouseID, &loan.ComponentID, &loan.ComponentName, &loan.Prefix, &loan.UnitID, &loan.Quantity, &loan.CustodianID, &loan.CustodianName,
+		&loan.StartsAt, &loan.EndsAt, &loan.Status, &loan.Overdue, &loan.Note, &loan.CreatedAt, &loan.CheckedOutAt, &loan.ReturnedAt, &loan.Reminders, pq.Array(&unit_ids)}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+
+	loan.UnitIDs = make([]int, len(unit_ids))
+	for i, id := range unit_ids {
+		loan.UnitIDs[i] = int(id)
+	}
+	return nil
+}
+
+func getLoan(tx *sql.Tx, loan_id int) (models.LoanModel, error) {
+	var loan models.LoanModel
+	err := scanLoan(tx.QueryRow(fmt.Sprintf("SELECT %s %s WHERE l.id = $1", loanColumns, loanFrom), loan_id), &loan)
+	return loan, err
+}
+
+// lendablePoolQuery selects the units of a component that can be lent: unassigned, working and not installed in another unit
+func lendablePoolQuery(prefix string) string {
+	return fmt.Sprintf(`SELECT u.id FROM %[1]s_units u
+		LEFT JOIN %[1]s_units_assigned a ON a.unit_id = u.id
+		WHERE u.component_id = $1 AND a.unit_id IS NULL AND u.status = 'working'
+		AND NOT EXISTS (SELECT 1 FROM unit_links ul WHERE ul.child_component_id = u.component_id AND ul.child_unit_id = u.id AND ul.unlinked_at IS NULL)`, prefix)
+}
+
+type loanWindow struct {
+	start, end time.Time
+	quantity   int
+}
+
+func getLoanWindows(tx *sql.Tx, component_id int, from, to time.Time) ([]loanWindow, error) {
+	rows, err := tx.Query(`SELECT l.starts_at, CASE WHEN l.status = 'checked_out' THEN GREATEST(l.ends_at, NOW()) ELSE l.ends_at END, l.quantity
+		FROM loans l WHERE l.component_id = $1 AND `+loanActive, component_id, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []loanWindow
+	for rows.Next() {
+		var window loanWindow
+		if err := rows.Scan(&window.start, &window.end, &window.quantity); err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+
+	return windows, rows.Err()
+}
+
+// peakDemand returns the largest number of units held at the same time by the windows within [from, to)
+func peakDemand(windows []loanWindow, from, to time.Time) int {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+
+	var events []event
+	for _, window := range windows {
+		if !window.start.Before(to) || !window.end.After(from) {
+			continue
+		}
+		start := window.start
+		if start.Before(from) {
+			start = from
+		}
+		events = append(events, event{start, window.quantity}, event{window.end, -window.quantity})
+	}
+
+	// a loan ending when another starts does not overlap it
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at.Equal(events[j].at) {
+			return events[i].delta < events[j].delta
+		}
+		return events[i].at.Before(events[j].at)
+	})
+
+	peak, current := 0, 0
+	for _, e := range events {
+		current += e.delta
+		if current > peak {
+			peak = current
+		}
+	}
+
+	return peak
+}
+
+// CreateReservation books a specific unit, or a quantity of any lendable units of the component, for the
+// custodian over [starts_at, ends_at)
+func (q *Query) CreateReservation(scope models.ScopeModel, reservation models.CreateReservationModel) (int, models.LoanModel, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	// serialises reservations of the component so availability cannot be double booked
+	var prefix, kind string
+	var warehouse_id int
+	if err := tx.QueryRow("SELECT prefix, kind, warehouse_id FROM components WHERE id = $1 FOR UPDATE", reservation.ComponentID).Scan(&prefix, &kind, &warehouse_id); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.LoanModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while locking component %v: %v", reservation.ComponentID, err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	} else if kind != "unit" {
+		return http.StatusBadRequest, models.LoanModel{}, fmt.Errorf("consumables cannot be lent")
+	}
+
+	var org_id, department_id int
+	var active, same_branch bool
+	if err := tx.QueryRow(`SELECT cu.org_id, cu.department_id, cu.active, EXISTS(
+			SELECT 1 FROM departments d JOIN warehouses w ON w.branch_id = d.branch_id WHERE d.department_id = cu.department_id AND w.id = $2
+		) FROM custodians cu WHERE cu.id = $1`, reservation.CustodianID, warehouse_id).Scan(&org_id, &department_id, &active, &same_branch); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.LoanModel{}, fmt.Errorf("custodian not found")
+		}
+		log.Printf("error while getting custodian %v: %v", reservation.CustodianID, err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+
+	if !slices.Contains(scope.WarehouseIDs, warehouse_id) && !slices.Contains(scope.DepartmentIDs, department_id) {
+		return http.StatusNotFound, models.LoanModel{}, fmt.Errorf("no matching data found")
+	} else if !same_branch {
+		return http.StatusBadRequest, models.LoanModel{}, fmt.Errorf("custodian is not in the warehouse's branch")
+	} else if !active {
+		return http.StatusConflict, models.LoanModel{}, fmt.Errorf("custodian is deactivated")
+	}
+
+	var pool int
+	if err := tx.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM (%s) p", lendablePoolQuery(prefix)), reservation.ComponentID).Scan(&pool); err != nil {
+		log.Printf("error while counting lendable units: %v", err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+
+	if reservation.UnitID > 0 {
+		var lendable, booked bool
+		if err := tx.QueryRow(fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM (%s) p WHERE p.id = $2)", lendablePoolQuery(prefix)), reservation.ComponentID, reservation.UnitID).Scan(&lendable); err != nil {
+			log.Printf("error while checking unit %v: %v", reservation.UnitID, err)
+			return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+		} else if !lendable {
+			return http.StatusConflict, models.LoanModel{}, fmt.Errorf("unit cannot be lent")
+		}
+
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM loans l WHERE l.component_id = $1 AND `+loanActive+`
+			AND (l.unit_id = $4 OR EXISTS (SELECT 1 FROM loan_units lu WHERE lu.loan_id = l.id AND lu.unit_id = $4 AND lu.returned_at IS NULL)))`,
+			reservation.ComponentID, reservation.StartsAt, reservation.EndsAt, reservation.UnitID).Scan(&booked); err != nil {
+			log.Printf("error while checking reservations of unit %v: %v", reservation.UnitID, err)
+			return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+		} else if booked {
+			return http.StatusConflict, models.LoanModel{}, fmt.Errorf("unit is already reserved in this period")
+		}
+	}
+
+	windows, err := getLoanWindows(tx, reservation.ComponentID, reservation.StartsAt, reservation.EndsAt)
+	if err != nil {
+		log.Printf("error while getting loans of component %v: %v", reservation.ComponentID, err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+
+	if available := pool - peakDemand(windows, reservation.StartsAt, reservation.EndsAt); available < reservation.Quantity {
+		return http.StatusConflict, models.LoanModel{}, fmt.Errorf("only %v units are available in this period", max(available, 0))
+	}
+
+	var id int
+	if err := tx.QueryRow(`INSERT INTO loans(org_id, warehouse_id, component_id, prefix, unit_id, quantity, custodian_id, starts_at, ends_at, note, created_by_role, created_by)
+		VALUES($1, $2, $3, $4, NULLIF($5, 0), $6, $7, $8, $9, NULLIF($10, ''), $11, $12) RETURNING id`,
+		org_id, warehouse_id, reservation.ComponentID, prefix, reservation.UnitID, reservation.Quantity, reservation.CustodianID, reservation.StartsAt, reservation.EndsAt, reservation.Note, scope.Role, scope.UserID).Scan(&id); err != nil {
+		log.Printf("error while creating reservation: %v", err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+
+	loan, err := getLoan(tx, id)
+	if err != nil {
+		log.Printf("error while getting loan %v: %v", id, err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing reservation: %v", err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, loan, nil
+}
+
+// lockLoan locks a loan of a warehouse in the scope, departments can only act on loans of their custodians
+func lockLoan(tx *sql.Tx, scope models.ScopeModel, loan_id int, warehouse_only bool) (int, models.LoanModel, error) {
+	var loan models.LoanModel
+	var department_id int
+	if err := tx.QueryRow(`SELECT l.warehouse_id, l.component_id, l.prefix, l.unit_id, l.quantity, l.status, l.starts_at, l.ends_at, cu.department_id
+		FROM loans l JOIN custodians cu ON cu.id = l.custodian_id
+		WHERE l.id = $1 FOR UPDATE OF l`, loan_id).Scan(&loan.WarehouseID, &loan.ComponentID, &loan.Prefix, &loan.UnitID, &loan.Quantity, &loan.Status, &loan.StartsAt, &loan.EndsAt, &department_id); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.LoanModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while locking loan %v: %v", loan_id, err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+	loan.LoanID = loan_id
+
+	if slices.Contains(scope.WarehouseIDs, loan.WarehouseID) {
+		return http.StatusOK, loan, nil
+	} else if !slices.Contains(scope.DepartmentIDs, department_id) {
+		return http.StatusNotFound, models.LoanModel{}, fmt.Errorf("no matching data found")
+	} else if warehouse_only {
+		return http.StatusForbidden, models.LoanModel{}, fmt.Errorf("only the warehouse can hand over or receive loans")
+	}
+
+	return http.StatusOK, loan, nil
+}
+
+// CheckoutLoan hands the reserved units over. Quantity reservations take the given unit_ids or the first
+// lendable units that are not out or reserved by someone else
+func (q *Query) CheckoutLoan(scope models.ScopeModel, checkout models.CheckoutLoanModel) (int, models.LoanModel, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	status, loan, err := lockLoan(tx, scope, checkout.LoanID, true)
+	if err != nil {
+		return status, models.LoanModel{}, err
+	} else if loan.Status != "reserved" {
+		return http.StatusConflict, models.LoanModel{}, fmt.Errorf("loan is %v", loan.Status)
+	} else if !loan.EndsAt.After(time.Now()) {
+		return http.StatusConflict, models.LoanModel{}, fmt.Errorf("reservation has ended")
+	}
+
+	var locked int
+	if err := tx.QueryRow("SELECT id FROM components WHERE id = $1 FOR UPDATE", loan.ComponentID).Scan(&locked); err != nil {
+		log.Printf("error while locking component %v: %v", loan.ComponentID, err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+
+	unit_ids := checkout.UnitIDs
+	if loan.UnitID != nil {
+		unit_ids = []int{*loan.UnitID}
+	} else if len(unit_ids) > 0 && len(unit_ids) != loan.Quantity {
+		return http.StatusBadRequest, models.LoanModel{}, fmt.Errorf("%v unit ids are required", loan.Quantity)
+	}
+
+	query := fmt.Sprintf(`%s
+		AND NOT EXISTS (SELECT 1 FROM loan_units lu WHERE lu.component_id = u.component_id AND lu.unit_id = u.id AND lu.returned_at IS NULL)
+		AND NOT EXISTS (SELECT 1 FROM loans l WHERE l.component_id = u.component_id AND l.unit_id = u.id AND l.id <> $2 AND l.status = 'reserved' AND l.starts_at < $3 AND l.ends_at > NOW())
+		AND ($4::INTEGER[] IS NULL OR u.id = ANY($4))
+		ORDER BY u.id LIMIT $5`, lendablePoolQuery(loan.Prefix))
+
+	var ids interface{}
+	if len(unit_ids) > 0 {
+		ids = pq.Array(unit_ids)
+	}
+
+	rows, err := tx.Query(query, loan.ComponentID, loan.LoanID, loan.EndsAt, ids, loan.Quantity)
+	if err != nil {
+		log.Printf("error while selecting units for loan %v: %v", loan.LoanID, err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+
+	var picked []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			log.Printf("error while scanning units for loan: %v", err)
+			return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+		}
+		picked = append(picked, id)
+	}
+	rows.Close()
+
+	if len(picked) < loan.Quantity {
+		if len(unit_ids) > 0 {
+			return http.StatusConflict, models.LoanModel{}, fmt.Errorf("some of the units are not available to lend")
+		}
+		return http.StatusConflict, models.LoanModel{}, fmt.Errorf("only %v units are available to lend", len(picked))
+	}
+
+	for _, id := range picked {
+		if _, err := tx.Exec("INSERT INTO loan_units(loan_id, component_id, prefix, unit_id) VALUES($1, $2, $3, $4)", loan.LoanID, loan.ComponentID, loan.Prefix, id); err != nil {
+			if isDuplicateError(err) {
+				return http.StatusConflict, models.LoanModel{}, fmt.Errorf("unit %v is already out on loan", id)
+			}
+			log.Printf("error while checking out unit %v: %v", id, err)
+			return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+		}
+	}
+
+	// units picked up early are held from the moment they leave the warehouse
+	if _, err := tx.Exec("UPDATE loans SET status = 'checked_out', starts_at = LEAST(starts_at, NOW()), checked_out_by = $2, checked_out_at = NOW() WHERE id = $1", loan.LoanID, scope.UserID); err != nil {
+		log.Printf("error while checking out loan %v: %v", loan.LoanID, err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+
+	if loan, err = getLoan(tx, loan.LoanID); err != nil {
+		log.Printf("error while getting loan %v: %v", checkout.LoanID, err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing checkout: %v", err)
+		return http.StatusInternalServerError, models.LoanModel{}, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, loan, nil
+}
+
+func (q *Query) ReturnLoan(scope models.ScopeModel, loan_id int) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	status, loan, err := lockLoan(tx, scope, loan_id, true)
+	if err != nil {
+		return status, err
+	} else if loan.Status != "checked_out" {
+		return http.StatusConflict, fmt.Errorf("loan is %v", loan.Status)
+	}
+
+	if _, err := tx.Exec("UPDATE loan_units SET returned_at = NOW() WHERE loan_id = $1 AND returned_at IS NULL", loan_id); err != nil {
+		log.Printf("error while returning units of loan %v: %v", loan_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if _, err := tx.Exec("UPDATE loans SET status = 'returned', returned_by = $2, returned_at = NOW() WHERE id = $1", loan_id, scope.UserID); err != nil {
+		log.Printf("error while returning loan %v: %v", loan_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing loan return: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+func (q *Query) CancelLoan(scope models.ScopeModel, loan_id int) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	status, loan, err := lockLoan(tx, scope, loan_id, false)
+	if err != nil {
+		return status, err
+	} else if loan.Status != "reserved" {
+		return http.StatusConflict, fmt.Errorf("loan is %v", loan.Status)
+	}
+
+	if _, err := tx.Exec("UPDATE loans SET status = 'cancelled', cancelled_at = NOW() WHERE id = $1", loan_id); err != nil {
+		log.Printf("error while cancelling loan %v: %v", loan_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing loan cancellation: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+func (q *Query) GetLoans(scope models.ScopeModel, spec queryspec.Spec) (int, []models.LoanModel, models.PageModel, error) {
+	base := fmt.Sprintf("SELECT %s %s WHERE l.warehouse_id = ANY($1) OR cu.department_id = ANY($2)", loanColumns, loanFrom)
+
+	loans := []models.LoanModel{}
+
+	page, err := q.listPage(spec, base, "id, warehouse_id, component_id, component_name, prefix, unit_id, quantity, custodian_id, custodian_name, starts_at, ends_at, status, overdue, note, created_at, checked_out_at, returned_at, reminders, unit_ids", []interface{}{pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs)}, func(rows *sql.Rows, extra ...interface{}) error {
+		var loan models.LoanModel
+		if err := scanLoan(rows, &loan, extra...); err != nil {
+			return err
+		}
+		loans = append(loans, loan)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, loans, page, nil
+}
+
+// GetLoanCalendar returns, for every lendable component of the warehouse, the loans overlapping [from, to)
+// and the units still available on each day. Departments can view the warehouses of their branch
+func (q *Query) GetLoanCalendar(scope models.ScopeModel, warehouse_id, component_id int, from, to time.Time) (int, []models.LoanCalendarComponentModel, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var visible bool
+	if err := tx.QueryRow(`SELECT EXISTS(
+		SELECT 1 FROM warehouses w WHERE w.id = $1
+		AND (w.id = ANY($2) OR w.branch_id IN (SELECT branch_id FROM departments WHERE department_id = ANY($3)))
+	)`, warehouse_id, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs)).Scan(&visible); err != nil {
+		log.Printf("error while checking warehouse %v: %v", warehouse_id, err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	} else if !visible {
+		return http.StatusNotFound, nil, fmt.Errorf("no matching data found")
+	}
+
+	rows, err := tx.Query(`SELECT c.id, c.name, c.prefix FROM components c
+		WHERE c.warehouse_id = $1 AND c.kind = 'unit' AND ($2 = 0 OR c.id = $2)
+		AND EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = lower(c.prefix || '_units'))
+		ORDER BY c.name`, warehouse_id, component_id)
+	if err != nil {
+		log.Printf("error while getting components of warehouse %v: %v", warehouse_id, err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+
+	calendar := []models.LoanCalendarComponentModel{}
+	for rows.Next() {
+		component := models.LoanCalendarComponentModel{Days: []models.LoanDayModel{}, Loans: []models.LoanModel{}}
+		if err := rows.Scan(&component.ComponentID, &component.ComponentName, &component.Prefix); err != nil {
+			rows.Close()
+			log.Printf("error while scanning components: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		calendar = append(calendar, component)
+	}
+	rows.Close()
+
+	for i := range calendar {
+		component := &calendar[i]
+
+		if err := tx.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM (%s) p", lendablePoolQuery(component.Prefix)), component.ComponentID).Scan(&component.Pool); err != nil {
+			log.Printf("error while counting lendable units: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+
+		windows, err := getLoanWindows(tx, component.ComponentID, from, to)
+		if err != nil {
+			log.Printf("error while getting loans of component %v: %v", component.ComponentID, err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+
+		for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+			reserved := peakDemand(windows, day, day.AddDate(0, 0, 1))
+			component.Days = append(component.Days, models.LoanDayModel{
+				Date:      day.Format("2006-01-02"),
+				Reserved:  reserved,
+				Available: max(component.Pool-reserved, 0),
+			})
+		}
+
+		loans, err := tx.Query(fmt.Sprintf("SELECT %s %s WHERE l.component_id = $1 AND %s ORDER BY l.starts_at", loanColumns, loanFrom, loanActive), component.ComponentID, from, to)
+		if err != nil {
+			log.Printf("error while getting loans of component %v: %v", component.ComponentID, err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		for loans.Next() {
+			var loan models.LoanModel
+			if err := scanLoan(loans, &loan); err != nil {
+				loans.Close()
+				log.Printf("error while scanning loans: %v", err)
+				return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+			}
+			component.Loans = append(component.Loans, loan)
+		}
+		loans.Close()
+	}
+
+	return http.StatusOK, calendar, nil
+}
+
+// FlagOverdueLoans marks the checked out loans that passed their due date
+func (q *Query) FlagOverdueLoans() (int64, error) {
+	result, err := q.db.Exec("UPDATE loans SET overdue_at = NOW() WHERE status = 'checked_out' AND ends_at < NOW() AND overdue_at IS NULL")
+	if err != nil {
+		log.Printf("error while flagging overdue loans: %v", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetLoansToRemind lists the overdue loans whose custodian has not been reminded within interval
+func (q *Query) GetLoansToRemind(interval time.Duration) ([]models.LoanReminderModel, error) {
+	query := `SELECT l.id, cu.name, cu.email, c.name, l.prefix, l.quantity, l.ends_at
+		FROM loans l
+		JOIN components c ON c.id = l.component_id
+		JOIN custodians cu ON cu.id = l.custodian_id
+		WHERE l.status = 'checked_out' AND l.overdue_at IS NOT NULL
+		AND (l.last_reminded_at IS NULL OR l.last_reminded_at < $1)
+		ORDER BY l.ends_at`
+
+	rows, err := q.db.Query(query, time.Now().Add(-interval).UTC())
+	if err != nil {
+		log.Printf("error while getting overdue loans: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := []models.LoanReminderModel{}
+	for rows.Next() {
+		var reminder models.LoanReminderModel
+		if err := rows.Scan(&reminder.LoanID, &reminder.CustodianName, &reminder.CustodianEmail, &reminder.ComponentName, &reminder.Prefix, &reminder.Quantity, &reminder.DueAt); err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+func (q *Query) MarkLoanReminded(loan_id int) error {
+	if _, err := q.db.Exec("UPDATE loans SET last_reminded_at = NOW(), reminders = reminders + 1 WHERE id = $1", loan_id); err != nil {
+		log.Printf("error while recording reminder of loan %v: %v", loan_id, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,523 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
+)
+
+// locationRanks orders the location kinds from the outermost, a child must be ranked below its parent
+var locationRanks = map[string]int{"site": 0, "building": 1, "floor": 2, "room": 3}
+
+var UnitLocationsResource = queryspec.Resource{
+	IDColumn:    "id",
+	DefaultSort: "id",
+	Fields: map[string]queryspec.Field{
+		"id":             {Column: "id", Type: queryspec.Int, Sortable: true},
+		"component_id":   {Column: "component_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"component_name": {Column: "component_name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"prefix":         {Column: "prefix", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"unit_id":        {Column: "unit_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"warehouse_id":   {Column: "warehouse_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"department_id":  {Column: "department_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"workspace_id":   {Column: "workspace_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"bin_id":         {Column: "bin_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"location_id":    {Column: "location_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"location_path":  {Column: "location_path", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+	},
+}
+
+// locationPath builds the "site / building / floor / room" path of the location in column
+func locationPath(column string) string {
+	return fmt.Sprintf(`COALESCE((WITH RECURSIVE up AS (
+		SELECT id, parent_id, name, 0 AS depth FROM locations WHERE id = %s
+		UNION ALL
+		SELECT l.id, l.parent_id, l.name, up.depth + 1 FROM locations l JOIN up ON l.id = up.parent_id
+	) SELECT string_agg(name, ' / ' ORDER BY depth DESC) FROM up), '')`, column)
+}
+
+// locationSubtree selects the location in placeholder and every location below it
+func locationSubtree(placeholder string) string {
+	return fmt.Sprintf(`(WITH RECURSIVE down AS (
+		SELECT id FROM locations WHERE id = %s
+		UNION ALL
+		SELECT l.id FROM locations l JOIN down ON l.parent_id = down.id
+	) SELECT id FROM down)`, placeholder)
+}
+
+// locatedUnitsQuery extends scopedUnitsQuery with a located CTE: assigned units are where their workspace
+// is, units in stock are where their storage bin is
+func locatedUnitsQuery(components []models.ComponentModel) string {
+	return scopedUnitsQuery(components) + `,
+	located AS (
+		SELECT s.component_id, s.prefix, s.unit_id, s.warehouse_id, s.department_id, s.workspace_id, ub.bin_id,
+			CASE WHEN s.workspace_id IS NOT NULL THEN w.location_id ELSE b.location_id END AS location_id
+		FROM scoped s
+		LEFT JOIN workspaces w ON w.id = s.workspace_id
+		LEFT JOIN unit_bins ub ON s.workspace_id IS NULL AND ub.component_id = s.component_id AND ub.unit_id = s.unit_id
+		LEFT JOIN storage_bins b ON b.id = ub.bin_id
+	)`
+}
+
+// clearUnitBin takes a unit out of its storage bin when it leaves the warehouse
+func clearUnitBin(tx *sql.Tx, prefix string, unit_id int) error {
+	_, err := tx.Exec("DELETE FROM unit_bins WHERE lower(prefix) = lower($1) AND unit_id = $2", prefix, unit_id)
+	return err
+}
+
+// branchVisible reports whether the branch is in the scope or is the branch of a department or warehouse in it
+func branchVisible(row interface {
+	QueryRow(string, ...interface{}) *sql.Row
+}, scope models.ScopeModel, branch_id int) (bool, error) {
+	var visible bool
+	err := row.QueryRow(`SELECT EXISTS(
+		SELECT 1 FROM branches b WHERE b.branch_id = $1 AND (
+			b.branch_id = ANY($2)
+			OR b.branch_id IN (SELECT branch_id FROM departments WHERE department_id = ANY($3))
+			OR b.branch_id IN (SELECT branch_id FROM warehouses WHERE id = ANY($4))
+		)
+	)`, branch_id, pq.Array(scope.BranchIDs), pq.Array(scope.DepartmentIDs), pq.Array(scope.WarehouseIDs)).Scan(&visible)
+	return visible, err
+}
+
+// getLocationBranch returns the branch of a location, sql.ErrNoRows when it does not exist
+func getLocationBranch(tx *sql.Tx, location_id int) (int, error) {
+	var branch_id int
+	err := tx.QueryRow("SELECT branch_id FROM locations WHERE id = $1", location_id).Scan(&branch_id)
+	return branch_id, err
+}
+
+func (q *Query) CreateLocation(scope models.ScopeModel, location models.CreateLocationModel) (int, int, error) {
+	if !slices.Contains(scope.BranchIDs, location.BranchID) {
+		return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var org_id int
+	if err := tx.QueryRow("SELECT org_id FROM branches WHERE branch_id = $1", location.BranchID).Scan(&org_id); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting branch %v: %v", location.BranchID, err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	if location.ParentID != 0 {
+		var branch_id int
+		var kind string
+		if err := tx.QueryRow("SELECT branch_id, kind FROM locations WHERE id = $1 FOR SHARE", location.ParentID).Scan(&branch_id, &kind); err != nil {
+			if err == sql.ErrNoRows {
+				return http.StatusNotFound, -1, fmt.Errorf("parent location not found")
+			}
+			log.Printf("error while getting location %v: %v", location.ParentID, err)
+			return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+		} else if branch_id != location.BranchID {
+			return http.StatusBadRequest, -1, fmt.Errorf("parent location belongs to another branch")
+		} else if locationRanks[location.Kind] <= locationRanks[kind] {
+			return http.StatusBadRequest, -1, fmt.Errorf("a %v cannot be placed in a %v", location.Kind, kind)
+		}
+	}
+
+	var id int
+	if err := tx.QueryRow("INSERT INTO locations(org_id, branch_id, parent_id, kind, name) VALUES($1, $2, NULLIF($3, 0), $4, $5) RETURNING id",
+		org_id, location.BranchID, location.ParentID, location.Kind, location.Name).Scan(&id); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, -1, fmt.Errorf("a location named %v already exists here", location.Name)
+		}
+		log.Printf("error while creating location: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing location: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, id, nil
+}
+
+// DeleteLocation removes an empty location, locations still holding sub locations, workspaces or bins are kept
+func (q *Query) DeleteLocation(scope models.ScopeModel, location_id int) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var branch_id int
+	var in_use bool
+	if err := tx.QueryRow(`SELECT l.branch_id,
+		EXISTS(SELECT 1 FROM locations c WHERE c.parent_id = l.id)
+		OR EXISTS(SELECT 1 FROM workspaces w WHERE w.location_id = l.id)
+		OR EXISTS(SELECT 1 FROM storage_bins b WHERE b.location_id = l.id)
+		FROM locations l WHERE l.id = $1 FOR UPDATE OF l`, location_id).Scan(&branch_id, &in_use); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting location %v: %v", location_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if !slices.Contains(scope.BranchIDs, branch_id) {
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
+	} else if in_use {
+		return http.StatusConflict, fmt.Errorf("location still has sub locations, workspaces or storage bins")
+	}
+
+	if _, err := tx.Exec("DELETE FROM locations WHERE id = $1", location_id); err != nil {
+		log.Printf("error while deleting location %v: %v", location_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing location deletion: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+// GetLocationTree returns the locations of a branch with the units visible in the scope at each of them,
+// total_units includes the units of every location below
+func (q *Query) GetLocationTree(scope models.ScopeModel, branch_id int) (int, models.LocationTreeModel, error) {
+	if visible, err := branchVisible(q.db, scope, branch_id); err != nil {
+		log.Printf("error while checking branch %v: %v", branch_id, err)
+		return http.StatusInternalServerError, models.LocationTreeModel{}, fmt.Errorf("database error")
+	} else if !visible {
+		return http.StatusNotFound, models.LocationTreeModel{}, fmt.Errorf("no matching data found")
+	}
+
+	rows, err := q.db.Query(`SELECT l.id, l.parent_id, l.kind, l.name,
+		(SELECT COUNT(*) FROM workspaces w WHERE w.location_id = l.id),
+		(SELECT COUNT(*) FROM storage_bins b WHERE b.location_id = l.id)
+		FROM locations l WHERE l.branch_id = $1 ORDER BY l.name, l.id`, branch_id)
+	if err != nil {
+		log.Printf("error while getting locations of branch %v: %v", branch_id, err)
+		return http.StatusInternalServerError, models.LocationTreeModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	nodes := []models.LocationNodeModel{}
+	for rows.Next() {
+		var node models.LocationNodeModel
+		if err := rows.Scan(&node.LocationID, &node.ParentID, &node.Kind, &node.Name, &node.Workspaces, &node.Bins); err != nil {
+			log.Printf("error while scanning locations: %v", err)
+			return http.StatusInternalServerError, models.LocationTreeModel{}, fmt.Errorf("error occured while retrieving data")
+		}
+		nodes = append(nodes, node)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("row iteration error: %v", err)
+		return http.StatusInternalServerError, models.LocationTreeModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	tree := models.LocationTreeModel{BranchID: branch_id}
+	counts := map[int]int{}
+
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return http.StatusInternalServerError, models.LocationTreeModel{}, err
+	}
+
+	if len(components) > 0 {
+		// a unit belongs to the branch of its department, or of its warehouse while in stock
+		query := locatedUnitsQuery(components) + `
+		SELECT location_id, COUNT(*) FROM located
+		WHERE COALESCE((SELECT branch_id FROM departments WHERE department_id = located.department_id), (SELECT branch_id FROM warehouses WHERE id = located.warehouse_id)) = $3
+		GROUP BY location_id`
+
+		rows, err := q.db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs), branch_id)
+		if err != nil {
+			log.Printf("error while counting units by location: %v", err)
+			return http.StatusInternalServerError, models.LocationTreeModel{}, fmt.Errorf("error occured while retrieving data")
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var location_id sql.NullInt64
+			var count int
+			if err := rows.Scan(&location_id, &count); err != nil {
+				log.Printf("error while scanning unit counts: %v", err)
+				return http.StatusInternalServerError, models.LocationTreeModel{}, fmt.Errorf("error occured while retrieving data")
+			}
+			tree.Units += count
+			if location_id.Valid {
+				counts[int(location_id.Int64)] = count
+			} else {
+				tree.Unplaced = count
+			}
+		}
+	}
+
+	tree.Tree = buildLocationTree(nodes, counts, nil)
+
+	return http.StatusOK, tree, nil
+}
+
+// buildLocationTree nests the nodes under parent and rolls their unit counts up
+func buildLocationTree(nodes []models.LocationNodeModel, counts map[int]int, parent *int) []models.LocationNodeModel {
+	children := []models.LocationNodeModel{}
+	for _, node := range nodes {
+		if (parent == nil) != (node.ParentID == nil) || (parent != nil && *parent != *node.ParentID) {
+			continue
+		}
+
+		node.Units = counts[node.LocationID]
+		node.TotalUnits = node.Units
+		node.Children = buildLocationTree(nodes, counts, &node.LocationID)
+		for _, child := range node.Children {
+			node.TotalUnits += child.TotalUnits
+		}
+		children = append(children, node)
+	}
+
+	return children
+}
+
+// AttachWorkspaceLocation places a workspace in a location of its department's branch, location 0 detaches it
+func (q *Query) AttachWorkspaceLocation(scope models.ScopeModel, attach models.AttachWorkspaceLocationModel) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var department_id, branch_id int
+	if err := tx.QueryRow(`SELECT w.department_id, d.branch_id FROM workspaces w
+		JOIN departments d ON d.department_id = w.department_id
+		WHERE w.id = $1 FOR UPDATE OF w`, attach.WorkspaceID).Scan(&department_id, &branch_id); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting workspace %v: %v", attach.WorkspaceID, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if !slices.Contains(scope.DepartmentIDs, department_id) {
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
+	}
+
+	if attach.LocationID != 0 {
+		if location_branch, err := getLocationBranch(tx, attach.LocationID); err != nil {
+			if err == sql.ErrNoRows {
+				return http.StatusNotFound, fmt.Errorf("location not found")
+			}
+			log.Printf("error while getting location %v: %v", attach.LocationID, err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		} else if location_branch != branch_id {
+			return http.StatusBadRequest, fmt.Errorf("location belongs to another branch")
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE workspaces SET location_id = NULLIF($2, 0) WHERE id = $1", attach.WorkspaceID, attach.LocationID); err != nil {
+		log.Printf("error while updating location of workspace %v: %v", attach.WorkspaceID, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing workspace location: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+func (q *Query) CreateStorageBin(scope models.ScopeModel, bin models.CreateStorageBinModel) (int, int, error) {
+	if !slices.Contains(scope.WarehouseIDs, bin.WarehouseID) {
+		return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	if bin.LocationID != 0 {
+		var warehouse_branch int
+		if err := tx.QueryRow("SELECT branch_id FROM warehouses WHERE id = $1", bin.WarehouseID).Scan(&warehouse_branch); err != nil {
+			log.Printf("error while getting warehouse %v: %v", bin.WarehouseID, err)
+			return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+		}
+
+		if location_branch, err := getLocationBranch(tx, bin.LocationID); err != nil {
+			if err == sql.ErrNoRows {
+				return http.StatusNotFound, -1, fmt.Errorf("location not found")
+			}
+			log.Printf("error while getting location %v: %v", bin.LocationID, err)
+			return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+		} else if location_branch != warehouse_branch {
+			return http.StatusBadRequest, -1, fmt.Errorf("location belongs to another branch")
+		}
+	}
+
+	var id int
+	if err := tx.QueryRow("INSERT INTO storage_bins(warehouse_id, location_id, name) VALUES($1, NULLIF($2, 0), $3) RETURNING id", bin.WarehouseID, bin.LocationID, bin.Name).Scan(&id); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, -1, fmt.Errorf("a bin named %v already exists in this warehouse", bin.Name)
+		}
+		log.Printf("error while creating storage bin: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing storage bin: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, id, nil
+}
+
+// GetStorageBins lists the bins of a warehouse, departments can view the warehouses of their branch
+func (q *Query) GetStorageBins(scope models.ScopeModel, warehouse_id int) (int, []models.StorageBinModel, error) {
+	var visible bool
+	if err := q.db.QueryRow(`SELECT EXISTS(
+		SELECT 1 FROM warehouses w WHERE w.id = $1
+		AND (w.id = ANY($2) OR w.branch_id IN (SELECT branch_id FROM departments WHERE department_id = ANY($3)))
+	)`, warehouse_id, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs)).Scan(&visible); err != nil {
+		log.Printf("error while checking warehouse %v: %v", warehouse_id, err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
+	} else if !visible {
+		return http.StatusNotFound, nil, fmt.Errorf("no matching data found")
+	}
+
+	query := fmt.Sprintf(`SELECT b.id, b.warehouse_id, b.location_id, %s, b.name, (SELECT COUNT(*) FROM unit_bins ub WHERE ub.bin_id = b.id)
+		FROM storage_bins b WHERE b.warehouse_id = $1 ORDER BY b.name`, locationPath("b.location_id"))
+
+	rows, err := q.db.Query(query, warehouse_id)
+	if err != nil {
+		log.Printf("error while getting storage bins of warehouse %v: %v", warehouse_id, err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	bins := []models.StorageBinModel{}
+	for rows.Next() {
+		var bin models.StorageBinModel
+		if err := rows.Scan(&bin.BinID, &bin.WarehouseID, &bin.LocationID, &bin.LocationPath, &bin.Name, &bin.Units); err != nil {
+			log.Printf("error while scanning storage bins: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		bins = append(bins, bin)
+	}
+
+	return http.StatusOK, bins, nil
+}
+
+// StoreUnits puts units in stock into a bin of their warehouse, moving them out of any other bin
+func (q *Query) StoreUnits(scope models.ScopeModel, store models.StoreUnitsModel) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var warehouse_id int
+	if err := tx.QueryRow("SELECT warehouse_id FROM storage_bins WHERE id = $1 FOR SHARE", store.BinID).Scan(&warehouse_id); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting storage bin %v: %v", store.BinID, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if !slices.Contains(scope.WarehouseIDs, warehouse_id) {
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
+	}
+
+	var prefix, kind string
+	var component_warehouse int
+	if err := tx.QueryRow("SELECT prefix, kind, warehouse_id FROM components WHERE id = $1", store.ComponentID).Scan(&prefix, &kind, &component_warehouse); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("component not found")
+		}
+		log.Printf("error while getting component %v: %v", store.ComponentID, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if kind != "unit" {
+		return http.StatusBadRequest, fmt.Errorf("consumables are not stored by unit")
+	} else if component_warehouse != warehouse_id {
+		return http.StatusBadRequest, fmt.Errorf("component belongs to another warehouse")
+	}
+
+	query1 := fmt.Sprintf(`SELECT a.unit_id IS NOT NULL FROM %[1]s_units u
+		LEFT JOIN %[1]s_units_assigned a ON a.unit_id = u.id
+		WHERE u.id = $1 AND u.component_id = $2`, prefix)
+	query2 := `INSERT INTO unit_bins(component_id, prefix, unit_id, bin_id, stored_by) VALUES($1, $2, $3, $4, $5)
+		ON CONFLICT (component_id, unit_id) DO UPDATE SET bin_id = EXCLUDED.bin_id, stored_by = EXCLUDED.stored_by, stored_at = NOW()`
+
+	for _, unit := range store.UnitIDs {
+		var assigned bool
+		if err := tx.QueryRow(query1, unit, store.ComponentID).Scan(&assigned); err != nil {
+			if err == sql.ErrNoRows {
+				return http.StatusNotFound, fmt.Errorf("unit %v not found", unit)
+			}
+			log.Printf("error while getting unit %v: %v", unit, err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		} else if assigned {
+			return http.StatusConflict, fmt.Errorf("unit %v is assigned to a workspace", unit)
+		}
+
+		if _, err := tx.Exec(query2, store.ComponentID, prefix, unit, store.BinID, scope.UserID); err != nil {
+			log.Printf("error while storing unit %v: %v", unit, err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing stored units: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+// GetUnitLocations lists where the units visible in the scope are, within a location and everything below
+// it when location_id is set
+func (q *Query) GetUnitLocations(scope models.ScopeModel, location_id int, spec queryspec.Spec) (int, []models.UnitLocationModel, models.PageModel, error) {
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, err
+	}
+
+	units := []models.UnitLocationModel{}
+	if len(components) == 0 {
+		return http.StatusOK, units, spec.PageModel(0, ""), nil
+	}
+
+	// units are keyed by component and unit, the row number gives the pagination a single id
+	base := locatedUnitsQuery(components) + fmt.Sprintf(`
+	SELECT ROW_NUMBER() OVER (ORDER BY l.component_id, l.unit_id) AS id, l.component_id, c.name AS component_name, l.prefix, l.unit_id,
+		l.warehouse_id, l.department_id, l.workspace_id, l.bin_id, l.location_id, %s AS location_path
+	FROM located l JOIN components c ON c.id = l.component_id
+	WHERE $3 = 0 OR l.location_id IN %s`, locationPath("l.location_id"), locationSubtree("$3"))
+
+	page, err := q.listPage(spec, base, "id, component_id, component_name, prefix, unit_id, warehouse_id, department_id, workspace_id, bin_id, location_id, location_path", []interface{}{pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs), location_id}, func(rows *sql.Rows, extra ...interface{}) error {
+		var unit models.UnitLocationModel
+		var id int
+		dest := []interface{}{&id, &unit.ComponentID, &unit.ComponentName, &unit.Prefix, &unit.UnitID, &unit.WarehouseID, &unit.DepartmentID, &unit.WorkspaceID, &unit.BinID, &unit.LocationID, &unit.LocationPath}
+		if err := rows.Scan(append(dest, extra...)...); err != nil {
+			return err
+		}
+		units = append(units, unit)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, units, page, nil
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
 )
 
-func (q *Query) CreateSuperAdmin(superAdmin models.SuperAdminModel) (int, error) {
+func (q *Query) CreateSuperAdmin(superAdmin models.SuperAdminModel, invitation models.NewInvitationModel) (int, error) {
 	var sa_id int
 
 	var err error
@@ -40,6 +40,10 @@ func (q *Query) CreateSuperAdmin(superAdmin models.SuperAdminModel) (int, error)
 		return -1, err
 	}
 
+	if err = createInvitation(tx, superAdmin.SuperAdminEmail, invitation); err != nil {
+		return -1, err
+	}
+
 	return sa_id, nil
 
 }
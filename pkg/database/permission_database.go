@@ -0,0 +1,681 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
+)
+
+// Permissions is the catalogue roles are composed of, it is written to the permissions table on startup
+var Permissions = []models.PermissionModel{
+	{Name: "details.view", Description: "View branches, departments, warehouses and their details"},
+	{Name: "super_admins.manage", Description: "Create, delete and reassign super admins"},
+	{Name: "branches.manage", Description: "Create and delete branches and replace their heads"},
+	{Name: "departments.manage", Description: "Create and delete departments and replace their heads"},
+	{Name: "warehouses.manage", Description: "Create and delete warehouses and replace their accounts"},
+	{Name: "workspaces.manage", Description: "Create and delete workspaces"},
+	{Name: "components.manage", Description: "Create, rename, re-prefix, link to the catalogue and delete components"},
+	{Name: "units.manage", Description: "Add and delete units and edit their identifiers, attributes and links"},
+	{Name: "units.maintain", Description: "Update the status and maintenance cost of units"},
+	{Name: "consumables.manage", Description: "Stock in, issue and adjust consumables"},
+	{Name: "requests.raise", Description: "Request new units and withdraw requests"},
+	{Name: "reports.view", Description: "View cost analytics and dashboards"},
+	{Name: "reports.export", Description: "Download spreadsheet reports"},
+	{Name: "units.assign", Description: "Assign units to workspaces"},
+	{Name: "units.store", Description: "Manage storage bins and store units in them"},
+	{Name: "issues.raise", Description: "Raise and withdraw issues on assigned units"},
+	{Name: "issues.resolve", Description: "Update the status of issues"},
+	{Name: "issues.assign", Description: "Assign issues to warehouse staff"},
+	{Name: "catalogue.manage", Description: "Manage catalogue categories and models"},
+	{Name: "attributes.manage", Description: "Manage custom attribute definitions"},
+	{Name: "audits.conduct", Description: "Open, record and close stocktakes"},
+	{Name: "custodians.manage", Description: "Manage custodians and the units in their custody"},
+	{Name: "checklists.templates", Description: "Manage onboarding templates"},
+	{Name: "checklists.manage", Description: "Start and cancel onboarding and offboarding checklists"},
+	{Name: "loans.reserve", Description: "Reserve units and cancel reservations"},
+	{Name: "loans.checkout", Description: "Check loaned units out and back in"},
+	{Name: "locations.manage", Description: "Manage the location tree of a branch"},
+	{Name: "locations.attach", Description: "Place workspaces in locations"},
+	{Name: "hierarchy.move_workspace", Description: "Move workspaces between departments"},
+	{Name: "hierarchy.move_department", Description: "Move departments between branches"},
+	{Name: "records.restore", Description: "Restore deleted records"},
+	{Name: "staff.manage", Description: "Create and deactivate staff accounts and view their activity"},
+	{Name: "roles.manage", Description: "Manage roles and role bindings"},
+}
+
+var (
+	departmentPermissions = []string{"details.view", "reports.view", "reports.export", "workspaces.manage", "issues.raise", "requests.raise",
+		"custodians.manage", "checklists.manage", "loans.reserve", "locations.attach", "audits.conduct", "records.restore", "staff.manage"}
+	warehousePermissions = []string{"details.view", "reports.view", "reports.export", "components.manage", "units.manage", "units.maintain",
+		"units.assign", "units.store", "consumables.manage", "issues.resolve", "issues.assign", "loans.reserve", "loans.checkout", "audits.conduct",
+		"records.restore", "staff.manage"}
+)
+
+// builtinRoles are named after the account types and staff roles, the role of an account is looked up by that
+// name so an organization can override one by creating a role with the same name
+var builtinRoles = []struct {
+	Name        string
+	Description string
+	Permissions []string
+}{
+	{Name: "organization", Description: "Organization account", Permissions: allPermissions()},
+	{Name: "super_admin", Description: "Super admin of a group of branches", Permissions: allPermissions("super_admins.manage")},
+	{Name: "branch_head", Description: "Head of a branch", Permissions: allPermissions("super_admins.manage", "branches.manage", "catalogue.manage", "attributes.manage", "checklists.templates", "hierarchy.move_department", "roles.manage")},
+	{Name: "department_head", Description: "Department account", Permissions: departmentPermissions},
+	{Name: "head", Description: "Department staff with the head's permissions", Permissions: departmentPermissions},
+	{Name: "member", Description: "Department staff", Permissions: []string{"details.view", "reports.view", "issues.raise", "requests.raise", "loans.reserve", "audits.conduct"}},
+	{Name: "warehouses", Description: "Warehouse account", Permissions: warehousePermissions},
+	{Name: "manager", Description: "Warehouse staff with the warehouse account's permissions", Permissions: warehousePermissions},
+	{Name: "technician", Description: "Warehouse staff", Permissions: []string{"details.view", "reports.view", "units.maintain", "units.assign", "units.store", "issues.resolve", "issues.assign", "loans.checkout", "audits.conduct"}},
+}
+
+func allPermissions(except ...string) []string {
+	names := []string{}
+	for _, permission := range Permissions {
+		if !slices.Contains(except, permission.Name) {
+			names = append(names, permission.Name)
+		}
+	}
+	return names
+}
+
+func unknownPermission(names []string) (string, bool) {
+	for _, name := range names {
+		if !slices.ContainsFunc(Permissions, func(p models.PermissionModel) bool { return p.Name == name }) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// seedPermissions writes the permission catalogue and the built-in roles, built-in roles are read only so
+// their permissions are reset to the ones defined here
+func seedPermissions(tx *sql.Tx) error {
+	names := []string{}
+	for _, permission := range Permissions {
+		if _, err := tx.Exec(`INSERT INTO permissions(name, description) VALUES($1, $2)
+			ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description`, permission.Name, permission.Description); err != nil {
+			return err
+		}
+		names = append(names, permission.Name)
+	}
+
+	if _, err := tx.Exec("DELETE FROM permissions WHERE name <> ALL($1)", pq.Array(names)); err != nil {
+		return err
+	}
+
+	for _, role := range builtinRoles {
+		if _, err := tx.Exec("INSERT INTO roles(name, description, built_in) VALUES($1, $2, TRUE) ON CONFLICT DO NOTHING", role.Name, role.Description); err != nil {
+			return err
+		}
+
+		var role_id int
+		if err := tx.QueryRow("SELECT id FROM roles WHERE org_id IS NULL AND name = $1", role.Name).Scan(&role_id); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM role_permissions WHERE role_id = $1", role_id); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("INSERT INTO role_permissions(role_id, permission) SELECT $1, unnest($2::TEXT[])", role_id, pq.Array(role.Permissions)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// accountRole is the name of the role an account holds without any binding: its staff role or its account type
+func accountRole(scope models.ScopeModel) string {
+	if scope.StaffRole != "" {
+		return scope.StaffRole
+	}
+	return scope.Role
+}
+
+// accountRoleQuery selects the role the organization uses for an account, its own role of that name or the built-in one
+const accountRoleQuery = `SELECT id FROM roles WHERE lower(name) = lower($1) AND (org_id = $2 OR org_id IS NULL) ORDER BY org_id NULLS LAST LIMIT 1`
+
+// AuthorizeScope narrows the scope to the nodes where the user holds the permission, either everywhere in their own
+// scope through the role of their account, on the nodes of their role bindings and everything below them, or on the
+// nodes delegated to them by a head whose role grants it. Delegations that widen the scope are listed in OnBehalfOf.
+// ok is false when the permission is held nowhere
+func (q *Query) AuthorizeScope(scope models.ScopeModel, permission string) (models.ScopeModel, bool, error) {
+	var granted bool
+	if err := q.db.QueryRow("SELECT EXISTS(SELECT 1 FROM role_permissions WHERE role_id = ("+accountRoleQuery+") AND permission = $3)",
+		accountRole(scope), scope.OrgID, permission).Scan(&granted); err != nil {
+		log.Printf("error while checking account permission: %v", err)
+		return scope, false, fmt.Errorf("database error")
+	}
+
+	authorized := scope
+	authorized.OnBehalfOf = []int{}
+	if !granted {
+		authorized.BranchIDs, authorized.DepartmentIDs, authorized.WarehouseIDs = []int{}, []int{}, []int{}
+	} else if len(scope.Delegations) > 0 {
+		// the role of the account only covers its own scope, not what was delegated to it
+		own, err := q.GetUserScope(scope.Role, scope.UserID)
+		if err != nil {
+			return scope, false, err
+		}
+		authorized.BranchIDs, authorized.DepartmentIDs, authorized.WarehouseIDs = own.BranchIDs, own.DepartmentIDs, own.WarehouseIDs
+	}
+
+	rows, err := q.db.Query(`SELECT rb.node_type, rb.node_id FROM role_bindings rb
+		JOIN role_permissions rp ON rp.role_id = rb.role_id
+		WHERE rb.user_email = $1 AND rb.org_id = $2 AND rp.permission = $3`, scope.UserEmail, scope.OrgID, permission)
+	if err != nil {
+		log.Printf("error while getting role bindings: %v", err)
+		return scope, false, fmt.Errorf("database error")
+	}
+	defer rows.Close()
+
+	nodes := map[string][]int{}
+	for rows.Next() {
+		var node_type string
+		var node_id int
+		if err := rows.Scan(&node_type, &node_id); err != nil {
+			log.Printf("error while scanning role bindings: %v", err)
+			return scope, false, fmt.Errorf("database error")
+		}
+		nodes[node_type] = append(nodes[node_type], node_id)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("error while getting role bindings: %v", err)
+		return scope, false, fmt.Errorf("database error")
+	}
+
+	if len(nodes) > 0 {
+		bound, err := q.expandNodes(scope.OrgID, nodes)
+		if err != nil {
+			return scope, false, err
+		}
+
+		authorized.BranchIDs = mergeIDs(authorized.BranchIDs, bound.BranchIDs)
+		authorized.DepartmentIDs = mergeIDs(authorized.DepartmentIDs, bound.DepartmentIDs)
+		authorized.WarehouseIDs = mergeIDs(authorized.WarehouseIDs, bound.WarehouseIDs)
+		granted = true
+	}
+
+	for _, delegation := range scope.Delegations {
+		var delegated bool
+		if err := q.db.QueryRow("SELECT EXISTS(SELECT 1 FROM role_permissions WHERE role_id = ("+accountRoleQuery+") AND permission = $3)",
+			delegation.DelegatorRole, scope.OrgID, permission).Scan(&delegated); err != nil {
+			log.Printf("error while checking delegated permission: %v", err)
+			return scope, false, fmt.Errorf("database error")
+		} else if !delegated {
+			continue
+		}
+
+		node, err := q.expandNodes(scope.OrgID, map[string][]int{delegation.NodeType: {delegation.NodeID}})
+		if err != nil {
+			return scope, false, err
+		}
+
+		before := len(authorized.BranchIDs) + len(authorized.DepartmentIDs) + len(authorized.WarehouseIDs)
+		authorized.BranchIDs = mergeIDs(authorized.BranchIDs, node.BranchIDs)
+		authorized.DepartmentIDs = mergeIDs(authorized.DepartmentIDs, node.DepartmentIDs)
+		authorized.WarehouseIDs = mergeIDs(authorized.WarehouseIDs, node.WarehouseIDs)
+		if len(authorized.BranchIDs)+len(authorized.DepartmentIDs)+len(authorized.WarehouseIDs) > before {
+			authorized.OnBehalfOf = append(authorized.OnBehalfOf, delegation.DelegationID)
+		}
+		granted = true
+	}
+
+	return authorized, granted, nil
+}
+
+// expandNodes resolves organization, branch, department and warehouse nodes of the organization into the ids
+// below them
+func (q *Query) expandNodes(org_id int, nodes map[string][]int) (models.ScopeModel, error) {
+	expanded := models.ScopeModel{}
+
+	var err error
+	if expanded.BranchIDs, err = q.collectIDs("SELECT branch_id FROM branches WHERE org_id = $1 AND (org_id = ANY($2) OR branch_id = ANY($3))",
+		org_id, pq.Array(nodes["organization"]), pq.Array(nodes["branch"])); err != nil {
+		log.Printf("error while resolving bound branches: %v", err)
+		return expanded, fmt.Errorf("database error")
+	}
+	if err := q.expandBranchScope(&expanded); err != nil {
+		return expanded, err
+	}
+
+	departments, err := q.collectIDs(`SELECT d.department_id FROM departments d JOIN branches b ON b.branch_id = d.branch_id
+		WHERE b.org_id = $1 AND d.department_id = ANY($2)`, org_id, pq.Array(nodes["department"]))
+	if err != nil {
+		log.Printf("error while resolving bound departments: %v", err)
+		return expanded, fmt.Errorf("database error")
+	}
+
+	warehouses, err := q.collectIDs(`SELECT w.id FROM warehouses w JOIN branches b ON b.branch_id = w.branch_id
+		WHERE b.org_id = $1 AND w.id = ANY($2)`, org_id, pq.Array(nodes["warehouse"]))
+	if err != nil {
+		log.Printf("error while resolving bound warehouses: %v", err)
+		return expanded, fmt.Errorf("database error")
+	}
+
+	expanded.DepartmentIDs = mergeIDs(expanded.DepartmentIDs, departments)
+	expanded.WarehouseIDs = mergeIDs(expanded.WarehouseIDs, warehouses)
+
+	return expanded, nil
+}
+
+func mergeIDs(lists ...[]int) []int {
+	merged := []int{}
+	for _, list := range lists {
+		for _, id := range list {
+			if !slices.Contains(merged, id) {
+				merged = append(merged, id)
+			}
+		}
+	}
+	return merged
+}
+
+func (q *Query) GetRoles(scope models.ScopeModel) (int, []models.RoleModel, error) {
+	rows, err := q.db.Query(`SELECT r.id, r.name, r.description, r.built_in, r.created_at,
+			ARRAY(SELECT rp.permission FROM role_permissions rp WHERE rp.role_id = r.id ORDER BY rp.permission)
+		FROM roles r WHERE r.org_id IS NULL OR r.org_id = $1 ORDER BY r.built_in DESC, r.name`, scope.OrgID)
+	if err != nil {
+		log.Printf("error while getting roles: %v", err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	roles := []models.RoleModel{}
+	for rows.Next() {
+		var role models.RoleModel
+		if err := rows.Scan(&role.RoleID, &role.Name, &role.Description, &role.BuiltIn, &role.CreatedAt, pq.Array(&role.Permissions)); err != nil {
+			log.Printf("error while scanning roles: %v", err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		roles = append(roles, role)
+	}
+
+	return http.StatusOK, roles, rows.Err()
+}
+
+// isBuiltinRole reports whether name is the name of a built-in role, roles of that name decide the permissions
+// of every account of the organization holding it
+func isBuiltinRole(name string) bool {
+	for _, role := range builtinRoles {
+		if strings.EqualFold(role.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// canOverride reports whether the scope may create, change or delete an organization role named name, only the
+// organization account overrides built-in roles and the organization role itself is never overridden so the
+// organization account cannot lose its own permissions
+func canOverride(scope models.ScopeModel, name string) bool {
+	if !isBuiltinRole(name) {
+		return true
+	}
+	return accountRole(scope) == "organization" && !strings.EqualFold(name, "organization")
+}
+
+// roleNode is a node a role is bound on
+type roleNode struct {
+	NodeType string
+	NodeID   int
+}
+
+// ungrantedPermission returns a permission the scope does not hold on every one of the nodes, nobody grants more
+// than they hold themselves. Without nodes the permission only has to be held somewhere in the scope
+func (q *Query) ungrantedPermission(scope models.ScopeModel, permissions []string, nodes []roleNode) (string, bool, error) {
+	for _, permission := range permissions {
+		authorized, ok, err := q.AuthorizeScope(scope, permission)
+		if err != nil {
+			return "", false, err
+		} else if !ok {
+			return permission, true, nil
+		}
+
+		for _, node := range nodes {
+			if !nodeInScope(authorized, node.NodeType, node.NodeID) {
+				return permission, true, nil
+			}
+		}
+	}
+
+	return "", false, nil
+}
+
+// checkRoleScope locks an organization role and makes sure every node it is bound on lies within the scope,
+// a holder of roles.manage on one branch cannot change what the role grants elsewhere. It returns those nodes
+func checkRoleScope(tx *sql.Tx, scope models.ScopeModel, role_id int) ([]roleNode, int, error) {
+	var name string
+	var built_in bool
+	if err := tx.QueryRow("SELECT name, built_in FROM roles WHERE id = $1 AND (org_id = $2 OR org_id IS NULL) FOR UPDATE", role_id, scope.OrgID).Scan(&name, &built_in); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting role %v: %v", role_id, err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if built_in {
+		return nil, http.StatusBadRequest, fmt.Errorf("built-in roles cannot be changed, create a role with the same name instead")
+	} else if !canOverride(scope, name) {
+		return nil, http.StatusForbidden, fmt.Errorf("only the organization account can change the %v role", name)
+	}
+
+	rows, err := tx.Query("SELECT node_type, node_id FROM role_bindings WHERE role_id = $1", role_id)
+	if err != nil {
+		log.Printf("error while getting bindings of role %v: %v", role_id, err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer rows.Close()
+
+	nodes := []roleNode{}
+	for rows.Next() {
+		var node roleNode
+		if err := rows.Scan(&node.NodeType, &node.NodeID); err != nil {
+			log.Printf("error while scanning bindings of role %v: %v", role_id, err)
+			return nil, http.StatusInternalServerError, fmt.Errorf("database error")
+		}
+		if !nodeInScope(scope, node.NodeType, node.NodeID) {
+			return nil, http.StatusForbidden, fmt.Errorf("role is bound outside of your scope")
+		}
+		nodes = append(nodes, node)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("error while getting bindings of role %v: %v", role_id, err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return nodes, http.StatusOK, nil
+}
+
+// CreateRole adds a role to the organization, a role named after an account type or staff role replaces the
+// built-in one for the organization's accounts and only the organization account may create one
+func (q *Query) CreateRole(scope models.ScopeModel, role models.CreateRoleModel) (int, int, error) {
+	if name, ok := unknownPermission(role.Permissions); ok {
+		return http.StatusBadRequest, -1, fmt.Errorf("unknown permission %v", name)
+	} else if !canOverride(scope, role.Name) {
+		return http.StatusForbidden, -1, fmt.Errorf("%v is a built-in role, only the organization account can override it", role.Name)
+	}
+
+	if name, ok, err := q.ungrantedPermission(scope, role.Permissions, nil); err != nil {
+		return http.StatusInternalServerError, -1, err
+	} else if ok {
+		return http.StatusForbidden, -1, fmt.Errorf("you cannot grant %v, you do not hold it", name)
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var role_id int
+	if err := tx.QueryRow(`INSERT INTO roles(org_id, name, description, created_by_role, created_by) VALUES($1, $2, $3, $4, $5) RETURNING id`,
+		scope.OrgID, role.Name, role.Description, scope.Role, scope.UserID).Scan(&role_id); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, -1, fmt.Errorf("role %v already exists", role.Name)
+		}
+		log.Printf("error while creating role: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	if _, err := tx.Exec("INSERT INTO role_permissions(role_id, permission) SELECT DISTINCT $1, unnest($2::TEXT[])", role_id, pq.Array(role.Permissions)); err != nil {
+		log.Printf("error while adding role permissions: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing role: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, role_id, nil
+}
+
+func (q *Query) UpdateRolePermissions(scope models.ScopeModel, update models.UpdateRolePermissionsModel) (int, error) {
+	if name, ok := unknownPermission(update.Permissions); ok {
+		return http.StatusBadRequest, fmt.Errorf("unknown permission %v", name)
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	nodes, status, err := checkRoleScope(tx, scope, update.RoleID)
+	if err != nil {
+		return status, err
+	}
+
+	if name, ok, err := q.ungrantedPermission(scope, update.Permissions, nodes); err != nil {
+		return http.StatusInternalServerError, err
+	} else if ok {
+		return http.StatusForbidden, fmt.Errorf("you cannot grant %v where the role is bound, you do not hold it there", name)
+	}
+
+	if _, err := tx.Exec("DELETE FROM role_permissions WHERE role_id = $1", update.RoleID); err != nil {
+		log.Printf("error while clearing role permissions: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if _, err := tx.Exec("INSERT INTO role_permissions(role_id, permission) SELECT DISTINCT $1, unnest($2::TEXT[])", update.RoleID, pq.Array(update.Permissions)); err != nil {
+		log.Printf("error while adding role permissions: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing role: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+// DeleteRole removes an organization role with its bindings, accounts fall back to the built-in role of their name
+func (q *Query) DeleteRole(scope models.ScopeModel, role_id int) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	if _, status, err := checkRoleScope(tx, scope, role_id); err != nil {
+		return status, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM roles WHERE id = $1 AND org_id = $2", role_id, scope.OrgID); err != nil {
+		log.Printf("error while deleting role %v: %v", role_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing role: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+// nodeInScope reports whether a binding node lies within the scope, only the organization account covers the
+// organization node
+func nodeInScope(scope models.ScopeModel, node_type string, node_id int) bool {
+	switch node_type {
+	case "organization":
+		return scope.Role == "organization" && node_id == scope.OrgID
+	case "branch":
+		return slices.Contains(scope.BranchIDs, node_id)
+	case "department":
+		return slices.Contains(scope.DepartmentIDs, node_id)
+	case "warehouse":
+		return slices.Contains(scope.WarehouseIDs, node_id)
+	}
+	return false
+}
+
+// BindRole grants a role on a node, target_org is the organization of the bound user. The caller has to hold every
+// permission of the role on that node
+func (q *Query) BindRole(scope models.ScopeModel, bind models.BindRoleModel, target_org int) (int, int, error) {
+	if target_org != scope.OrgID || !nodeInScope(scope, bind.NodeType, bind.NodeID) {
+		return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	// the share lock keeps the permissions of the role from changing until the binding is committed
+	var permissions []string
+	if err := tx.QueryRow(`SELECT ARRAY(SELECT rp.permission FROM role_permissions rp WHERE rp.role_id = r.id)
+		FROM roles r WHERE r.id = $1 AND (r.org_id = $2 OR r.org_id IS NULL) FOR SHARE`, bind.RoleID, scope.OrgID).Scan(pq.Array(&permissions)); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting role %v: %v", bind.RoleID, err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	if name, ok, err := q.ungrantedPermission(scope, permissions, []roleNode{{NodeType: bind.NodeType, NodeID: bind.NodeID}}); err != nil {
+		return http.StatusInternalServerError, -1, err
+	} else if ok {
+		return http.StatusForbidden, -1, fmt.Errorf("you cannot bind a role granting %v here, you do not hold it", name)
+	}
+
+	var binding_id int
+	if err := tx.QueryRow(`INSERT INTO role_bindings(org_id, role_id, user_email, node_type, node_id, created_by_role, created_by)
+		VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		scope.OrgID, bind.RoleID, bind.UserEmail, bind.NodeType, bind.NodeID, scope.Role, scope.UserID).Scan(&binding_id); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, -1, fmt.Errorf("role is already bound to this user here")
+		}
+		log.Printf("error while binding role: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing role binding: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, binding_id, nil
+}
+
+func (q *Query) UnbindRole(scope models.ScopeModel, binding_id int) (int, error) {
+	var node_type string
+	var node_id int
+	if err := q.db.QueryRow("SELECT node_type, node_id FROM role_bindings WHERE id = $1 AND org_id = $2", binding_id, scope.OrgID).Scan(&node_type, &node_id); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting role binding %v: %v", binding_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if !nodeInScope(scope, node_type, node_id) {
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
+	}
+
+	if _, err := q.db.Exec("DELETE FROM role_bindings WHERE id = $1", binding_id); err != nil {
+		log.Printf("error while deleting role binding %v: %v", binding_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+var RoleBindingsResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":         {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"role_id":    {Column: "role_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"role_name":  {Column: "role_name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"user_email": {Column: "user_email", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"node_type":  {Column: "node_type", Type: queryspec.Enum, Values: []string{"organization", "branch", "department", "warehouse"}, Filterable: true, Sortable: true},
+		"node_id":    {Column: "node_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"created_at": {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+const roleBindingColumns = "id, role_id, role_name, user_email, node_type, node_id, created_by_role, created_by, created_at"
+
+const roleBindingsQuery = `SELECT rb.id, rb.role_id, r.name AS role_name, rb.user_email, rb.node_type, rb.node_id, rb.created_by_role, rb.created_by, rb.created_at
+	FROM role_bindings rb JOIN roles r ON r.id = rb.role_id`
+
+func scanRoleBinding(rows *sql.Rows, binding *models.RoleBindingModel, extra ...interface{}) error {
+	dest := []interface{}{&binding.BindingID, &binding.RoleID, &binding.RoleName, &binding.UserEmail, &binding.NodeType, &binding.NodeID,
+		&binding.CreatedByRole, &binding.CreatedBy, &binding.CreatedAt}
+	return rows.Scan(append(dest, extra...)...)
+}
+
+func (q *Query) GetRoleBindings(scope models.ScopeModel, spec queryspec.Spec) (int, []models.RoleBindingModel, models.PageModel, error) {
+	base := roleBindingsQuery + ` WHERE rb.org_id = $1 AND (
+			(rb.node_type = 'organization' AND $2 = 'organization')
+			OR (rb.node_type = 'branch' AND rb.node_id = ANY($3))
+			OR (rb.node_type = 'department' AND rb.node_id = ANY($4))
+			OR (rb.node_type = 'warehouse' AND rb.node_id = ANY($5))
+		)`
+
+	bindings := []models.RoleBindingModel{}
+
+	page, err := q.listPage(spec, base, roleBindingColumns,
+		[]interface{}{scope.OrgID, scope.Role, pq.Array(scope.BranchIDs), pq.Array(scope.DepartmentIDs), pq.Array(scope.WarehouseIDs)}, func(rows *sql.Rows, extra ...interface{}) error {
+			var binding models.RoleBindingModel
+			if err := scanRoleBinding(rows, &binding, extra...); err != nil {
+				return err
+			}
+			bindings = append(bindings, binding)
+			return nil
+		})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, bindings, page, nil
+}
+
+func (q *Query) GetEffectivePermissions(scope models.ScopeModel) (int, models.EffectivePermissionsModel, error) {
+	effective := models.EffectivePermissionsModel{Role: accountRole(scope), Permissions: []string{}, Bindings: []models.RoleBindingModel{},
+		Delegations: scope.Delegations}
+	if effective.Delegations == nil {
+		effective.Delegations = []models.DelegatedScopeModel{}
+	}
+
+	if err := q.db.QueryRow("SELECT ARRAY(SELECT permission FROM role_permissions WHERE role_id = ("+accountRoleQuery+") ORDER BY permission)",
+		effective.Role, scope.OrgID).Scan(pq.Array(&effective.Permissions)); err != nil {
+		log.Printf("error while getting account permissions: %v", err)
+		return http.StatusInternalServerError, models.EffectivePermissionsModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	rows, err := q.db.Query(roleBindingsQuery+" WHERE rb.user_email = $1 AND rb.org_id = $2 ORDER BY rb.id", scope.UserEmail, scope.OrgID)
+	if err != nil {
+		log.Printf("error while getting role bindings: %v", err)
+		return http.StatusInternalServerError, models.EffectivePermissionsModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var binding models.RoleBindingModel
+		if err := scanRoleBinding(rows, &binding); err != nil {
+			log.Printf("error while scanning role bindings: %v", err)
+			return http.StatusInternalServerError, models.EffectivePermissionsModel{}, fmt.Errorf("error occured while retrieving data")
+		}
+		effective.Bindings = append(effective.Bindings, binding)
+	}
+
+	return http.StatusOK, effective, rows.Err()
+}
@@ -215,7 +215,7 @@ func (db *Query) InitialiseDBqueries() error {
 		`CREATE TABLE IF NOT EXISTS components (
 			id SERIAL PRIMARY KEY,
 			name VARCHAR(30) NOT NULL,
-			prefix VARCHAR(3) NOT NULL UNIQUE,
+			prefix VARCHAR(8) NOT NULL UNIQUE,
 			warehouse_id INTEGER NOT NULL,
 			a_at TIMESTAMPTZ DEFAULT NOW(),
 			CONSTRAINT fk_component_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON UPDATE CASCADE ON DELETE CASCADE
@@ -224,7 +224,7 @@ func (db *Query) InitialiseDBqueries() error {
 			id SERIAL PRIMARY KEY,
 			component_id INTEGER NOT NULL,
 			component_name VARCHAR(30) NOT NULL,
-			prefix VARCHAR(3) NOT NULL,
+			prefix VARCHAR(8) NOT NULL,
 			deleted_by INTEGER NOT NULL,
 			deleted_at TIMESTAMPTZ DEFAULT NOW()
 		)`,
@@ -234,7 +234,7 @@ func (db *Query) InitialiseDBqueries() error {
 			warehouse_id INTEGER NOT NULL,
 			workspace_id INTEGER NOT NULL,
 			unit_id INTEGER NOT NULL,
-			unit_prefix VARCHAR(3) NOT NULL,
+			unit_prefix VARCHAR(8) NOT NULL,
 			issue VARCHAR(100) NOT NULL,
 			created_at TIMESTAMPTZ DEFAULT NOW(),
 			status issue_status DEFAULT 'raised',
@@ -258,7 +258,7 @@ func (db *Query) InitialiseDBqueries() error {
 			department_id INTEGER NOT NULL,
 			workspace_id INTEGER NOT NULL,
 			unit_id INTEGER NOT NULL,
-			unit_prefix VARCHAR(3) NOT NULL,
+			unit_prefix VARCHAR(8) NOT NULL,
 			issue VARCHAR(100) NOT NULL,
 			created_at TIMESTAMPTZ DEFAULT NOW(),
 			status issue_status DEFAULT 'raised',
@@ -277,7 +277,7 @@ func (db *Query) InitialiseDBqueries() error {
 		`CREATE TABLE IF NOT EXISTS deleted_units (
 			id SERIAL PRIMARY KEY,
 			unit_id INTEGER NOT NULL,
-			unit_prefix VARCHAR(3) NOT NULL,
+			unit_prefix VARCHAR(8) NOT NULL,
 			component_id INTEGER NOT NULL,
 			warehouse_id INTEGER NOT NULL,
 			deleted_by INTEGER NOT NULL,
@@ -290,7 +290,7 @@ func (db *Query) InitialiseDBqueries() error {
 			warehouse_id INTEGER NOT NULL,
 			component_id INTEGER NOT NULL,
 			number_of_units INTEGER NOT NULL,
-			prefix VARCHAR(3) NOT NULL,
+			prefix VARCHAR(8) NOT NULL,
 			created_by INTEGER NOT NULL,
 			created_at TIMESTAMPTZ DEFAULT NOW(),
 			status request_status DEFAULT 'raised',
@@ -301,20 +301,505 @@ func (db *Query) InitialiseDBqueries() error {
 		)`,
 	}
 
-	// CREATE EXTENSION IF NOT EXISTS pg_cron;
-
-	// SELECT cron.schedule(
-	// 	'cleanup_deleted_units',
-	// 	'0 0 * * *',
-	// 	$$
-	// 	DELETE FROM deleted_units WHERE deleted_at < NOW() - INTERVAL '30 days';
-	// 	$$
-	// );
-
 	queries = append(queries, "CREATE OR REPLACE PROCEDURE delete_department(dep_id INTEGER, deleter_id INTEGER) LANGUAGE plpgsql AS $$ DECLARE r_workspace RECORD; r_component RECORD; r_dep_head RECORD; has_assignments BOOLEAN; dyn_sql TEXT; BEGIN INSERT INTO deleted_departments(department_id, branch_id, deleted_by) SELECT department_id, branch_id, deleter_id FROM departments WHERE department_id = dep_id; INSERT INTO deleted_department_head(department_id, department_head_id, email, deleted_by) SELECT department_id, id, email, deleter_id FROM department_head WHERE department_id = dep_id; FOR r_workspace IN SELECT * FROM workspaces WHERE department_id = dep_id LOOP INSERT INTO deleted_workpaces(workspace_id, department_id, deleted_by) VALUES (r_workspace.id, r_workspace.department_id, deleter_id); FOR r_component IN SELECT DISTINCT c.id, c.name, c.prefix FROM components c WHERE EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = lower(c.prefix || '_units_assigned')) LOOP dyn_sql := format('SELECT EXISTS (SELECT 1 FROM %I_units_assigned WHERE department_id = $1 AND workspace_id = $2)', r_component.prefix); EXECUTE dyn_sql INTO has_assignments USING dep_id, r_workspace.id; IF has_assignments THEN INSERT INTO deleted_components(component_id, component_name, prefix, deleted_by) VALUES (r_component.id, r_component.name, r_component.prefix, deleter_id); EXECUTE format('INSERT INTO deleted_units_assigned(unit_id, department_id, workspace_id, assigned_at, deleted_by) SELECT id, department_id, workspace_id, assigned_at, $1 FROM %I_units_assigned WHERE department_id = $2 AND workspace_id = $3', r_component.prefix) USING deleter_id, dep_id, r_workspace.id; EXECUTE format('UPDATE %I_units SET status = ''not_assigned'' WHERE id IN (SELECT unit_id FROM deleted_units_assigned WHERE department_id = $1 AND workspace_id = $2 AND deleted_by = $3)', r_component.prefix) USING dep_id, r_workspace.id, deleter_id; END IF; END LOOP; END LOOP; DELETE FROM workspaces WHERE department_id = dep_id; FOR r_dep_head IN SELECT email FROM department_head WHERE department_id = dep_id LOOP INSERT INTO deleted_users(user_email, user_level, ever_logged_in, latest_token, created_at, deleted_by) SELECT u.user_email, u.user_level, u.ever_logged_in, u.latest_token, u.created_at, deleter_id FROM users u WHERE u.user_email = r_dep_head.email; DELETE FROM users WHERE user_email = r_dep_head.email; END LOOP; DELETE FROM department_head WHERE department_id = dep_id; DELETE FROM departments WHERE department_id = dep_id; END; $$;",
 		"CREATE OR REPLACE PROCEDURE delete_component(comp_id INTEGER, deleter_id INTEGER) LANGUAGE plpgsql AS $$ DECLARE r_comp_details RECORD; units_table_name TEXT; units_assigned_table_name TEXT; BEGIN SELECT name, prefix INTO r_comp_details FROM components WHERE id = comp_id; IF NOT FOUND THEN RAISE EXCEPTION 'Component with ID % not found.', comp_id; END IF; units_table_name := lower(r_comp_details.prefix || '_units'); units_assigned_table_name := lower(r_comp_details.prefix || '_units_assigned'); INSERT INTO deleted_components(component_id, component_name, prefix, deleted_by) VALUES (comp_id, r_comp_details.name, r_comp_details.prefix, deleter_id); IF EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = units_assigned_table_name) THEN EXECUTE format('INSERT INTO deleted_units_assigned(unit_id, department_id, workspace_id, assigned_at, deleted_by) SELECT sua.id, sua.department_id, sua.workspace_id, sua.assigned_at, $1 FROM %I sua JOIN %I su ON sua.id = su.id WHERE su.component_id = $2', units_assigned_table_name, units_table_name) USING deleter_id, comp_id; END IF; IF EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = units_table_name) THEN EXECUTE format('INSERT INTO deleted_units(unit_id, unit_prefix, component_id, warehouse_id, deleted_by) SELECT id, $1, component_id, warehouse_id, $2 FROM %I WHERE component_id = $3', units_table_name) USING r_comp_details.prefix, deleter_id, comp_id; END IF; IF EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = units_table_name) THEN EXECUTE format('DELETE FROM %I WHERE component_id = $1', units_table_name) USING comp_id; END IF; DELETE FROM components WHERE id = comp_id; END $$;",
 		"CREATE OR REPLACE PROCEDURE delete_warehouse(wh_id INTEGER, deleter_id INTEGER) LANGUAGE plpgsql AS $$ DECLARE r_warehouse_head_details RECORD; r_component RECORD; BEGIN SELECT id, email INTO r_warehouse_head_details FROM warehouses WHERE id = wh_id; IF NOT FOUND THEN RAISE EXCEPTION 'Warehouse head with ID % not found.', wh_id; END IF; INSERT INTO deleted_warehouse_heads(warehouse_id, email, deleted_by) VALUES (r_warehouse_head_details.id, r_warehouse_head_details.email, deleter_id); INSERT INTO deleted_users(user_email, user_level, ever_logged_in, latest_token, created_at, deleted_by) SELECT u.user_email, u.user_level, u.ever_logged_in, u.latest_token, u.created_at, deleter_id FROM users u WHERE u.user_email = r_warehouse_head_details.email; DELETE FROM users WHERE user_email = r_warehouse_head_details.email; FOR r_component IN SELECT id FROM components WHERE warehouse_id = wh_id LOOP CALL delete_component(r_component.id, deleter_id); END LOOP; DELETE FROM warehouses WHERE id = wh_id; END $$;",
 		"CREATE OR REPLACE PROCEDURE delete_branch(br_id INTEGER, deleter_id INTEGER) LANGUAGE plpgsql AS $$ DECLARE r_branch_head RECORD; r_department RECORD; r_warehouse RECORD; BEGIN INSERT INTO deleted_branches(branch_id, super_admin_id, deleted_by) SELECT branch_id, super_admin_id, deleter_id FROM branches WHERE branch_id = br_id; FOR r_branch_head IN SELECT id, email FROM branch_head WHERE branch_id = br_id LOOP INSERT INTO deleted_branch_head(branch_id, branch_head_id, email, deleted_by) VALUES (br_id, r_branch_head.id, r_branch_head.email, deleter_id); INSERT INTO deleted_users(user_email, user_level, ever_logged_in, latest_token, created_at, deleted_by) SELECT u.user_email, u.user_level, u.ever_logged_in, u.latest_token, u.created_at, deleter_id FROM users u WHERE u.user_email = r_branch_head.email; DELETE FROM users WHERE user_email = r_branch_head.email; END LOOP; FOR r_department IN SELECT department_id FROM departments WHERE branch_id = br_id LOOP CALL delete_department(r_department.department_id, deleter_id); END LOOP; FOR r_warehouse IN SELECT id FROM warehouses WHERE branch_id = br_id LOOP CALL delete_warehouse(r_warehouse.id, deleter_id); END LOOP; DELETE FROM branch_head WHERE branch_id = br_id; DELETE FROM departments WHERE branch_id = br_id; DELETE FROM warehouses WHERE branch_id = br_id; DELETE FROM branches WHERE branch_id = br_id; END $$;",
+		"DO $$ DECLARE r_component RECORD; BEGIN FOR r_component IN SELECT prefix FROM components WHERE EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = lower(prefix || '_units')) LOOP EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ DEFAULT NOW()', lower(r_component.prefix || '_units')); EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS serial_number VARCHAR(100), ADD COLUMN IF NOT EXISTS asset_tag VARCHAR(100)', lower(r_component.prefix || '_units')); EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I (lower(serial_number) text_pattern_ops)', lower('idx_' || r_component.prefix || '_units_serial_number'), lower(r_component.prefix || '_units')); EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I (lower(asset_tag) text_pattern_ops)', lower('idx_' || r_component.prefix || '_units_asset_tag'), lower(r_component.prefix || '_units')); END LOOP; END $$;",
+		"CREATE INDEX IF NOT EXISTS idx_branches_search ON branches USING GIN (to_tsvector('simple', branch_name || ' ' || branch_location))",
+		"CREATE INDEX IF NOT EXISTS idx_departments_search ON departments USING GIN (to_tsvector('simple', department_name))",
+		"CREATE INDEX IF NOT EXISTS idx_workspaces_search ON workspaces USING GIN (to_tsvector('simple', workspace_name))",
+		"ALTER TABLE components ALTER COLUMN prefix TYPE VARCHAR(8)",
+		"ALTER TABLE deleted_components ALTER COLUMN prefix TYPE VARCHAR(8)",
+		"ALTER TABLE issues ALTER COLUMN unit_prefix TYPE VARCHAR(8)",
+		"ALTER TABLE deleted_issues ALTER COLUMN unit_prefix TYPE VARCHAR(8)",
+		"ALTER TABLE deleted_units ALTER COLUMN unit_prefix TYPE VARCHAR(8)",
+		"ALTER TABLE requests ALTER COLUMN prefix TYPE VARCHAR(8)",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_components_prefix_lower ON components (lower(prefix))",
+		"CREATE INDEX IF NOT EXISTS idx_components_search ON components USING GIN (to_tsvector('simple', name || ' ' || prefix))",
+		"CREATE INDEX IF NOT EXISTS idx_issues_search ON issues USING GIN (to_tsvector('simple', issue))",
+		"CREATE INDEX IF NOT EXISTS idx_super_admin_search ON super_admin USING GIN (to_tsvector('simple', name || ' ' || email))",
+		"CREATE INDEX IF NOT EXISTS idx_branch_head_search ON branch_head USING GIN (to_tsvector('simple', name || ' ' || email))",
+		"CREATE INDEX IF NOT EXISTS idx_department_head_search ON department_head USING GIN (to_tsvector('simple', name || ' ' || email))",
+		"CREATE INDEX IF NOT EXISTS idx_warehouses_search ON warehouses USING GIN (to_tsvector('simple', name || ' ' || email))",
+		`CREATE TABLE IF NOT EXISTS archived_rows (
+			id BIGSERIAL PRIMARY KEY,
+			table_name VARCHAR(63) NOT NULL,
+			data JSONB NOT NULL,
+			batch BIGINT NOT NULL DEFAULT txid_current(),
+			archived_at TIMESTAMPTZ DEFAULT NOW(),
+			restored_at TIMESTAMPTZ
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_archived_rows_table_name ON archived_rows (table_name, archived_at)",
+		"CREATE INDEX IF NOT EXISTS idx_archived_rows_batch ON archived_rows (batch)",
+		`CREATE TABLE IF NOT EXISTS job_runs (
+			id SERIAL PRIMARY KEY,
+			job VARCHAR(50) NOT NULL,
+			trigger VARCHAR(20) NOT NULL,
+			started_at TIMESTAMPTZ NOT NULL,
+			finished_at TIMESTAMPTZ NOT NULL,
+			purged JSONB NOT NULL DEFAULT '{}',
+			error TEXT
+		)`,
+		"CREATE OR REPLACE FUNCTION archive_deleted_row() RETURNS TRIGGER LANGUAGE plpgsql AS $$ BEGIN INSERT INTO archived_rows(table_name, data) VALUES (TG_TABLE_NAME, to_jsonb(OLD)); RETURN OLD; END $$;",
+		"DO $$ DECLARE t TEXT; BEGIN FOREACH t IN ARRAY ARRAY['users', 'departments', 'department_head', 'workspaces', 'components', 'issues', 'resolved_issues', 'requests'] LOOP EXECUTE format('DROP TRIGGER IF EXISTS %I ON %I', 'archive_' || t, t); EXECUTE format('CREATE TRIGGER %I AFTER DELETE ON %I FOR EACH ROW EXECUTE FUNCTION archive_deleted_row()', 'archive_' || t, t); END LOOP; FOR t IN SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_name IN (SELECT lower(prefix || suffix) FROM components, (VALUES ('_units'), ('_units_assigned')) AS s(suffix)) LOOP EXECUTE format('DROP TRIGGER IF EXISTS %I ON %I', 'archive_' || t, t); EXECUTE format('CREATE TRIGGER %I AFTER DELETE ON %I FOR EACH ROW EXECUTE FUNCTION archive_deleted_row()', 'archive_' || t, t); END LOOP; END $$;",
+		`CREATE TABLE IF NOT EXISTS component_categories (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER,
+			name VARCHAR(50) NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_component_categories_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_component_categories_name ON component_categories (COALESCE(org_id, 0), lower(name))",
+		"INSERT INTO component_categories(name) VALUES ('laptop'), ('monitor'), ('network'), ('peripheral') ON CONFLICT DO NOTHING",
+		`CREATE TABLE IF NOT EXISTS catalogue_models (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			category_id INTEGER NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			manufacturer VARCHAR(100) NOT NULL,
+			model_number VARCHAR(100) NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_catalogue_models_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_catalogue_models_category_id FOREIGN KEY (category_id) REFERENCES component_categories(id) ON UPDATE CASCADE ON DELETE RESTRICT
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_catalogue_models_model_number ON catalogue_models (org_id, lower(manufacturer), lower(model_number))",
+		"ALTER TABLE components ADD COLUMN IF NOT EXISTS catalogue_id INTEGER REFERENCES catalogue_models(id) ON UPDATE CASCADE ON DELETE SET NULL",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_components_catalogue_id ON components (warehouse_id, catalogue_id) WHERE catalogue_id IS NOT NULL",
+		`CREATE TABLE IF NOT EXISTS attribute_definitions (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			category_id INTEGER,
+			component_id INTEGER,
+			key VARCHAR(50) NOT NULL,
+			label VARCHAR(100) NOT NULL,
+			type VARCHAR(10) NOT NULL CHECK (type IN ('string', 'number', 'date', 'enum')),
+			options TEXT[] NOT NULL DEFAULT '{}',
+			required BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT chk_attribute_definitions_target CHECK ((category_id IS NULL) <> (component_id IS NULL)),
+			CONSTRAINT fk_attribute_definitions_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_attribute_definitions_category_id FOREIGN KEY (category_id) REFERENCES component_categories(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_attribute_definitions_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_attribute_definitions_key ON attribute_definitions (org_id, COALESCE(category_id, 0), COALESCE(component_id, 0), key)",
+		"DO $$ DECLARE r_component RECORD; BEGIN FOR r_component IN SELECT prefix FROM components WHERE EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = lower(prefix || '_units')) LOOP EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS attributes JSONB DEFAULT ''{}''', lower(r_component.prefix || '_units')); EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I USING GIN (attributes jsonb_path_ops)', lower('idx_' || r_component.prefix || '_units_attributes'), lower(r_component.prefix || '_units')); END LOOP; END $$;",
+		"ALTER TABLE components ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'unit' CHECK (kind IN ('unit', 'consumable'))",
+		`CREATE TABLE IF NOT EXISTS consumable_stock (
+			component_id INTEGER PRIMARY KEY,
+			warehouse_id INTEGER NOT NULL,
+			quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_consumable_stock_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_consumable_stock_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS consumable_ledger (
+			id SERIAL PRIMARY KEY,
+			component_id INTEGER NOT NULL,
+			warehouse_id INTEGER NOT NULL,
+			type VARCHAR(20) NOT NULL CHECK (type IN ('stock_in', 'issue', 'adjustment')),
+			quantity INTEGER NOT NULL,
+			balance INTEGER NOT NULL,
+			department_id INTEGER,
+			workspace_id INTEGER,
+			request_id INTEGER,
+			note VARCHAR(255),
+			created_by INTEGER NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_consumable_ledger_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_consumable_ledger_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_consumable_ledger_department_id FOREIGN KEY (department_id) REFERENCES departments(department_id) ON UPDATE CASCADE ON DELETE SET NULL,
+			CONSTRAINT fk_consumable_ledger_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON UPDATE CASCADE ON DELETE SET NULL,
+			CONSTRAINT fk_consumable_ledger_request_id FOREIGN KEY (request_id) REFERENCES requests(id) ON UPDATE CASCADE ON DELETE SET NULL
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_consumable_ledger_component_id ON consumable_ledger (component_id, created_at)",
+		`CREATE TABLE IF NOT EXISTS unit_links (
+			id SERIAL PRIMARY KEY,
+			warehouse_id INTEGER NOT NULL,
+			parent_component_id INTEGER NOT NULL,
+			parent_unit_id INTEGER NOT NULL,
+			child_component_id INTEGER NOT NULL,
+			child_unit_id INTEGER NOT NULL,
+			relation VARCHAR(20) NOT NULL CHECK (relation IN ('installed_in', 'kit')),
+			linked_by INTEGER NOT NULL,
+			linked_at TIMESTAMPTZ DEFAULT NOW(),
+			unlinked_by INTEGER,
+			unlinked_at TIMESTAMPTZ,
+			CONSTRAINT fk_unit_links_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_unit_links_parent_component_id FOREIGN KEY (parent_component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_unit_links_child_component_id FOREIGN KEY (child_component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_unit_links_child ON unit_links (child_component_id, child_unit_id) WHERE unlinked_at IS NULL",
+		"CREATE INDEX IF NOT EXISTS idx_unit_links_parent ON unit_links (parent_component_id, parent_unit_id) WHERE unlinked_at IS NULL",
+		`CREATE TABLE IF NOT EXISTS audit_sessions (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			warehouse_id INTEGER,
+			department_id INTEGER,
+			status VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+			note VARCHAR(255),
+			expected INTEGER,
+			opened_by_role VARCHAR(20) NOT NULL,
+			opened_by INTEGER NOT NULL,
+			opened_at TIMESTAMPTZ DEFAULT NOW(),
+			closed_by_role VARCHAR(20),
+			closed_by INTEGER,
+			closed_at TIMESTAMPTZ,
+			CONSTRAINT chk_audit_sessions_target CHECK ((warehouse_id IS NULL) <> (department_id IS NULL)),
+			CONSTRAINT fk_audit_sessions_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_audit_sessions_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_audit_sessions_department_id FOREIGN KEY (department_id) REFERENCES departments(department_id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_sessions_open ON audit_sessions (COALESCE(warehouse_id, 0), COALESCE(department_id, 0)) WHERE status = 'open'",
+		`CREATE TABLE IF NOT EXISTS audit_sightings (
+			id SERIAL PRIMARY KEY,
+			session_id INTEGER NOT NULL,
+			component_id INTEGER,
+			prefix VARCHAR(8),
+			unit_id INTEGER,
+			code VARCHAR(100),
+			workspace_id INTEGER,
+			recorded_by_role VARCHAR(20) NOT NULL,
+			recorded_by INTEGER NOT NULL,
+			recorded_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_audit_sightings_session_id FOREIGN KEY (session_id) REFERENCES audit_sessions(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_audit_sightings_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE SET NULL,
+			CONSTRAINT fk_audit_sightings_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON UPDATE CASCADE ON DELETE SET NULL
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_sightings_unit ON audit_sightings (session_id, lower(prefix), unit_id) WHERE unit_id IS NOT NULL",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_sightings_code ON audit_sightings (session_id, lower(code)) WHERE unit_id IS NULL",
+		`CREATE TABLE IF NOT EXISTS audit_discrepancies (
+			id SERIAL PRIMARY KEY,
+			session_id INTEGER NOT NULL,
+			component_id INTEGER,
+			prefix VARCHAR(8),
+			unit_id INTEGER,
+			code VARCHAR(100),
+			kind VARCHAR(20) NOT NULL CHECK (kind IN ('missing', 'unexpected', 'mislocated')),
+			expected_warehouse_id INTEGER,
+			expected_department_id INTEGER,
+			expected_workspace_id INTEGER,
+			found_workspace_id INTEGER,
+			action VARCHAR(30) NOT NULL DEFAULT 'none',
+			CONSTRAINT fk_audit_discrepancies_session_id FOREIGN KEY (session_id) REFERENCES audit_sessions(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_audit_discrepancies_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE SET NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS custodians (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			department_id INTEGER NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			email VARCHAR(100) NOT NULL,
+			employee_code VARCHAR(50),
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			deactivated_at TIMESTAMPTZ,
+			CONSTRAINT fk_custodians_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_custodians_department_id FOREIGN KEY (department_id) REFERENCES departments(department_id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_custodians_email ON custodians (org_id, lower(email))",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_custodians_employee_code ON custodians (org_id, lower(employee_code)) WHERE employee_code IS NOT NULL",
+		`CREATE TABLE IF NOT EXISTS unit_custody (
+			id SERIAL PRIMARY KEY,
+			custodian_id INTEGER NOT NULL,
+			component_id INTEGER NOT NULL,
+			prefix VARCHAR(8) NOT NULL,
+			unit_id INTEGER NOT NULL,
+			note VARCHAR(255),
+			assigned_by_role VARCHAR(20) NOT NULL,
+			assigned_by INTEGER NOT NULL,
+			assigned_at TIMESTAMPTZ DEFAULT NOW(),
+			ack_token VARCHAR(64),
+			acknowledged_at TIMESTAMPTZ,
+			returned_by_role VARCHAR(20),
+			returned_by INTEGER,
+			returned_at TIMESTAMPTZ,
+			CONSTRAINT fk_unit_custody_custodian_id FOREIGN KEY (custodian_id) REFERENCES custodians(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_unit_custody_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_unit_custody_unit ON unit_custody (component_id, unit_id) WHERE returned_at IS NULL",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_unit_custody_ack_token ON unit_custody (ack_token) WHERE ack_token IS NOT NULL",
+		"CREATE INDEX IF NOT EXISTS idx_unit_custody_custodian_id ON unit_custody (custodian_id) WHERE returned_at IS NULL",
+		`CREATE TABLE IF NOT EXISTS onboarding_templates (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			job_role VARCHAR(100),
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_onboarding_templates_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_onboarding_templates_name ON onboarding_templates (org_id, lower(name))",
+		`CREATE TABLE IF NOT EXISTS onboarding_template_items (
+			id SERIAL PRIMARY KEY,
+			template_id INTEGER NOT NULL,
+			catalogue_id INTEGER NOT NULL,
+			quantity INTEGER NOT NULL CHECK (quantity > 0),
+			CONSTRAINT uq_onboarding_template_items UNIQUE (template_id, catalogue_id),
+			CONSTRAINT fk_onboarding_template_items_template_id FOREIGN KEY (template_id) REFERENCES onboarding_templates(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_onboarding_template_items_catalogue_id FOREIGN KEY (catalogue_id) REFERENCES catalogue_models(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS staff_checklists (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			department_id INTEGER NOT NULL,
+			custodian_id INTEGER NOT NULL,
+			kind VARCHAR(20) NOT NULL CHECK (kind IN ('onboarding', 'offboarding')),
+			template_id INTEGER,
+			warehouse_id INTEGER,
+			workspace_id INTEGER,
+			status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'completed', 'cancelled')),
+			created_by_role VARCHAR(20) NOT NULL,
+			created_by INTEGER NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			completed_at TIMESTAMPTZ,
+			CONSTRAINT fk_staff_checklists_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_staff_checklists_department_id FOREIGN KEY (department_id) REFERENCES departments(department_id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_staff_checklists_custodian_id FOREIGN KEY (custodian_id) REFERENCES custodians(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_staff_checklists_template_id FOREIGN KEY (template_id) REFERENCES onboarding_templates(id) ON UPDATE CASCADE ON DELETE SET NULL,
+			CONSTRAINT fk_staff_checklists_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON UPDATE CASCADE ON DELETE SET NULL,
+			CONSTRAINT fk_staff_checklists_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON UPDATE CASCADE ON DELETE SET NULL
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_staff_checklists_open ON staff_checklists (custodian_id, kind) WHERE status = 'open'",
+		`CREATE TABLE IF NOT EXISTS staff_checklist_items (
+			id SERIAL PRIMARY KEY,
+			checklist_id INTEGER NOT NULL,
+			component_id INTEGER NOT NULL,
+			prefix VARCHAR(8) NOT NULL,
+			quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+			fulfilled INTEGER NOT NULL DEFAULT 0,
+			request_id INTEGER,
+			unit_id INTEGER,
+			custody_id INTEGER,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'done', 'lost')),
+			resolved_by_role VARCHAR(20),
+			resolved_by INTEGER,
+			resolved_at TIMESTAMPTZ,
+			CONSTRAINT fk_staff_checklist_items_checklist_id FOREIGN KEY (checklist_id) REFERENCES staff_checklists(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_staff_checklist_items_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_staff_checklist_items_request_id FOREIGN KEY (request_id) REFERENCES requests(id) ON UPDATE CASCADE ON DELETE SET NULL,
+			CONSTRAINT fk_staff_checklist_items_custody_id FOREIGN KEY (custody_id) REFERENCES unit_custody(id) ON UPDATE CASCADE ON DELETE SET NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS loans (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			warehouse_id INTEGER NOT NULL,
+			component_id INTEGER NOT NULL,
+			prefix VARCHAR(8) NOT NULL,
+			unit_id INTEGER,
+			quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+			custodian_id INTEGER NOT NULL,
+			starts_at TIMESTAMPTZ NOT NULL,
+			ends_at TIMESTAMPTZ NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'reserved' CHECK (status IN ('reserved', 'checked_out', 'returned', 'cancelled')),
+			note VARCHAR(255),
+			created_by_role VARCHAR(20) NOT NULL,
+			created_by INTEGER NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			checked_out_by INTEGER,
+			checked_out_at TIMESTAMPTZ,
+			returned_by INTEGER,
+			returned_at TIMESTAMPTZ,
+			cancelled_at TIMESTAMPTZ,
+			overdue_at TIMESTAMPTZ,
+			last_reminded_at TIMESTAMPTZ,
+			reminders INTEGER NOT NULL DEFAULT 0,
+			CONSTRAINT chk_loans_window CHECK (ends_at > starts_at),
+			CONSTRAINT chk_loans_unit CHECK (unit_id IS NULL OR quantity = 1),
+			CONSTRAINT fk_loans_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_loans_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_loans_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_loans_custodian_id FOREIGN KEY (custodian_id) REFERENCES custodians(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_loans_component_window ON loans (component_id, starts_at, ends_at) WHERE status IN ('reserved', 'checked_out')",
+		`CREATE TABLE IF NOT EXISTS loan_units (
+			id SERIAL PRIMARY KEY,
+			loan_id INTEGER NOT NULL,
+			component_id INTEGER NOT NULL,
+			prefix VARCHAR(8) NOT NULL,
+			unit_id INTEGER NOT NULL,
+			checked_out_at TIMESTAMPTZ DEFAULT NOW(),
+			returned_at TIMESTAMPTZ,
+			CONSTRAINT fk_loan_units_loan_id FOREIGN KEY (loan_id) REFERENCES loans(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_loan_units_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_loan_units_out ON loan_units (component_id, unit_id) WHERE returned_at IS NULL",
+		`CREATE TABLE IF NOT EXISTS locations (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			branch_id INTEGER NOT NULL,
+			parent_id INTEGER,
+			kind VARCHAR(20) NOT NULL CHECK (kind IN ('site', 'building', 'floor', 'room')),
+			name VARCHAR(100) NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_locations_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_locations_branch_id FOREIGN KEY (branch_id) REFERENCES branches(branch_id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_locations_parent_id FOREIGN KEY (parent_id) REFERENCES locations(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_locations_name ON locations (branch_id, COALESCE(parent_id, 0), lower(name))",
+		"CREATE INDEX IF NOT EXISTS idx_locations_parent_id ON locations (parent_id)",
+		"ALTER TABLE workspaces ADD COLUMN IF NOT EXISTS location_id INTEGER REFERENCES locations(id) ON UPDATE CASCADE ON DELETE SET NULL",
+		`CREATE TABLE IF NOT EXISTS storage_bins (
+			id SERIAL PRIMARY KEY,
+			warehouse_id INTEGER NOT NULL,
+			location_id INTEGER,
+			name VARCHAR(50) NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_storage_bins_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_storage_bins_location_id FOREIGN KEY (location_id) REFERENCES locations(id) ON UPDATE CASCADE ON DELETE SET NULL
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_storage_bins_name ON storage_bins (warehouse_id, lower(name))",
+		`CREATE TABLE IF NOT EXISTS unit_bins (
+			component_id INTEGER NOT NULL,
+			prefix VARCHAR(8) NOT NULL,
+			unit_id INTEGER NOT NULL,
+			bin_id INTEGER NOT NULL,
+			stored_by INTEGER NOT NULL,
+			stored_at TIMESTAMPTZ DEFAULT NOW(),
+			PRIMARY KEY (component_id, unit_id),
+			CONSTRAINT fk_unit_bins_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_unit_bins_bin_id FOREIGN KEY (bin_id) REFERENCES storage_bins(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS hierarchy_moves (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			entity VARCHAR(20) NOT NULL CHECK (entity IN ('workspace', 'department')),
+			entity_id INTEGER NOT NULL,
+			from_id INTEGER NOT NULL,
+			to_id INTEGER NOT NULL,
+			units INTEGER NOT NULL DEFAULT 0,
+			issues INTEGER NOT NULL DEFAULT 0,
+			requests INTEGER NOT NULL DEFAULT 0,
+			moved_by_role VARCHAR(20) NOT NULL,
+			moved_by INTEGER NOT NULL,
+			moved_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_hierarchy_moves_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_hierarchy_moves_entity ON hierarchy_moves (entity, entity_id)",
+		`CREATE TABLE IF NOT EXISTS staff (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			user_email VARCHAR(50) UNIQUE NOT NULL,
+			name VARCHAR(50) NOT NULL,
+			password VARCHAR(256) NOT NULL,
+			department_id INTEGER,
+			warehouse_id INTEGER,
+			staff_role VARCHAR(20) NOT NULL CHECK (staff_role IN ('head', 'member', 'manager', 'technician')),
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_by_role VARCHAR(20) NOT NULL,
+			created_by INTEGER NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			deactivated_at TIMESTAMPTZ,
+			CONSTRAINT chk_staff_unit CHECK ((department_id IS NULL) <> (warehouse_id IS NULL)),
+			CONSTRAINT chk_staff_role CHECK (
+				(department_id IS NOT NULL AND staff_role IN ('head', 'member'))
+				OR (warehouse_id IS NOT NULL AND staff_role IN ('manager', 'technician'))
+			),
+			CONSTRAINT fk_staff_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_staff_user_email FOREIGN KEY (user_email) REFERENCES users(user_email) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_staff_department_id FOREIGN KEY (department_id) REFERENCES departments(department_id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_staff_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS staff_activity (
+			id SERIAL PRIMARY KEY,
+			staff_id INTEGER NOT NULL,
+			method VARCHAR(10) NOT NULL,
+			path VARCHAR(200) NOT NULL,
+			status INTEGER NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_staff_activity_staff_id FOREIGN KEY (staff_id) REFERENCES staff(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_staff_activity_staff_id ON staff_activity (staff_id, created_at)",
+		"ALTER TABLE issues ADD COLUMN IF NOT EXISTS assigned_staff_id INTEGER REFERENCES staff(id) ON UPDATE CASCADE ON DELETE SET NULL",
+		"ALTER TABLE issues ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ",
+		`CREATE TABLE IF NOT EXISTS permissions (
+			name VARCHAR(50) PRIMARY KEY,
+			description VARCHAR(200) NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS roles (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER,
+			name VARCHAR(50) NOT NULL,
+			description VARCHAR(200) NOT NULL DEFAULT '',
+			built_in BOOLEAN NOT NULL DEFAULT FALSE,
+			created_by_role VARCHAR(20),
+			created_by INTEGER,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT chk_roles_built_in CHECK (built_in = (org_id IS NULL)),
+			CONSTRAINT fk_roles_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name ON roles (COALESCE(org_id, 0), lower(name))",
+		`CREATE TABLE IF NOT EXISTS role_permissions (
+			role_id INTEGER NOT NULL,
+			permission VARCHAR(50) NOT NULL,
+			PRIMARY KEY (role_id, permission),
+			CONSTRAINT fk_role_permissions_role_id FOREIGN KEY (role_id) REFERENCES roles(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission) REFERENCES permissions(name) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS role_bindings (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			role_id INTEGER NOT NULL,
+			user_email VARCHAR(50) NOT NULL,
+			node_type VARCHAR(20) NOT NULL CHECK (node_type IN ('organization', 'branch', 'department', 'warehouse')),
+			node_id INTEGER NOT NULL,
+			created_by_role VARCHAR(20) NOT NULL,
+			created_by INTEGER NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_role_bindings_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_role_bindings_role_id FOREIGN KEY (role_id) REFERENCES roles(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_role_bindings_user_email FOREIGN KEY (user_email) REFERENCES users(user_email) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_role_bindings_node ON role_bindings (role_id, user_email, node_type, node_id)",
+		"CREATE INDEX IF NOT EXISTS idx_role_bindings_user_email ON role_bindings (user_email)",
+		`CREATE TABLE IF NOT EXISTS delegations (
+			id SERIAL PRIMARY KEY,
+			org_id INTEGER NOT NULL,
+			delegator_email VARCHAR(50) NOT NULL,
+			delegator_role VARCHAR(20) NOT NULL,
+			delegate_email VARCHAR(50) NOT NULL,
+			node_type VARCHAR(20) NOT NULL CHECK (node_type IN ('branch', 'department')),
+			node_id INTEGER NOT NULL,
+			starts_at TIMESTAMPTZ NOT NULL,
+			ends_at TIMESTAMPTZ NOT NULL,
+			reason VARCHAR(200) NOT NULL DEFAULT '',
+			revoked_by VARCHAR(50),
+			revoked_at TIMESTAMPTZ,
+			expired_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT chk_delegations_period CHECK (ends_at > starts_at),
+			CONSTRAINT chk_delegations_delegate CHECK (delegate_email <> delegator_email),
+			CONSTRAINT fk_delegations_org_id FOREIGN KEY (org_id) REFERENCES organization(id) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_delegations_delegator_email FOREIGN KEY (delegator_email) REFERENCES users(user_email) ON UPDATE CASCADE ON DELETE CASCADE,
+			CONSTRAINT fk_delegations_delegate_email FOREIGN KEY (delegate_email) REFERENCES users(user_email) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_delegations_delegate_email ON delegations (delegate_email, ends_at)",
+		`CREATE TABLE IF NOT EXISTS delegation_activity (
+			id SERIAL PRIMARY KEY,
+			delegation_id INTEGER NOT NULL,
+			method VARCHAR(10) NOT NULL,
+			path VARCHAR(200) NOT NULL,
+			status INTEGER NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_delegation_activity_delegation_id FOREIGN KEY (delegation_id) REFERENCES delegations(id) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_delegation_activity_delegation_id ON delegation_activity (delegation_id, created_at)",
+		`CREATE TABLE IF NOT EXISTS invitations (
+			id SERIAL PRIMARY KEY,
+			user_email VARCHAR(50) NOT NULL,
+			name VARCHAR(50) NOT NULL,
+			user_level VARCHAR(20) NOT NULL,
+			token_hash VARCHAR(64) NOT NULL UNIQUE,
+			created_by_role VARCHAR(20) NOT NULL,
+			created_by INTEGER NOT NULL,
+			sent_count INTEGER NOT NULL DEFAULT 1,
+			last_sent_at TIMESTAMPTZ DEFAULT NOW(),
+			expires_at TIMESTAMPTZ NOT NULL,
+			accepted_at TIMESTAMPTZ,
+			revoked_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			CONSTRAINT fk_invitations_user_email FOREIGN KEY (user_email) REFERENCES users(user_email) ON UPDATE CASCADE ON DELETE CASCADE
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_invitations_created_by ON invitations (created_by_role, created_by)",
 	)
 
 	tx, err := db.db.Begin()
@@ -340,6 +825,11 @@ func (db *Query) InitialiseDBqueries() error {
 		}
 	}
 
+	if err = seedPermissions(tx); err != nil {
+		log.Printf("error while seeding permissions: %v", err)
+		return err
+	}
+
 	return nil
 
 }
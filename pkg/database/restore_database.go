@@ -0,0 +1,378 @@
+package database
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/lib/pq"
+)
+
+// archiveTriggerQuery makes deletes on table snapshot the removed row into archived_rows
+func archiveTriggerQuery(table string) string {
+	return fmt.Sprintf("DROP TRIGGER IF EXISTS archive_%[1]s ON %[1]s; CREATE TRIGGER archive_%[1]s AFTER DELETE ON %[1]s FOR EACH ROW EXECUTE FUNCTION archive_deleted_row()", strings.ToLower(table))
+}
+
+type restoreEntity struct {
+	table       string
+	key         string
+	scopeColumn string
+}
+
+var restoreEntities = map[string]restoreEntity{
+	"department": {table: "departments", key: "department_id", scopeColumn: "branch_id"},
+	"workspace":  {table: "workspaces", key: "id", scopeColumn: "department_id"},
+	"component":  {table: "components", key: "id", scopeColumn: "warehouse_id"},
+	"unit":       {table: "_units", key: "id", scopeColumn: "warehouse_id"},
+	"issue":      {table: "issues", key: "id", scopeColumn: "department_id"},
+}
+
+func scopeIDs(scope models.ScopeModel, column string) []int {
+	switch column {
+	case "branch_id":
+		return scope.BranchIDs
+	case "department_id":
+		return scope.DepartmentIDs
+	default:
+		return scope.WarehouseIDs
+	}
+}
+
+// GetArchivedEntities lists deleted rows of one entity type that are still inside the retention window
+func (q *Query) GetArchivedEntities(entity_type string, scope models.ScopeModel, retention_days int) (int, []models.ArchivedEntityModel, error) {
+	entity, ok := restoreEntities[entity_type]
+	if !ok {
+		return http.StatusBadRequest, nil, fmt.Errorf("invalid entity type")
+	}
+
+	tableCondition := "table_name = $1"
+	if entity_type == "unit" {
+		tableCondition = `table_name LIKE '%' || $1`
+	}
+
+	query := fmt.Sprintf(`SELECT table_name, (data->>$2)::INTEGER, archived_at, archived_at + make_interval(days => $3), data
+		FROM archived_rows
+		WHERE %s
+		AND restored_at IS NULL
+		AND archived_at >= NOW() - make_interval(days => $3)
+		AND (data->>$4)::INTEGER = ANY($5)
+		ORDER BY archived_at DESC
+		LIMIT 200`, tableCondition)
+
+	rows, err := q.db.Query(query, entity.table, entity.key, retention_days, entity.scopeColumn, pq.Array(scopeIDs(scope, entity.scopeColumn)))
+	if err != nil {
+		log.Printf("error while getting archived %v: %v", entity_type, err)
+		return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+	}
+	defer rows.Close()
+
+	archived := []models.ArchivedEntityModel{}
+	for rows.Next() {
+		var table string
+		item := models.ArchivedEntityModel{EntityType: entity_type}
+		if err := rows.Scan(&table, &item.ID, &item.ArchivedAt, &item.ExpiresAt, &item.Data); err != nil {
+			log.Printf("error while scanning archived %v: %v", entity_type, err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("error occured while retrieving data")
+		}
+		if entity_type == "unit" {
+			item.Prefix = strings.TrimSuffix(table, "_units")
+		}
+		archived = append(archived, item)
+	}
+
+	return http.StatusOK, archived, nil
+}
+
+type restorer struct {
+	tx     *sql.Tx
+	batch  int64
+	result *models.RestoreResultModel
+}
+
+// restoreRows reinserts the archived rows of the batch matching condition, rows that cannot be
+// reinserted are reported as conflicts and the restored rows are returned
+func (r *restorer) restoreRows(table, key, condition string, args ...interface{}) ([]map[string]interface{}, error) {
+	query := fmt.Sprintf("SELECT id, data FROM archived_rows WHERE batch = $1 AND table_name = $2 AND restored_at IS NULL AND (%s) ORDER BY id FOR UPDATE", condition)
+
+	rows, err := r.tx.Query(query, append([]interface{}{r.batch, table}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	type archivedRow struct {
+		id   int64
+		data []byte
+	}
+	var archived []archivedRow
+	for rows.Next() {
+		var row archivedRow
+		if err := rows.Scan(&row.id, &row.data); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		archived = append(archived, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	restored := []map[string]interface{}{}
+	for _, row := range archived {
+		var data map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(row.data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&data); err != nil {
+			return nil, err
+		}
+
+		if _, err := r.tx.Exec("SAVEPOINT restore_row"); err != nil {
+			return nil, err
+		}
+
+		if _, err := r.tx.Exec(fmt.Sprintf("INSERT INTO %[1]s SELECT * FROM jsonb_populate_record(NULL::%[1]s, $1)", pq.QuoteIdentifier(table)), row.data); err != nil {
+			if _, rbErr := r.tx.Exec("ROLLBACK TO SAVEPOINT restore_row"); rbErr != nil {
+				return nil, rbErr
+			}
+			r.result.Conflicts = append(r.result.Conflicts, models.RestoreConflictModel{
+				Table:  table,
+				Key:    fmt.Sprintf("%s=%v", key, data[key]),
+				Reason: restoreConflictReason(err),
+			})
+			continue
+		}
+
+		if _, err := r.tx.Exec("RELEASE SAVEPOINT restore_row"); err != nil {
+			return nil, err
+		}
+
+		if _, err := r.tx.Exec("UPDATE archived_rows SET restored_at = NOW() WHERE id = $1", row.id); err != nil {
+			return nil, err
+		}
+
+		r.result.Restored[table]++
+		restored = append(restored, data)
+	}
+
+	return restored, nil
+}
+
+// restoreAssignments reinserts unit assignments from every <prefix>_units_assigned table archived in the batch
+func (r *restorer) restoreAssignments(column string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	tables, err := r.tables(`table_name LIKE '%\_units\_assigned'`)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if _, err := r.restoreRows(table, "unit_id", fmt.Sprintf("data->>'%s' = ANY($3)", column), pq.Array(values)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *restorer) tables(condition string) ([]string, error) {
+	rows, err := r.tx.Query(fmt.Sprintf("SELECT DISTINCT table_name FROM archived_rows WHERE batch = $1 AND restored_at IS NULL AND %s", condition), r.batch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
+}
+
+func keyValues(rows []map[string]interface{}, key string) []string {
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, fmt.Sprint(row[key]))
+	}
+	return values
+}
+
+func restoreConflictReason(err error) string {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			return "a row with the same key already exists"
+		case "23503":
+			return "the parent record no longer exists"
+		}
+		return pqErr.Message
+	}
+	return err.Error()
+}
+
+// Restore recreates a deleted department, workspace, component, unit or issue together with the
+// dependent rows removed by the same delete, as long as it was deleted within the retention window
+func (q *Query) Restore(entity_type string, id int, prefix string, scope models.ScopeModel, retention_days int) (int, models.RestoreResultModel, error) {
+	entity, ok := restoreEntities[entity_type]
+	if !ok {
+		return http.StatusBadRequest, models.RestoreResultModel{}, fmt.Errorf("invalid entity type")
+	}
+
+	table := entity.table
+	if entity_type == "unit" {
+		table = strings.ToLower(prefix) + entity.table
+	}
+
+	result := models.RestoreResultModel{
+		EntityType: entity_type,
+		EntityID:   id,
+		Restored:   map[string]int{},
+		Conflicts:  []models.RestoreConflictModel{},
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.RestoreResultModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var batch int64
+	var scopeID int
+	query := `SELECT batch, (data->>$3)::INTEGER FROM archived_rows
+		WHERE table_name = $1 AND data->>$2 = $4 AND restored_at IS NULL AND archived_at >= NOW() - make_interval(days => $5)
+		ORDER BY archived_at DESC
+		LIMIT 1`
+
+	if err := tx.QueryRow(query, table, entity.key, entity.scopeColumn, strconv.Itoa(id), retention_days).Scan(&batch, &scopeID); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.RestoreResultModel{}, fmt.Errorf("no restorable %v found", entity_type)
+		}
+		log.Printf("error while finding archived %v %v: %v", entity_type, id, err)
+		return http.StatusInternalServerError, models.RestoreResultModel{}, fmt.Errorf("database error")
+	}
+
+	if !slices.Contains(scopeIDs(scope, entity.scopeColumn), scopeID) {
+		return http.StatusUnauthorized, models.RestoreResultModel{}, fmt.Errorf("invalid user details")
+	}
+
+	r := &restorer{tx: tx, batch: batch, result: &result}
+	idValue := strconv.Itoa(id)
+
+	root, err := r.restoreRows(table, entity.key, fmt.Sprintf("data->>'%s' = $3", entity.key), idValue)
+	if err != nil {
+		log.Printf("error while restoring %v %v: %v", entity_type, id, err)
+		return http.StatusInternalServerError, models.RestoreResultModel{}, fmt.Errorf("database error")
+	}
+	if len(root) == 0 {
+		// nothing was restored and nothing conflicted, a concurrent restore got to the rows first
+		if len(result.Conflicts) == 0 {
+			return http.StatusNotFound, models.RestoreResultModel{}, fmt.Errorf("no restorable %v found", entity_type)
+		}
+		return http.StatusConflict, result, fmt.Errorf("%v could not be restored: %v", entity_type, result.Conflicts[0].Reason)
+	}
+
+	switch entity_type {
+	case "department":
+		err = r.restoreDepartment(idValue)
+	case "workspace":
+		err = r.restoreWorkspace(idValue)
+	case "component":
+		err = r.restoreComponent(idValue, root[0])
+	case "unit":
+		err = r.restoreAssignments("unit_id", []string{idValue})
+	case "issue":
+		_, err = r.restoreRows("resolved_issues", "id", "data->>'issue_id' = $3", idValue)
+	}
+	if err != nil {
+		log.Printf("error while restoring rows dependent on %v %v: %v", entity_type, id, err)
+		return http.StatusInternalServerError, models.RestoreResultModel{}, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing restore of %v %v: %v", entity_type, id, err)
+		return http.StatusInternalServerError, models.RestoreResultModel{}, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, result, nil
+}
+
+func (r *restorer) restoreDepartment(department_id string) error {
+	// department heads only come back together with their user account
+	users, err := r.restoreRows("users", "user_email", "data->>'user_email' IN (SELECT data->>'email' FROM archived_rows WHERE batch = $1 AND table_name = 'department_head' AND data->>'department_id' = $3)", department_id)
+	if err != nil {
+		return err
+	}
+
+	emails := keyValues(users, "user_email")
+	if _, err := r.restoreRows("department_head", "id", "data->>'department_id' = $3 AND data->>'email' = ANY($4)", department_id, pq.Array(emails)); err != nil {
+		return err
+	}
+
+	workspaces, err := r.restoreRows("workspaces", "id", "data->>'department_id' = $3", department_id)
+	if err != nil {
+		return err
+	}
+
+	issues, err := r.restoreRows("issues", "id", "data->>'department_id' = $3", department_id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.restoreRows("resolved_issues", "id", "data->>'issue_id' = ANY($3)", pq.Array(keyValues(issues, "id"))); err != nil {
+		return err
+	}
+
+	if _, err := r.restoreRows("requests", "id", "data->>'department_id' = $3", department_id); err != nil {
+		return err
+	}
+
+	return r.restoreAssignments("workspace_id", keyValues(workspaces, "id"))
+}
+
+func (r *restorer) restoreWorkspace(workspace_id string) error {
+	issues, err := r.restoreRows("issues", "id", "data->>'workspace_id' = $3", workspace_id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.restoreRows("resolved_issues", "id", "data->>'issue_id' = ANY($3)", pq.Array(keyValues(issues, "id"))); err != nil {
+		return err
+	}
+
+	if _, err := r.restoreRows("requests", "id", "data->>'workspace_id' = $3", workspace_id); err != nil {
+		return err
+	}
+
+	return r.restoreAssignments("workspace_id", []string{workspace_id})
+}
+
+func (r *restorer) restoreComponent(component_id string, component map[string]interface{}) error {
+	prefix := strings.ToLower(fmt.Sprint(component["prefix"]))
+
+	units, err := r.restoreRows(prefix+"_units", "id", "data->>'component_id' = $3", component_id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.restoreRows(prefix+"_units_assigned", "unit_id", "data->>'unit_id' = ANY($3)", pq.Array(keyValues(units, "id"))); err != nil {
+		return err
+	}
+
+	_, err = r.restoreRows("requests", "id", "data->>'component_id' = $3", component_id)
+	return err
+}
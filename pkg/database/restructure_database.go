@@ -0,0 +1,296 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
+)
+
+var MovesResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "moved_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":        {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"entity":    {Column: "entity", Type: queryspec.Enum, Values: []string{"workspace", "department"}, Filterable: true, Sortable: true},
+		"entity_id": {Column: "entity_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"from_id":   {Column: "from_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"to_id":     {Column: "to_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"moved_at":  {Column: "moved_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+// hasOpenAudit reports whether a stocktake is running in one of the departments, moving units under it
+// would invalidate its expected list
+func hasOpenAudit(tx *sql.Tx, department_ids ...int) (bool, error) {
+	var open bool
+	err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM audit_sessions WHERE status = 'open' AND department_id = ANY($1))", pq.Array(department_ids)).Scan(&open)
+	return open, err
+}
+
+// crossBranchRecords counts the unit assignments, open issues and pending requests of a department or
+// workspace that point at warehouses outside branch_id, they would be left behind by a move to that branch
+func crossBranchRecords(tx *sql.Tx, tables []string, column string, id, branch_id int) (int, int, int, error) {
+	var units, issues, requests int
+	for _, table := range tables {
+		var count int
+		if err := tx.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %s a JOIN %s u ON u.id = a.unit_id
+			JOIN warehouses w ON w.id = u.warehouse_id
+			WHERE a.%s = $1 AND w.branch_id <> $2`, pq.QuoteIdentifier(table), pq.QuoteIdentifier(strings.TrimSuffix(table, "_assigned")), column),
+			id, branch_id).Scan(&count); err != nil {
+			return 0, 0, 0, err
+		}
+		units += count
+	}
+
+	err := tx.QueryRow(fmt.Sprintf(`SELECT
+			(SELECT COUNT(*) FROM issues i JOIN warehouses w ON w.id = i.warehouse_id WHERE i.%[1]s = $1 AND i.status <> 'resolved' AND w.branch_id <> $2),
+			(SELECT COUNT(*) FROM requests r JOIN warehouses w ON w.id = r.warehouse_id WHERE r.%[1]s = $1 AND r.status = 'raised' AND w.branch_id <> $2)`, column),
+		id, branch_id).Scan(&issues, &requests)
+
+	return units, issues, requests, err
+}
+
+// rejectCrossBranch fails a move to another branch while records still depend on the warehouses of the old one
+func rejectCrossBranch(tx *sql.Tx, tables []string, column string, id, branch_id int) (int, error) {
+	units, issues, requests, err := crossBranchRecords(tx, tables, column, id, branch_id)
+	if err != nil {
+		log.Printf("error while checking records left in the old branch: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if units+issues+requests > 0 {
+		return http.StatusConflict, fmt.Errorf("return %d assigned units, resolve %d open issues and settle %d pending requests of warehouses in the old branch before moving", units, issues, requests)
+	}
+
+	return http.StatusOK, nil
+}
+
+func insertMove(tx *sql.Tx, scope models.ScopeModel, move *models.MoveModel) error {
+	return tx.QueryRow(`INSERT INTO hierarchy_moves(org_id, entity, entity_id, from_id, to_id, units, issues, requests, moved_by_role, moved_by)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, moved_at`,
+		scope.OrgID, move.Entity, move.EntityID, move.FromID, move.ToID, move.Units, move.Issues, move.Requests, scope.Role, scope.UserID).Scan(&move.MoveID, &move.MovedAt)
+}
+
+// MoveWorkspace re-parents a workspace to another department, carrying its unit assignments, issues and
+// requests along. Past assignments in deleted_units_assigned keep the department they were made in, a move
+// to another branch is refused while the workspace still depends on warehouses of the old one
+func (q *Query) MoveWorkspace(scope models.ScopeModel, move models.MoveWorkspaceModel) (int, models.MoveModel, error) {
+	tables, err := q.GetTablesWithSuffix("_units_assigned")
+	if err != nil {
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var from_department, from_branch int
+	var location_id sql.NullInt64
+	if err := tx.QueryRow(`SELECT w.department_id, d.branch_id, w.location_id FROM workspaces w
+		JOIN departments d ON d.department_id = w.department_id
+		WHERE w.id = $1 FOR UPDATE OF w`, move.WorkspaceID).Scan(&from_department, &from_branch, &location_id); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.MoveModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting workspace %v: %v", move.WorkspaceID, err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+
+	if !slices.Contains(scope.DepartmentIDs, from_department) || !slices.Contains(scope.DepartmentIDs, move.DepartmentID) {
+		return http.StatusNotFound, models.MoveModel{}, fmt.Errorf("no matching data found")
+	} else if from_department == move.DepartmentID {
+		return http.StatusBadRequest, models.MoveModel{}, fmt.Errorf("workspace is already in this department")
+	}
+
+	var to_branch int
+	if err := tx.QueryRow("SELECT branch_id FROM departments WHERE department_id = $1 FOR SHARE", move.DepartmentID).Scan(&to_branch); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.MoveModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting department %v: %v", move.DepartmentID, err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+
+	if open, err := hasOpenAudit(tx, from_department, move.DepartmentID); err != nil {
+		log.Printf("error while checking audit sessions: %v", err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	} else if open {
+		return http.StatusConflict, models.MoveModel{}, fmt.Errorf("close the open stocktake of the departments before moving")
+	}
+
+	if from_branch != to_branch {
+		if status, err := rejectCrossBranch(tx, tables, "workspace_id", move.WorkspaceID, to_branch); err != nil {
+			return status, models.MoveModel{}, err
+		}
+	}
+
+	result := models.MoveModel{Entity: "workspace", EntityID: move.WorkspaceID, FromID: from_department, ToID: move.DepartmentID}
+
+	// locations belong to a branch, the workspace has to be placed again in its new one
+	if _, err := tx.Exec("UPDATE workspaces SET department_id = $2, location_id = CASE WHEN $3 THEN location_id END WHERE id = $1",
+		move.WorkspaceID, move.DepartmentID, from_branch == to_branch); err != nil {
+		log.Printf("error while moving workspace %v: %v", move.WorkspaceID, err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+
+	for _, table := range tables {
+		moved, err := tx.Exec(fmt.Sprintf("UPDATE %s SET department_id = $2 WHERE workspace_id = $1", pq.QuoteIdentifier(table)), move.WorkspaceID, move.DepartmentID)
+		if err != nil {
+			log.Printf("error while moving assignments in %v: %v", table, err)
+			return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+		}
+		count, _ := moved.RowsAffected()
+		result.Units += int(count)
+	}
+
+	issues, err := tx.Exec("UPDATE issues SET department_id = $2 WHERE workspace_id = $1", move.WorkspaceID, move.DepartmentID)
+	if err != nil {
+		log.Printf("error while moving issues of workspace %v: %v", move.WorkspaceID, err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+	count, _ := issues.RowsAffected()
+	result.Issues = int(count)
+
+	requests, err := tx.Exec("UPDATE requests SET department_id = $2 WHERE workspace_id = $1", move.WorkspaceID, move.DepartmentID)
+	if err != nil {
+		log.Printf("error while moving requests of workspace %v: %v", move.WorkspaceID, err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+	count, _ = requests.RowsAffected()
+	result.Requests = int(count)
+
+	if err := insertMove(tx, scope, &result); err != nil {
+		log.Printf("error while recording move: %v", err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+	result.MovedByRole, result.MovedBy = scope.Role, scope.UserID
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing workspace move: %v", err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, result, nil
+}
+
+// MoveDepartment re-parents a department, with its workspaces, head and assignments, to another branch
+// of the organization. Workspace locations of the old branch are cleared, the move is refused while
+// assignments, open issues or pending requests still depend on warehouses of the old branch
+func (q *Query) MoveDepartment(scope models.ScopeModel, move models.MoveDepartmentModel) (int, models.MoveModel, error) {
+	tables, err := q.GetTablesWithSuffix("_units_assigned")
+	if err != nil {
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var from_branch int
+	if err := tx.QueryRow("SELECT branch_id FROM departments WHERE department_id = $1 FOR UPDATE", move.DepartmentID).Scan(&from_branch); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, models.MoveModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting department %v: %v", move.DepartmentID, err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+
+	if !slices.Contains(scope.BranchIDs, from_branch) || !slices.Contains(scope.BranchIDs, move.BranchID) {
+		return http.StatusNotFound, models.MoveModel{}, fmt.Errorf("no matching data found")
+	} else if from_branch == move.BranchID {
+		return http.StatusBadRequest, models.MoveModel{}, fmt.Errorf("department is already in this branch")
+	}
+
+	if open, err := hasOpenAudit(tx, move.DepartmentID); err != nil {
+		log.Printf("error while checking audit sessions: %v", err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	} else if open {
+		return http.StatusConflict, models.MoveModel{}, fmt.Errorf("close the open stocktake of the department before moving")
+	}
+
+	if status, err := rejectCrossBranch(tx, tables, "department_id", move.DepartmentID, move.BranchID); err != nil {
+		return status, models.MoveModel{}, err
+	}
+
+	result := models.MoveModel{Entity: "department", EntityID: move.DepartmentID, FromID: from_branch, ToID: move.BranchID}
+
+	if _, err := tx.Exec("UPDATE departments SET branch_id = $2 WHERE department_id = $1", move.DepartmentID, move.BranchID); err != nil {
+		log.Printf("error while moving department %v: %v", move.DepartmentID, err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+
+	if _, err := tx.Exec("UPDATE workspaces SET location_id = NULL WHERE department_id = $1 AND location_id IS NOT NULL", move.DepartmentID); err != nil {
+		log.Printf("error while clearing workspace locations: %v", err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+
+	// assignments, issues and requests keep their department, they are counted for the record
+	for _, table := range tables {
+		var count int
+		if err := tx.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE department_id = $1", pq.QuoteIdentifier(table)), move.DepartmentID).Scan(&count); err != nil {
+			log.Printf("error while counting assignments in %v: %v", table, err)
+			return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+		}
+		result.Units += count
+	}
+
+	if err := tx.QueryRow(`SELECT (SELECT COUNT(*) FROM issues WHERE department_id = $1), (SELECT COUNT(*) FROM requests WHERE department_id = $1)`,
+		move.DepartmentID).Scan(&result.Issues, &result.Requests); err != nil {
+		log.Printf("error while counting department records: %v", err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+
+	if err := insertMove(tx, scope, &result); err != nil {
+		log.Printf("error while recording move: %v", err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+	result.MovedByRole, result.MovedBy = scope.Role, scope.UserID
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing department move: %v", err)
+		return http.StatusInternalServerError, models.MoveModel{}, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, result, nil
+}
+
+// GetMoves lists the workspace moves touching a department in the scope and the department moves
+// touching a branch in it
+func (q *Query) GetMoves(scope models.ScopeModel, spec queryspec.Spec) (int, []models.MoveModel, models.PageModel, error) {
+	base := `SELECT id, entity, entity_id, from_id, to_id, units, issues, requests, moved_by_role, moved_by, moved_at FROM hierarchy_moves
+		WHERE org_id = $1 AND (
+			(entity = 'workspace' AND (from_id = ANY($2) OR to_id = ANY($2)))
+			OR (entity = 'department' AND (from_id = ANY($3) OR to_id = ANY($3)))
+		)`
+
+	moves := []models.MoveModel{}
+
+	page, err := q.listPage(spec, base, "id, entity, entity_id, from_id, to_id, units, issues, requests, moved_by_role, moved_by, moved_at", []interface{}{scope.OrgID, pq.Array(scope.DepartmentIDs), pq.Array(scope.BranchIDs)}, func(rows *sql.Rows, extra ...interface{}) error {
+		var move models.MoveModel
+		dest := []interface{}{&move.MoveID, &move.Entity, &move.EntityID, &move.FromID, &move.ToID, &move.Units, &move.Issues, &move.Requests, &move.MovedByRole, &move.MovedBy, &move.MovedAt}
+		if err := rows.Scan(append(dest, extra...)...); err != nil {
+			return err
+		}
+		moves = append(moves, move)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, moves, page, nil
+}
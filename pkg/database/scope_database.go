@@ -0,0 +1,197 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/lib/pq"
+)
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func (q *Query) collectIDs(query string, args ...interface{}) ([]int, error) {
+	return collectIDsOn(q.db, query, args...)
+}
+
+func collectIDsOn(db querier, query string, args ...interface{}) ([]int, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetUserScope resolves the organization, branches, departments and warehouses visible to a user
+func (q *Query) GetUserScope(role string, user_id int) (models.ScopeModel, error) {
+	scope := models.ScopeModel{
+		Role:          role,
+		UserID:        user_id,
+		BranchIDs:     []int{},
+		DepartmentIDs: []int{},
+		WarehouseIDs:  []int{},
+	}
+
+	var err error
+
+	switch role {
+	case "organization":
+		scope.OrgID = user_id
+		scope.BranchIDs, err = q.collectIDs("SELECT branch_id FROM branches WHERE org_id = $1", user_id)
+	case "super_admin":
+		if err = q.db.QueryRow("SELECT org_id FROM super_admin WHERE id = $1", user_id).Scan(&scope.OrgID); err != nil {
+			break
+		}
+		scope.BranchIDs, err = q.collectIDs("SELECT branch_id FROM branches WHERE super_admin_id = $1", user_id)
+	case "branch_head":
+		var branch_id int
+		if err = q.db.QueryRow("SELECT b.branch_id, b.org_id FROM branch_head bh JOIN branches b ON b.branch_id = bh.branch_id WHERE bh.id = $1", user_id).Scan(&branch_id, &scope.OrgID); err != nil {
+			break
+		}
+		scope.BranchIDs = []int{branch_id}
+	case "department_head":
+		var department_id int
+		if err = q.db.QueryRow("SELECT d.department_id, b.org_id FROM department_head dh JOIN departments d ON d.department_id = dh.department_id JOIN branches b ON b.branch_id = d.branch_id WHERE dh.id = $1", user_id).Scan(&department_id, &scope.OrgID); err != nil {
+			break
+		}
+		scope.DepartmentIDs = []int{department_id}
+		return scope, nil
+	case "warehouses":
+		if err = q.db.QueryRow("SELECT b.org_id FROM warehouses w JOIN branches b ON b.branch_id = w.branch_id WHERE w.id = $1", user_id).Scan(&scope.OrgID); err != nil {
+			break
+		}
+		scope.WarehouseIDs = []int{user_id}
+		return scope, nil
+	default:
+		return scope, fmt.Errorf("invalid user role")
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no scope found for %v %v", role, user_id)
+			return scope, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while resolving user scope: %v", err)
+		return scope, fmt.Errorf("database error")
+	}
+
+	if err = q.expandBranchScope(&scope); err != nil {
+		return scope, err
+	}
+
+	return scope, nil
+}
+
+// expandBranchScope fills the departments and warehouses of every branch in the scope
+func (q *Query) expandBranchScope(scope *models.ScopeModel) error {
+	var err error
+
+	scope.DepartmentIDs, err = q.collectIDs("SELECT department_id FROM departments WHERE branch_id = ANY($1)", pq.Array(scope.BranchIDs))
+	if err != nil {
+		log.Printf("error while resolving scope departments: %v", err)
+		return fmt.Errorf("database error")
+	}
+
+	scope.WarehouseIDs, err = q.collectIDs("SELECT id FROM warehouses WHERE branch_id = ANY($1)", pq.Array(scope.BranchIDs))
+	if err != nil {
+		log.Printf("error while resolving scope warehouses: %v", err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
+
+// countScopeUnits returns the number of units visible in the scope and how many of them are assigned
+func (q *Query) countScopeUnits(scope models.ScopeModel) (int, int, error) {
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	if len(components) == 0 {
+		return 0, 0, nil
+	}
+
+	query := scopedUnitsQuery(components) + `
+	SELECT COUNT(*), COUNT(department_id) FROM scoped`
+
+	var total, assigned int
+	if err := q.db.QueryRow(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs)).Scan(&total, &assigned); err != nil {
+		log.Printf("error while counting scope units: %v", err)
+		return -1, -1, fmt.Errorf("database error")
+	}
+
+	return total, assigned, nil
+}
+
+// GetScopeComponents returns every component that can hold units visible in the scope
+func (q *Query) GetScopeComponents(scope models.ScopeModel) ([]models.ComponentModel, error) {
+	return getScopeComponents(q.db, scope)
+}
+
+func getScopeComponents(db querier, scope models.ScopeModel) ([]models.ComponentModel, error) {
+	query := `SELECT c.id, c.name, c.prefix, c.warehouse_id
+		FROM components c
+		WHERE (
+			c.warehouse_id = ANY($1)
+			OR c.warehouse_id IN (
+				SELECT w.id FROM warehouses w
+				JOIN departments d ON d.branch_id = w.branch_id
+				WHERE d.department_id = ANY($2)
+			)
+		)
+		AND EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = lower(c.prefix || '_units_assigned'))
+		ORDER BY c.id`
+
+	rows, err := db.Query(query, pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs))
+	if err != nil {
+		log.Printf("error while getting scope components: %v", err)
+		return nil, fmt.Errorf("database error")
+	}
+	defer rows.Close()
+
+	components := []models.ComponentModel{}
+	for rows.Next() {
+		var component models.ComponentModel
+		if err := rows.Scan(&component.ComponentID, &component.ComponentName, &component.Prefix, &component.WarehouseID); err != nil {
+			log.Printf("error while scanning scope components: %v", err)
+			return nil, fmt.Errorf("error occured while retrieving data")
+		}
+		components = append(components, component)
+	}
+
+	return components, nil
+}
+
+// scopedUnitsQuery builds the units and scoped CTEs over every per-prefix unit table,
+// $1 and $2 are reserved for the scope warehouse and department ids
+func scopedUnitsQuery(components []models.ComponentModel) string {
+	parts := make([]string, 0, len(components))
+	for _, component := range components {
+		parts = append(parts, fmt.Sprintf(`SELECT '%[1]s'::TEXT AS prefix, u.id AS unit_id, u.component_id, u.warehouse_id, a.department_id, a.workspace_id, u.status::TEXT AS status, u.cost, u.maintainance_cost, u.warranty_date, u.last_maintenance_date, u.created_at, a.assigned_at, u.serial_number, u.asset_tag FROM %[1]s_units u LEFT JOIN %[1]s_units_assigned a ON a.unit_id = u.id`, component.Prefix))
+	}
+
+	return fmt.Sprintf(`WITH units AS (
+		%s
+	),
+	scoped AS (
+		SELECT * FROM units WHERE warehouse_id = ANY($1) OR department_id = ANY($2)
+	)`, strings.Join(parts, " UNION ALL "))
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/lib/pq"
+)
+
+var unitCodePattern = regexp.MustCompile(`^([a-z]+)[-_ ]?([0-9]+)$`)
+
+// searchTsQuery turns free text into a prefix-matching tsquery, dropping anything that is not a letter or digit.
+// Email addresses are kept whole since the parser indexes them as a single token
+func searchTsQuery(term string) string {
+	words := []string{}
+	for _, field := range strings.Fields(strings.ToLower(term)) {
+		if strings.Contains(field, "@") {
+			if email := strings.Trim(strings.Map(emailRune, field), "@.-_"); email != "" {
+				words = append(words, email)
+			}
+			continue
+		}
+
+		words = append(words, strings.FieldsFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+// emailRune keeps the characters of an email address that cannot break the tsquery syntax
+func emailRune(r rune) rune {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("@.-_", r) {
+		return r
+	}
+	return -1
+}
+
+func (q *Query) runSearchQuery(query string, args ...interface{}) ([]models.SearchResultModel, error) {
+	rows, err := q.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.SearchResultModel{}
+	for rows.Next() {
+		var result models.SearchResultModel
+		if err := rows.Scan(&result.Type, &result.ID, &result.Prefix, &result.UserType, &result.Title, &result.Subtitle, &result.Rank); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+func (q *Query) Search(scope models.ScopeModel, term string, types map[string]bool, limit int) (int, []models.SearchResultModel, error) {
+	tsQuery := searchTsQuery(term)
+	warehouses := pq.Array(scope.WarehouseIDs)
+	departments := pq.Array(scope.DepartmentIDs)
+
+	type search struct {
+		query string
+		args  []interface{}
+	}
+
+	searches := map[string]search{
+		"branch": {`SELECT 'branch', b.branch_id, '', '', b.branch_name, b.branch_location, ts_rank(to_tsvector('simple', b.branch_name || ' ' || b.branch_location), to_tsquery('simple', $1))
+			FROM branches b
+			WHERE b.branch_id = ANY($2) AND to_tsvector('simple', b.branch_name || ' ' || b.branch_location) @@ to_tsquery('simple', $1)
+			ORDER BY 7 DESC LIMIT $3`, []interface{}{tsQuery, pq.Array(scope.BranchIDs), limit}},
+		"department": {`SELECT 'department', d.department_id, '', '', d.department_name, b.branch_name, ts_rank(to_tsvector('simple', d.department_name), to_tsquery('simple', $1))
+			FROM departments d
+			JOIN branches b ON b.branch_id = d.branch_id
+			WHERE d.department_id = ANY($2) AND to_tsvector('simple', d.department_name) @@ to_tsquery('simple', $1)
+			ORDER BY 7 DESC LIMIT $3`, []interface{}{tsQuery, departments, limit}},
+		"workspace": {`SELECT 'workspace', w.id, '', '', w.workspace_name, d.department_name, ts_rank(to_tsvector('simple', w.workspace_name), to_tsquery('simple', $1))
+			FROM workspaces w
+			JOIN departments d ON d.department_id = w.department_id
+			WHERE w.department_id = ANY($2) AND to_tsvector('simple', w.workspace_name) @@ to_tsquery('simple', $1)
+			ORDER BY 7 DESC LIMIT $3`, []interface{}{tsQuery, departments, limit}},
+		"component": {`SELECT 'component', c.id, c.prefix, '', c.name, w.name, ts_rank(to_tsvector('simple', c.name || ' ' || c.prefix), to_tsquery('simple', $1))
+			FROM components c
+			JOIN warehouses w ON w.id = c.warehouse_id
+			WHERE (c.warehouse_id = ANY($2) OR w.branch_id IN (SELECT branch_id FROM departments WHERE department_id = ANY($3)))
+			AND to_tsvector('simple', c.name || ' ' || c.prefix) @@ to_tsquery('simple', $1)
+			ORDER BY 7 DESC LIMIT $4`, []interface{}{tsQuery, warehouses, departments, limit}},
+		"issue": {`SELECT 'issue', i.id, i.unit_prefix, '', i.issue, i.status::TEXT, ts_rank(to_tsvector('simple', i.issue), to_tsquery('simple', $1))
+			FROM issues i
+			WHERE (i.warehouse_id = ANY($2) OR i.department_id = ANY($3)) AND to_tsvector('simple', i.issue) @@ to_tsquery('simple', $1)
+			ORDER BY 7 DESC LIMIT $4`, []interface{}{tsQuery, warehouses, departments, limit}},
+		"user": {`SELECT * FROM (
+				SELECT 'user', sa.id, '', 'super_admin', sa.name, sa.email, ts_rank(to_tsvector('simple', sa.name || ' ' || sa.email), to_tsquery('simple', $1))
+				FROM super_admin sa
+				WHERE sa.org_id = $2 AND $3 = 'organization' AND to_tsvector('simple', sa.name || ' ' || sa.email) @@ to_tsquery('simple', $1)
+				UNION ALL
+				SELECT 'user', bh.id, '', 'branch_head', bh.name, bh.email, ts_rank(to_tsvector('simple', bh.name || ' ' || bh.email), to_tsquery('simple', $1))
+				FROM branch_head bh
+				WHERE bh.branch_id = ANY($4) AND to_tsvector('simple', bh.name || ' ' || bh.email) @@ to_tsquery('simple', $1)
+				UNION ALL
+				SELECT 'user', dh.id, '', 'department_head', dh.name, dh.email, ts_rank(to_tsvector('simple', dh.name || ' ' || dh.email), to_tsquery('simple', $1))
+				FROM department_head dh
+				WHERE dh.department_id = ANY($5) AND to_tsvector('simple', dh.name || ' ' || dh.email) @@ to_tsquery('simple', $1)
+				UNION ALL
+				SELECT 'user', w.id, '', 'warehouses', w.name, w.email, ts_rank(to_tsvector('simple', w.name || ' ' || w.email), to_tsquery('simple', $1))
+				FROM warehouses w
+				WHERE w.id = ANY($6) AND to_tsvector('simple', w.name || ' ' || w.email) @@ to_tsquery('simple', $1)
+				UNION ALL
+				SELECT 'user', st.id, '', 'staff', st.name, st.user_email, ts_rank(to_tsvector('simple', st.name || ' ' || st.user_email), to_tsquery('simple', $1))
+				FROM staff st
+				WHERE (st.department_id = ANY($5) OR st.warehouse_id = ANY($6)) AND to_tsvector('simple', st.name || ' ' || st.user_email) @@ to_tsquery('simple', $1)
+			) users ORDER BY 7 DESC LIMIT $7`, []interface{}{tsQuery, scope.OrgID, scope.Role, pq.Array(scope.BranchIDs), departments, warehouses, limit}},
+	}
+
+	results := []models.SearchResultModel{}
+
+	for _, kind := range []string{"branch", "department", "workspace", "component", "issue", "user"} {
+		if !types[kind] || tsQuery == "" {
+			continue
+		}
+
+		s := searches[kind]
+		found, err := q.runSearchQuery(s.query, s.args...)
+		if err != nil {
+			log.Printf("error while searching %v: %v", kind, err)
+			return http.StatusInternalServerError, nil, fmt.Errorf("internal server error, please try again later")
+		}
+		results = append(results, found...)
+	}
+
+	if types["unit"] {
+		units, err := q.searchUnits(scope, term, limit)
+		if err != nil {
+			return http.StatusInternalServerError, nil, fmt.Errorf("internal server error, please try again later")
+		}
+		results = append(results, units...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+
+	return http.StatusOK, results, nil
+}
+
+// searchUnits matches units by prefix and id (e.g. "lap-12") or by the start of their serial number or asset tag.
+// Every unit table is filtered on its own so the lower(...) text_pattern_ops indexes serve the prefix match
+func (q *Query) searchUnits(scope models.ScopeModel, term string, limit int) ([]models.SearchResultModel, error) {
+	components, err := q.GetScopeComponents(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(components) == 0 {
+		return []models.SearchResultModel{}, nil
+	}
+
+	term = strings.ToLower(strings.TrimSpace(term))
+
+	prefix, unit_id := "", -1
+	if match := unitCodePattern.FindStringSubmatch(term); match != nil {
+		prefix = match[1]
+		unit_id, _ = strconv.Atoi(match[2])
+	}
+
+	like := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term) + "%"
+
+	args := []interface{}{pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs), term, like, limit}
+
+	parts := make([]string, 0, len(components))
+	for _, component := range components {
+		// only the table of the searched prefix can match the unit id, $6 is bound once it is used
+		id_match := "FALSE"
+		if strings.EqualFold(component.Prefix, prefix) {
+			if len(args) == 5 {
+				args = append(args, unit_id)
+			}
+			id_match = "u.id = $6"
+		}
+
+		parts = append(parts, fmt.Sprintf(`SELECT 'unit', u.id, '%[1]s', '', '%[1]s-' || u.id || ' ' || c.name, COALESCE(u.serial_number, '') || ' ' || COALESCE(u.asset_tag, ''),
+			CASE WHEN %[2]s THEN 1 WHEN lower(u.serial_number) = $3 OR lower(u.asset_tag) = $3 THEN 0.9 ELSE 0.5 END
+		FROM %[1]s_units u
+		JOIN components c ON c.id = u.component_id
+		LEFT JOIN %[1]s_units_assigned a ON a.unit_id = u.id
+		WHERE (u.warehouse_id = ANY($1) OR a.department_id = ANY($2))
+		AND (%[2]s OR lower(u.serial_number) LIKE $4 OR lower(u.asset_tag) LIKE $4)`, component.Prefix, id_match))
+	}
+
+	query := fmt.Sprintf("SELECT * FROM (%s) units ORDER BY 7 DESC LIMIT $5", strings.Join(parts, " UNION ALL "))
+
+	results, err := q.runSearchQuery(query, args...)
+	if err != nil {
+		log.Printf("error while searching units: %v", err)
+		return nil, err
+	}
+
+	return results, nil
+}
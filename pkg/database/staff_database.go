@@ -0,0 +1,285 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
+)
+
+var StaffResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "name",
+	DefaultOrder: "asc",
+	Fields: map[string]queryspec.Field{
+		"id":            {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"name":          {Column: "name", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"email":         {Column: "user_email", Type: queryspec.String, Filterable: true, Sortable: true, Searchable: true},
+		"staff_role":    {Column: "staff_role", Type: queryspec.Enum, Values: []string{"head", "member", "manager", "technician"}, Filterable: true, Sortable: true},
+		"department_id": {Column: "department_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"warehouse_id":  {Column: "warehouse_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"active":        {Column: "active::TEXT", Type: queryspec.Enum, Values: []string{"true", "false"}, Filterable: true},
+		"created_at":    {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+var StaffIssuesResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":                {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"status":            {Column: "status::TEXT", Type: queryspec.Enum, Values: []string{"raised", "accepted", "resolved"}, Filterable: true, Sortable: true},
+		"department_id":     {Column: "department_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"warehouse_id":      {Column: "warehouse_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"assigned_staff_id": {Column: "assigned_staff_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"issue":             {Column: "issue", Type: queryspec.String, Filterable: true, Searchable: true},
+		"created_at":        {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+var StaffActivityResource = queryspec.Resource{
+	IDColumn:     "id",
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	Fields: map[string]queryspec.Field{
+		"id":         {Column: "id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"staff_id":   {Column: "staff_id", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"method":     {Column: "method", Type: queryspec.Enum, Values: []string{"POST", "PUT", "PATCH", "DELETE"}, Filterable: true},
+		"path":       {Column: "path", Type: queryspec.String, Filterable: true, Searchable: true},
+		"status":     {Column: "status", Type: queryspec.Int, Filterable: true, Sortable: true},
+		"created_at": {Column: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	},
+}
+
+// CreateStaff adds a login for a department or a warehouse, the users row carries the role of the unit
+// so the staff member signs in with the same permissions as its primary account
+func (q *Query) CreateStaff(scope models.ScopeModel, staff models.CreateStaffModel, password string, invitation models.NewInvitationModel) (int, int, error) {
+	account_type := "department_head"
+	if staff.DepartmentID != 0 {
+		if !slices.Contains(scope.DepartmentIDs, staff.DepartmentID) {
+			return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+		} else if staff.StaffRole != "head" && staff.StaffRole != "member" {
+			return http.StatusBadRequest, -1, fmt.Errorf("department staff must be a head or a member")
+		}
+	} else {
+		account_type = "warehouses"
+		if !slices.Contains(scope.WarehouseIDs, staff.WarehouseID) {
+			return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+		} else if staff.StaffRole != "manager" && staff.StaffRole != "technician" {
+			return http.StatusBadRequest, -1, fmt.Errorf("warehouse staff must be a manager or a technician")
+		}
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("INSERT INTO users(user_email, user_level) VALUES($1, $2)", staff.Email, account_type); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, -1, fmt.Errorf("email is already in use")
+		}
+		log.Printf("error while creating user %v: %v", staff.Email, err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	var staff_id int
+	if err := tx.QueryRow(`INSERT INTO staff(org_id, user_email, name, password, department_id, warehouse_id, staff_role, created_by_role, created_by)
+		VALUES($1, $2, $3, $4, NULLIF($5, 0), NULLIF($6, 0), $7, $8, $9) RETURNING id`,
+		scope.OrgID, staff.Email, staff.Name, password, staff.DepartmentID, staff.WarehouseID, staff.StaffRole, scope.Role, scope.UserID).Scan(&staff_id); err != nil {
+		log.Printf("error while creating staff %v: %v", staff.Email, err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	if err := createInvitation(tx, staff.Email, invitation); err != nil {
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing staff %v: %v", staff.Email, err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, staff_id, nil
+}
+
+func (q *Query) GetStaff(scope models.ScopeModel, spec queryspec.Spec) (int, []models.StaffModel, models.PageModel, error) {
+	base := `SELECT id, name, user_email, staff_role, department_id, warehouse_id, active, created_by_role, created_by, created_at, deactivated_at
+		FROM staff WHERE department_id = ANY($1) OR warehouse_id = ANY($2)`
+
+	staff := []models.StaffModel{}
+
+	page, err := q.listPage(spec, base, "id, name, user_email, staff_role, COALESCE(department_id, 0), COALESCE(warehouse_id, 0), active, created_by_role, created_by, created_at, deactivated_at",
+		[]interface{}{pq.Array(scope.DepartmentIDs), pq.Array(scope.WarehouseIDs)}, func(rows *sql.Rows, extra ...interface{}) error {
+			var member models.StaffModel
+			dest := []interface{}{&member.StaffID, &member.Name, &member.Email, &member.StaffRole, &member.DepartmentID, &member.WarehouseID, &member.Active,
+				&member.CreatedByRole, &member.CreatedBy, &member.CreatedAt, &member.DeactivatedAt}
+			if err := rows.Scan(append(dest, extra...)...); err != nil {
+				return err
+			}
+			staff = append(staff, member)
+			return nil
+		})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, staff, page, nil
+}
+
+// DeactivateStaff blocks a staff login and hands its open issues back to the warehouse, the account and
+// its activity are kept for the audit trail
+func (q *Query) DeactivateStaff(scope models.ScopeModel, staff_id int) (int, error) {
+	if staff_id == scope.StaffID {
+		return http.StatusBadRequest, fmt.Errorf("you cannot deactivate your own account")
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE staff SET active = FALSE, deactivated_at = NOW()
+		WHERE id = $1 AND active AND (department_id = ANY($2) OR warehouse_id = ANY($3))`, staff_id, pq.Array(scope.DepartmentIDs), pq.Array(scope.WarehouseIDs))
+	if err != nil {
+		log.Printf("error while deactivating staff %v: %v", staff_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		log.Printf("error while deactivating staff %v: %v", staff_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if rows == 0 {
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
+	}
+
+	if _, err := tx.Exec("UPDATE issues SET assigned_staff_id = NULL, assigned_at = NULL WHERE assigned_staff_id = $1 AND status <> 'resolved'", staff_id); err != nil {
+		log.Printf("error while unassigning issues of staff %v: %v", staff_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing staff %v: %v", staff_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+// AssignIssue hands an open issue to a manager or technician of the issue's warehouse, technicians can
+// only take issues themselves
+func (q *Query) AssignIssue(scope models.ScopeModel, assign models.AssignIssueModel) (int, error) {
+	if scope.StaffRole == "technician" && assign.StaffID != scope.StaffID {
+		return http.StatusForbidden, fmt.Errorf("technicians can only assign issues to themselves")
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var warehouse_id int
+	var status string
+	if err := tx.QueryRow("SELECT warehouse_id, status FROM issues WHERE id = $1 FOR UPDATE", assign.IssueID).Scan(&warehouse_id, &status); err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting issue %v: %v", assign.IssueID, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if !slices.Contains(scope.WarehouseIDs, warehouse_id) {
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
+	} else if status == "resolved" {
+		return http.StatusConflict, fmt.Errorf("issue is already resolved")
+	}
+
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM staff WHERE id = $1 AND warehouse_id = $2 AND active)", assign.StaffID, warehouse_id).Scan(&exists); err != nil {
+		log.Printf("error while getting staff %v: %v", assign.StaffID, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if !exists {
+		return http.StatusBadRequest, fmt.Errorf("staff member does not work in the issue's warehouse")
+	}
+
+	if _, err := tx.Exec("UPDATE issues SET assigned_staff_id = $1, assigned_at = NOW() WHERE id = $2", assign.StaffID, assign.IssueID); err != nil {
+		log.Printf("error while assigning issue %v: %v", assign.IssueID, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing issue %v: %v", assign.IssueID, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
+func (q *Query) GetStaffIssues(scope models.ScopeModel, spec queryspec.Spec) (int, []models.StaffIssueModel, models.PageModel, error) {
+	base := `SELECT i.id, i.issue, i.status, i.department_id, i.warehouse_id, i.workspace_id, i.unit_id, i.unit_prefix,
+			COALESCE(i.assigned_staff_id, 0) AS assigned_staff_id, COALESCE(s.name, '') AS assigned_to, i.assigned_at, i.created_at
+		FROM issues i LEFT JOIN staff s ON s.id = i.assigned_staff_id
+		WHERE i.warehouse_id = ANY($1) OR i.department_id = ANY($2)`
+
+	issues := []models.StaffIssueModel{}
+
+	page, err := q.listPage(spec, base, "id, issue, status, department_id, warehouse_id, workspace_id, unit_id, unit_prefix, assigned_staff_id, assigned_to, assigned_at, created_at",
+		[]interface{}{pq.Array(scope.WarehouseIDs), pq.Array(scope.DepartmentIDs)}, func(rows *sql.Rows, extra ...interface{}) error {
+			var issue models.StaffIssueModel
+			dest := []interface{}{&issue.IssueID, &issue.Issue, &issue.Status, &issue.DepartmentID, &issue.WarehouseID, &issue.WorkspaceID, &issue.UnitID, &issue.UnitPrefix,
+				&issue.AssignedStaffID, &issue.AssignedTo, &issue.AssignedAt, &issue.CreatedAt}
+			if err := rows.Scan(append(dest, extra...)...); err != nil {
+				return err
+			}
+			issues = append(issues, issue)
+			return nil
+		})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, issues, page, nil
+}
+
+func (q *Query) RecordStaffActivity(staff_id int, method, path string, status int) error {
+	if len(path) > 200 {
+		path = path[:200]
+	}
+	_, err := q.db.Exec("INSERT INTO staff_activity(staff_id, method, path, status) VALUES($1, $2, $3, $4)", staff_id, method, path, status)
+	return err
+}
+
+func (q *Query) GetStaffActivity(scope models.ScopeModel, spec queryspec.Spec) (int, []models.StaffActivityModel, models.PageModel, error) {
+	base := `SELECT a.id, a.staff_id, s.name AS staff_name, a.method, a.path, a.status, a.created_at
+		FROM staff_activity a JOIN staff s ON s.id = a.staff_id
+		WHERE s.department_id = ANY($1) OR s.warehouse_id = ANY($2)`
+
+	activity := []models.StaffActivityModel{}
+
+	page, err := q.listPage(spec, base, "id, staff_id, staff_name, method, path, status, created_at",
+		[]interface{}{pq.Array(scope.DepartmentIDs), pq.Array(scope.WarehouseIDs)}, func(rows *sql.Rows, extra ...interface{}) error {
+			var entry models.StaffActivityModel
+			dest := []interface{}{&entry.ActivityID, &entry.StaffID, &entry.StaffName, &entry.Method, &entry.Path, &entry.Status, &entry.CreatedAt}
+			if err := rows.Scan(append(dest, extra...)...); err != nil {
+				return err
+			}
+			activity = append(activity, entry)
+			return nil
+		})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	return http.StatusOK, activity, page, nil
+}
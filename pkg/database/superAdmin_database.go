@@ -9,7 +9,7 @@ import (
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
 )
 
-func (q *Query) CreateBranch(branch models.CreateBranchModel, superAdminID int, hashedPassword string) error {
+func (q *Query) CreateBranch(branch models.CreateBranchModel, superAdminID int, hashedPassword string, invitation models.NewInvitationModel) error {
 	var branch_org_id, branch_id int
 	query1 := "SELECT org_id FROM super_admin WHERE id = $1"
 	query2 := "INSERT INTO branches(org_id, super_admin_id, branch_name, branch_location) VALUES($1, $2, $3, $4) RETURNING branch_id"
@@ -47,6 +47,10 @@ func (q *Query) CreateBranch(branch models.CreateBranchModel, superAdminID int,
 		return err
 	}
 
+	if err = createInvitation(tx, branch.BranchHeadEmail, invitation); err != nil {
+		return err
+	}
+
 	return nil
 
 }
@@ -54,31 +58,32 @@ func (q *Query) CreateBranch(branch models.CreateBranchModel, superAdminID int,
 func (q *Query) DeleteBranch(branch models.DeleteBranchModel, superAdminID int) (int, error) {
 	query1 := "CALL delete_branch($1, $2)"
 
-	tx, err := q.db.Begin()
+	tx, err := q.beginDeletion()
 	if err != nil {
 		log.Printf("error while initialising DB: %v", err)
 		return http.StatusInternalServerError, err
 	}
 
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
+	defer tx.Rollback()
+
+	if status, err := q.checkDeletionToken(tx, "branch", branch.BranchID, branch.PreviewToken); err != nil {
+		return status, err
+	}
 
 	if _, err := tx.Exec(query1, branch.BranchID, superAdminID); err != nil {
-		return http.StatusInternalServerError, err
+		return deletionFailed(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return deletionFailed(err)
 	}
 
 	return http.StatusNoContent, nil
 
 }
 
-func (q *Query) UpdateBranchHead(branchHead models.UpdateBranchHeadModel, superAdminID int, password string) (int, error) {
-	query1 := "DELETE FROM branch_head WHERE id =$1 RETURNING branch_id"
+func (q *Query) UpdateBranchHead(branchHead models.UpdateBranchHeadModel, superAdminID int, password string, invitation models.NewInvitationModel) (int, error) {
+	query1 := "DELETE FROM branch_head WHERE id = $1 AND email = $2 RETURNING branch_id"
 	query2 := "DELETE FROM users WHERE user_email = $1"
 	query3 := "INSERT INTO deleted_branch_head(branch_id, branch_head_id, email, deleted_by) VALUES($1, $2, $3, $4)"
 	query4 := "INSERT INTO users(user_email, user_level) VALUES($1, $2)"
@@ -101,7 +106,7 @@ func (q *Query) UpdateBranchHead(branchHead models.UpdateBranchHeadModel, superA
 
 	var branch_id int
 
-	if err = tx.QueryRow(query1, branchHead.BranchHeadID).Scan(&branch_id); err != nil {
+	if err = tx.QueryRow(query1, branchHead.BranchHeadID, branchHead.BranchHeadEmail).Scan(&branch_id); err != nil {
 		if err == sql.ErrNoRows {
 			return http.StatusNotFound, fmt.Errorf("no matching data found")
 		}
@@ -124,6 +129,10 @@ func (q *Query) UpdateBranchHead(branchHead models.UpdateBranchHeadModel, superA
 		return http.StatusInternalServerError, err
 	}
 
+	if err = createInvitation(tx, branchHead.NewBranchHeadEmail, invitation); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
 	return http.StatusOK, nil
 
 }
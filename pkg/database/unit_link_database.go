@@ -0,0 +1,303 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+)
+
+type linkedUnit struct {
+	ComponentID       int
+	Prefix            string
+	UnitID            int
+	ParentComponentID int
+	ParentUnitID      int
+	Relation          string
+}
+
+type unitState struct {
+	Prefix       string
+	Status       string
+	DepartmentID sql.NullInt64
+	WorkspaceID  sql.NullInt64
+}
+
+func getUnitState(tx *sql.Tx, component_id, unit_id, warehouse_id int) (unitState, error) {
+	var state unitState
+	if err := tx.QueryRow("SELECT prefix FROM components WHERE id = $1 AND warehouse_id = $2 AND kind = 'unit'", component_id, warehouse_id).Scan(&state.Prefix); err != nil {
+		return state, err
+	}
+
+	query := fmt.Sprintf(`SELECT u.status::TEXT, a.department_id, a.workspace_id
+		FROM %[1]s_units u
+		LEFT JOIN %[1]s_units_assigned a ON a.unit_id = u.id
+		WHERE u.id = $1 AND u.component_id = $2 AND u.warehouse_id = $3
+		FOR UPDATE OF u`, state.Prefix)
+	err := tx.QueryRow(query, unit_id, component_id, warehouse_id).Scan(&state.Status, &state.DepartmentID, &state.WorkspaceID)
+	return state, err
+}
+
+// getUnitDescendants walks the active links below a unit, parents are always listed before their children
+func getUnitDescendants(tx *sql.Tx, component_id, unit_id int) ([]linkedUnit, error) {
+	query := `WITH RECURSIVE tree AS (
+			SELECT child_component_id, child_unit_id, parent_component_id, parent_unit_id, relation, 1 AS depth
+			FROM unit_links
+			WHERE parent_component_id = $1 AND parent_unit_id = $2 AND unlinked_at IS NULL
+			UNION ALL
+			SELECT l.child_component_id, l.child_unit_id, l.parent_component_id, l.parent_unit_id, l.relation, t.depth + 1
+			FROM unit_links l
+			JOIN tree t ON l.parent_component_id = t.child_component_id AND l.parent_unit_id = t.child_unit_id
+			WHERE l.unlinked_at IS NULL
+		)
+		SELECT t.child_component_id, c.prefix, t.child_unit_id, t.parent_component_id, t.parent_unit_id, t.relation
+		FROM tree t
+		JOIN components c ON c.id = t.child_component_id
+		ORDER BY t.depth`
+
+	rows, err := tx.Query(query, component_id, unit_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var units []linkedUnit
+	for rows.Next() {
+		var unit linkedUnit
+		if err := rows.Scan(&unit.ComponentID, &unit.Prefix, &unit.UnitID, &unit.ParentComponentID, &unit.ParentUnitID, &unit.Relation); err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+
+	return units, rows.Err()
+}
+
+func getUnitParent(tx *sql.Tx, component_id, unit_id int) (*models.UnitRefModel, error) {
+	var parent models.UnitRefModel
+	err := tx.QueryRow(`SELECT l.parent_component_id, c.prefix, l.parent_unit_id
+		FROM unit_links l
+		JOIN components c ON c.id = l.parent_component_id
+		WHERE l.child_component_id = $1 AND l.child_unit_id = $2 AND l.unlinked_at IS NULL`, component_id, unit_id).Scan(&parent.ComponentID, &parent.Prefix, &parent.UnitID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &parent, nil
+}
+
+// moveUnitAssignment assigns a unit to the workspace, recording any previous assignment in deleted_units_assigned
+func moveUnitAssignment(tx *sql.Tx, prefix string, unit_id, department_id, workspace_id, user_id int) error {
+	query1 := fmt.Sprintf(`INSERT INTO deleted_units_assigned(unit_id, department_id, workspace_id, assigned_at, deleted_by)
+		SELECT unit_id, department_id, workspace_id, assigned_at, $2 FROM %s_units_assigned WHERE unit_id = $1 AND workspace_id <> $3`, prefix)
+	query2 := fmt.Sprintf(`INSERT INTO %s_units_assigned(unit_id, department_id, workspace_id) VALUES($1, $2, $3)
+		ON CONFLICT (unit_id) DO UPDATE SET department_id = EXCLUDED.department_id, workspace_id = EXCLUDED.workspace_id, assigned_at = NOW()
+		WHERE %[1]s_units_assigned.workspace_id <> EXCLUDED.workspace_id`, prefix)
+
+	if _, err := tx.Exec(query1, unit_id, user_id, workspace_id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(query2, unit_id, department_id, workspace_id); err != nil {
+		return err
+	}
+	return clearUnitBin(tx, prefix, unit_id)
+}
+
+// unassignUnit returns a unit to its warehouse stock, recording the assignment in deleted_units_assigned
+func unassignUnit(tx *sql.Tx, prefix string, unit_id, user_id int) error {
+	query := fmt.Sprintf(`WITH removed AS (DELETE FROM %s_units_assigned WHERE unit_id = $1 RETURNING unit_id, department_id, workspace_id, assigned_at)
+		INSERT INTO deleted_units_assigned(unit_id, department_id, workspace_id, assigned_at, deleted_by) SELECT unit_id, department_id, workspace_id, assigned_at, $2 FROM removed`, prefix)
+	_, err := tx.Exec(query, unit_id, user_id)
+	return err
+}
+
+// LinkUnits installs the child unit in the parent unit, or adds it to the parent's kit. The child and
+// everything linked below it move to the parent's workspace when the parent is assigned
+func (q *Query) LinkUnits(link models.LinkUnitsModel, warehouse_id int) (int, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	// serialises linking per warehouse so the cycle check below cannot race
+	var locked int
+	if err := tx.QueryRow("SELECT id FROM warehouses WHERE id = $1 FOR UPDATE", warehouse_id).Scan(&locked); err != nil {
+		log.Printf("error while locking warehouse %v: %v", warehouse_id, err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	parent, err := getUnitState(tx, link.ParentComponentID, link.ParentUnitID, warehouse_id)
+	if err == sql.ErrNoRows {
+		return http.StatusNotFound, fmt.Errorf("parent unit not found")
+	} else if err != nil {
+		log.Printf("error while getting parent unit: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	child, err := getUnitState(tx, link.ChildComponentID, link.ChildUnitID, warehouse_id)
+	if err == sql.ErrNoRows {
+		return http.StatusNotFound, fmt.Errorf("child unit not found")
+	} else if err != nil {
+		log.Printf("error while getting child unit: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if existing, err := getUnitParent(tx, link.ChildComponentID, link.ChildUnitID); err != nil {
+		log.Printf("error while getting parent of unit: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if existing != nil {
+		return http.StatusConflict, fmt.Errorf("unit is already linked to %s unit %v", existing.Prefix, existing.UnitID)
+	}
+
+	descendants, err := getUnitDescendants(tx, link.ChildComponentID, link.ChildUnitID)
+	if err != nil {
+		log.Printf("error while getting linked units: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	for _, unit := range descendants {
+		if unit.ComponentID == link.ParentComponentID && unit.UnitID == link.ParentUnitID {
+			return http.StatusConflict, fmt.Errorf("parent unit is already linked below the child unit")
+		}
+	}
+
+	if child.WorkspaceID.Valid && (!parent.WorkspaceID.Valid || parent.WorkspaceID.Int64 != child.WorkspaceID.Int64) {
+		return http.StatusConflict, fmt.Errorf("child unit is assigned to workspace %v", child.WorkspaceID.Int64)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO unit_links(warehouse_id, parent_component_id, parent_unit_id, child_component_id, child_unit_id, relation, linked_by)
+		VALUES($1, $2, $3, $4, $5, $6, $7)`, warehouse_id, link.ParentComponentID, link.ParentUnitID, link.ChildComponentID, link.ChildUnitID, link.Relation, warehouse_id); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, fmt.Errorf("unit is already linked")
+		}
+		log.Printf("error while linking units: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if parent.WorkspaceID.Valid {
+		moved := append([]linkedUnit{{ComponentID: link.ChildComponentID, Prefix: child.Prefix, UnitID: link.ChildUnitID}}, descendants...)
+		for _, unit := range moved {
+			if err := moveUnitAssignment(tx, unit.Prefix, unit.UnitID, int(parent.DepartmentID.Int64), int(parent.WorkspaceID.Int64), warehouse_id); err != nil {
+				log.Printf("error while moving %s unit %v: %v", unit.Prefix, unit.UnitID, err)
+				return http.StatusInternalServerError, fmt.Errorf("database error")
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing unit link: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusCreated, nil
+}
+
+// UnlinkUnit detaches a unit from its parent, it keeps its current assignment
+func (q *Query) UnlinkUnit(component_id, unit_id, warehouse_id int) (int, error) {
+	result, err := q.db.Exec("UPDATE unit_links SET unlinked_at = NOW(), unlinked_by = $4 WHERE child_component_id = $1 AND child_unit_id = $2 AND warehouse_id = $3 AND unlinked_at IS NULL", component_id, unit_id, warehouse_id, warehouse_id)
+	if err != nil {
+		log.Printf("error while unlinking unit: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return http.StatusNotFound, fmt.Errorf("unit is not linked to another unit")
+	}
+
+	return http.StatusOK, nil
+}
+
+// GetUnitComposition returns the unit with everything installed in or kitted with it. open_issues counts
+// the unresolved issues of a unit, subtree_issues adds those of all units below it
+func (q *Query) GetUnitComposition(component_id, unit_id, warehouse_id int) (int, models.UnitCompositionModel, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, models.UnitCompositionModel{}, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	root, err := getUnitState(tx, component_id, unit_id, warehouse_id)
+	if err == sql.ErrNoRows {
+		return http.StatusNotFound, models.UnitCompositionModel{}, fmt.Errorf("no matching data found")
+	} else if err != nil {
+		log.Printf("error while getting unit: %v", err)
+		return http.StatusInternalServerError, models.UnitCompositionModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	parent, err := getUnitParent(tx, component_id, unit_id)
+	if err != nil {
+		log.Printf("error while getting parent of unit: %v", err)
+		return http.StatusInternalServerError, models.UnitCompositionModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	descendants, err := getUnitDescendants(tx, component_id, unit_id)
+	if err != nil {
+		log.Printf("error while getting linked units: %v", err)
+		return http.StatusInternalServerError, models.UnitCompositionModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+
+	node := func(unit linkedUnit, state unitState) (*models.UnitCompositionModel, error) {
+		composition := &models.UnitCompositionModel{
+			ComponentID: unit.ComponentID,
+			Prefix:      unit.Prefix,
+			UnitID:      unit.UnitID,
+			Relation:    unit.Relation,
+			Status:      state.Status,
+			Children:    []*models.UnitCompositionModel{},
+		}
+		if state.WorkspaceID.Valid {
+			workspace_id := int(state.WorkspaceID.Int64)
+			composition.WorkspaceID = &workspace_id
+		}
+		err := tx.QueryRow("SELECT COUNT(*) FROM issues WHERE unit_prefix = $1 AND unit_id = $2 AND status <> 'resolved'", unit.Prefix, unit.UnitID).Scan(&composition.OpenIssues)
+		return composition, err
+	}
+
+	result, err := node(linkedUnit{ComponentID: component_id, Prefix: root.Prefix, UnitID: unit_id}, root)
+	if err != nil {
+		log.Printf("error while counting issues: %v", err)
+		return http.StatusInternalServerError, models.UnitCompositionModel{}, fmt.Errorf("error occured while retrieving data")
+	}
+	result.Parent = parent
+
+	nodes := map[[2]int]*models.UnitCompositionModel{{component_id, unit_id}: result}
+	for _, unit := range descendants {
+		var state unitState
+		query := fmt.Sprintf(`SELECT u.status::TEXT, a.department_id, a.workspace_id
+			FROM %[1]s_units u
+			LEFT JOIN %[1]s_units_assigned a ON a.unit_id = u.id
+			WHERE u.id = $1`, unit.Prefix)
+		if err := tx.QueryRow(query, unit.UnitID).Scan(&state.Status, &state.DepartmentID, &state.WorkspaceID); err != nil {
+			log.Printf("error while getting %s unit %v: %v", unit.Prefix, unit.UnitID, err)
+			return http.StatusInternalServerError, models.UnitCompositionModel{}, fmt.Errorf("error occured while retrieving data")
+		}
+
+		child, err := node(unit, state)
+		if err != nil {
+			log.Printf("error while counting issues: %v", err)
+			return http.StatusInternalServerError, models.UnitCompositionModel{}, fmt.Errorf("error occured while retrieving data")
+		}
+
+		parent := nodes[[2]int{unit.ParentComponentID, unit.ParentUnitID}]
+		parent.Children = append(parent.Children, child)
+		nodes[[2]int{unit.ComponentID, unit.UnitID}] = child
+	}
+
+	var count func(*models.UnitCompositionModel) int
+	count = func(n *models.UnitCompositionModel) int {
+		n.SubtreeIssues = n.OpenIssues
+		for _, child := range n.Children {
+			n.SubtreeIssues += count(child)
+		}
+		return n.SubtreeIssues
+	}
+	count(result)
+
+	return http.StatusOK, *result, nil
+}
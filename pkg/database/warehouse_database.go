@@ -2,33 +2,24 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/lib/pq"
 )
 
 func (q *Query) IfPrefixExists(prefix string) bool {
 	var exists bool
-	q.db.QueryRow("SELECT EXISTS(SELECT 1 FROM components WHERE prefix = $1)", prefix).Scan(&exists)
+	q.db.QueryRow("SELECT EXISTS(SELECT 1 FROM components WHERE lower(prefix) = lower($1))", prefix).Scan(&exists)
 	return exists
 }
-func (q *Query) IfComponentExists(name string, warehouseID int) (bool, error) {
-	var exists bool
-	err := q.db.QueryRow(`
-		SELECT EXISTS(
-			SELECT 1 FROM components WHERE name = $1 AND warehouse_id = $2
-		)
-	`, name, warehouseID).Scan(&exists)
-
-	if err != nil {
-		return false, err
-	}
-	return exists, nil
-}
 
 func (q *Query) CheckIfComponentIDExists(component_id, warehouse_id int) (string, bool, error) {
 	query := "SELECT prefix FROM components WHERE id = $1 AND warehouse_id = $2"
@@ -40,10 +31,85 @@ func (q *Query) CheckIfComponentIDExists(component_id, warehouse_id int) (string
 	return prefix, true, nil
 }
 
-func (q *Query) CreateComponent(name, prefix string, warehouse_id int) (id int, err error) {
-	query1 := "INSERT INTO components(name, prefix, warehouse_id) VALUES($1, $2, $3) RETURNING id"
-	query2 := fmt.Sprintf(`
-	CREATE TABLE IF NOT EXISTS %s_units (
+func (q *Query) GetComponentKind(component_id int) (string, error) {
+	var kind string
+	err := q.db.QueryRow("SELECT kind FROM components WHERE id = $1", component_id).Scan(&kind)
+	return kind, err
+}
+
+// maxPrefixAttempts bounds how many taken prefixes are skipped, maxPrefixRetries how often
+// a prefix claimed by a concurrent request is retried
+const (
+	maxPrefixAttempts = 10000
+	maxPrefixRetries  = 5
+)
+
+var (
+	errComponentExists = errors.New("component already exists")
+	errPrefixTaken     = errors.New("prefix already in use")
+
+	errCatalogueNotFound = errors.New("catalogue model not found")
+	errCatalogueStocked  = errors.New("the warehouse already stocks this model in another component")
+)
+
+// CreateComponent allocates a prefix starting from prefix and creates the component together with
+// its unit tables in a single transaction, next gives the prefix to try when one is taken and is
+// nil when the warehouse chose the prefix itself
+func (q *Query) CreateComponent(name, kind string, warehouse_id, catalogue_id int, prefix string, next func(string) (string, error)) (int, int, string, error) {
+	retries := 0
+
+	for attempt := 0; attempt < maxPrefixAttempts; attempt++ {
+		taken := q.IfPrefixExists(prefix)
+		if !taken {
+			id, err := q.createComponent(name, kind, prefix, warehouse_id, catalogue_id)
+			if err == nil {
+				return http.StatusOK, id, prefix, nil
+			} else if errors.Is(err, errComponentExists) || errors.Is(err, errCatalogueStocked) {
+				return http.StatusConflict, -1, "", err
+			} else if errors.Is(err, errCatalogueNotFound) {
+				return http.StatusNotFound, -1, "", err
+			} else if !isDuplicateError(err) {
+				log.Printf("error while creating component %v with prefix %v: %v", name, prefix, err)
+				return http.StatusInternalServerError, -1, "", fmt.Errorf("unable to create component at the moment, please try again later")
+			} else if retries >= maxPrefixRetries {
+				log.Printf("giving up on component %v after %v prefix conflicts: %v", name, retries, err)
+				return http.StatusConflict, -1, "", fmt.Errorf("unable to allocate a prefix, please try again")
+			}
+			log.Printf("prefix %v was taken concurrently: %v", prefix, err)
+			taken = true
+			retries++
+		}
+
+		if taken && next == nil {
+			return http.StatusConflict, -1, "", errPrefixTaken
+		}
+
+		var err error
+		if prefix, err = next(prefix); err != nil {
+			log.Printf("error while generating prefix: %v", err)
+			return http.StatusInternalServerError, -1, "", fmt.Errorf("failed to generate prefix, please try again later")
+		}
+	}
+
+	return http.StatusInternalServerError, -1, "", fmt.Errorf("failed to generate prefix, please try again later")
+}
+
+func isDuplicateError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code {
+	case "23505", "42P07", "42710":
+		return true
+	}
+	return false
+}
+
+func (q *Query) createComponent(name, kind, prefix string, warehouse_id, catalogue_id int) (int, error) {
+	queries := []string{
+		fmt.Sprintf(`
+	CREATE TABLE %[1]s_units (
 		id SERIAL PRIMARY KEY,
 		component_id INTEGER NOT NULL,
 		warehouse_id INTEGER NOT NULL,
@@ -52,167 +118,93 @@ func (q *Query) CreateComponent(name, prefix string, warehouse_id int) (id int,
 		cost NUMERIC(10, 2) NOT NULL,
 		maintainance_cost NUMERIC(10, 2) DEFAULT 0,
 		last_maintenance_date TIMESTAMPTZ,
-		CONSTRAINT fk_%s_units_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON DELETE CASCADE,
-		CONSTRAINT fk_%s_units_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON DELETE CASCADE
-	)`, prefix, prefix, prefix)
-	// query3 := fmt.Sprintf(`
-	// CREATE TABLE IF NOT EXISTS %s_units_assigned (
-	// 	id INTEGER PRIMARY KEY,
-	// 	department_id INTEGER NOT NULL,
-	// 	workspace_id INTEGER NOT NULL,
-	// 	assigned_at TIMESTAMPTZ DEFAULT NOW(),
-	// 	CONSTRAINT fk_%s_units_department_id FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE CASCADE,
-	// 	CONSTRAINT fk_%s_units_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE,
-	// 	CONSTRAINT fk_%s_units_id FOREIGN KEY (id) REFERENCES %s_units(id) ON DELETE CASCADE
-	// )`, prefix, prefix, prefix, prefix, prefix)
-
-	// query4 := fmt.Sprintf(`
-	// CREATE TABLE IF NOT EXISTS %s_deleted_units (
-	// 	id SERIAL PRIMARY KEY,
-	// 	unit_id INTEGER NOT NULL,
-	// 	warehouse_id INTEGER NOT NULL,
-	// 	deleted_at TIMESTAMPTZ DEFAULT NOW(),
-	// 	deleted_by INTEGER NOT NULL
-	// )`, prefix)
-	if q.db == nil {
-		log.Println("the db is nil")
-	}
-
-	log.Println("the db is not nil")
-
-	log.Println(query1)
-	log.Println(query2)
-
-	tx, err := q.db.Begin()
-	if err != nil {
-		log.Printf("error while initialising DB: %v", err)
-		return -1, err
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
-
-	if err = tx.QueryRow(query1, name, prefix, warehouse_id).Scan(&id); err != nil {
-		log.Println("query1")
-		return -1, err
-	}
-
-	if _, err = tx.Exec(query2); err != nil {
-		log.Println("query2")
-		return -1, err
-	}
-
-	// if _, err = tx.Exec(query3); err != nil {
-	// 	log.Println("query3")
-	// 	return -1, err
-	// }
-	// log.Println("error 3:" + err.Error())
-
-	// if _, err = tx.Exec(query4); err != nil {
-	// 	log.Println("query4")
-	// 	return -1, err
-	// }
-	// log.Println("error 4:" + err.Error())
-
-	return id, nil
-}
-
-func (q *Query) CreateAssignedAndDeleteUnitTable(prefix string) error {
-	// query3 := fmt.Sprintf(`
-	// CREATE TABLE IF NOT EXISTS %s_units_assigned (
-	// 	unit_id INTEGER PRIMARY KEY,
-	// 	department_id INTEGER NOT NULL,
-	// 	workspace_id INTEGER NOT NULL,
-	// 	assigned_at TIMESTAMPTZ DEFAULT NOW(),
-	// 	CONSTRAINT fk_%s_units_department_id FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE CASCADE,
-	// 	CONSTRAINT fk_%s_units_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
-	// )`, prefix, prefix, prefix)
-	// CONSTRAINT fk_%s_units_id FOREIGN KEY (unit_id) REFERENCES %s_units(id) ON DELETE CASCADE
-
-	query3 := fmt.Sprintf(`
-	CREATE TABLE IF NOT EXISTS %s_units_assigned (
+		created_at TIMESTAMPTZ DEFAULT NOW(),
+		serial_number VARCHAR(100),
+		asset_tag VARCHAR(100),
+		attributes JSONB DEFAULT '{}',
+		CONSTRAINT fk_%[1]s_units_component_id FOREIGN KEY (component_id) REFERENCES components(id) ON DELETE CASCADE,
+		CONSTRAINT fk_%[1]s_units_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON DELETE CASCADE
+	)`, prefix),
+		fmt.Sprintf("CREATE INDEX idx_%[1]s_units_serial_number ON %[1]s_units (lower(serial_number) text_pattern_ops)", prefix),
+		fmt.Sprintf("CREATE INDEX idx_%[1]s_units_asset_tag ON %[1]s_units (lower(asset_tag) text_pattern_ops)", prefix),
+		fmt.Sprintf("CREATE INDEX idx_%[1]s_units_attributes ON %[1]s_units USING GIN (attributes jsonb_path_ops)", prefix),
+		fmt.Sprintf(`
+	CREATE TABLE %[1]s_units_assigned (
 		unit_id INTEGER PRIMARY KEY,
 		department_id INTEGER NOT NULL,
 		workspace_id INTEGER NOT NULL,
 		assigned_at TIMESTAMPTZ DEFAULT NOW(),
-		CONSTRAINT fk_%s_units_department_id FOREIGN KEY (department_id) REFERENCES departments(department_id) ON DELETE CASCADE,
-		CONSTRAINT fk_%s_units_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE,
-		CONSTRAINT fk_%s_units_assigned_unit_id FOREIGN KEY (unit_id) REFERENCES %s_units(id) ON DELETE CASCADE
-	)`, prefix, prefix, prefix, prefix, prefix)
-
-	query4 := fmt.Sprintf(`
-	CREATE TABLE IF NOT EXISTS %s_deleted_units (
+		CONSTRAINT fk_%[1]s_units_department_id FOREIGN KEY (department_id) REFERENCES departments(department_id) ON DELETE CASCADE,
+		CONSTRAINT fk_%[1]s_units_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE,
+		CONSTRAINT fk_%[1]s_units_assigned_unit_id FOREIGN KEY (unit_id) REFERENCES %[1]s_units(id) ON DELETE CASCADE
+	)`, prefix),
+		fmt.Sprintf(`
+	CREATE TABLE %s_deleted_units (
 		id SERIAL PRIMARY KEY,
 		unit_id INTEGER NOT NULL,
 		warehouse_id INTEGER NOT NULL,
 		deleted_at TIMESTAMPTZ DEFAULT NOW(),
 		deleted_by INTEGER NOT NULL
-	)`, prefix)
+	)`, prefix),
+		archiveTriggerQuery(prefix + "_units"),
+		archiveTriggerQuery(prefix + "_units_assigned"),
+	}
 
 	tx, err := q.db.Begin()
 	if err != nil {
-		log.Printf("error while initialising DB: %v", err)
-		return err
+		return -1, err
 	}
+	defer tx.Rollback()
 
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
-
-	log.Println(query3)
-	log.Println(query4)
-
-	if _, err = tx.Exec(query3); err != nil {
-		log.Println("query3")
-		return err
+	// serialises component creation per warehouse so the name check below cannot race
+	var locked int
+	if err := tx.QueryRow("SELECT id FROM warehouses WHERE id = $1 FOR UPDATE", warehouse_id).Scan(&locked); err != nil {
+		return -1, err
 	}
 
-	if _, err = tx.Exec(query4); err != nil {
-		log.Println("query4")
-		return err
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM components WHERE name = $1 AND warehouse_id = $2)", name, warehouse_id).Scan(&exists); err != nil {
+		return -1, err
+	} else if exists {
+		return -1, errComponentExists
 	}
 
-	return nil
-}
-
-func (q *Query) UndoCreateComponent(prefix string) {
-	query1 := "DELETE FROM components WHERE prefix = $1"
+	if catalogue_id > 0 {
+		if ok, err := checkCatalogueInOrg(tx, catalogue_id, warehouse_id); err != nil {
+			return -1, err
+		} else if !ok {
+			return -1, errCatalogueNotFound
+		}
 
-	query2 := fmt.Sprintf("DROP TABLE %s_units", prefix)
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM components WHERE catalogue_id = $1 AND warehouse_id = $2)", catalogue_id, warehouse_id).Scan(&exists); err != nil {
+			return -1, err
+		} else if exists {
+			return -1, errCatalogueStocked
+		}
+	}
 
-	tx, err := q.db.Begin()
-	if err != nil {
-		log.Println("error while initialising DB" + err.Error())
+	var id int
+	if err := tx.QueryRow("INSERT INTO components(name, prefix, warehouse_id, catalogue_id, kind) VALUES($1, $2, $3, NULLIF($4, 0), $5) RETURNING id", name, prefix, warehouse_id, catalogue_id, kind).Scan(&id); err != nil {
+		return -1, err
 	}
 
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
+	if kind == "consumable" {
+		if _, err := tx.Exec("INSERT INTO consumable_stock(component_id, warehouse_id) VALUES($1, $2)", id, warehouse_id); err != nil {
+			return -1, err
 		}
-	}()
+	}
 
-	if _, err = tx.Exec(query1, prefix); err != nil {
-		log.Println("query1: " + err.Error())
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			return -1, err
+		}
 	}
 
-	if _, err = tx.Exec(query2); err != nil {
-		log.Println("query2 " + err.Error())
+	if err := tx.Commit(); err != nil {
+		return -1, err
 	}
 
+	return id, nil
 }
 
 func (q *Query) DeleteComponent(del_component models.DeleteComponentModel, warehouse_id int) (int, error) {
@@ -242,8 +234,8 @@ func (q *Query) DeleteComponent(del_component models.DeleteComponentModel, wareh
 	return http.StatusNoContent, nil
 }
 
-func (q *Query) CreateComponentUnit(warranty_date time.Time, cost float32, prifix string, warehouse_id, number, component_id int) (int, error) {
-	query1 := fmt.Sprintf("INSERT INTO %s_units(component_id, warehouse_id, warranty_date, cost) VALUES($1, $2, $3, $4)", prifix)
+func (q *Query) CreateComponentUnit(warranty_date time.Time, cost float32, prifix string, warehouse_id, number, component_id int, attributes []byte) (int, error) {
+	query1 := fmt.Sprintf("INSERT INTO %s_units(component_id, warehouse_id, warranty_date, cost, attributes) VALUES($1, $2, $3, $4, $5)", prifix)
 
 	tx, err := q.db.Begin()
 	if err != nil {
@@ -261,7 +253,7 @@ func (q *Query) CreateComponentUnit(warranty_date time.Time, cost float32, prifi
 	}()
 
 	for range number {
-		_, err = tx.Exec(query1, component_id, warehouse_id, warranty_date, cost)
+		_, err = tx.Exec(query1, component_id, warehouse_id, warranty_date, cost, attributes)
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
@@ -271,7 +263,7 @@ func (q *Query) CreateComponentUnit(warranty_date time.Time, cost float32, prifi
 
 }
 
-func (q *Query) AssignUnitWorkspace(workspace_id int, unit_id []int, prefix string) (int, error) {
+func (q *Query) AssignUnitWorkspace(workspace_id int, component_id int, unit_id []int, prefix string, user_id int) (int, error) {
 	query1 := "SELECT department_id FROM workspaces WHERE id = $1"
 	query2 := fmt.Sprintf("INSERT INTO %s_units_assigned(department_id, workspace_id, unit_id) VALUES($1, $2, $3)", prefix)
 
@@ -302,95 +294,76 @@ func (q *Query) AssignUnitWorkspace(workspace_id int, unit_id []int, prefix stri
 	}
 
 	for _, unit := range unit_id {
+		var parent *models.UnitRefModel
+		if parent, err = getUnitParent(tx, component_id, unit); err != nil {
+			log.Printf("error while getting parent of unit: %v", err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		} else if parent != nil {
+			err = fmt.Errorf("unit %v is linked to %s unit %v, assign that unit instead", unit, parent.Prefix, parent.UnitID)
+			return http.StatusBadRequest, err
+		}
+
+		var on_loan bool
+		if err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM loan_units WHERE component_id = $1 AND unit_id = $2 AND returned_at IS NULL)", component_id, unit).Scan(&on_loan); err != nil {
+			log.Printf("error while checking loans of unit: %v", err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		} else if on_loan {
+			err = fmt.Errorf("unit %v is out on loan", unit)
+			return http.StatusConflict, err
+		}
+
 		if _, err = tx.Exec(query2, department_id, workspace_id, unit); err != nil {
 			log.Printf("error while assigning units: %v", err)
 			return http.StatusInternalServerError, fmt.Errorf("database error")
 		}
+		if err = clearUnitBin(tx, prefix, unit); err != nil {
+			log.Printf("error while removing unit from its bin: %v", err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		}
+
+		// linked units travel with the unit they are installed in or kitted with
+		var linked []linkedUnit
+		if linked, err = getUnitDescendants(tx, component_id, unit); err != nil {
+			log.Printf("error while getting linked units: %v", err)
+			return http.StatusInternalServerError, fmt.Errorf("database error")
+		}
+		for _, child := range linked {
+			if err = moveUnitAssignment(tx, child.Prefix, child.UnitID, department_id, workspace_id, user_id); err != nil {
+				log.Printf("error while assigning %s unit %v: %v", child.Prefix, child.UnitID, err)
+				return http.StatusInternalServerError, fmt.Errorf("database error")
+			}
+		}
 	}
 
 	return http.StatusOK, nil
 
 }
 
-func (q *Query) GetAllIssues(id int, sort models.SortModel) (int, []models.IssueModel, int, error) {
-	var issues []models.IssueModel
-
-	tx, err := q.db.Begin()
-	if err != nil {
-		log.Printf("error while initialising DB: %v", err)
-		return http.StatusInternalServerError, []models.IssueModel{}, 0, fmt.Errorf("database error")
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
-
-	args := []interface{}{sort.Limit, sort.Offset}
-	argIndex := 3
-	whereClause := fmt.Sprintf("WHERE workspace_id = %d ", id)
-
-	if sort.Search != "" {
-		var id int
-		id, err = strconv.Atoi(sort.Search)
-		if err == nil {
-			whereClause += fmt.Sprintf("AND (id = $%d OR department_id = $%d OR workspace_id = $%d OR unit_id = $%d OR created_at = $%d) ", argIndex, argIndex, argIndex, argIndex, argIndex)
-			args = append(args, id)
-		} else {
-			whereClause += fmt.Sprintf("AND (unit_prefix ILIKE $%d OR issue ILIKE $%d OR status ILIKE $%d) ", argIndex, argIndex, argIndex)
-			args = append(args, "%"+sort.Search+"%")
-		}
-	}
+func (q *Query) GetAllIssues(warehouse_id int, spec queryspec.Spec) (int, []models.IssueModel, models.PageModel, error) {
+	base := `SELECT id, department_id, warehouse_id, workspace_id, unit_id, unit_prefix, issue, created_at, status
+	FROM issues
+	WHERE warehouse_id = $1`
 
-	query := fmt.Sprintf(`SELECT id, department_id, workspace_id, unit_id, unit_prefix, issue, created_at, status FROM issues
-							%s
-							ORDER BY %s %s 
-							LIMIT $1 OFFSET $2
-							`, whereClause, sort.SortBy, sort.Order)
+	issues := []models.IssueModel{}
 
-	var rows *sql.Rows
-	rows, err = tx.Query(query, args...)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no matching data found : %v", err)
-			return http.StatusNotFound, []models.IssueModel{}, 0, fmt.Errorf("no matching data found")
-		}
-		log.Printf("error while querying data: %v", err)
-		return http.StatusInternalServerError, []models.IssueModel{}, 0, fmt.Errorf("error occured while retrieving data")
-	}
-	defer rows.Close()
-
-	for rows.Next() {
+	page, err := q.listPage(spec, base, "id, department_id, warehouse_id, workspace_id, unit_id, unit_prefix, issue, created_at, status", []interface{}{warehouse_id}, func(rows *sql.Rows, extra ...interface{}) error {
 		var issue models.IssueModel
-		if err = rows.Scan(&issue.IssueID, &issue.DepartmentID, &issue.WorkspaceID, &issue.UnitID, &issue.UnitPrefix, &issue.Issue, &issue.Created_at, &issue.Status); err != nil {
-			log.Printf("error while scanning data: %v", err)
-			return http.StatusInternalServerError, []models.IssueModel{}, 0, fmt.Errorf("error occured while retrieving data")
+		if err := rows.Scan(append([]interface{}{&issue.IssueID, &issue.DepartmentID, &issue.WarehouseID, &issue.WorkspaceID, &issue.UnitID, &issue.UnitPrefix, &issue.Issue, &issue.Created_at, &issue.Status}, extra...)...); err != nil {
+			return err
 		}
 		issues = append(issues, issue)
+		return nil
+	})
+	if err != nil {
+		log.Printf("error while getting issues of warehouse %v: %v", warehouse_id, err)
+		return http.StatusInternalServerError, []models.IssueModel{}, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
 	}
 
-	if err = rows.Err(); err != nil {
-		log.Printf("row iteration error: %v", err)
-		return http.StatusInternalServerError, []models.IssueModel{}, 0, fmt.Errorf("internal server error, please try again later")
-	}
-
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM issues %s`, whereClause)
-	var total int
-	if err = tx.QueryRow(countQuery).Scan(&total); err != nil {
-		log.Printf("error while scanning data: %v", err)
-		return http.StatusInternalServerError, []models.IssueModel{}, 0, fmt.Errorf("error occured while retrieving data")
-	}
-
-	return http.StatusOK, issues, total, nil
-
+	return http.StatusOK, issues, page, nil
 }
 
 func (q *Query) GetAllWarehouseComponents(warehouse_id int) ([]models.AllWarehouseComponentsModel, error) {
-	query := "SELECT id, name, prefix FROM components WHERE warehouse_id = $1"
+	query := "SELECT c.id, c.name, c.prefix, c.kind, COALESCE(cs.quantity, 0) FROM components c LEFT JOIN consumable_stock cs ON cs.component_id = c.id WHERE c.warehouse_id = $1"
 	var components []models.AllWarehouseComponentsModel
 	tx, err := q.db.Begin()
 	if err != nil {
@@ -425,11 +398,18 @@ func (q *Query) GetAllWarehouseComponents(warehouse_id int) ([]models.AllWarehou
 			&component.ComponentID,
 			&component.ComponentName,
 			&component.Prefix,
+			&component.Kind,
+			&component.Quantity,
 		); err != nil {
 			log.Printf("error while scanning data: %v", err)
 			return nil, fmt.Errorf("error occured while retrieving data")
 		}
 
+		if component.Kind == "consumable" {
+			components = append(components, component)
+			continue
+		}
+
 		Query := fmt.Sprintf(`SELECT COUNT(*) FROM %s_units WHERE component_id = $1`, component.Prefix)
 
 		var units int
@@ -446,74 +426,33 @@ func (q *Query) GetAllWarehouseComponents(warehouse_id int) ([]models.AllWarehou
 	return components, nil
 }
 
-func (q *Query) GetAllWarehouseComponentUnits(component_id int) ([]models.AllComponentUnitsModel, error) {
-	query1 := "SELECT prefix FROM components WHERE id = $1"
+func (q *Query) GetAllWarehouseComponentUnits(prefix string, component_id int, spec queryspec.Spec) (int, []models.AllComponentUnitsModel, models.PageModel, error) {
+	base := fmt.Sprintf(`SELECT u.id, u.warehouse_id, u.warranty_date, u.status::TEXT AS status, u.cost, u.maintainance_cost, u.created_at,
+			u.serial_number, u.asset_tag, COALESCE(u.attributes, '{}') AS attributes, a.workspace_id, a.unit_id IS NOT NULL AS assigned
+		FROM %[1]s_units u
+		LEFT JOIN %[1]s_units_assigned a ON a.unit_id = u.id
+		WHERE u.component_id = $1`, prefix)
 
-	var units []models.AllComponentUnitsModel
+	units := []models.AllComponentUnitsModel{}
 
-	tx, err := q.db.Begin()
-	if err != nil {
-		log.Printf("error while initialising DB: %v", err)
-		return nil, fmt.Errorf("database error")
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-			log.Println("Initialised Database")
-		}
-	}()
-
-	var prefix string
-
-	err = tx.QueryRow(query1, component_id).Scan(&prefix)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no components found for component id %v", component_id)
-			return nil, fmt.Errorf("no components found")
-		}
-		log.Printf("error while querying data: %v", err)
-		return nil, fmt.Errorf("error occured while retrieving data")
-	}
-
-	query2 := fmt.Sprintf(`SELECT id, warehouse_id, warranty_date, status, cost, maintainance_cost FROM %s_units WHERE component_id = $1`, prefix)
-	var rows *sql.Rows
-	rows, err = tx.Query(query2, component_id)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no units found for component id %v", component_id)
-			return nil, fmt.Errorf("no units found")
-		}
-		log.Printf("error while querying data: %v", err)
-		return nil, fmt.Errorf("error occured while retrieving data")
-	}
-	defer rows.Close()
-
-	for rows.Next() {
+	page, err := q.listPage(spec, base, "id, warehouse_id, warranty_date, status, cost, maintainance_cost, COALESCE(serial_number, ''), COALESCE(asset_tag, ''), attributes, assigned", []interface{}{component_id}, func(rows *sql.Rows, extra ...interface{}) error {
 		var unit models.AllComponentUnitsModel
-		if err = rows.Scan(&unit.UnitID, &unit.WarehouseID, &unit.WarrantyDate, &unit.Status, &unit.Cost, &unit.MaintenanceCost); err != nil {
-			log.Printf("error while scanning data: %v", err)
-			return nil, fmt.Errorf("error occured while retrieving data")
+		var attributes []byte
+		dest := []interface{}{&unit.UnitID, &unit.WarehouseID, &unit.WarrantyDate, &unit.Status, &unit.Cost, &unit.MaintenanceCost, &unit.SerialNumber, &unit.AssetTag, &attributes, &unit.Assigned}
+		if err := rows.Scan(append(dest, extra...)...); err != nil {
+			return err
 		}
-		query3 := fmt.Sprintf(`SELECT COUNT(*) FROM %s_units_assigned WHERE unit_id = $1`, prefix)
-
-		var units_assigned int
-
-		if err = tx.QueryRow(query3, unit.UnitID).Scan(&units_assigned); err != nil {
-			log.Printf("error while scanning data: %v", err)
-			return nil, fmt.Errorf("error occured while retrieving data")
-		}
-		if units_assigned > 0 {
-			unit.Assigned = true
-		} else {
-			unit.Assigned = false
+		if err := json.Unmarshal(attributes, &unit.Attributes); err != nil {
+			return err
 		}
 		units = append(units, unit)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, models.PageModel{}, fmt.Errorf("error occured while retrieving data")
 	}
 
-	return units, nil
+	return http.StatusOK, units, page, nil
 }
 
 func (q *Query) GetIssueDetails(issue_id int) (models.IssueDetailsModel, error) {
@@ -594,33 +533,171 @@ func (q *Query) GetUnitAssignmentHistory(unit_id int) ([]models.HistoryModel, er
 	return History, nil
 }
 
-func (q *Query) UpdateIssueStatus(issue_id int, status string) (int, error) {
-	query := "UPDATE issues SET status = $1 WHERE id = $2"
+func (q *Query) UpdateIssueStatus(issue_id, warehouse_id int, status string) (int, error) {
+	query := "UPDATE issues SET status = $1 WHERE id = $2 AND warehouse_id = $3"
 
-	if _, err := q.db.Exec(query, status, issue_id); err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("no matching data found")
-			return http.StatusNotFound, fmt.Errorf("no matching data found")
-		}
+	result, err := q.db.Exec(query, status, issue_id, warehouse_id)
+	if err != nil {
+		log.Printf("error while updating issue status: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
 		log.Printf("error while updating issue status: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if n == 0 {
+		log.Printf("no matching data found")
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
 	}
 
 	return http.StatusOK, nil
 }
 
-func (q *Query) UpdateComponentName(component_id int, component_name string) (int, error) {
-	query := "UPDATE components SET name = $1 WHERE id = $2"
+// RenameComponentPrefix moves a component to a new prefix, renaming its unit tables with their
+// sequences, indexes, constraints and triggers and rewriting every record that refers to the old prefix
+func (q *Query) RenameComponentPrefix(component_id, warehouse_id int, prefix string) (int, string, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		log.Printf("error while initialising DB: %v", err)
+		return http.StatusInternalServerError, "", fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
 
-	if _, err := q.db.Exec(query, component_name, component_id); err != nil {
+	var name, old string
+	if err := tx.QueryRow("SELECT name, prefix FROM components WHERE id = $1 AND warehouse_id = $2 FOR UPDATE", component_id, warehouse_id).Scan(&name, &old); err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("no matching data found")
-			return http.StatusNotFound, fmt.Errorf("no matching data found")
+			return http.StatusNotFound, "", fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while locking component %v: %v", component_id, err)
+		return http.StatusInternalServerError, "", fmt.Errorf("database error")
+	}
+
+	old = strings.ToLower(old)
+	if old == prefix {
+		return http.StatusOK, name, nil
+	}
+
+	var at time.Time
+	if err := tx.QueryRow("UPDATE components SET prefix = $1 WHERE id = $2 RETURNING a_at", prefix, component_id).Scan(&at); err != nil {
+		if isDuplicateError(err) {
+			return http.StatusConflict, "", errPrefixTaken
+		}
+		log.Printf("error while updating component prefix: %v", err)
+		return http.StatusInternalServerError, "", fmt.Errorf("database error")
+	}
+
+	renames := []struct {
+		kind string
+		name string
+	}{
+		{"TABLE", "%s_units"},
+		{"SEQUENCE", "%s_units_id_seq"},
+		{"INDEX", "%s_units_pkey"},
+		{"INDEX", "idx_%s_units_serial_number"},
+		{"INDEX", "idx_%s_units_asset_tag"},
+		{"INDEX", "idx_%s_units_attributes"},
+		{"TABLE", "%s_units_assigned"},
+		{"INDEX", "%s_units_assigned_pkey"},
+		{"TABLE", "%s_deleted_units"},
+		{"SEQUENCE", "%s_deleted_units_id_seq"},
+		{"INDEX", "%s_deleted_units_pkey"},
+	}
+
+	for _, rename := range renames {
+		query := fmt.Sprintf("ALTER %s IF EXISTS %s RENAME TO %s", rename.kind, pq.QuoteIdentifier(fmt.Sprintf(rename.name, old)), pq.QuoteIdentifier(fmt.Sprintf(rename.name, prefix)))
+		if _, err := tx.Exec(query); err != nil {
+			if isDuplicateError(err) {
+				return http.StatusConflict, "", errPrefixTaken
+			}
+			log.Printf("error while renaming %v: %v", fmt.Sprintf(rename.name, old), err)
+			return http.StatusInternalServerError, "", fmt.Errorf("database error")
+		}
+	}
+
+	constraints := map[string][]string{
+		"%s_units":          {"fk_%s_units_component_id", "fk_%s_units_warehouse_id"},
+		"%s_units_assigned": {"fk_%s_units_department_id", "fk_%s_units_workspace_id", "fk_%s_units_assigned_unit_id"},
+	}
+
+	for table, names := range constraints {
+		table = fmt.Sprintf(table, prefix)
+		for _, constraint := range names {
+			var exists bool
+			if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_constraint WHERE conrelid = to_regclass($1) AND conname = $2)", table, fmt.Sprintf(constraint, old)).Scan(&exists); err != nil {
+				log.Printf("error while checking constraint %v: %v", fmt.Sprintf(constraint, old), err)
+				return http.StatusInternalServerError, "", fmt.Errorf("database error")
+			} else if !exists {
+				continue
+			}
+
+			query := fmt.Sprintf("ALTER TABLE %s RENAME CONSTRAINT %s TO %s", pq.QuoteIdentifier(table), pq.QuoteIdentifier(fmt.Sprintf(constraint, old)), pq.QuoteIdentifier(fmt.Sprintf(constraint, prefix)))
+			if _, err := tx.Exec(query); err != nil {
+				log.Printf("error while renaming constraint %v: %v", fmt.Sprintf(constraint, old), err)
+				return http.StatusInternalServerError, "", fmt.Errorf("database error")
+			}
+		}
+	}
+
+	for _, table := range []string{"_units", "_units_assigned"} {
+		query := fmt.Sprintf("DROP TRIGGER IF EXISTS archive_%[1]s%[3]s ON %[2]s%[3]s; ", old, prefix, table) + archiveTriggerQuery(prefix+table)
+		if _, err := tx.Exec(query); err != nil {
+			log.Printf("error while moving archive trigger of %v: %v", prefix+table, err)
+			return http.StatusInternalServerError, "", fmt.Errorf("database error")
+		}
+	}
+
+	updates := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"UPDATE issues SET unit_prefix = $1 WHERE lower(unit_prefix) = $2", []interface{}{prefix, old}},
+		{"UPDATE deleted_issues SET unit_prefix = $1 WHERE lower(unit_prefix) = $2 AND created_at >= $3", []interface{}{prefix, old, at}},
+		{"UPDATE requests SET prefix = $1 WHERE component_id = $2", []interface{}{prefix, component_id}},
+		{"UPDATE deleted_units SET unit_prefix = $1 WHERE component_id = $2", []interface{}{prefix, component_id}},
+		{"UPDATE audit_sightings SET prefix = $1 WHERE component_id = $2", []interface{}{prefix, component_id}},
+		{"UPDATE audit_discrepancies SET prefix = $1 WHERE component_id = $2", []interface{}{prefix, component_id}},
+		{"UPDATE unit_custody SET prefix = $1 WHERE component_id = $2", []interface{}{prefix, component_id}},
+		{"UPDATE staff_checklist_items SET prefix = $1 WHERE component_id = $2", []interface{}{prefix, component_id}},
+		{"UPDATE loans SET prefix = $1 WHERE component_id = $2", []interface{}{prefix, component_id}},
+		{"UPDATE loan_units SET prefix = $1 WHERE component_id = $2", []interface{}{prefix, component_id}},
+		{"UPDATE unit_bins SET prefix = $1 WHERE component_id = $2", []interface{}{prefix, component_id}},
+		{"UPDATE archived_rows SET table_name = $1 || substr(table_name, length($2) + 1) WHERE table_name IN ($2 || '_units', $2 || '_units_assigned') AND restored_at IS NULL", []interface{}{prefix, old}},
+		{"UPDATE archived_rows SET data = jsonb_set(data, '{unit_prefix}', to_jsonb($1::text)) WHERE table_name = 'issues' AND lower(data->>'unit_prefix') = $2 AND restored_at IS NULL", []interface{}{prefix, old}},
+		{"UPDATE archived_rows SET data = jsonb_set(data, '{prefix}', to_jsonb($1::text)) WHERE table_name = 'requests' AND (data->>'component_id')::int = $2 AND restored_at IS NULL", []interface{}{prefix, component_id}},
+	}
+
+	for _, update := range updates {
+		if _, err := tx.Exec(update.query, update.args...); err != nil {
+			log.Printf("error while updating records for prefix %v: %v", prefix, err)
+			return http.StatusInternalServerError, "", fmt.Errorf("database error")
 		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("error while committing prefix change: %v", err)
+		return http.StatusInternalServerError, "", fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, name, nil
+}
+
+func (q *Query) UpdateComponentName(component_id, warehouse_id int, component_name string) (int, error) {
+	query := "UPDATE components SET name = $1 WHERE id = $2 AND warehouse_id = $3"
+
+	result, err := q.db.Exec(query, component_name, component_id, warehouse_id)
+	if err != nil {
 		log.Printf("error while updating component name: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
 	}
 
+	if n, err := result.RowsAffected(); err != nil {
+		log.Printf("error while updating component name: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if n == 0 {
+		log.Printf("no matching data found")
+		return http.StatusNotFound, fmt.Errorf("no matching data found")
+	}
+
 	return http.StatusOK, nil
 }
 
@@ -686,7 +763,7 @@ func (q *Query) GetAssignedUnits(prefix string, workspace_id, limit, offset int)
 }
 
 func (q *Query) UpdateMaintenanceCost(unit_id int, prefix string, cost float32) (int, error) {
-	query := fmt.Sprintf("UPDATE %s_units SET maintainance_cost = $1 WHERE id = $2", prefix)
+	query := fmt.Sprintf("UPDATE %s_units SET maintainance_cost = $1, last_maintenance_date = NOW() WHERE id = $2", prefix)
 
 	if _, err := q.db.Exec(query, cost, unit_id); err != nil {
 		if err == sql.ErrNoRows {
@@ -715,6 +792,17 @@ func (q *Query) UpdateUnitStatus(unit_id int, prefix string, status string) (int
 	return http.StatusOK, nil
 }
 
+func (q *Query) UpdateUnitIdentifiers(unit_id int, prefix string, serial_number, asset_tag string) (int, error) {
+	query := fmt.Sprintf("UPDATE %s_units SET serial_number = NULLIF($1, ''), asset_tag = NULLIF($2, '') WHERE id = $3", prefix)
+
+	if _, err := q.db.Exec(query, serial_number, asset_tag, unit_id); err != nil {
+		log.Printf("error while updating unit identifiers: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	return http.StatusOK, nil
+}
+
 func (q *Query) DeleteUnit(unit_id int, prefix string, user_id int) (int, error) {
 	query1 := fmt.Sprintf("DELETE FROM %s_units WHERE id = $1 AND warehouse_id = $2", prefix)
 	query2 := fmt.Sprintf("INSERT INTO %s_deleted_units(unit_id, warehouse_id, deleted_by) VALUES($1, $2, $3)", prefix)
@@ -734,6 +822,30 @@ func (q *Query) DeleteUnit(unit_id int, prefix string, user_id int) (int, error)
 		}
 	}()
 
+	// units installed in or kitted with the deleted unit stay behind on their own
+	query3 := fmt.Sprintf(`UPDATE unit_links SET unlinked_at = NOW(), unlinked_by = $2
+		WHERE unlinked_at IS NULL AND EXISTS (
+			SELECT 1 FROM %s_units u WHERE u.id = $1
+			AND ((parent_component_id = u.component_id AND parent_unit_id = u.id) OR (child_component_id = u.component_id AND child_unit_id = u.id))
+		)`, prefix)
+	if _, err = tx.Exec(query3, unit_id, user_id); err != nil {
+		log.Printf("error while unlinking unit: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	query4 := fmt.Sprintf(`UPDATE unit_custody SET returned_at = NOW(), returned_by_role = 'warehouses', returned_by = $2
+		WHERE returned_at IS NULL AND unit_id = $1 AND component_id = (SELECT component_id FROM %s_units WHERE id = $1)`, prefix)
+	if _, err = tx.Exec(query4, unit_id, user_id); err != nil {
+		log.Printf("error while closing custody of unit: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	query5 := fmt.Sprintf("DELETE FROM unit_bins WHERE unit_id = $1 AND component_id = (SELECT component_id FROM %s_units WHERE id = $1)", prefix)
+	if _, err = tx.Exec(query5, unit_id); err != nil {
+		log.Printf("error while removing unit from its bin: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
 	if _, err = tx.Exec(query1, unit_id, user_id); err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("no matching data found")
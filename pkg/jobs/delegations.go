@@ -0,0 +1,20 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+)
+
+// DelegationExpiryJob stamps delegations whose period ended so the audit trail shows when they lapsed
+func DelegationExpiryJob() Job {
+	return Job{
+		Name:     "delegation_expiry",
+		Interval: 15 * time.Minute,
+		Run: func(ctx context.Context, query *database.Query) (map[string]int64, error) {
+			count, err := query.ExpireDelegations()
+			return map[string]int64{"delegations_expired": count}, err
+		},
+	}
+}
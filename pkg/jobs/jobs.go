@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+)
+
+// Job is a unit of background work, Run reports the rows it affected per table
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context, query *database.Query) (map[string]int64, error)
+}
+
+// Runner schedules jobs in process and records every run in job_runs
+type Runner struct {
+	db   *sql.DB
+	jobs []Job
+	mu   sync.Mutex
+}
+
+func NewRunner(db *sql.DB) *Runner {
+	return &Runner{
+		db:   db,
+		jobs: []Job{RetentionJob(), OtpCleanupJob(), LoanOverdueJob(), DelegationExpiryJob()},
+	}
+}
+
+// Start runs every job on its own interval until ctx is cancelled
+func (r *Runner) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		go func(job Job) {
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+
+			for {
+				if _, err := r.run(ctx, job, "schedule"); err != nil {
+					log.Printf("job %v failed: %v", job.Name, err)
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(job)
+	}
+}
+
+// RunNow runs the named job, or every job when name is empty, and returns the reports
+func (r *Runner) RunNow(ctx context.Context, name, trigger string) ([]models.JobRunModel, error) {
+	runs := []models.JobRunModel{}
+
+	for _, job := range r.jobs {
+		if name != "" && job.Name != name {
+			continue
+		}
+		run, _ := r.run(ctx, job, trigger)
+		runs = append(runs, run)
+	}
+
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("unknown job %v", name)
+	}
+
+	return runs, nil
+}
+
+func (r *Runner) run(ctx context.Context, job Job, trigger string) (models.JobRunModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	query := database.NewDBinstance(r.db)
+
+	run := models.JobRunModel{Job: job.Name, Trigger: trigger, StartedAt: time.Now().UTC()}
+
+	purged, err := job.Run(ctx, query)
+	run.FinishedAt = time.Now().UTC()
+	run.Purged = purged
+	if run.Purged == nil {
+		run.Purged = map[string]int64{}
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	if id, serr := query.SaveJobRun(run); serr != nil {
+		log.Printf("error while recording run of %v: %v", job.Name, serr)
+	} else {
+		run.ID = id
+	}
+
+	return run, err
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/utils"
+)
+
+// loanReminderInterval is how often a custodian is reminded about the same overdue loan
+const loanReminderInterval = 24 * time.Hour
+
+func LoanOverdueJob() Job {
+	return Job{
+		Name:     "loan_overdue",
+		Interval: time.Hour,
+		Run:      runLoanOverdue,
+	}
+}
+
+// runLoanOverdue flags loans past their due date and emails their custodians, a loan is only marked
+// reminded once the email went out so a failed send is retried on the next run
+func runLoanOverdue(ctx context.Context, query *database.Query) (map[string]int64, error) {
+	report := map[string]int64{}
+
+	flagged, err := query.FlagOverdueLoans()
+	if err != nil {
+		return report, err
+	}
+	report["loans_overdue"] = flagged
+
+	reminders, err := query.GetLoansToRemind(loanReminderInterval)
+	if err != nil {
+		return report, err
+	}
+
+	for _, reminder := range reminders {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		equipment := reminder.ComponentName
+		if reminder.Quantity > 1 {
+			equipment = fmt.Sprintf("%v x %s", reminder.Quantity, reminder.ComponentName)
+		}
+
+		if err := utils.SendLoanOverdueReminder(reminder.CustodianEmail, reminder.CustodianName, equipment, reminder.DueAt); err != nil {
+			log.Printf("error while sending overdue reminder of loan %v to %v: %v", reminder.LoanID, reminder.CustodianEmail, err)
+			continue
+		}
+
+		if err := query.MarkLoanReminded(reminder.LoanID); err != nil {
+			return report, err
+		}
+		report["loan_reminders"]++
+	}
+
+	return report, nil
+}
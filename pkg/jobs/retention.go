@@ -0,0 +1,89 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/utils"
+)
+
+// Policy purges rows of Table whose Column is older than the retention configured
+// with RETENTION_DAYS_<TABLE>, Days is used when the variable is not set and 0 keeps rows
+type Policy struct {
+	Table  string
+	Column string
+	Days   int
+}
+
+const defaultDeletedUnitsDays = 30
+
+func Policies() []Policy {
+	return []Policy{
+		{"archived_rows", "archived_at", utils.ArchiveRetentionDays()},
+		{"deleted_units", "deleted_at", defaultDeletedUnitsDays},
+		{"deleted_units_assigned", "deleted_at", 0},
+		{"deleted_users", "deleted_at", 0},
+		{"deleted_department_head", "deleted_at", 0},
+		{"deleted_departments", "deleted_at", 0},
+		{"deleted_workpaces", "deleted_at", 0},
+		{"deleted_components", "deleted_at", 0},
+		{"deleted_issues", "deleted_at", 0},
+		{"job_runs", "finished_at", 90},
+	}
+}
+
+func RetentionJob() Job {
+	return Job{
+		Name:     "retention",
+		Interval: 6 * time.Hour,
+		Run:      runRetention,
+	}
+}
+
+func runRetention(ctx context.Context, query *database.Query) (map[string]int64, error) {
+	purged := map[string]int64{}
+
+	policies := Policies()
+
+	tables, err := query.GetTablesWithSuffix("_deleted_units")
+	if err != nil {
+		return purged, err
+	}
+	for _, table := range tables {
+		policies = append(policies, Policy{table, "deleted_at", utils.RetentionDays("deleted_units", defaultDeletedUnitsDays)})
+	}
+
+	for _, policy := range policies {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+
+		days := utils.RetentionDays(policy.Table, policy.Days)
+		if days == 0 {
+			continue
+		}
+
+		count, err := query.PurgeOlderThan(policy.Table, policy.Column, days)
+		if err != nil {
+			return purged, err
+		}
+		purged[policy.Table] = count
+	}
+
+	log.Printf("retention purged %v", purged)
+
+	return purged, nil
+}
+
+func OtpCleanupJob() Job {
+	return Job{
+		Name:     "otp_cleanup",
+		Interval: 10 * time.Minute,
+		Run: func(ctx context.Context, query *database.Query) (map[string]int64, error) {
+			count, err := query.PurgeExpiredOtps(utils.OtpValidity)
+			return map[string]int64{"otps": count}, err
+		},
+	}
+}
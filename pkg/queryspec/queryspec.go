@@ -0,0 +1,379 @@
+package queryspec
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+)
+
+type FieldType int
+
+const (
+	String FieldType = iota
+	Int
+	Time
+	Enum
+	Number
+)
+
+// Field maps a public query parameter to a column, only declared fields can be filtered, sorted or searched
+type Field struct {
+	Column     string
+	Type       FieldType
+	Values     []string
+	Filterable bool
+	Sortable   bool
+	Searchable bool
+	// JSONColumn and JSONKey mark a field read from a key of a JSONB column, equality filters then use
+	// containment so a GIN jsonb_path_ops index on the column applies
+	JSONColumn string
+	JSONKey    string
+}
+
+type Resource struct {
+	Fields       map[string]Field
+	IDColumn     string
+	DefaultSort  string
+	DefaultOrder string
+	MaxLimit     int
+}
+
+type Filter struct {
+	Field  Field
+	Op     string
+	Values []interface{}
+}
+
+// Cursor is the sort value and id of the last row of a page, Null is set when the sort value was NULL
+type Cursor struct {
+	Value string `json:"v"`
+	Null  bool   `json:"n,omitempty"`
+	ID    int    `json:"id"`
+}
+
+type Spec struct {
+	Filters  []Filter
+	SortBy   string
+	Order    string
+	Limit    int
+	Page     int
+	Offset   int
+	Search   string
+	Cursor   *Cursor
+	resource Resource
+}
+
+var operators = map[string]string{
+	"eq":   "=",
+	"ne":   "<>",
+	"gt":   ">",
+	"gte":  ">=",
+	"lt":   "<",
+	"lte":  "<=",
+	"in":   "IN",
+	"nin":  "NOT IN",
+	"like": "ILIKE",
+}
+
+var reserved = map[string]bool{"limit": true, "page": true, "cursor": true, "order": true, "sortBy": true, "search": true}
+
+// Parse reads limit, page, cursor, order, sortBy, search and field filters such as
+// status=in:raised,accepted or created_at=gte:2024-01-01 from the query string
+func Parse(params url.Values, resource Resource) (Spec, error) {
+	spec := Spec{resource: resource}
+
+	maxLimit := resource.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = 100
+	}
+
+	spec.Limit, _ = strconv.Atoi(params.Get("limit"))
+	if spec.Limit <= 0 || spec.Limit > maxLimit {
+		spec.Limit = 10
+	}
+
+	spec.Page, _ = strconv.Atoi(params.Get("page"))
+	if spec.Page <= 0 {
+		spec.Page = 1
+	}
+	spec.Offset = (spec.Page - 1) * spec.Limit
+
+	spec.Order = strings.ToLower(params.Get("order"))
+	if spec.Order != "asc" && spec.Order != "desc" {
+		spec.Order = resource.DefaultOrder
+		if spec.Order == "" {
+			spec.Order = "asc"
+		}
+	}
+
+	spec.SortBy = params.Get("sortBy")
+	if field, ok := resource.Fields[spec.SortBy]; !ok || !field.Sortable {
+		spec.SortBy = resource.DefaultSort
+	}
+
+	spec.Search = strings.TrimSpace(params.Get("search"))
+
+	if raw := params.Get("cursor"); raw != "" {
+		cursor, err := decodeCursor(raw)
+		if err != nil {
+			return spec, fmt.Errorf("invalid cursor")
+		}
+		spec.Cursor = &cursor
+		spec.Offset = 0
+	}
+
+	for name, values := range params {
+		if reserved[name] {
+			continue
+		}
+
+		field, ok := resource.Fields[name]
+		if !ok || !field.Filterable {
+			continue
+		}
+
+		for _, value := range values {
+			filter, err := parseFilter(name, field, value)
+			if err != nil {
+				return spec, err
+			}
+			spec.Filters = append(spec.Filters, filter)
+		}
+	}
+
+	return spec, nil
+}
+
+func parseFilter(name string, field Field, raw string) (Filter, error) {
+	op, value := "eq", raw
+	if i := strings.Index(raw, ":"); i > 0 {
+		if _, ok := operators[raw[:i]]; ok {
+			op, value = raw[:i], raw[i+1:]
+		}
+	}
+
+	if op == "like" && field.Type != String {
+		return Filter{}, fmt.Errorf("like is only supported on text fields")
+	}
+
+	parts := []string{value}
+	if op == "in" || op == "nin" {
+		parts = strings.Split(value, ",")
+	}
+
+	filter := Filter{Field: field, Op: op}
+
+	for _, part := range parts {
+		converted, err := convert(field, strings.TrimSpace(part))
+		if err != nil {
+			return Filter{}, fmt.Errorf("invalid value for %v", name)
+		}
+		if op == "like" {
+			converted = "%" + escapeLike(part) + "%"
+		}
+		filter.Values = append(filter.Values, converted)
+	}
+
+	return filter, nil
+}
+
+func convert(field Field, value string) (interface{}, error) {
+	switch field.Type {
+	case Int:
+		return strconv.Atoi(value)
+	case Number:
+		number, err := strconv.ParseFloat(value, 64)
+		if err == nil && (math.IsNaN(number) || math.IsInf(number, 0)) {
+			return nil, fmt.Errorf("invalid value")
+		}
+		return number, err
+	case Time:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", value)
+	case Enum:
+		if !slices.Contains(field.Values, value) {
+			return nil, fmt.Errorf("invalid value")
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func castFor(field Field) string {
+	switch field.Type {
+	case Int:
+		return "::BIGINT"
+	case Number:
+		return "::NUMERIC"
+	case Time:
+		return "::TIMESTAMPTZ"
+	default:
+		return "::TEXT"
+	}
+}
+
+func columnFor(field Field) string {
+	if field.Type == Enum {
+		return field.Column + "::TEXT"
+	}
+	return field.Column
+}
+
+// Where returns the filter and search conditions, each prefixed with AND, numbering
+// placeholders after the args already bound by the caller
+func (s Spec) Where(args []interface{}) (string, []interface{}) {
+	var clauses []string
+
+	for _, filter := range s.Filters {
+		column := columnFor(filter.Field)
+		switch filter.Op {
+		case "in", "nin":
+			placeholders := make([]string, 0, len(filter.Values))
+			for _, value := range filter.Values {
+				args = append(args, value)
+				placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+			}
+			clauses = append(clauses, fmt.Sprintf("%s %s (%s)", column, operators[filter.Op], strings.Join(placeholders, ", ")))
+		default:
+			if filter.Op == "eq" && filter.Field.JSONColumn != "" && filter.Field.Type != Time {
+				data, _ := json.Marshal(map[string]interface{}{filter.Field.JSONKey: filter.Values[0]})
+				args = append(args, string(data))
+				clauses = append(clauses, fmt.Sprintf("%s @> $%d::JSONB", filter.Field.JSONColumn, len(args)))
+				continue
+			}
+			args = append(args, filter.Values[0])
+			clauses = append(clauses, fmt.Sprintf("%s %s $%d", column, operators[filter.Op], len(args)))
+		}
+	}
+
+	if s.Search != "" {
+		var searches []string
+		id, err := strconv.Atoi(s.Search)
+
+		for _, name := range s.sortedFieldNames() {
+			field := s.resource.Fields[name]
+			if !field.Searchable {
+				continue
+			}
+			switch field.Type {
+			case Int:
+				if err == nil {
+					args = append(args, id)
+					searches = append(searches, fmt.Sprintf("%s = $%d", field.Column, len(args)))
+				}
+			case String, Enum:
+				args = append(args, "%"+escapeLike(s.Search)+"%")
+				searches = append(searches, fmt.Sprintf("%s ILIKE $%d", columnFor(field), len(args)))
+			}
+		}
+
+		if len(searches) > 0 {
+			clauses = append(clauses, "("+strings.Join(searches, " OR ")+")")
+		} else {
+			clauses = append(clauses, "FALSE")
+		}
+	}
+
+	if len(clauses) == 0 {
+		return "", args
+	}
+
+	return " AND " + strings.Join(clauses, " AND "), args
+}
+
+// Paginate returns the keyset condition for the cursor, the ORDER BY and the LIMIT/OFFSET,
+// fetching one extra row so callers can tell whether another page exists. NULL sort values
+// come last in both directions
+func (s Spec) Paginate(args []interface{}) (string, string, []interface{}) {
+	field := s.resource.Fields[s.SortBy]
+	column := columnFor(field)
+	direction := "ASC"
+	comparison := ">"
+	if s.Order == "desc" {
+		direction = "DESC"
+		comparison = "<"
+	}
+
+	keyset := ""
+	if s.Cursor != nil && s.Cursor.Null {
+		args = append(args, s.Cursor.ID)
+		keyset = fmt.Sprintf(" AND %s IS NULL AND %s %s $%d", column, s.resource.IDColumn, comparison, len(args))
+	} else if s.Cursor != nil {
+		args = append(args, s.Cursor.Value, s.Cursor.ID)
+		value := fmt.Sprintf("$%d%s", len(args)-1, castFor(field))
+		keyset = fmt.Sprintf(" AND (%s %s %s OR (%s = %s AND %s %s $%d) OR %s IS NULL)",
+			column, comparison, value, column, value, s.resource.IDColumn, comparison, len(args), column)
+	}
+
+	args = append(args, s.Limit+1, s.Offset)
+	tail := fmt.Sprintf(" ORDER BY %s %s NULLS LAST, %s %s LIMIT $%d OFFSET $%d", column, direction, s.resource.IDColumn, direction, len(args)-1, len(args))
+
+	return keyset, tail, args
+}
+
+// CursorColumns selects the sort value and id of each row so the last one can become the next cursor
+func (s Spec) CursorColumns() string {
+	return fmt.Sprintf("%s::TEXT, %s", columnFor(s.resource.Fields[s.SortBy]), s.resource.IDColumn)
+}
+
+// NextCursor encodes the position after the last returned row, or "" when there is no next page.
+// value is nil when the sort value of the row was NULL
+func (s Spec) NextCursor(rows int, value *string, id int) string {
+	if rows <= s.Limit {
+		return ""
+	}
+
+	cursor := Cursor{ID: id, Null: value == nil}
+	if value != nil {
+		cursor.Value = *value
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (s Spec) PageModel(total int, nextCursor string) models.PageModel {
+	return models.PageModel{
+		Total:      total,
+		Page:       s.Page,
+		Limit:      s.Limit,
+		Pages:      int(math.Ceil(float64(total) / float64(s.Limit))),
+		NextCursor: nextCursor,
+	}
+}
+
+func (s Spec) sortedFieldNames() []string {
+	names := make([]string, 0, len(s.resource.Fields))
+	for name := range s.resource.Fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func decodeCursor(raw string) (Cursor, error) {
+	var cursor Cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, err
+	}
+
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
@@ -0,0 +1,260 @@
+package queryspec
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testResource = Resource{
+	IDColumn:    "id",
+	DefaultSort: "id",
+	Fields: map[string]Field{
+		"id":         {Column: "id", Type: Int, Filterable: true, Sortable: true, Searchable: true},
+		"name":       {Column: "name", Type: String, Filterable: true, Sortable: true, Searchable: true},
+		"status":     {Column: "status", Type: Enum, Values: []string{"raised", "accepted"}, Filterable: true, Sortable: true},
+		"cost":       {Column: "cost", Type: Number, Filterable: true, Sortable: true},
+		"created_at": {Column: "created_at", Type: Time, Filterable: true, Sortable: true},
+		"secret":     {Column: "secret", Type: String},
+	},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    Spec
+		filters int
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  Spec{SortBy: "id", Order: "asc", Limit: 10, Page: 1},
+		},
+		{
+			name:  "paging and sorting",
+			query: "limit=25&page=3&order=DESC&sortBy=name&search=+lap+",
+			want:  Spec{SortBy: "name", Order: "desc", Limit: 25, Page: 3, Offset: 50, Search: "lap"},
+		},
+		{
+			name:  "out of range limit and page fall back",
+			query: "limit=1000&page=-2",
+			want:  Spec{SortBy: "id", Order: "asc", Limit: 10, Page: 1},
+		},
+		{
+			name:  "unsortable and unknown sort fall back to the default",
+			query: "sortBy=secret&order=sideways",
+			want:  Spec{SortBy: "id", Order: "asc", Limit: 10, Page: 1},
+		},
+		{
+			name:    "undeclared and unfilterable fields are ignored",
+			query:   "secret=x&unknown=y&status=in:raised,accepted",
+			want:    Spec{SortBy: "id", Order: "asc", Limit: 10, Page: 1},
+			filters: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, _ := url.ParseQuery(tt.query)
+			got, err := Parse(params, testResource)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got.Filters) != tt.filters {
+				t.Errorf("got %d filters, want %d", len(got.Filters), tt.filters)
+			}
+			got.Filters, got.resource = nil, Resource{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		raw     string
+		op      string
+		values  []interface{}
+		wantErr bool
+	}{
+		{name: "plain value is eq", field: "id", raw: "7", op: "eq", values: []interface{}{7}},
+		{name: "operator prefix", field: "cost", raw: "gte:10.5", op: "gte", values: []interface{}{10.5}},
+		{name: "in splits and trims", field: "status", raw: "in:raised, accepted", op: "in", values: []interface{}{"raised", "accepted"}},
+		{name: "unknown prefix stays in the value", field: "name", raw: "a:b", op: "eq", values: []interface{}{"a:b"}},
+		{name: "like escapes wildcards", field: "name", raw: "like:50%_off", op: "like", values: []interface{}{`%50\%\_off%`}},
+		{name: "date", field: "created_at", raw: "lt:2024-01-02", op: "lt", values: []interface{}{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}},
+		{name: "like on a non text field", field: "id", raw: "like:1", wantErr: true},
+		{name: "invalid int", field: "id", raw: "abc", wantErr: true},
+		{name: "value outside the enum", field: "status", raw: "in:raised,closed", wantErr: true},
+		{name: "NaN number", field: "cost", raw: "NaN", wantErr: true},
+		{name: "infinite number", field: "cost", raw: "gt:Inf", wantErr: true},
+		{name: "invalid date", field: "created_at", raw: "2024-13-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFilter(tt.field, testResource.Fields[tt.field], tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Op != tt.op || !reflect.DeepEqual(got.Values, tt.values) {
+				t.Errorf("got %v %v, want %v %v", got.Op, got.Values, tt.op, tt.values)
+			}
+		})
+	}
+}
+
+func TestWhere(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+		args  []interface{}
+	}{
+		{name: "no conditions", query: "", want: "", args: []interface{}{1}},
+		{name: "numbers placeholders after bound args", query: "status=in:raised,accepted", want: " AND status::TEXT IN ($2, $3)", args: []interface{}{1, "raised", "accepted"}},
+		{name: "search int and text fields", query: "search=12", want: " AND (id = $2 OR name ILIKE $3)", args: []interface{}{1, 12, "%12%"}},
+		{name: "search without matching fields", query: "search=x&sortBy=id", want: " AND (name ILIKE $2)", args: []interface{}{1, "%x%"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, _ := url.ParseQuery(tt.query)
+			spec, err := Parse(params, testResource)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, args := spec.Where([]interface{}{1})
+			if got != tt.want || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("got %q %v, want %q %v", got, args, tt.want, tt.args)
+			}
+		})
+	}
+}
+
+func TestWhereJSONContainment(t *testing.T) {
+	resource := Resource{IDColumn: "id", DefaultSort: "id", Fields: map[string]Field{
+		"id":        {Column: "id", Type: Int, Sortable: true},
+		"attr.ram":  {Column: "(attributes->>'ram')::NUMERIC", Type: Number, Filterable: true, JSONColumn: "attributes", JSONKey: "ram"},
+		"attr.from": {Column: "(attributes->>'from')::DATE", Type: Time, Filterable: true, JSONColumn: "attributes", JSONKey: "from"},
+	}}
+
+	params, _ := url.ParseQuery("attr.ram=16")
+	spec, _ := Parse(params, resource)
+	got, args := spec.Where(nil)
+	if got != " AND attributes @> $1::JSONB" || !reflect.DeepEqual(args, []interface{}{`{"ram":16}`}) {
+		t.Errorf("got %q %v", got, args)
+	}
+
+	params, _ = url.ParseQuery("attr.from=2024-01-01")
+	spec, _ = Parse(params, resource)
+	if got, _ := spec.Where(nil); !strings.HasPrefix(got, " AND (attributes->>'from')::DATE = $1") {
+		t.Errorf("dates must compare the cast column, got %q", got)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		keyset string
+		tail   string
+		args   []interface{}
+	}{
+		{
+			name:  "offset pages",
+			query: "page=2&limit=5&sortBy=name",
+			tail:  " ORDER BY name ASC NULLS LAST, id ASC LIMIT $2 OFFSET $3",
+			args:  []interface{}{1, 6, 5},
+		},
+		{
+			name:   "cursor after a value",
+			query:  "sortBy=cost&order=desc&cursor=" + encode(Cursor{Value: "9.5", ID: 4}),
+			keyset: " AND (cost < $2::NUMERIC OR (cost = $2::NUMERIC AND id < $3) OR cost IS NULL)",
+			tail:   " ORDER BY cost DESC NULLS LAST, id DESC LIMIT $4 OFFSET $5",
+			args:   []interface{}{1, "9.5", 4, 11, 0},
+		},
+		{
+			name:   "cursor after a NULL value",
+			query:  "sortBy=cost&cursor=" + encode(Cursor{Null: true, ID: 4}),
+			keyset: " AND cost IS NULL AND id > $2",
+			tail:   " ORDER BY cost ASC NULLS LAST, id ASC LIMIT $3 OFFSET $4",
+			args:   []interface{}{1, 4, 11, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, _ := url.ParseQuery(tt.query)
+			spec, err := Parse(params, testResource)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			keyset, tail, args := spec.Paginate([]interface{}{1})
+			if keyset != tt.keyset || tail != tt.tail || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("got %q %q %v, want %q %q %v", keyset, tail, args, tt.keyset, tt.tail, tt.args)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	value := "2024-01-02 10:00:00+00"
+	empty := ""
+
+	tests := []struct {
+		name  string
+		value *string
+		want  Cursor
+	}{
+		{name: "value", value: &value, want: Cursor{Value: value, ID: 42}},
+		{name: "empty string is not NULL", value: &empty, want: Cursor{Value: "", ID: 42}},
+		{name: "NULL", value: nil, want: Cursor{Null: true, ID: 42}},
+	}
+
+	spec := Spec{Limit: 10}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := spec.NextCursor(11, tt.value, 42)
+			params := url.Values{"cursor": {raw}}
+			parsed, err := Parse(params, testResource)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if parsed.Cursor == nil || *parsed.Cursor != tt.want {
+				t.Errorf("got %+v, want %+v", parsed.Cursor, tt.want)
+			}
+			if parsed.Offset != 0 {
+				t.Errorf("cursor pages must not use an offset, got %d", parsed.Offset)
+			}
+		})
+	}
+
+	if raw := spec.NextCursor(10, &value, 42); raw != "" {
+		t.Errorf("last page must not have a cursor, got %q", raw)
+	}
+
+	if _, err := Parse(url.Values{"cursor": {"not a cursor"}}, testResource); err == nil {
+		t.Errorf("expected an error for an invalid cursor")
+	}
+}
+
+func encode(cursor Cursor) string {
+	value := &cursor.Value
+	if cursor.Null {
+		value = nil
+	}
+	return Spec{}.NextCursor(1, value, cursor.ID)
+}
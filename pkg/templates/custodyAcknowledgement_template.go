@@ -0,0 +1,172 @@
+package templates
+
+import "html"
+
+func GetCustodyAcknowledgementTemplate(name, unit, token, link string) string {
+	button := ""
+	if link != "" {
+		button = `<a href="` + html.EscapeString(link) + `" class="login-btn">Acknowledge Receipt</a>`
+	}
+
+	return `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>HACFY - Acknowledge Equipment Receipt</title>
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <style>
+    body, table, td, div, p, a { -webkit-text-size-adjust:100%; -ms-text-size-adjust:100%; }
+    body {
+      font-family: Arial, sans-serif;
+      background-color: #1E275A;
+      margin: 0;
+      padding: 0;
+    }
+
+    .wrapper {
+      width: 100%;
+      table-layout: fixed;
+      background-color: #1E275A;
+      padding-bottom: 40px;
+    }
+
+    .outer {
+      margin: 0 auto;
+      width: 100%;
+      max-width: 600px;
+      background: #ffffff;
+      border-radius: 40px 40px 0 0;
+      overflow: hidden;
+    }
+
+    .header {
+      text-align: center;
+      padding: 30px 20px 20px;
+      background: #1E275A;
+    }
+
+    .header h1 {
+      color: #ffffff;
+      font-size: 24px;
+      margin: 10px 0 0;
+    }
+
+    .main {
+      padding: 30px 20px;
+      text-align: center;
+    }
+
+    .main h2 {
+      color: #1E275A;
+      font-size: 20px;
+      margin-bottom: 10px;
+    }
+
+    .main p {
+      color: #666;
+      font-size: 15px;
+      margin-bottom: 20px;
+      line-height: 1.5;
+    }
+
+    .form-box {
+      background: #2A3B6B;
+      padding: 20px;
+      border-radius: 16px;
+      margin: 0 auto;
+      max-width: 400px;
+      color: #fff;
+      text-align: left;
+    }
+
+    .info-label {
+      font-size: 13px;
+      color: #eee;
+      margin-bottom: 6px;
+    }
+
+    .info-value {
+      display: block;
+      width: 100%;
+      padding: 14px;
+      border-radius: 10px;
+      background: #ffffff;
+      color: #1E275A;
+      font-size: 15px;
+      font-weight: 700;
+      text-align: center;
+      word-break: break-all;
+      box-sizing: border-box;
+      margin-bottom: 15px;
+    }
+
+    .login-btn {
+      display: block;
+      text-align: center;
+      width: 100%;
+      padding: 12px;
+      background: #ffffff;
+      color: #1E275A !important;
+      font-size: 16px;
+      font-weight: bold;
+      border-radius: 8px;
+      text-decoration: none;
+      margin-top: 10px;
+    }
+
+    .info-text {
+      margin-top: 15px;
+      font-size: 12px;
+      color: #ddd;
+      line-height: 1.4;
+    }
+
+    .footer {
+      background: #f5f5f5;
+      text-align: center;
+      padding: 15px;
+      font-size: 12px;
+      color: #666;
+    }
+  </style>
+</head>
+<body>
+  <center class="wrapper">
+    <div class="outer">
+      <div class="header">
+        <h1>IT Inventory</h1>
+      </div>
+
+      <div class="main">
+        <h2>Hello ` + html.EscapeString(name) + `</h2>
+        <p>The equipment below has been assigned to you. Please confirm that you have received it.</p>
+
+        <div class="form-box">
+          <div>
+            <div class="info-label">Equipment</div>
+            <div class="info-value">` + html.EscapeString(unit) + `</div>
+          </div>
+
+          <div>
+            <div class="info-label">Acknowledgement code</div>
+            <div class="info-value">` + token + `</div>
+          </div>
+
+          ` + button + `
+
+          <p class="info-text">
+            If you did not receive this equipment, please do not acknowledge it and contact your department head.
+          </p>
+        </div>
+      </div>
+
+      <div class="footer">
+        © 2025 IT Management System. All Rights Reserved
+      </div>
+    </div>
+  </center>
+</body>
+</html>
+	`
+}
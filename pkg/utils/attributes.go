@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+)
+
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+func ValidAttributeKey(key string) bool {
+	return attributeKeyPattern.MatchString(key)
+}
+
+// ValidateAttributes checks values against the definitions that apply to a component and returns them
+// normalised for storage, numbers as float64 and dates as YYYY-MM-DD. Values are merged over current,
+// a null value removes an attribute, and required attributes must be present in the result
+func ValidateAttributes(definitions []models.AttributeDefinitionModel, values, current map[string]interface{}) (map[string]interface{}, error) {
+	byKey := make(map[string]models.AttributeDefinitionModel, len(definitions))
+	for _, definition := range definitions {
+		byKey[definition.Key] = definition
+	}
+
+	result := make(map[string]interface{}, len(current)+len(values))
+	for key, value := range current {
+		if _, ok := byKey[key]; ok {
+			result[key] = value
+		}
+	}
+
+	for key, value := range values {
+		definition, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown attribute %v", key)
+		}
+
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+
+		normalised, err := normaliseAttribute(definition, value)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = normalised
+	}
+
+	for _, definition := range definitions {
+		if _, ok := result[definition.Key]; definition.Required && !ok {
+			return nil, fmt.Errorf("attribute %v is required", definition.Key)
+		}
+	}
+
+	return result, nil
+}
+
+func normaliseAttribute(definition models.AttributeDefinitionModel, value interface{}) (interface{}, error) {
+	invalid := fmt.Errorf("invalid value for attribute %v, expected %v", definition.Key, definition.Type)
+
+	switch definition.Type {
+	case "number":
+		var n float64
+		switch v := value.(type) {
+		case float64:
+			n = v
+		case string:
+			var err error
+			if n, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+				return nil, invalid
+			}
+		default:
+			return nil, invalid
+		}
+		// NaN and Inf parse but cannot be stored as JSON
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, invalid
+		}
+		return n, nil
+	case "date":
+		if v, ok := value.(string); ok {
+			v = strings.TrimSpace(v)
+			if t, err := time.Parse("2006-01-02", v); err == nil {
+				return t.Format("2006-01-02"), nil
+			}
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t.UTC().Format("2006-01-02"), nil
+			}
+		}
+	case "enum":
+		if v, ok := value.(string); ok && slices.Contains(definition.Options, v) {
+			return v, nil
+		}
+		return nil, fmt.Errorf("invalid value for attribute %v, expected one of %v", definition.Key, strings.Join(definition.Options, ", "))
+	default:
+		if v, ok := value.(string); ok && len(v) <= 255 {
+			return v, nil
+		}
+	}
+
+	return nil, invalid
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+)
+
+func TestValidateAttributes(t *testing.T) {
+	definitions := []models.AttributeDefinitionModel{
+		{Key: "ram_gb", Type: "number", Required: true},
+		{Key: "purchased", Type: "date"},
+		{Key: "colour", Type: "enum", Options: []string{"black", "silver"}},
+		{Key: "notes", Type: "text"},
+	}
+
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		current map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "normalises numbers and dates",
+			values: map[string]interface{}{"ram_gb": " 16 ", "purchased": "2024-03-05T10:00:00+05:30", "colour": "black"},
+			want:   map[string]interface{}{"ram_gb": 16.0, "purchased": "2024-03-05", "colour": "black"},
+		},
+		{
+			name:    "merges over current and drops unknown current keys",
+			values:  map[string]interface{}{"notes": "spare"},
+			current: map[string]interface{}{"ram_gb": 8.0, "removed": "x"},
+			want:    map[string]interface{}{"ram_gb": 8.0, "notes": "spare"},
+		},
+		{
+			name:    "null removes an attribute",
+			values:  map[string]interface{}{"notes": nil},
+			current: map[string]interface{}{"ram_gb": 8.0, "notes": "spare"},
+			want:    map[string]interface{}{"ram_gb": 8.0},
+		},
+		{
+			name:    "required attribute missing",
+			values:  map[string]interface{}{"notes": "spare"},
+			wantErr: true,
+		},
+		{
+			name:    "null cannot remove a required attribute",
+			values:  map[string]interface{}{"ram_gb": nil},
+			current: map[string]interface{}{"ram_gb": 8.0},
+			wantErr: true,
+		},
+		{
+			name:    "unknown attribute",
+			values:  map[string]interface{}{"ram_gb": 8.0, "cpu": "i7"},
+			wantErr: true,
+		},
+		{
+			name:    "NaN is rejected",
+			values:  map[string]interface{}{"ram_gb": "NaN"},
+			wantErr: true,
+		},
+		{
+			name:    "Inf is rejected",
+			values:  map[string]interface{}{"ram_gb": "-Inf"},
+			wantErr: true,
+		},
+		{
+			name:    "number of the wrong type",
+			values:  map[string]interface{}{"ram_gb": true},
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			values:  map[string]interface{}{"ram_gb": 8.0, "purchased": "2024-02-30"},
+			wantErr: true,
+		},
+		{
+			name:    "value outside the enum options",
+			values:  map[string]interface{}{"ram_gb": 8.0, "colour": "gold"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateAttributes(definitions, tt.values, tt.current)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/Hacfy/IT_INVENTORY/pkg/templates"
 	"gopkg.in/gomail.v2"
@@ -113,3 +114,167 @@ func SendForgotPasswordEmail(email, otp string) error {
 
 	return nil
 }
+
+// SendCustodyAcknowledgement asks a custodian to confirm receipt of a unit, the link is only
+// included when CUSTODY_ACKNOWLEDGE_URL is set
+func SendCustodyAcknowledgement(email, name, unit, token string) error {
+
+	smtpHost := os.Getenv("SMTP_HOST")
+
+	if smtpHost == "" {
+		return errors.New("SMTP_HOST env was missing")
+	}
+
+	smtpPort := os.Getenv("SMTP_PORT")
+
+	if smtpPort == "" {
+		return errors.New("SMTP_PORT env was missing")
+	}
+
+	hostEmail := os.Getenv("HOST_EMAIL")
+
+	if hostEmail == "" {
+		return errors.New("HOST_EMAIL env was missing")
+	}
+
+	appPassword := os.Getenv("APP_PASSWORD")
+
+	if appPassword == "" {
+		return errors.New("APP_PASSWORD env was missing")
+	}
+
+	smtpPortInt, err := strconv.Atoi(smtpPort)
+
+	if err != nil {
+		return err
+	}
+
+	link := ""
+	if acknowledgeURL := os.Getenv("CUSTODY_ACKNOWLEDGE_URL"); acknowledgeURL != "" {
+		link = acknowledgeURL + "?token=" + token
+	}
+
+	client := gomail.NewDialer(smtpHost, smtpPortInt, hostEmail, appPassword)
+
+	htmlTemplate := templates.GetCustodyAcknowledgementTemplate(name, unit, token, link)
+
+	message := gomail.NewMessage()
+
+	message.SetHeader("From", hostEmail)
+	message.SetHeader("To", email)
+	message.SetHeader("Subject", "Please Acknowledge Your Equipment")
+	message.SetBody("text/html", htmlTemplate)
+
+	if err = client.DialAndSend(message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+func SendLoanOverdueReminder(email, name, equipment string, dueAt time.Time) error {
+
+	smtpHost := os.Getenv("SMTP_HOST")
+
+	if smtpHost == "" {
+		return errors.New("SMTP_HOST env was missing")
+	}
+
+	smtpPort := os.Getenv("SMTP_PORT")
+
+	if smtpPort == "" {
+		return errors.New("SMTP_PORT env was missing")
+	}
+
+	hostEmail := os.Getenv("HOST_EMAIL")
+
+	if hostEmail == "" {
+		return errors.New("HOST_EMAIL env was missing")
+	}
+
+	appPassword := os.Getenv("APP_PASSWORD")
+
+	if appPassword == "" {
+		return errors.New("APP_PASSWORD env was missing")
+	}
+
+	smtpPortInt, err := strconv.Atoi(smtpPort)
+
+	if err != nil {
+		return err
+	}
+
+	client := gomail.NewDialer(smtpHost, smtpPortInt, hostEmail, appPassword)
+
+	htmlTemplate := templates.GetLoanOverdueTemplate(name, equipment, dueAt.UTC().Format("02 Jan 2006 15:04 MST"))
+
+	message := gomail.NewMessage()
+
+	message.SetHeader("From", hostEmail)
+	message.SetHeader("To", email)
+	message.SetHeader("Subject", "Borrowed Equipment Is Overdue")
+	message.SetBody("text/html", htmlTemplate)
+
+	if err = client.DialAndSend(message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// SendInvitation invites a new account holder to set their password, the link is only included when
+// INVITATION_URL is set
+func SendInvitation(email, name, role, token string, expiresAt time.Time) error {
+
+	smtpHost := os.Getenv("SMTP_HOST")
+
+	if smtpHost == "" {
+		return errors.New("SMTP_HOST env was missing")
+	}
+
+	smtpPort := os.Getenv("SMTP_PORT")
+
+	if smtpPort == "" {
+		return errors.New("SMTP_PORT env was missing")
+	}
+
+	hostEmail := os.Getenv("HOST_EMAIL")
+
+	if hostEmail == "" {
+		return errors.New("HOST_EMAIL env was missing")
+	}
+
+	appPassword := os.Getenv("APP_PASSWORD")
+
+	if appPassword == "" {
+		return errors.New("APP_PASSWORD env was missing")
+	}
+
+	smtpPortInt, err := strconv.Atoi(smtpPort)
+
+	if err != nil {
+		return err
+	}
+
+	link := ""
+	if invitationURL := os.Getenv("INVITATION_URL"); invitationURL != "" {
+		link = invitationURL + "?token=" + token
+	}
+
+	client := gomail.NewDialer(smtpHost, smtpPortInt, hostEmail, appPassword)
+
+	htmlTemplate := templates.GetInvitationTemplate(name, role, token, link, expiresAt.UTC().Format("02 Jan 2006 15:04 MST"))
+
+	message := gomail.NewMessage()
+
+	message.SetHeader("From", hostEmail)
+	message.SetHeader("To", email)
+	message.SetHeader("Subject", "You Are Invited to IT Inventory")
+	message.SetBody("text/html", htmlTemplate)
+
+	if err = client.DialAndSend(message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
@@ -63,13 +63,17 @@ func GenerateCookieToken(userEmail, userType string, userID int, exp, iat int64)
 	return signedToken, nil
 }
 
-func GenerateUserToken(userEmail, userType, userName string, userID int, exp, iat int64) (string, error) {
+// GenerateUserToken signs the user token, staffID and staffRole are set when a staff member signs in
+// as their department or warehouse
+func GenerateUserToken(userEmail, userType, userName string, userID, staffID int, staffRole string, exp, iat int64) (string, error) {
 	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
 	claims := &models.UserTokenModel{
 		UserID:    userID,
 		UserEmail: userEmail,
 		UserName:  userName,
 		UserType:  userType,
+		StaffID:   staffID,
+		StaffRole: staffRole,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Unix(exp, 0).UTC()),
 			IssuedAt:  jwt.NewNumericDate(time.Unix(iat, 0).UTC()),
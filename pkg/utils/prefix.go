@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxPrefixLength is the longest prefix a component can use, it is part of the per-component table names
+const MaxPrefixLength = 8
+
+var prefixPattern = regexp.MustCompile(`^[a-z][a-z0-9]{1,7}$`)
+
+// NormalisePrefix lowercases prefix and reports whether it can be used as a component prefix
+func NormalisePrefix(prefix string) (string, bool) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	return prefix, prefixPattern.MatchString(prefix)
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultArchiveRetentionDays = 30
+
+// OtpValidity is how long a forgot password otp can be used before it is purged
+const OtpValidity = 5 * time.Minute
+
+// InvitationValidity is how long an invitation link can be used to set the first password
+const InvitationValidity = 72 * time.Hour
+
+// ArchiveRetentionDays is how long deleted rows stay restorable, set with ARCHIVE_RETENTION_DAYS
+func ArchiveRetentionDays() int {
+	days, err := strconv.Atoi(os.Getenv("ARCHIVE_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		return defaultArchiveRetentionDays
+	}
+	return days
+}
+
+// RetentionDays reads RETENTION_DAYS_<TABLE>, 0 keeps rows forever
+func RetentionDays(table string, fallback int) int {
+	value, ok := os.LookupEnv("RETENTION_DAYS_" + strings.ToUpper(table))
+	if !ok {
+		return fallback
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return fallback
+	}
+	return days
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+type AnalyticsRepo struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepo(db *sql.DB) *AnalyticsRepo {
+	return &AnalyticsRepo{db: db}
+}
+
+func (ar *AnalyticsRepo) GetCostByComponent(e echo.Context) (int, []models.ComponentCostModel, error) {
+	status, scope, err := authorize(e, ar.db, "reports.view")
+	if err != nil {
+		return status, []models.ComponentCostModel{}, err
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.GetCostByComponent(scope)
+}
+
+func (ar *AnalyticsRepo) GetCostByWarehouse(e echo.Context) (int, []models.WarehouseCostModel, error) {
+	status, scope, err := authorize(e, ar.db, "reports.view")
+	if err != nil {
+		return status, []models.WarehouseCostModel{}, err
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.GetCostByWarehouse(scope)
+}
+
+func (ar *AnalyticsRepo) GetCostByDepartment(e echo.Context) (int, []models.DepartmentCostModel, error) {
+	status, scope, err := authorize(e, ar.db, "reports.view")
+	if err != nil {
+		return status, []models.DepartmentCostModel{}, err
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.GetCostByDepartment(scope)
+}
+
+func (ar *AnalyticsRepo) GetCostByPeriod(e echo.Context) (int, []models.PeriodCostModel, error) {
+	interval := e.QueryParam("interval")
+	if interval == "" {
+		interval = "month"
+	}
+
+	allowed := map[string]bool{"day": true, "week": true, "month": true, "quarter": true, "year": true}
+	if !allowed[interval] {
+		log.Printf("invalid interval %v", interval)
+		return http.StatusBadRequest, []models.PeriodCostModel{}, fmt.Errorf("invalid interval")
+	}
+
+	to := time.Now()
+	if param := e.QueryParam("to"); param != "" {
+		date, err := time.Parse("2006-01-02", param)
+		if err != nil {
+			log.Printf("error while parsing to date: %v", err)
+			return http.StatusBadRequest, []models.PeriodCostModel{}, fmt.Errorf("invalid request format")
+		}
+		to = date.AddDate(0, 0, 1)
+	}
+
+	from := to.AddDate(-1, 0, 0)
+	if param := e.QueryParam("from"); param != "" {
+		date, err := time.Parse("2006-01-02", param)
+		if err != nil {
+			log.Printf("error while parsing from date: %v", err)
+			return http.StatusBadRequest, []models.PeriodCostModel{}, fmt.Errorf("invalid request format")
+		}
+		from = date
+	}
+
+	if !from.Before(to) {
+		return http.StatusBadRequest, []models.PeriodCostModel{}, fmt.Errorf("from date must be before to date")
+	}
+
+	status, scope, err := authorize(e, ar.db, "reports.view")
+	if err != nil {
+		return status, []models.PeriodCostModel{}, err
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.GetCostByPeriod(scope, interval, from, to)
+}
+
+func (ar *AnalyticsRepo) GetTopMaintenanceUnits(e echo.Context) (int, []models.UnitMaintenanceCostModel, error) {
+	limit, _ := strconv.Atoi(e.QueryParam("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	status, scope, err := authorize(e, ar.db, "reports.view")
+	if err != nil {
+		return status, []models.UnitMaintenanceCostModel{}, err
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.GetTopMaintenanceUnits(scope, limit)
+}
+
+func (ar *AnalyticsRepo) GetBranchComponentCostComparison(e echo.Context) (int, []models.BranchComponentCostModel, error) {
+	status, scope, err := authorize(e, ar.db, "reports.view")
+	if err != nil {
+		return status, []models.BranchComponentCostModel{}, err
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.GetBranchComponentCostComparison(scope, e.QueryParam("component_name"))
+}
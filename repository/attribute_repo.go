@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type AttributeRepo struct {
+	db *sql.DB
+}
+
+func NewAttributeRepo(db *sql.DB) *AttributeRepo {
+	return &AttributeRepo{db: db}
+}
+
+func (ar *AttributeRepo) CreateAttributeDefinition(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, ar.db, "attributes.manage")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var definition models.CreateAttributeDefinitionModel
+
+	if err := e.Bind(&definition); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	definition.Key = strings.ToLower(strings.TrimSpace(definition.Key))
+	definition.Label = strings.TrimSpace(definition.Label)
+
+	if err := validate.Struct(definition); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	if (definition.CategoryID > 0) == (definition.ComponentID > 0) {
+		return http.StatusBadRequest, -1, fmt.Errorf("exactly one of category_id or component_id is required")
+	}
+
+	if !utils.ValidAttributeKey(definition.Key) {
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid attribute key")
+	}
+
+	if definition.Type == "enum" {
+		options := make([]string, 0, len(definition.Options))
+		seen := make(map[string]bool)
+		for _, option := range definition.Options {
+			option = strings.TrimSpace(option)
+			if option == "" || seen[option] {
+				continue
+			}
+			seen[option] = true
+			options = append(options, option)
+		}
+		if len(options) == 0 {
+			return http.StatusBadRequest, -1, fmt.Errorf("enum attributes require options")
+		}
+		definition.Options = options
+	} else if len(definition.Options) > 0 {
+		return http.StatusBadRequest, -1, fmt.Errorf("options are only allowed for enum attributes")
+	} else {
+		definition.Options = []string{}
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.CreateAttributeDefinition(scope.OrgID, definition)
+}
+
+func (ar *AttributeRepo) GetAttributeDefinitions(e echo.Context) (int, []models.AttributeDefinitionModel, error) {
+	status, scope, err := getUserScope(e, ar.db)
+	if err != nil {
+		return status, []models.AttributeDefinitionModel{}, err
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	if e.QueryParam("component_id") != "" {
+		component_id, err := strconv.Atoi(e.QueryParam("component_id"))
+		if err != nil || component_id <= 0 {
+			log.Printf("invalid component id: %v", e.QueryParam("component_id"))
+			return http.StatusBadRequest, []models.AttributeDefinitionModel{}, fmt.Errorf("invalid component id")
+		}
+		return query.GetOrgComponentAttributeDefinitions(scope.OrgID, component_id)
+	}
+
+	category_id := 0
+	if e.QueryParam("category_id") != "" {
+		category_id, err = strconv.Atoi(e.QueryParam("category_id"))
+		if err != nil || category_id <= 0 {
+			log.Printf("invalid category id: %v", e.QueryParam("category_id"))
+			return http.StatusBadRequest, []models.AttributeDefinitionModel{}, fmt.Errorf("invalid category id")
+		}
+	}
+
+	return query.GetAttributeDefinitions(scope.OrgID, category_id)
+}
+
+func (ar *AttributeRepo) DeleteAttributeDefinition(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, ar.db, "attributes.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var definition models.DeleteAttributeDefinitionModel
+
+	if err := e.Bind(&definition); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(definition); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.DeleteAttributeDefinition(scope.OrgID, definition.DefinitionID)
+}
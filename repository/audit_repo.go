@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/Hacfy/IT_INVENTORY/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type AuditRepo struct {
+	db *sql.DB
+}
+
+func NewAuditRepo(db *sql.DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+func (ar *AuditRepo) CreateAuditSession(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, ar.db, "audits.conduct")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var session models.CreateAuditSessionModel
+
+	if err := e.Bind(&session); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	session.Note = strings.TrimSpace(session.Note)
+
+	if err := validate.Struct(session); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	if (session.WarehouseID > 0) == (session.DepartmentID > 0) {
+		return http.StatusBadRequest, -1, fmt.Errorf("exactly one of warehouse_id or department_id is required")
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.CreateAuditSession(scope, session.WarehouseID, session.DepartmentID, session.Note)
+}
+
+func (ar *AuditRepo) RecordSighting(e echo.Context) (int, models.AuditSightingModel, error) {
+	status, scope, err := authorize(e, ar.db, "audits.conduct")
+	if err != nil {
+		return status, models.AuditSightingModel{}, err
+	}
+
+	var sighting models.RecordSightingModel
+
+	if err := e.Bind(&sighting); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.AuditSightingModel{}, fmt.Errorf("invalid request format")
+	}
+
+	sighting.Code = strings.TrimSpace(sighting.Code)
+
+	if err := validate.Struct(sighting); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.AuditSightingModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	if sighting.UnitID > 0 {
+		var ok bool
+		if sighting.Prefix, ok = utils.NormalisePrefix(sighting.Prefix); !ok {
+			log.Printf("invalid prefix %v", sighting.Prefix)
+			return http.StatusBadRequest, models.AuditSightingModel{}, fmt.Errorf("invalid prefix")
+		}
+	} else if sighting.Code == "" {
+		return http.StatusBadRequest, models.AuditSightingModel{}, fmt.Errorf("prefix and unit_id or code is required")
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.RecordSighting(scope, sighting)
+}
+
+func (ar *AuditRepo) GetAuditSessions(e echo.Context) (int, []models.AuditSessionModel, models.PageModel, error) {
+	status, scope, err := getUserScope(e, ar.db)
+	if err != nil {
+		return status, []models.AuditSessionModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.AuditSessionsResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.AuditSessionModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.GetAuditSessions(scope, spec)
+}
+
+func (ar *AuditRepo) GetAuditReport(e echo.Context) (int, models.AuditReportModel, error) {
+	session_id, err := strconv.Atoi(e.QueryParam("session_id"))
+	if err != nil || session_id <= 0 {
+		log.Printf("invalid session id: %v", e.QueryParam("session_id"))
+		return http.StatusBadRequest, models.AuditReportModel{}, fmt.Errorf("invalid session id")
+	}
+
+	status, scope, err := getUserScope(e, ar.db)
+	if err != nil {
+		return status, models.AuditReportModel{}, err
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.GetAuditReport(scope, session_id)
+}
+
+func (ar *AuditRepo) CloseAuditSession(e echo.Context) (int, models.AuditReportModel, error) {
+	status, scope, err := authorize(e, ar.db, "audits.conduct")
+	if err != nil {
+		return status, models.AuditReportModel{}, err
+	}
+
+	var closeSession models.CloseAuditSessionModel
+
+	if err := e.Bind(&closeSession); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.AuditReportModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(closeSession); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.AuditReportModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(ar.db)
+
+	return query.CloseAuditSession(scope, closeSession)
+}
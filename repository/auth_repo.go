@@ -68,6 +68,12 @@ func (ar *AuthRepo) UserLogin(e echo.Context) (int, string, string, string, erro
 		return http.StatusBadRequest, "", "", "", fmt.Errorf("invalid user credentials")
 	}
 
+	staff_id, staff_role, _, err := query.GetStaffAccount(req_user.Email)
+	if err != nil {
+		log.Printf("Error checking staff details: %v", err)
+		return http.StatusInternalServerError, "", "", "", fmt.Errorf("database error")
+	}
+
 	token_iat := time.Now().Local()
 
 	if err := query.UpdateUserTokenTimestamp(req_user.Email, token_iat); err != nil {
@@ -89,7 +95,7 @@ func (ar *AuthRepo) UserLogin(e echo.Context) (int, string, string, string, erro
 		return http.StatusInternalServerError, "", "", "", fmt.Errorf("unable to generate token, please try again later")
 	}
 
-	token, err := utils.GenerateUserToken(req_user.Email, userType, db_name, db_id, time.Now().Local().Add(7*24*time.Hour).Unix(), token_unix)
+	token, err := utils.GenerateUserToken(req_user.Email, userType, db_name, db_id, staff_id, staff_role, time.Now().Local().Add(7*24*time.Hour).Unix(), token_unix)
 	if err != nil {
 		log.Printf("error while generating token for user %s: %v", req_user.Email, err)
 		return http.StatusInternalServerError, "", "", "", fmt.Errorf("unable to generate token, please try again later")
@@ -193,7 +199,7 @@ func (ar *AuthRepo) ChangeUserPassword(e echo.Context) (int, string, string, str
 		return http.StatusInternalServerError, "", "", "", fmt.Errorf("unable to generate token, please try again later")
 	}
 
-	Token, err := utils.GenerateUserToken(claims.UserEmail, claims.UserType, claims.UserName, claims.UserID, time.Now().Local().Add(7*24*time.Hour).Unix(), token_unix)
+	Token, err := utils.GenerateUserToken(claims.UserEmail, claims.UserType, claims.UserName, claims.UserID, claims.StaffID, claims.StaffRole, time.Now().Local().Add(7*24*time.Hour).Unix(), token_unix)
 	if err != nil {
 		log.Printf("error while generating token for user %s: %v", claims.UserEmail, err)
 		return http.StatusInternalServerError, "", "", "", fmt.Errorf("unable to generate token, please try again later")
@@ -325,13 +331,6 @@ func (ar *AuthRepo) ForgotPassword(e echo.Context) (int, time.Time, error) {
 		return http.StatusInternalServerError, time.Time{}, fmt.Errorf("failed to send forgot password email, please try again later")
 	}
 
-	go func() {
-		log.Println("waiting for 5 minutes")
-		time.Sleep(time.Minute * 5)
-		query.DeleteOtp(req_user.Email, Time.Unix())
-		log.Println("otp deleted")
-	}()
-
 	return http.StatusOK, Time.UTC(), nil
 }
 
@@ -350,7 +349,7 @@ func (ar *AuthRepo) VerifyForgotPasswordRequest(e echo.Context) (int, error) {
 
 	query := database.NewDBinstance(ar.db)
 
-	if time.Since(req_user.Time) > 5*time.Minute {
+	if time.Since(req_user.Time) > utils.OtpValidity {
 		log.Printf("otp expired")
 		return http.StatusUnauthorized, fmt.Errorf("otp expired")
 	}
@@ -421,6 +420,11 @@ func (ar *AuthRepo) ResetPassword(e echo.Context) (int, string, string, string,
 		return http.StatusUnauthorized, "", "", "", fmt.Errorf("invalid user details")
 	}
 
+	StaffID, StaffRole, _, err := query.GetStaffAccount(req_user.Email)
+	if err != nil {
+		return http.StatusInternalServerError, "", "", "", fmt.Errorf("failed to get user details, please try again later")
+	}
+
 	accessToken, err := utils.GenerateCookieToken(req_user.Email, UserType, UserID, time.Now().Local().Add(24*time.Hour).Unix(), time_unix)
 	if err != nil {
 		return http.StatusInternalServerError, "", "", "", fmt.Errorf("failed to generate token, please try again later")
@@ -431,7 +435,7 @@ func (ar *AuthRepo) ResetPassword(e echo.Context) (int, string, string, string,
 		return http.StatusInternalServerError, "", "", "", fmt.Errorf("failed to generate token, please try again later")
 	}
 
-	token, err := utils.GenerateUserToken(req_user.Email, UserType, UserName, UserID, time.Now().Local().Add(7*24*time.Hour).Unix(), time_unix)
+	token, err := utils.GenerateUserToken(req_user.Email, UserType, UserName, UserID, StaffID, StaffRole, time.Now().Local().Add(7*24*time.Hour).Unix(), time_unix)
 	if err != nil {
 		return http.StatusInternalServerError, "", "", "", fmt.Errorf("failed to generate token, please try again later")
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
 	"github.com/Hacfy/IT_INVENTORY/pkg/database"
@@ -24,22 +25,11 @@ func NewBranchRepo(db *sql.DB) *BranchRepo {
 
 func (br *BranchRepo) CreateDepartment(e echo.Context) (int, error) {
 
-	status, claims, err := utils.VerifyUserToken(e, "branch_head", br.db)
+	status, scope, err := authorize(e, br.db, "departments.manage")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(br.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "branch_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var new_department models.CreateDepartmentModel
 
 	if err := e.Bind(&new_department); err != nil {
@@ -52,60 +42,40 @@ func (br *BranchRepo) CreateDepartment(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
-	password, err := utils.GeneratePassword()
+	status, branch_id, err := targetNode(e, br.db, scope, "branch", new_department.BranchID)
 	if err != nil {
-		log.Printf("error while generating password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
+		return status, err
 	}
 
-	for !utils.StrongPasswordValidator(password) {
-		password, err = utils.GeneratePassword()
-		if err != nil {
-			log.Printf("error while generating password: %v", err)
-			return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
-		}
+	hash, err := unusablePassword()
+	if err != nil {
+		return http.StatusInternalServerError, err
 	}
 
-	hash, err := utils.HashPassword(password)
+	token, invitation, err := newInvitation(new_department.DepartmentHeadName, "department_head", scope.Role, scope.UserID)
 	if err != nil {
-		log.Printf("error while hashing password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to secure your password, please try again")
+		return http.StatusInternalServerError, err
 	}
 
-	if err := query.CreateDepartment(new_department, claims.UserID, hash); err != nil {
+	query := database.NewDBinstance(br.db)
+
+	if err := query.CreateDepartment(new_department, branch_id, hash, invitation); err != nil {
 		log.Printf("error while storing Department data in DB: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("unable to create department at the moment, please try again later")
 	}
 
-	go func() {
-		log.Printf("sending login credentials to %v", new_department.DepartmentHeadEmail)
-		if err := utils.SendLoginCredentials(new_department.DepartmentHeadEmail, password); err != nil {
-			log.Printf("error while sending login credentials to %v: %v", new_department.DepartmentHeadEmail, err)
-		}
-		log.Printf("credentials sent to %v", new_department.DepartmentHeadEmail)
-	}()
+	go sendInvitation(new_department.DepartmentHeadEmail, new_department.DepartmentHeadName, "department_head", token, invitation.ExpiresAt)
 
 	return http.StatusCreated, nil
 }
 
 func (br *BranchRepo) CreateWarehouse(e echo.Context) (int, error) {
 
-	status, claims, err := utils.VerifyUserToken(e, "branch_head", br.db)
+	status, scope, err := authorize(e, br.db, "warehouses.manage")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(br.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "branch_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var new_warehouse models.CreateWarehouseModel
 
 	if err := e.Bind(&new_warehouse); err != nil {
@@ -118,61 +88,41 @@ func (br *BranchRepo) CreateWarehouse(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
-	password, err := utils.GeneratePassword()
+	status, branch_id, err := targetNode(e, br.db, scope, "branch", new_warehouse.BranchID)
 	if err != nil {
-		log.Printf("error while generating password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
+		return status, err
 	}
 
-	for !utils.StrongPasswordValidator(password) {
-		password, err = utils.GeneratePassword()
-		if err != nil {
-			log.Printf("error while generating password: %v", err)
-			return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
-		}
+	hash, err := unusablePassword()
+	if err != nil {
+		return http.StatusInternalServerError, err
 	}
 
-	hash, err := utils.HashPassword(password)
+	token, invitation, err := newInvitation(new_warehouse.WarehouseUserName, "warehouses", scope.Role, scope.UserID)
 	if err != nil {
-		log.Printf("error while hashing password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to secure your password, please try again")
+		return http.StatusInternalServerError, err
 	}
 
-	status, err = query.CreateWarehouse(new_warehouse, claims.UserID, hash)
+	query := database.NewDBinstance(br.db)
+
+	status, err = query.CreateWarehouse(new_warehouse, branch_id, hash, invitation)
 	if err != nil {
 		log.Printf("error while storing Warehouse data in DB: %v", err)
 		return status, fmt.Errorf("unable to create warehouse at the moment, please try again later")
 	}
 
-	go func() {
-		log.Printf("sending login credentials to %v", new_warehouse.WarehouseUserEmail)
-		if err := utils.SendLoginCredentials(new_warehouse.WarehouseUserEmail, password); err != nil {
-			log.Printf("error while sending login credentials to %v: %v", new_warehouse.WarehouseUserEmail, err)
-		}
-		log.Printf("credentials sent to %v", new_warehouse.WarehouseUserEmail)
-	}()
+	go sendInvitation(new_warehouse.WarehouseUserEmail, new_warehouse.WarehouseUserName, "warehouses", token, invitation.ExpiresAt)
 
 	return http.StatusCreated, nil
 }
 
 func (br *BranchRepo) UpdateDepartmentHead(e echo.Context) (int, error) {
 
-	status, claims, err := utils.VerifyUserToken(e, "branch_head", br.db)
+	status, scope, err := authorize(e, br.db, "departments.manage")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(br.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "branch_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var new_department_head models.UpdateDepartmentHeadModel
 
 	if err := e.Bind(&new_department_head); err != nil {
@@ -185,60 +135,40 @@ func (br *BranchRepo) UpdateDepartmentHead(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
-	password, err := utils.GeneratePassword()
+	status, _, err = targetNode(e, br.db, scope, "department", new_department_head.DepartmentID)
 	if err != nil {
-		log.Printf("error while generating password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
+		return status, err
 	}
 
-	for !utils.StrongPasswordValidator(password) {
-		password, err = utils.GeneratePassword()
-		if err != nil {
-			log.Printf("error while generating password: %v", err)
-			return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
-		}
+	hash, err := unusablePassword()
+	if err != nil {
+		return http.StatusInternalServerError, err
 	}
 
-	hash, err := utils.HashPassword(password)
+	token, invitation, err := newInvitation(new_department_head.NewDepartmentHeadName, "department_head", scope.Role, scope.UserID)
 	if err != nil {
-		log.Printf("error while hashing password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to secure your password, please try again")
+		return http.StatusInternalServerError, err
 	}
 
-	if status, err := query.UpdateDepartmentHead(new_department_head, claims.UserID, hash); err != nil {
+	query := database.NewDBinstance(br.db)
+
+	if status, err := query.UpdateDepartmentHead(new_department_head, scope.UserID, hash, invitation); err != nil {
 		log.Printf("error while storing DepartmentHead data in DB: %v", err)
 		return status, fmt.Errorf("unable to update department head at the moment, please try again later")
 	}
 
-	go func() {
-		log.Printf("sending login credentials to %v", new_department_head.NewDepartmentHeadEmail)
-		if err := utils.SendLoginCredentials(new_department_head.NewDepartmentHeadEmail, password); err != nil {
-			log.Printf("error while sending login credentials to %v: %v", new_department_head.NewDepartmentHeadEmail, err)
-		}
-		log.Printf("credentials sent to %v", new_department_head.NewDepartmentHeadEmail)
-	}()
+	go sendInvitation(new_department_head.NewDepartmentHeadEmail, new_department_head.NewDepartmentHeadName, "department_head", token, invitation.ExpiresAt)
 
 	return http.StatusOK, nil
 }
 
 func (br *BranchRepo) UpdateWarehouseHead(e echo.Context) (int, error) {
 
-	status, claims, err := utils.VerifyUserToken(e, "branch_head", br.db)
+	status, scope, err := authorize(e, br.db, "warehouses.manage")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(br.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "branch_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var new_warehouse_head models.UpdateWarehouseHeadModel
 
 	if err := e.Bind(&new_warehouse_head); err != nil {
@@ -251,60 +181,40 @@ func (br *BranchRepo) UpdateWarehouseHead(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
-	password, err := utils.GeneratePassword()
+	status, _, err = targetNode(e, br.db, scope, "warehouse", new_warehouse_head.WarehouseID)
 	if err != nil {
-		log.Printf("error while generating password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
+		return status, err
 	}
 
-	for !utils.StrongPasswordValidator(password) {
-		password, err = utils.GeneratePassword()
-		if err != nil {
-			log.Printf("error while generating password: %v", err)
-			return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
-		}
+	hash, err := unusablePassword()
+	if err != nil {
+		return http.StatusInternalServerError, err
 	}
 
-	hash, err := utils.HashPassword(password)
+	token, invitation, err := newInvitation(new_warehouse_head.NewWarehouseHeadName, "warehouses", scope.Role, scope.UserID)
 	if err != nil {
-		log.Printf("error while hashing password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to secure your password, please try again")
+		return http.StatusInternalServerError, err
 	}
 
-	if status, err := query.UpdateWarehouseHead(new_warehouse_head, claims.UserID, hash); err != nil {
+	query := database.NewDBinstance(br.db)
+
+	if status, err := query.UpdateWarehouseHead(new_warehouse_head, scope.UserID, hash, invitation); err != nil {
 		log.Printf("error while storing WarehouseHead data in DB: %v", err)
 		return status, fmt.Errorf("unable to update warehouse head at the moment, please try again later")
 	}
 
-	go func() {
-		log.Printf("sending login credentials to %v", new_warehouse_head.NewWarehouseHeadEmail)
-		if err := utils.SendLoginCredentials(new_warehouse_head.NewWarehouseHeadEmail, password); err != nil {
-			log.Printf("error while sending login credentials to %v: %v", new_warehouse_head.NewWarehouseHeadEmail, err)
-		}
-		log.Printf("credentials sent to %v", new_warehouse_head.NewWarehouseHeadEmail)
-	}()
+	go sendInvitation(new_warehouse_head.NewWarehouseHeadEmail, new_warehouse_head.NewWarehouseHeadName, "warehouses", token, invitation.ExpiresAt)
 
 	return http.StatusOK, nil
 }
 
 func (br *BranchRepo) DeleteDepartment(e echo.Context) (int, error) {
 
-	status, claims, err := utils.VerifyUserToken(e, "branch_head", br.db)
+	status, scope, err := authorize(e, br.db, "departments.manage")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(br.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "branch_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var department models.DeleteDepartmentModel
 
 	if err := e.Bind(&department); err != nil {
@@ -317,7 +227,14 @@ func (br *BranchRepo) DeleteDepartment(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
-	UserPassword, _, _, ok, err := query.GetUserPasswordID(claims.UserEmail, claims.UserType)
+	status, _, err = targetNode(e, br.db, scope, "department", department.DepartmentID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(br.db)
+
+	UserPassword, _, _, ok, err := query.GetUserPasswordID(scope.UserEmail, scope.Role)
 	if err != nil {
 		log.Printf("Error checking user details: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
@@ -331,18 +248,12 @@ func (br *BranchRepo) DeleteDepartment(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("invalid user details")
 	}
 
-	ok, err = query.CheckIfDepartmentUnderBranchHead(department.DepartmentID, claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
-	status, err = query.DeleteDepartment(department.DepartmentID, claims.UserID)
+	status, err = query.DeleteDepartment(department.DepartmentID, scope.UserID, department.PreviewToken)
 	if err != nil {
 		log.Printf("error while deleting the department %v: %v", department.DepartmentID, err)
+		if status == http.StatusConflict || status == http.StatusNotFound || status == http.StatusPreconditionRequired {
+			return status, err
+		}
 		return http.StatusInternalServerError, fmt.Errorf("database error")
 	}
 
@@ -351,22 +262,11 @@ func (br *BranchRepo) DeleteDepartment(e echo.Context) (int, error) {
 
 func (br *BranchRepo) DeleteWarehouse(e echo.Context) (int, error) {
 
-	status, claims, err := utils.VerifyUserToken(e, "branch_head", br.db)
+	status, scope, err := authorize(e, br.db, "warehouses.manage")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(br.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "branch_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var warehouse models.DeleteWarehouseModel
 
 	if err := e.Bind(&warehouse); err != nil {
@@ -379,16 +279,14 @@ func (br *BranchRepo) DeleteWarehouse(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
-	ok, err = query.VerifyUser(claims.UserEmail, "branch_head", claims.UserID)
+	status, _, err = targetNode(e, br.db, scope, "warehouse", warehouse.WarehouseID)
 	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
+		return status, err
 	}
 
-	UserPassword, _, _, ok, err := query.GetUserPasswordID(claims.UserEmail, claims.UserType)
+	query := database.NewDBinstance(br.db)
+
+	UserPassword, _, _, ok, err := query.GetUserPasswordID(scope.UserEmail, scope.Role)
 	if err != nil {
 		log.Printf("Error checking user details: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
@@ -401,20 +299,57 @@ func (br *BranchRepo) DeleteWarehouse(e echo.Context) (int, error) {
 		log.Printf("wrong password %v: %v", warehouse.BranchHeadPassword, err)
 		return http.StatusBadRequest, fmt.Errorf("invalid user details")
 	}
-	ok, err = query.CheckIfWarehouseUnderBranchHead(warehouse.WarehouseID, claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
 
-	status, err = query.DeleteWarehouse(warehouse.WarehouseID, claims.UserID)
+	status, err = query.DeleteWarehouse(warehouse.WarehouseID, scope.UserID, warehouse.PreviewToken)
 	if err != nil {
 		log.Printf("error while deleting the warehouse %v: %v", warehouse.WarehouseID, err)
+		if status == http.StatusConflict || status == http.StatusNotFound || status == http.StatusPreconditionRequired {
+			return status, err
+		}
 		return status, fmt.Errorf("database error")
 	}
 
 	return status, nil
 }
+
+func (br *BranchRepo) PreviewDeleteDepartment(e echo.Context) (int, models.DeletionImpactModel, error) {
+	status, scope, err := authorize(e, br.db, "departments.manage")
+	if err != nil {
+		return status, models.DeletionImpactModel{}, err
+	}
+
+	DepartmentID, err := strconv.Atoi(e.QueryParam("department_id"))
+	if err != nil {
+		log.Printf("error while parsing department id: %v", err)
+		return http.StatusBadRequest, models.DeletionImpactModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if status, _, err := targetNode(e, br.db, scope, "department", DepartmentID); err != nil {
+		return status, models.DeletionImpactModel{}, err
+	}
+
+	query := database.NewDBinstance(br.db)
+
+	return query.GetDeletionImpact("department", DepartmentID)
+}
+
+func (br *BranchRepo) PreviewDeleteWarehouse(e echo.Context) (int, models.DeletionImpactModel, error) {
+	status, scope, err := authorize(e, br.db, "warehouses.manage")
+	if err != nil {
+		return status, models.DeletionImpactModel{}, err
+	}
+
+	WarehouseID, err := strconv.Atoi(e.QueryParam("warehouse_id"))
+	if err != nil {
+		log.Printf("error while parsing warehouse id: %v", err)
+		return http.StatusBadRequest, models.DeletionImpactModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if status, _, err := targetNode(e, br.db, scope, "warehouse", WarehouseID); err != nil {
+		return status, models.DeletionImpactModel{}, err
+	}
+
+	query := database.NewDBinstance(br.db)
+
+	return query.GetDeletionImpact("warehouse", WarehouseID)
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/labstack/echo/v4"
+)
+
+type CatalogueRepo struct {
+	db *sql.DB
+}
+
+func NewCatalogueRepo(db *sql.DB) *CatalogueRepo {
+	return &CatalogueRepo{db: db}
+}
+
+func (cr *CatalogueRepo) CreateCategory(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, cr.db, "catalogue.manage")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var category models.CreateCategoryModel
+
+	if err := e.Bind(&category); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	category.Name = strings.ToLower(strings.TrimSpace(category.Name))
+
+	if err := validate.Struct(category); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.CreateCategory(scope.OrgID, category.Name)
+}
+
+func (cr *CatalogueRepo) GetCategories(e echo.Context) (int, []models.CategoryModel, error) {
+	status, scope, err := getUserScope(e, cr.db)
+	if err != nil {
+		return status, []models.CategoryModel{}, err
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.GetCategories(scope.OrgID)
+}
+
+func (cr *CatalogueRepo) CreateCatalogueModel(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, cr.db, "catalogue.manage")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var model models.CreateCatalogueModel
+
+	if err := e.Bind(&model); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	model.Name = strings.TrimSpace(model.Name)
+	model.Manufacturer = strings.TrimSpace(model.Manufacturer)
+	model.ModelNumber = strings.TrimSpace(model.ModelNumber)
+
+	if err := validate.Struct(model); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.CreateCatalogueModel(scope.OrgID, model)
+}
+
+func (cr *CatalogueRepo) GetCatalogue(e echo.Context) (int, []models.CatalogueModel, models.PageModel, error) {
+	spec, err := queryspec.Parse(e.QueryParams(), database.CatalogueResource)
+	if err != nil {
+		log.Printf("invalid list parameters: %v", err)
+		return http.StatusBadRequest, []models.CatalogueModel{}, models.PageModel{}, err
+	}
+
+	status, scope, err := getUserScope(e, cr.db)
+	if err != nil {
+		return status, []models.CatalogueModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.GetCatalogue(scope, spec)
+}
+
+func (cr *CatalogueRepo) GetCatalogueStock(e echo.Context) (int, []models.CatalogueStockModel, error) {
+	catalogue_id, err := strconv.Atoi(e.QueryParam("catalogue_id"))
+	if err != nil || catalogue_id <= 0 {
+		log.Printf("invalid catalogue id: %v", e.QueryParam("catalogue_id"))
+		return http.StatusBadRequest, []models.CatalogueStockModel{}, fmt.Errorf("invalid catalogue id")
+	}
+
+	status, scope, err := getUserScope(e, cr.db)
+	if err != nil {
+		return status, []models.CatalogueStockModel{}, err
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.GetCatalogueStock(scope, catalogue_id)
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/labstack/echo/v4"
+)
+
+type ChecklistRepo struct {
+	db *sql.DB
+}
+
+func NewChecklistRepo(db *sql.DB) *ChecklistRepo {
+	return &ChecklistRepo{db: db}
+}
+
+func (cr *ChecklistRepo) CreateOnboardingTemplate(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, cr.db, "checklists.templates")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var template models.CreateOnboardingTemplateModel
+
+	if err := e.Bind(&template); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	template.Name = strings.TrimSpace(template.Name)
+	template.JobRole = strings.TrimSpace(template.JobRole)
+
+	if err := validate.Struct(template); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	seen := make(map[int]bool, len(template.Items))
+	for _, item := range template.Items {
+		if seen[item.CatalogueID] {
+			return http.StatusBadRequest, -1, fmt.Errorf("catalogue model %v is listed more than once", item.CatalogueID)
+		}
+		seen[item.CatalogueID] = true
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.CreateOnboardingTemplate(scope.OrgID, template)
+}
+
+func (cr *ChecklistRepo) GetOnboardingTemplates(e echo.Context) (int, []models.OnboardingTemplateModel, error) {
+	status, scope, err := getUserScope(e, cr.db)
+	if err != nil {
+		return status, []models.OnboardingTemplateModel{}, err
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.GetOnboardingTemplates(scope.OrgID)
+}
+
+func (cr *ChecklistRepo) DeleteOnboardingTemplate(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, cr.db, "checklists.templates")
+	if err != nil {
+		return status, err
+	}
+
+	var template models.DeleteOnboardingTemplateModel
+
+	if err := e.Bind(&template); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(template); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.DeleteOnboardingTemplate(scope.OrgID, template.TemplateID)
+}
+
+func (cr *ChecklistRepo) StartOnboarding(e echo.Context) (int, models.ChecklistDetailsModel, error) {
+	status, scope, err := authorize(e, cr.db, "checklists.manage")
+	if err != nil {
+		return status, models.ChecklistDetailsModel{}, err
+	}
+
+	var onboarding models.StartOnboardingModel
+
+	if err := e.Bind(&onboarding); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.ChecklistDetailsModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(onboarding); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.ChecklistDetailsModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.StartOnboarding(scope, onboarding)
+}
+
+func (cr *ChecklistRepo) StartOffboarding(e echo.Context) (int, models.ChecklistDetailsModel, error) {
+	status, scope, err := authorize(e, cr.db, "checklists.manage")
+	if err != nil {
+		return status, models.ChecklistDetailsModel{}, err
+	}
+
+	var offboarding models.StartOffboardingModel
+
+	if err := e.Bind(&offboarding); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.ChecklistDetailsModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(offboarding); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.ChecklistDetailsModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.StartOffboarding(scope, offboarding.CustodianID)
+}
+
+func (cr *ChecklistRepo) GetChecklists(e echo.Context) (int, []models.ChecklistModel, models.PageModel, error) {
+	status, scope, err := getUserScope(e, cr.db)
+	if err != nil {
+		return status, []models.ChecklistModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.ChecklistsResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.ChecklistModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.GetChecklists(scope, spec)
+}
+
+func (cr *ChecklistRepo) GetChecklistDetails(e echo.Context) (int, models.ChecklistDetailsModel, error) {
+	checklist_id, err := strconv.Atoi(e.QueryParam("checklist_id"))
+	if err != nil || checklist_id <= 0 {
+		log.Printf("invalid checklist id: %v", e.QueryParam("checklist_id"))
+		return http.StatusBadRequest, models.ChecklistDetailsModel{}, fmt.Errorf("invalid checklist id")
+	}
+
+	status, scope, err := getUserScope(e, cr.db)
+	if err != nil {
+		return status, models.ChecklistDetailsModel{}, err
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.GetChecklistDetails(scope, checklist_id)
+}
+
+func (cr *ChecklistRepo) ResolveChecklistItem(e echo.Context) (int, models.ChecklistDetailsModel, error) {
+	status, scope, err := authorizeHandover(e, cr.db, "checklists.manage", "units.assign")
+	if err != nil {
+		return status, models.ChecklistDetailsModel{}, err
+	}
+
+	var resolve models.ResolveChecklistItemModel
+
+	if err := e.Bind(&resolve); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.ChecklistDetailsModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(resolve); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.ChecklistDetailsModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.ResolveChecklistItem(scope, resolve)
+}
+
+func (cr *ChecklistRepo) CancelChecklist(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, cr.db, "checklists.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var checklist models.CancelChecklistModel
+
+	if err := e.Bind(&checklist); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(checklist); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.CancelChecklist(scope, checklist.ChecklistID)
+}
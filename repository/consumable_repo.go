@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/Hacfy/IT_INVENTORY/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type ConsumableRepo struct {
+	db *sql.DB
+}
+
+func NewConsumableRepo(db *sql.DB) *ConsumableRepo {
+	return &ConsumableRepo{db: db}
+}
+
+func (cr *ConsumableRepo) verifyWarehouse(e echo.Context, permission string) (int, int, error) {
+	status, claims, err := utils.VerifyUserToken(e, "warehouses", cr.db)
+	if err != nil {
+		return status, -1, err
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
+	if err != nil {
+		log.Printf("Error checking user details: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	} else if !ok {
+		log.Printf("Invalid user details")
+		return http.StatusUnauthorized, -1, fmt.Errorf("invalid user details")
+	}
+
+	if status, err := checkPermission(cr.db, claims, permission); err != nil {
+		return status, -1, err
+	}
+
+	return http.StatusOK, claims.UserID, nil
+}
+
+func (cr *ConsumableRepo) StockIn(e echo.Context) (int, models.ConsumableLedgerModel, error) {
+	status, warehouse_id, err := cr.verifyWarehouse(e, "consumables.manage")
+	if err != nil {
+		return status, models.ConsumableLedgerModel{}, err
+	}
+
+	var stockIn models.ConsumableStockInModel
+
+	if err := e.Bind(&stockIn); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.ConsumableLedgerModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(stockIn); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.ConsumableLedgerModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.RecordConsumableTransaction(warehouse_id, warehouse_id, "stock_in", stockIn.ComponentID, stockIn.Quantity, 0, 0, strings.TrimSpace(stockIn.Note))
+}
+
+func (cr *ConsumableRepo) Issue(e echo.Context) (int, models.ConsumableLedgerModel, error) {
+	status, warehouse_id, err := cr.verifyWarehouse(e, "consumables.manage")
+	if err != nil {
+		return status, models.ConsumableLedgerModel{}, err
+	}
+
+	var issue models.ConsumableIssueModel
+
+	if err := e.Bind(&issue); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.ConsumableLedgerModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(issue); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.ConsumableLedgerModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	if issue.RequestID == 0 && (issue.WorkspaceID <= 0 || issue.Quantity == 0) {
+		return http.StatusBadRequest, models.ConsumableLedgerModel{}, fmt.Errorf("workspace_id and quantity are required without a request_id")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.RecordConsumableTransaction(warehouse_id, warehouse_id, "issue", issue.ComponentID, issue.Quantity, issue.WorkspaceID, issue.RequestID, strings.TrimSpace(issue.Note))
+}
+
+func (cr *ConsumableRepo) Adjust(e echo.Context) (int, models.ConsumableLedgerModel, error) {
+	status, warehouse_id, err := cr.verifyWarehouse(e, "consumables.manage")
+	if err != nil {
+		return status, models.ConsumableLedgerModel{}, err
+	}
+
+	var adjustment models.ConsumableAdjustmentModel
+
+	if err := e.Bind(&adjustment); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.ConsumableLedgerModel{}, fmt.Errorf("invalid request format")
+	}
+
+	adjustment.Note = strings.TrimSpace(adjustment.Note)
+
+	if err := validate.Struct(adjustment); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.ConsumableLedgerModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.RecordConsumableTransaction(warehouse_id, warehouse_id, "adjustment", adjustment.ComponentID, adjustment.Quantity, 0, 0, adjustment.Note)
+}
+
+func (cr *ConsumableRepo) GetConsumableLedger(e echo.Context) (int, []models.ConsumableLedgerModel, models.PageModel, error) {
+	status, warehouse_id, err := cr.verifyWarehouse(e, "details.view")
+	if err != nil {
+		return status, []models.ConsumableLedgerModel{}, models.PageModel{}, err
+	}
+
+	component_id, err := strconv.Atoi(e.QueryParam("component_id"))
+	if err != nil || component_id <= 0 {
+		log.Printf("invalid component id: %v", e.QueryParam("component_id"))
+		return http.StatusBadRequest, []models.ConsumableLedgerModel{}, models.PageModel{}, fmt.Errorf("invalid component id")
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.ConsumableLedgerResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.ConsumableLedgerModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.GetConsumableLedger(component_id, warehouse_id, spec)
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/Hacfy/IT_INVENTORY/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type CustodianRepo struct {
+	db *sql.DB
+}
+
+func NewCustodianRepo(db *sql.DB) *CustodianRepo {
+	return &CustodianRepo{db: db}
+}
+
+func (cr *CustodianRepo) CreateCustodian(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, cr.db, "custodians.manage")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var custodian models.CreateCustodianModel
+
+	if err := e.Bind(&custodian); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	custodian.Name = strings.TrimSpace(custodian.Name)
+	custodian.Email = strings.ToLower(strings.TrimSpace(custodian.Email))
+	custodian.EmployeeCode = strings.TrimSpace(custodian.EmployeeCode)
+
+	if err := validate.Struct(custodian); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.CreateCustodian(scope, custodian)
+}
+
+func (cr *CustodianRepo) GetCustodians(e echo.Context) (int, []models.CustodianModel, models.PageModel, error) {
+	status, scope, err := getUserScope(e, cr.db)
+	if err != nil {
+		return status, []models.CustodianModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.CustodiansResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.CustodianModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.GetCustodians(scope, spec)
+}
+
+func (cr *CustodianRepo) DeactivateCustodian(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, cr.db, "custodians.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var custodian models.DeactivateCustodianModel
+
+	if err := e.Bind(&custodian); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(custodian); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.DeactivateCustodian(scope, custodian.CustodianID)
+}
+
+func (cr *CustodianRepo) AssignCustody(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, cr.db, "custodians.manage")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var custody models.AssignCustodyModel
+
+	if err := e.Bind(&custody); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	custody.Note = strings.TrimSpace(custody.Note)
+
+	if err := validate.Struct(custody); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		log.Printf("error while generating acknowledgement token: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("failed to assign unit, please try again later")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	status, custodian, holding, err := query.AssignCustody(scope, custody, utils.HashToken(token))
+	if err != nil {
+		return status, -1, err
+	}
+
+	go func() {
+		unit := fmt.Sprintf("%s %s-%v", holding.ComponentName, holding.Prefix, holding.UnitID)
+		if holding.AssetTag != "" {
+			unit += " (" + holding.AssetTag + ")"
+		}
+		log.Printf("sending custody acknowledgement to %v", custodian.Email)
+		if err := utils.SendCustodyAcknowledgement(custodian.Email, custodian.Name, unit, token); err != nil {
+			log.Printf("error while sending custody acknowledgement to %v: %v", custodian.Email, err)
+		}
+	}()
+
+	return status, holding.CustodyID, nil
+}
+
+func (cr *CustodianRepo) ReturnCustody(e echo.Context) (int, error) {
+	status, scope, err := authorizeHandover(e, cr.db, "custodians.manage", "units.assign")
+	if err != nil {
+		return status, err
+	}
+
+	var custody models.ReturnCustodyModel
+
+	if err := e.Bind(&custody); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(custody); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.ReturnCustody(scope, custody.ComponentID, custody.UnitID)
+}
+
+// AcknowledgeCustody is called by the custodian with the token they were emailed, it needs no login
+func (cr *CustodianRepo) AcknowledgeCustody(e echo.Context) (int, error) {
+	var acknowledgement models.AcknowledgeCustodyModel
+
+	if err := e.Bind(&acknowledgement); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	acknowledgement.Token = strings.TrimSpace(acknowledgement.Token)
+
+	if err := validate.Struct(acknowledgement); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.AcknowledgeCustody(utils.HashToken(acknowledgement.Token))
+}
+
+func (cr *CustodianRepo) GetCustodianHoldings(e echo.Context) (int, models.CustodianHoldingsModel, error) {
+	custodian_id, err := strconv.Atoi(e.QueryParam("custodian_id"))
+	if err != nil || custodian_id <= 0 {
+		log.Printf("invalid custodian id: %v", e.QueryParam("custodian_id"))
+		return http.StatusBadRequest, models.CustodianHoldingsModel{}, fmt.Errorf("invalid custodian id")
+	}
+
+	status, scope, err := getUserScope(e, cr.db)
+	if err != nil {
+		return status, models.CustodianHoldingsModel{}, err
+	}
+
+	query := database.NewDBinstance(cr.db)
+
+	return query.GetCustodianHoldings(scope, custodian_id)
+}
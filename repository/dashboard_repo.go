@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+type DashboardRepo struct {
+	db *sql.DB
+}
+
+func NewDashboardRepo(db *sql.DB) *DashboardRepo {
+	return &DashboardRepo{db: db}
+}
+
+func (dr *DashboardRepo) GetDashboard(e echo.Context) (int, models.DashboardModel, error) {
+	WarrantyDays, _ := strconv.Atoi(e.QueryParam("warranty_days"))
+	if WarrantyDays <= 0 || WarrantyDays > 365 {
+		WarrantyDays = 30
+	}
+
+	status, scope, err := authorize(e, dr.db, "reports.view")
+	if err != nil {
+		return status, models.DashboardModel{}, err
+	}
+
+	query := database.NewDBinstance(dr.db)
+
+	return query.GetDashboard(scope, WarrantyDays)
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/labstack/echo/v4"
+)
+
+type DelegationRepo struct {
+	db *sql.DB
+}
+
+func NewDelegationRepo(db *sql.DB) *DelegationRepo {
+	return &DelegationRepo{db: db}
+}
+
+func (dr *DelegationRepo) CreateDelegation(e echo.Context) (int, int, error) {
+	status, scope, err := getUserScope(e, dr.db)
+	if err != nil {
+		return status, -1, err
+	}
+
+	var delegation models.CreateDelegationModel
+
+	if err := e.Bind(&delegation); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	delegation.DelegateEmail = strings.ToLower(strings.TrimSpace(delegation.DelegateEmail))
+	delegation.Reason = strings.TrimSpace(delegation.Reason)
+
+	if err := validate.Struct(delegation); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(dr.db)
+
+	status, delegate_org, err := getUserOrg(query, delegation.DelegateEmail)
+	if err != nil {
+		return status, -1, err
+	}
+
+	return query.CreateDelegation(scope, delegation, delegate_org)
+}
+
+func (dr *DelegationRepo) RevokeDelegation(e echo.Context) (int, error) {
+	status, scope, err := getUserScope(e, dr.db)
+	if err != nil {
+		return status, err
+	}
+
+	var revoke models.RevokeDelegationModel
+
+	if err := e.Bind(&revoke); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(revoke); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(dr.db)
+
+	return query.RevokeDelegation(scope, revoke.DelegationID)
+}
+
+func (dr *DelegationRepo) GetDelegations(e echo.Context) (int, []models.DelegationModel, models.PageModel, error) {
+	status, scope, err := getUserScope(e, dr.db)
+	if err != nil {
+		return status, []models.DelegationModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.DelegationsResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.DelegationModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(dr.db)
+
+	return query.GetDelegations(scope, spec)
+}
+
+func (dr *DelegationRepo) GetDelegationActivity(e echo.Context) (int, []models.DelegationActivityModel, models.PageModel, error) {
+	status, scope, err := getUserScope(e, dr.db)
+	if err != nil {
+		return status, []models.DelegationActivityModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.DelegationActivityResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.DelegationActivityModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(dr.db)
+
+	return query.GetDelegationActivity(scope, spec)
+}
@@ -9,7 +9,6 @@ import (
 
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
 	"github.com/Hacfy/IT_INVENTORY/pkg/database"
-	"github.com/Hacfy/IT_INVENTORY/pkg/utils"
 	"github.com/labstack/echo/v4"
 )
 
@@ -24,20 +23,10 @@ func NewDepartmentRepo(db *sql.DB) *DepartmentRepo {
 }
 
 func (dr *DepartmentRepo) CreateWorkspace(e echo.Context) (int, int, error) {
-	status, claims, err := utils.VerifyUserToken(e, "department_head", dr.db)
+	status, scope, err := authorize(e, dr.db, "workspaces.manage")
 	if err != nil {
 		return status, -1, err
 	}
-	query := database.NewDBinstance(dr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "department_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, -1, fmt.Errorf("invalid user details")
-	}
 
 	var new_workspace models.CreateWorkspaceModel
 
@@ -51,7 +40,14 @@ func (dr *DepartmentRepo) CreateWorkspace(e echo.Context) (int, int, error) {
 		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
 	}
 
-	status, workspace_id, err := query.CreateWorkspace(new_workspace, claims.UserID)
+	status, department_id, err := targetNode(e, dr.db, scope, "department", new_workspace.DepartmentID)
+	if err != nil {
+		return status, -1, err
+	}
+
+	query := database.NewDBinstance(dr.db)
+
+	status, workspace_id, err := query.CreateWorkspace(new_workspace, department_id)
 	if err != nil {
 		log.Printf("error while storing Workspace data in DB: %v", err)
 		return status, -1, fmt.Errorf("unable to create workspace at the moment, please try again later")
@@ -61,22 +57,11 @@ func (dr *DepartmentRepo) CreateWorkspace(e echo.Context) (int, int, error) {
 }
 
 func (dr *DepartmentRepo) DeleteWorkspace(e echo.Context) (int, error) {
-	status, claims, err := utils.VerifyUserToken(e, "department_head", dr.db)
+	status, scope, err := authorize(e, dr.db, "workspaces.manage")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(dr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "department_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var workspace models.DeleteWorkspaceModel
 
 	if err := e.Bind(&workspace); err != nil {
@@ -89,9 +74,16 @@ func (dr *DepartmentRepo) DeleteWorkspace(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
+	status, department_id, err := targetNode(e, dr.db, scope, "department", workspace.DepartmentID)
+	if err != nil {
+		return status, err
+	}
+
 	workspace.WorkspaceName = strings.ToLower(workspace.WorkspaceName)
 
-	status, err = query.DeleteWorkspace(workspace, claims.UserID)
+	query := database.NewDBinstance(dr.db)
+
+	status, err = query.DeleteWorkspace(workspace, department_id, scope.UserID)
 	if err != nil {
 		log.Printf("error while deleting the workspace %v: %v", workspace.WorkspaceID, err)
 		return status, fmt.Errorf("error while deleting the workspace, please try again later")
@@ -101,20 +93,10 @@ func (dr *DepartmentRepo) DeleteWorkspace(e echo.Context) (int, error) {
 }
 
 func (dr *DepartmentRepo) RaiseIssue(e echo.Context) (int, int, error) {
-	status, claims, err := utils.VerifyUserToken(e, "department_head", dr.db)
+	status, scope, err := authorize(e, dr.db, "issues.raise")
 	if err != nil {
 		return status, -1, err
 	}
-	query := database.NewDBinstance(dr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "department_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, -1, fmt.Errorf("invalid user details")
-	}
 
 	var Issue models.IssueModel
 
@@ -128,6 +110,12 @@ func (dr *DepartmentRepo) RaiseIssue(e echo.Context) (int, int, error) {
 		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
 	}
 
+	if status, _, err := targetNode(e, dr.db, scope, "department", Issue.DepartmentID); err != nil {
+		return status, -1, err
+	}
+
+	query := database.NewDBinstance(dr.db)
+
 	status, IssueID, err := query.RaiseIssue(Issue)
 	if err != nil {
 		log.Printf("error while storing Issue data in DB: %v", err)
@@ -138,20 +126,10 @@ func (dr *DepartmentRepo) RaiseIssue(e echo.Context) (int, int, error) {
 }
 
 func (dr *DepartmentRepo) RequestNewUnits(e echo.Context) (int, map[int]int, error) {
-	Status, claims, err := utils.VerifyUserToken(e, "department_head", dr.db)
+	Status, scope, err := authorize(e, dr.db, "requests.raise")
 	if err != nil {
 		return Status, map[int]int{}, err
 	}
-	query := database.NewDBinstance(dr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "department_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, map[int]int{}, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, map[int]int{}, fmt.Errorf("invalid user details")
-	}
 
 	var new_unit models.RequestNewUnitModel
 
@@ -165,7 +143,13 @@ func (dr *DepartmentRepo) RequestNewUnits(e echo.Context) (int, map[int]int, err
 		return http.StatusBadRequest, map[int]int{}, fmt.Errorf("failed to validate request")
 	}
 
-	ok, err = query.CheckIfWarehouseIDExistsInTheDepartmentsBranch(new_unit.WarehouseID, claims.UserID)
+	if status, _, err := targetNode(e, dr.db, scope, "department", new_unit.DepartmentID); err != nil {
+		return status, map[int]int{}, err
+	}
+
+	query := database.NewDBinstance(dr.db)
+
+	ok, err := query.CheckIfWarehouseIDExistsInTheDepartmentsBranch(new_unit.WarehouseID, new_unit.DepartmentID)
 	if err != nil {
 		log.Printf("Error checking warehouse details: %v", err)
 		return http.StatusInternalServerError, map[int]int{}, fmt.Errorf("database error")
@@ -186,7 +170,7 @@ func (dr *DepartmentRepo) RequestNewUnits(e echo.Context) (int, map[int]int, err
 			return http.StatusUnauthorized, map[int]int{}, fmt.Errorf("invalid component details")
 		}
 
-		Status, requestID, err := query.RequestNewUnits(new_unit.DepartmentID, new_unit.WorkspaceID, new_unit.WarehouseID, Key, Value, prefix, claims.UserID)
+		Status, requestID, err := query.RequestNewUnits(new_unit.DepartmentID, new_unit.WorkspaceID, new_unit.WarehouseID, Key, Value, prefix, scope.UserID)
 		if err != nil {
 			log.Printf("error while requesting new units: %v", err)
 			return Status, map[int]int{}, fmt.Errorf("unable to request new units at the moment, please try again later")
@@ -199,19 +183,9 @@ func (dr *DepartmentRepo) RequestNewUnits(e echo.Context) (int, map[int]int, err
 }
 
 func (dr *DepartmentRepo) GetAllDepartmentRequests(e echo.Context) (int, []models.AllRequestsModel, error) {
-	Status, claims, err := utils.VerifyUserToken(e, "department_head", dr.db)
+	Status, scope, err := authorize(e, dr.db, "details.view")
 	if err != nil {
-		return Status, []models.AllRequestsModel{}, fmt.Errorf("invalid use token")
-	}
-	query := database.NewDBinstance(dr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "department_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, []models.AllRequestsModel{}, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, []models.AllRequestsModel{}, fmt.Errorf("invalid user details")
+		return Status, []models.AllRequestsModel{}, err
 	}
 
 	var getAllRequests models.GetAllRequestsModel
@@ -227,6 +201,12 @@ func (dr *DepartmentRepo) GetAllDepartmentRequests(e echo.Context) (int, []model
 		return http.StatusBadRequest, []models.AllRequestsModel{}, fmt.Errorf("failed to validate request")
 	}
 
+	if status, _, err := targetNode(e, dr.db, scope, "department", getAllRequests.DepartmentID); err != nil {
+		return status, []models.AllRequestsModel{}, err
+	}
+
+	query := database.NewDBinstance(dr.db)
+
 	requests, err := query.GetAllRequests(getAllRequests.DepartmentID)
 	if err != nil {
 		log.Printf("error while fetching requests: %v", err)
@@ -237,19 +217,9 @@ func (dr *DepartmentRepo) GetAllDepartmentRequests(e echo.Context) (int, []model
 }
 
 func (dr *DepartmentRepo) GetDepartmentRequestDetails(e echo.Context) (int, models.RequestDetailsModel, error) {
-	Status, claims, err := utils.VerifyUserToken(e, "department_head", dr.db)
-	if err != nil {
-		return Status, models.RequestDetailsModel{}, fmt.Errorf("invalid use token")
-	}
-	query := database.NewDBinstance(dr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "department_head", claims.UserID)
+	Status, scope, err := authorize(e, dr.db, "details.view")
 	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, models.RequestDetailsModel{}, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, models.RequestDetailsModel{}, fmt.Errorf("invalid user details")
+		return Status, models.RequestDetailsModel{}, err
 	}
 
 	var GetRequestDetails models.GetRequestDetailsModel
@@ -265,6 +235,12 @@ func (dr *DepartmentRepo) GetDepartmentRequestDetails(e echo.Context) (int, mode
 		return http.StatusBadRequest, models.RequestDetailsModel{}, fmt.Errorf("failed to validate request")
 	}
 
+	if status, _, err := targetNode(e, dr.db, scope, "department", GetRequestDetails.DepartmentID); err != nil {
+		return status, models.RequestDetailsModel{}, err
+	}
+
+	query := database.NewDBinstance(dr.db)
+
 	request, err := query.GetRequestDetails(GetRequestDetails)
 	if err != nil {
 		log.Printf("error while fetching request details: %v", err)
@@ -275,20 +251,10 @@ func (dr *DepartmentRepo) GetDepartmentRequestDetails(e echo.Context) (int, mode
 }
 
 func (dr *DepartmentRepo) DeleteIssue(e echo.Context) (int, error) {
-	Status, claims, err := utils.VerifyUserToken(e, "department_head", dr.db)
+	Status, scope, err := authorize(e, dr.db, "issues.raise")
 	if err != nil {
 		return Status, err
 	}
-	query := database.NewDBinstance(dr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "department_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
 
 	var deleteIssue models.DeleteIssueModel
 
@@ -307,12 +273,13 @@ func (dr *DepartmentRepo) DeleteIssue(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("invalid issue id")
 	}
 
-	departmentID, err := query.GetDepartmentID(claims.UserID)
+	status, departmentID, err := targetNode(e, dr.db, scope, "department", deleteIssue.DepartmentID)
 	if err != nil {
-		log.Printf("error while getting department id: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
+		return status, err
 	}
 
+	query := database.NewDBinstance(dr.db)
+
 	if exists, err := query.CheckIfIssueIDExistsUnderDepartment(deleteIssue.IssueID, departmentID); err != nil {
 		log.Printf("error while checking if issue exists: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
@@ -321,7 +288,7 @@ func (dr *DepartmentRepo) DeleteIssue(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("issue with id %v does not exist", deleteIssue.IssueID)
 	}
 
-	status, err := query.DeleteIssue(deleteIssue.IssueID, claims.UserID)
+	status, err = query.DeleteIssue(deleteIssue.IssueID, scope.UserID)
 	if err != nil {
 		log.Printf("error while deleting issue: %v", err)
 		return status, fmt.Errorf("database error")
@@ -331,20 +298,10 @@ func (dr *DepartmentRepo) DeleteIssue(e echo.Context) (int, error) {
 }
 
 func (dr *DepartmentRepo) DeleteRequest(e echo.Context) (int, error) {
-	Status, claims, err := utils.VerifyUserToken(e, "department_head", dr.db)
+	Status, scope, err := authorize(e, dr.db, "requests.raise")
 	if err != nil {
 		return Status, err
 	}
-	query := database.NewDBinstance(dr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "department_head", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
 
 	var deleteRequest models.DeleteRequestModel
 
@@ -363,12 +320,13 @@ func (dr *DepartmentRepo) DeleteRequest(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("invalid request id")
 	}
 
-	departmentID, err := query.GetDepartmentID(claims.UserID)
+	status, departmentID, err := targetNode(e, dr.db, scope, "department", deleteRequest.DepartmentID)
 	if err != nil {
-		log.Printf("error while getting department id: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
+		return status, err
 	}
 
+	query := database.NewDBinstance(dr.db)
+
 	if exists, err := query.CheckIfRequestIDExistsUnderDepartment(deleteRequest.RequestID, departmentID); err != nil {
 		log.Printf("error while checking if request exists: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
@@ -377,7 +335,7 @@ func (dr *DepartmentRepo) DeleteRequest(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("request with id %v does not exist", deleteRequest.RequestID)
 	}
 
-	status, err := query.DeleteRequest(deleteRequest.RequestID, claims.UserID)
+	status, err = query.DeleteRequest(deleteRequest.RequestID, departmentID)
 	if err != nil {
 		log.Printf("error while deleting request: %v", err)
 		return status, fmt.Errorf("database error")
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
 	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
 	"github.com/Hacfy/IT_INVENTORY/pkg/utils"
 	"github.com/labstack/echo/v4"
 )
@@ -23,463 +25,298 @@ func NewDetailsRepo(db *sql.DB) *DetailsRepo {
 
 // remove the details route and implement them in the specified repo
 
-// returns Departments, status, Total_Departments, Page, Limit, error
-func (dr *DetailsRepo) GetAllDepartmentsRepo(e echo.Context) ([]models.AllDepartmentsModel, int, int, int, int, error) {
-	var Sort models.SortModel
-	Sort.Limit, _ = strconv.Atoi(e.QueryParam("limit"))
-	if Sort.Limit <= 0 || Sort.Limit > 100 {
-		Sort.Limit = 10
-	}
-	Sort.Page, _ = strconv.Atoi(e.QueryParam("page"))
-	if Sort.Page <= 0 {
-		Sort.Page = 1
-	}
-	Sort.Offset = (Sort.Page - 1) * Sort.Limit
-
-	Sort.Order = e.QueryParam("order")
-	if Sort.Order != "asc" && Sort.Order != "desc" {
-		Sort.Order = "asc"
-	}
-
-	Sort.SortBy = e.QueryParam("sortBy")
-	if Sort.SortBy == "" {
-		Sort.SortBy = "department_id"
-	}
-
-	allowed := map[string]bool{"department_name": true, "department_id": true}
-	if !allowed[Sort.SortBy] {
-		Sort.SortBy = "department_id"
-	}
-
-	Sort.Search = e.QueryParam("search")
-	role, ok := e.Get("userType").(string)
-	if !ok {
-		return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user credentials")
-	}
-	userID, ok := e.Get("userID").(int)
-	if !ok {
-		return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user credentials")
-	}
-	userEmail, ok := e.Get("userEmail").(string)
-	if !ok {
-		return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user credentials")
+// returns Departments, status, Page, error
+func (dr *DetailsRepo) GetAllDepartmentsRepo(e echo.Context) ([]models.AllDepartmentsModel, int, models.PageModel, error) {
+	spec, err := queryspec.Parse(e.QueryParams(), database.DepartmentsResource)
+	if err != nil {
+		return []models.AllDepartmentsModel{}, http.StatusBadRequest, models.PageModel{}, err
 	}
 
-	query := database.NewDBinstance(dr.db)
-
-	ok, err := query.VerifyUser(userEmail, role, userID)
+	status, scope, err := authorize(e, dr.db, "details.view")
 	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
+		return []models.AllDepartmentsModel{}, status, models.PageModel{}, err
 	}
 
 	BranchID, err := strconv.Atoi(e.QueryParam("branch_id"))
 	if err != nil {
 		log.Printf("error while parsing branch id: %v", err)
-		return []models.AllDepartmentsModel{}, http.StatusBadRequest, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid request format")
+		return []models.AllDepartmentsModel{}, http.StatusBadRequest, models.PageModel{}, fmt.Errorf("invalid request format")
 	}
 
 	if BranchID == 0 {
 		log.Printf("invalid branch id")
-		return []models.AllDepartmentsModel{}, http.StatusBadRequest, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid branch id")
+		return []models.AllDepartmentsModel{}, http.StatusBadRequest, models.PageModel{}, fmt.Errorf("invalid branch id")
 	}
 
-	switch role {
-	case "branch_head":
-		ok, err := query.CheckBranchHead(userID, BranchID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
-		}
-	case "super_admin":
-		ok, err := query.CheckIfBranchUnderSuperAdmin(BranchID, userID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
-		}
-	case "organization":
-		ok, err := query.CheckIfBranchUnderorganizationAdmin(BranchID, userID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
-		}
-	default:
-		log.Printf("Invalid user role")
-		return []models.AllDepartmentsModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user role")
+	if !slices.Contains(scope.BranchIDs, BranchID) {
+		log.Printf("Invalid user details")
+		return []models.AllDepartmentsModel{}, http.StatusUnauthorized, models.PageModel{}, fmt.Errorf("invalid user details")
 	}
 
-	status, Departments, Total_Departments, err := query.GetAllDepartments(BranchID, Sort)
+	query := database.NewDBinstance(dr.db)
+
+	status, Departments, Page, err := query.GetAllDepartments(BranchID, spec)
 	if err != nil {
-		return []models.AllDepartmentsModel{}, status, Total_Departments, Sort.Page, Sort.Limit, err
+		return []models.AllDepartmentsModel{}, status, models.PageModel{}, err
 	}
 
-	return Departments, status, Total_Departments, Sort.Page, Sort.Limit, nil
+	return Departments, status, Page, nil
 
 }
 
-func (dr *DetailsRepo) GetDepartmentIssues(e echo.Context) (int, []models.DepartmentIssuesModel, int, int, int, error) {
-	var Sort models.SortModel
-	Sort.Limit, _ = strconv.Atoi(e.QueryParam("limit"))
-	if Sort.Limit <= 0 || Sort.Limit > 100 {
-		Sort.Limit = 10
-	}
-	Sort.Page, _ = strconv.Atoi(e.QueryParam("page"))
-	if Sort.Page <= 0 {
-		Sort.Page = 1
-	}
-	Sort.Offset = (Sort.Page - 1) * Sort.Limit
-
-	Sort.Order = e.QueryParam("order")
-	if Sort.Order != "asc" && Sort.Order != "desc" {
-		Sort.Order = "asc"
+// checkDepartmentScope parses department_id and makes sure it is visible to the user
+func (dr *DetailsRepo) checkDepartmentScope(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, dr.db, "details.view")
+	if err != nil {
+		return status, -1, err
 	}
 
-	Sort.SortBy = e.QueryParam("sortBy")
-	if Sort.SortBy == "" {
-		Sort.SortBy = "created_at"
+	DepartmentID, err := strconv.Atoi(e.QueryParam("department_id"))
+	if err != nil {
+		log.Printf("error while parsing department id: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
 	}
 
-	allowed := map[string]bool{"created_at": true}
-	if !allowed[Sort.SortBy] {
-		Sort.SortBy = "created_at"
+	if !slices.Contains(scope.DepartmentIDs, DepartmentID) {
+		log.Printf("Invalid user details")
+		return http.StatusUnauthorized, -1, fmt.Errorf("invalid user details")
 	}
 
-	Sort.Search = e.QueryParam("search")
-	role, ok := e.Get("userType").(string)
-	if !ok {
-		return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid use credentials")
-	}
+	return http.StatusOK, DepartmentID, nil
+}
 
-	userID, ok := e.Get("userID").(int)
-	if !ok {
-		return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid use credentials")
+func (dr *DetailsRepo) GetDepartmentIssues(e echo.Context) (int, []models.DepartmentIssuesModel, models.PageModel, error) {
+	spec, err := queryspec.Parse(e.QueryParams(), database.DepartmentIssuesResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.DepartmentIssuesModel{}, models.PageModel{}, err
 	}
 
-	userEmail, ok := e.Get("userEmail").(string)
-	if !ok {
-		return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid use credentials")
+	status, DepartmentID, err := dr.checkDepartmentScope(e)
+	if err != nil {
+		return status, []models.DepartmentIssuesModel{}, models.PageModel{}, err
 	}
 
 	query := database.NewDBinstance(dr.db)
 
-	ok, err := query.VerifyUser(userEmail, role, userID)
+	return query.GetDepartmentIssues(DepartmentID, spec)
+}
+
+func (dr *DetailsRepo) GetDepartmentWorkspaces(e echo.Context) ([]models.DepartmentWorkspaceModel, int, models.PageModel, error) {
+	spec, err := queryspec.Parse(e.QueryParams(), database.WorkspacesResource)
 	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
+		return nil, http.StatusBadRequest, models.PageModel{}, err
 	}
 
-	DepartmentID, err := strconv.Atoi(e.QueryParam("department_id"))
+	status, DepartmentID, err := dr.checkDepartmentScope(e)
 	if err != nil {
-		log.Printf("error while parsing department id: %v", err)
-		return http.StatusBadRequest, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid request format")
+		return nil, status, models.PageModel{}, err
 	}
 
-	switch role {
-	case "department_head":
-		ok, err := query.CheckDepartmentHead(userID, DepartmentID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
-		}
-	case "branch_head":
-		ok, err := query.CheckIfDepartmentUnderBranchHead(DepartmentID, userID)
-		if err != nil {
-			log.Printf("Error checking user details:: %v", err)
-			return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
-		}
-	case "super_admin":
-		ok, err := query.CheckIfDepartmentUnderSuperAdmin(DepartmentID, userID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
-		}
-	case "organization_admin":
-		ok, err := query.CheckIfDepartmentUnderorganizationAdmin(DepartmentID, userID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
-		}
-	default:
-		log.Printf("Invalid user role")
-		return http.StatusUnauthorized, []models.DepartmentIssuesModel{}, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user role")
-	}
+	query := database.NewDBinstance(dr.db)
 
-	status, Issues, Total_Issues, err := query.GetDepartmentIssues(DepartmentID, Sort)
+	status, Workspaces, Page, err := query.GetAllWorkspaces(DepartmentID, spec)
 	if err != nil {
-		return status, []models.DepartmentIssuesModel{}, Total_Issues, Sort.Page, Sort.Limit, err
+		return nil, status, models.PageModel{}, err
 	}
 
-	return status, Issues, Total_Issues, Sort.Page, Sort.Limit, nil
+	return Workspaces, status, Page, nil
 }
 
-func (dr *DetailsRepo) GetDepartmentWorkspaces(e echo.Context) ([]models.DepartmentWorkspaceModel, int, int, int, int, error) {
-	var Sort models.SortModel
-	Sort.Limit, _ = strconv.Atoi(e.QueryParam("limit"))
-	if Sort.Limit <= 0 || Sort.Limit > 100 {
-		Sort.Limit = 10
-	}
-	Sort.Page, _ = strconv.Atoi(e.QueryParam("page"))
-	if Sort.Page <= 0 {
-		Sort.Page = 1
+// get all branches
+func (dr *DetailsRepo) GetAllBranchesUnderSuperAdmin(e echo.Context) ([]models.AllBranchesModel, int, models.PageModel, error) {
+	spec, err := queryspec.Parse(e.QueryParams(), database.BranchesResource)
+	if err != nil {
+		return []models.AllBranchesModel{}, http.StatusBadRequest, models.PageModel{}, err
 	}
-	Sort.Offset = (Sort.Page - 1) * Sort.Limit
 
-	Sort.Order = e.QueryParam("order")
-	if Sort.Order != "asc" && Sort.Order != "desc" {
-		Sort.Order = "asc"
+	status, scope, err := authorize(e, dr.db, "details.view")
+	if err != nil {
+		return []models.AllBranchesModel{}, status, models.PageModel{}, err
 	}
 
-	Sort.SortBy = e.QueryParam("sortBy")
-	if Sort.SortBy == "" {
-		Sort.SortBy = "department_id"
-	}
+	query := database.NewDBinstance(dr.db)
 
-	allowed := map[string]bool{"department_name": true, "department_id": true}
-	if !allowed[Sort.SortBy] {
-		Sort.SortBy = "department_id"
+	status, Branches, Page, err := query.GetAllBranches(scope.BranchIDs, spec)
+	if err != nil {
+		return []models.AllBranchesModel{}, status, models.PageModel{}, err
 	}
 
-	Sort.Search = e.QueryParam("search")
-	role, ok := e.Get("userType").(string)
-	if !ok {
-		return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid use credentials")
-	}
-	userID, ok := e.Get("userID").(int)
-	if !ok {
-		return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid use credentials")
+	return Branches, status, Page, nil
+}
+
+// get organization details
+
+func (dr *DetailsRepo) GetOrganizationDetails(e echo.Context) (int, models.OrganizationDetailsModel, error) {
+	status, scope, err := authorize(e, dr.db, "details.view")
+	if err != nil {
+		return status, models.OrganizationDetailsModel{}, err
 	}
-	userEmail, ok := e.Get("userEmail").(string)
-	if !ok {
-		return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid use credentials")
+
+	if scope.Role != "organization" {
+		log.Printf("Invalid user role")
+		return http.StatusUnauthorized, models.OrganizationDetailsModel{}, fmt.Errorf("invalid user role")
 	}
 
 	query := database.NewDBinstance(dr.db)
 
-	ok, err := query.VerifyUser(userEmail, role, userID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
-	}
+	return query.GetOrganizationDetails(scope.OrgID)
+}
 
-	DepartmentID, err := strconv.Atoi(e.QueryParam("department_id"))
+// get super admin details
+
+func (dr *DetailsRepo) GetSuperAdminDetails(e echo.Context) (int, models.SuperAdminDetailsModel, error) {
+	status, scope, err := authorize(e, dr.db, "details.view")
 	if err != nil {
-		log.Printf("error while parsing department id: %v", err)
-		return nil, http.StatusBadRequest, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid request format")
+		return status, models.SuperAdminDetailsModel{}, err
 	}
 
-	switch role {
-	case "department_head":
-		ok, err := query.CheckDepartmentHead(userID, DepartmentID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
-		}
-	case "branch_head":
-		ok, err := query.CheckIfDepartmentUnderBranchHead(DepartmentID, userID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
-		}
+	query := database.NewDBinstance(dr.db)
+
+	var SuperAdminID int
+
+	switch scope.Role {
 	case "super_admin":
-		ok, err := query.CheckIfDepartmentUnderSuperAdmin(DepartmentID, userID)
+		SuperAdminID = scope.UserID
+	case "organization":
+		SuperAdminID, err = strconv.Atoi(e.QueryParam("super_admin_id"))
 		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
+			log.Printf("error while parsing super admin id: %v", err)
+			return http.StatusBadRequest, models.SuperAdminDetailsModel{}, fmt.Errorf("invalid request format")
 		}
-	case "organization_admin":
-		ok, err := query.CheckIfDepartmentUnderorganizationAdmin(DepartmentID, userID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
+
+		if err := query.CheckIfSuperAdminExists(SuperAdminID, scope.UserID); err != nil {
+			log.Printf("Error checking super admin details: %v", err)
+			return http.StatusUnauthorized, models.SuperAdminDetailsModel{}, fmt.Errorf("invalid user details")
 		}
 	default:
 		log.Printf("Invalid user role")
-		return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user role")
-	}
-
-	status, Workspaces, Total_Workspaces, err := query.GetAllWorkspaces(DepartmentID, Sort)
-	if err != nil {
-		return nil, status, -1, Sort.Page, Sort.Limit, err
+		return http.StatusUnauthorized, models.SuperAdminDetailsModel{}, fmt.Errorf("invalid user role")
 	}
 
-	return Workspaces, status, Total_Workspaces, Sort.Page, Sort.Limit, nil
+	return query.GetSuperAdminDetails(SuperAdminID)
 }
 
-// get all branches
-func (dr *DetailsRepo) GetAllBranchesUnderSuperAdmin(e echo.Context) ([]models.AllBranchesModel, int, int, int, int, error) {
-	var Sort models.SortModel
-	Sort.Limit, _ = strconv.Atoi(e.QueryParam("limit"))
-	if Sort.Limit <= 0 || Sort.Limit > 100 {
-		Sort.Limit = 10
-	}
-	Sort.Page, _ = strconv.Atoi(e.QueryParam("page"))
-	if Sort.Page <= 0 {
-		Sort.Page = 1
+// get branch details
+
+func (dr *DetailsRepo) GetBranchDetails(e echo.Context) (int, models.BranchDetailsModel, error) {
+	status, scope, err := authorize(e, dr.db, "details.view")
+	if err != nil {
+		return status, models.BranchDetailsModel{}, err
 	}
-	Sort.Offset = (Sort.Page - 1) * Sort.Limit
 
-	Sort.Order = e.QueryParam("order")
-	if Sort.Order != "asc" && Sort.Order != "desc" {
-		Sort.Order = "asc"
+	BranchID, err := strconv.Atoi(e.QueryParam("branch_id"))
+	if err != nil {
+		if scope.Role != "branch_head" || len(scope.BranchIDs) != 1 {
+			log.Printf("error while parsing branch id: %v", err)
+			return http.StatusBadRequest, models.BranchDetailsModel{}, fmt.Errorf("invalid request format")
+		}
+		BranchID = scope.BranchIDs[0]
 	}
 
-	Sort.SortBy = e.QueryParam("sortBy")
-	if Sort.SortBy == "" {
-		Sort.SortBy = "branch_id"
+	if !slices.Contains(scope.BranchIDs, BranchID) {
+		log.Printf("branch %v is not under user %v", BranchID, scope.UserID)
+		return http.StatusUnauthorized, models.BranchDetailsModel{}, fmt.Errorf("invalid user details")
 	}
 
-	allowed := map[string]bool{"branch_name": true, "branch_id": true}
-	if !allowed[Sort.SortBy] {
-		Sort.SortBy = "branch_id"
+	query := database.NewDBinstance(dr.db)
+
+	return query.GetBranchDetails(BranchID)
+}
+
+// get department details
+
+func (dr *DetailsRepo) GetDepartmentDetails(e echo.Context) (int, models.DepartmentDetailsModel, error) {
+	status, scope, err := authorize(e, dr.db, "details.view")
+	if err != nil {
+		return status, models.DepartmentDetailsModel{}, err
 	}
 
-	Sort.Search = e.QueryParam("search")
-	userID, ok := e.Get("userID").(int)
-	if !ok {
-		return []models.AllBranchesModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid use credentials")
+	DepartmentID, err := strconv.Atoi(e.QueryParam("department_id"))
+	if err != nil {
+		if scope.Role != "department_head" || len(scope.DepartmentIDs) != 1 {
+			log.Printf("error while parsing department id: %v", err)
+			return http.StatusBadRequest, models.DepartmentDetailsModel{}, fmt.Errorf("invalid request format")
+		}
+		DepartmentID = scope.DepartmentIDs[0]
 	}
-	userEmail, ok := e.Get("userEmail").(string)
-	if !ok {
-		return []models.AllBranchesModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid use credentials")
+
+	if !slices.Contains(scope.DepartmentIDs, DepartmentID) {
+		log.Printf("department %v is not under user %v", DepartmentID, scope.UserID)
+		return http.StatusUnauthorized, models.DepartmentDetailsModel{}, fmt.Errorf("invalid user details")
 	}
 
 	query := database.NewDBinstance(dr.db)
 
-	ok, err := query.VerifyUser(userEmail, "super_admin", userID)
+	return query.GetDepartmentDetails(DepartmentID)
+}
+
+// get warehouse details
+
+func (dr *DetailsRepo) GetWarehouseDetails(e echo.Context) (int, models.WarehouseDetailsModel, error) {
+	status, scope, err := authorize(e, dr.db, "details.view")
 	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return []models.AllBranchesModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return []models.AllBranchesModel{}, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
+		return status, models.WarehouseDetailsModel{}, err
 	}
 
-	status, Branches, Total_Branches, err := query.GetAllBranches(userID, Sort)
+	WarehouseID, err := strconv.Atoi(e.QueryParam("warehouse_id"))
 	if err != nil {
-		return []models.AllBranchesModel{}, status, -1, Sort.Page, Sort.Limit, err
+		if scope.Role != "warehouses" {
+			log.Printf("error while parsing warehouse id: %v", err)
+			return http.StatusBadRequest, models.WarehouseDetailsModel{}, fmt.Errorf("invalid request format")
+		}
+		WarehouseID = scope.UserID
 	}
 
-	return Branches, status, Total_Branches, Sort.Page, Sort.Limit, nil
-}
+	if !slices.Contains(scope.WarehouseIDs, WarehouseID) {
+		log.Printf("warehouse %v is not under user %v", WarehouseID, scope.UserID)
+		return http.StatusUnauthorized, models.WarehouseDetailsModel{}, fmt.Errorf("invalid user details")
+	}
 
-// get branch details
+	query := database.NewDBinstance(dr.db)
 
-// func (dr *DetailsRepo) GetBranchDetails(e echo.Context) ([]models.BranchDetailsModel, int, int, int, int, error) {
-// 	var Sort models.SortModel
-// 	Sort.Limit, _ = strconv.Atoi(e.QueryParam("limit"))
-// 	if Sort.Limit <= 0 || Sort.Limit > 100 {
-// 		Sort.Limit = 10
-// 	}
-// 	Sort.Page, _ = strconv.Atoi(e.QueryParam("page"))
-// 	if Sort.Page <= 0 {
-// 		Sort.Page = 1
-// 	}
-// 	Sort.Offset = (Sort.Page - 1) * Sort.Limit
-
-// 	Sort.Order = e.QueryParam("order")
-// 	if Sort.Order != "asc" && Sort.Order != "desc" {
-// 		Sort.Order = "asc"
-// 	}
-
-// 	// Sort.SortBy = e.QueryParam("sortBy")
-// 	// if Sort.SortBy == "" {
-// 	// 	Sort.SortBy = "branch_id"
-// 	// }
-// 	Sort.Search = e.QueryParam("search")
-// 	role, ok := e.Get("userType").(string)
-// 	if !ok {
-// 		return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid use credentials")
-// 	}
-// 	userID, ok := e.Get("userID").(int)
-// 	if !ok {
-// 		return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid use credentials")
-// 	}
-// 	userEmail, ok := e.Get("userEmail").(string)
-// 	if !ok {
-// 		return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid use credentials")
-// 	}
-
-// 	query := database.NewDBinstance(dr.db)
-
-// 	ok, err := query.VerifyUser(userEmail, role, userID)
-// 	if err != nil {
-// 		log.Printf("Error checking user details:", err)
-// 		return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("database error")
-// 	} else if !ok {
-// 		log.Printf("Invalid user details")
-// 		return nil, http.StatusUnauthorized, -1, Sort.Page, Sort.Limit, fmt.Errorf("invalid user details")
-// 	}
-
-// }
+	return query.GetWarehouseDetails(WarehouseID)
+}
 
-// get all warehouses
+// get component details
 
-func (dr *DetailsRepo) GetAllWarehouses(e echo.Context) ([]models.AllWarehousesModel, int, error) {
+func (dr *DetailsRepo) GetComponentDetails(e echo.Context) (int, models.ComponentDetailsModel, error) {
+	status, scope, err := authorize(e, dr.db, "details.view")
+	if err != nil {
+		return status, models.ComponentDetailsModel{}, err
+	}
 
-	role, ok := e.Get("userType").(string)
-	if !ok {
-		return nil, http.StatusUnauthorized, fmt.Errorf("invalid use credentials")
+	ComponentID, err := strconv.Atoi(e.QueryParam("component_id"))
+	if err != nil {
+		log.Printf("error while parsing component id: %v", err)
+		return http.StatusBadRequest, models.ComponentDetailsModel{}, fmt.Errorf("invalid request format")
 	}
-	userID, ok := e.Get("userID").(int)
-	if !ok {
-		return nil, http.StatusUnauthorized, fmt.Errorf("invalid use credentials")
+
+	query := database.NewDBinstance(dr.db)
+
+	WarehouseID, err := query.GetWarehouseIdOfComponent(ComponentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no component with id %v found", ComponentID)
+			return http.StatusNotFound, models.ComponentDetailsModel{}, fmt.Errorf("no matching data found")
+		}
+		log.Printf("error while getting warehouse of component: %v", err)
+		return http.StatusInternalServerError, models.ComponentDetailsModel{}, fmt.Errorf("database error")
 	}
-	userEmail, ok := e.Get("userEmail").(string)
-	if !ok {
-		return nil, http.StatusUnauthorized, fmt.Errorf("invalid use credentials")
+
+	if !slices.Contains(scope.WarehouseIDs, WarehouseID) {
+		log.Printf("component %v is not under user %v", ComponentID, scope.UserID)
+		return http.StatusUnauthorized, models.ComponentDetailsModel{}, fmt.Errorf("invalid user details")
 	}
 
-	query := database.NewDBinstance(dr.db)
+	return query.GetComponentDetails(ComponentID)
+}
+
+// get all warehouses
 
-	ok, err := query.VerifyUser(userEmail, role, userID)
+func (dr *DetailsRepo) GetAllWarehouses(e echo.Context) ([]models.AllWarehousesModel, int, error) {
+	status, scope, err := authorize(e, dr.db, "details.view")
 	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return nil, http.StatusUnauthorized, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return nil, http.StatusUnauthorized, fmt.Errorf("invalid user details")
+		return nil, status, err
 	}
 
 	BranchID, err := strconv.Atoi(e.QueryParam("branch_id"))
@@ -488,39 +325,13 @@ func (dr *DetailsRepo) GetAllWarehouses(e echo.Context) ([]models.AllWarehousesM
 		return nil, http.StatusBadRequest, fmt.Errorf("invalid request format")
 	}
 
-	switch role {
-	case "branch_head":
-		ok, err := query.CheckBranchHead(userID, BranchID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return nil, http.StatusUnauthorized, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return nil, http.StatusUnauthorized, fmt.Errorf("invalid user details")
-		}
-	case "super_admin":
-		ok, err := query.CheckIfBranchUnderSuperAdmin(BranchID, userID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return nil, http.StatusUnauthorized, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return nil, http.StatusUnauthorized, fmt.Errorf("invalid user details")
-		}
-	case "organization_admin":
-		ok, err := query.CheckIfBranchUnderorganizationAdmin(BranchID, userID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return nil, http.StatusUnauthorized, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return nil, http.StatusUnauthorized, fmt.Errorf("invalid user details")
-		}
-	default:
-		log.Printf("Invalid user role")
-		return nil, http.StatusUnauthorized, fmt.Errorf("invalid user role")
+	if !slices.Contains(scope.BranchIDs, BranchID) {
+		log.Printf("branch %v is not under user %v", BranchID, scope.UserID)
+		return nil, http.StatusUnauthorized, fmt.Errorf("invalid user details")
 	}
 
+	query := database.NewDBinstance(dr.db)
+
 	status, warehouses, err := query.GetAllWarehouses(BranchID)
 	if err != nil {
 		return nil, status, err
@@ -533,95 +344,69 @@ func (dr *DetailsRepo) GetAllWarehouses(e echo.Context) ([]models.AllWarehousesM
 // // get department details
 // // get warehouse details
 
-func (dr *DetailsRepo) GetAllOutOfWarentyUnitsInDepartment(e echo.Context) (int, []models.AllOutOfWarentyUnitsModel, int, int, int, error) {
-	role, ok := e.Get("userType").(string)
-	if !ok {
-		return http.StatusUnauthorized, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("invalid use credentials")
-	}
-
-	userEmail, ok := e.Get("userEmail").(string)
-	if !ok {
-		return http.StatusUnauthorized, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("invalid use credentials")
-	}
-
-	userID, ok := e.Get("userID").(int)
-	if !ok {
-		return http.StatusUnauthorized, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("invalid use credentials")
+func (dr *DetailsRepo) GetAllOutOfWarentyUnitsInDepartment(e echo.Context) (int, []models.AllOutOfWarentyUnitsModel, models.PageModel, error) {
+	spec, err := queryspec.Parse(e.QueryParams(), database.OutOfWarrantyUnitsResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.AllOutOfWarentyUnitsModel{}, models.PageModel{}, err
 	}
 
-	var Sort models.SortModel
-	Sort.Limit, _ = strconv.Atoi(e.QueryParam("limit"))
-	if Sort.Limit <= 0 || Sort.Limit > 100 {
-		Sort.Limit = 10
-	}
-	Sort.Page, _ = strconv.Atoi(e.QueryParam("page"))
-	if Sort.Page <= 0 {
-		Sort.Page = 1
+	status, scope, err := authorize(e, dr.db, "details.view")
+	if err != nil {
+		return status, []models.AllOutOfWarentyUnitsModel{}, models.PageModel{}, err
 	}
-	Sort.Offset = (Sort.Page - 1) * Sort.Limit
 
 	ComponentID, err := strconv.Atoi(e.QueryParam("component_id"))
 	if err != nil {
 		log.Printf("error while parsing component id: %v", err)
-		return http.StatusBadRequest, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("invalid component id")
+		return http.StatusBadRequest, []models.AllOutOfWarentyUnitsModel{}, models.PageModel{}, fmt.Errorf("invalid component id")
 	}
 
 	DepartmentID, err := strconv.Atoi(e.QueryParam("department_id"))
 	if err != nil {
 		log.Printf("error while parsing department id: %v", err)
-		return http.StatusBadRequest, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("invalid department id")
+		return http.StatusBadRequest, []models.AllOutOfWarentyUnitsModel{}, models.PageModel{}, fmt.Errorf("invalid department id")
+	}
+
+	if !slices.Contains(scope.DepartmentIDs, DepartmentID) {
+		log.Printf("department %v is not under user %v", DepartmentID, scope.UserID)
+		return http.StatusNotFound, []models.AllOutOfWarentyUnitsModel{}, models.PageModel{}, fmt.Errorf("no matching data found")
 	}
 
 	query := database.NewDBinstance(dr.db)
 
-	ok, err = query.VerifyUser(userEmail, role, userID)
+	// department heads see the components of their branch's warehouses, the scope components cover both sides
+	components, err := query.GetScopeComponents(scope)
 	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusUnauthorized, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("invalid user details")
+		return http.StatusInternalServerError, []models.AllOutOfWarentyUnitsModel{}, models.PageModel{}, err
 	}
-
-	switch role {
-	case "department_head":
-		ok, err := query.CheckDepartmentHead(userID, DepartmentID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return http.StatusUnauthorized, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return http.StatusUnauthorized, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("invalid user details")
-		}
-	case "warehouses":
-		ok, err := query.CheckWarehouseHead(userID, ComponentID)
-		if err != nil {
-			log.Printf("Error checking user details: %v", err)
-			return http.StatusUnauthorized, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("database error")
-		} else if !ok {
-			log.Printf("Invalid user details")
-			return http.StatusUnauthorized, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("invalid user details")
-		}
+	if !slices.ContainsFunc(components, func(component models.ComponentModel) bool { return component.ComponentID == ComponentID }) {
+		log.Printf("component %v is not under user %v", ComponentID, scope.UserID)
+		return http.StatusNotFound, []models.AllOutOfWarentyUnitsModel{}, models.PageModel{}, fmt.Errorf("no matching data found")
 	}
 
 	_, prefix, err := query.GetComponentNameAndPrefix(ComponentID)
 	if err != nil {
 		log.Printf("error while fetching assigned units: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyUnitsModel{}, -1, -1, -1, fmt.Errorf("database error")
+		return http.StatusInternalServerError, []models.AllOutOfWarentyUnitsModel{}, models.PageModel{}, fmt.Errorf("database error")
 	}
 
-	status, warehouses, total, err := query.GetAllOutOfWarentyUnitsInDepartment(DepartmentID, ComponentID, prefix, Sort.Limit, Sort.Offset)
+	status, units, page, err := query.GetAllOutOfWarentyUnitsInDepartment(DepartmentID, ComponentID, prefix, spec)
 	if err != nil {
-		return status, []models.AllOutOfWarentyUnitsModel{}, total, -1, -1, err
+		return status, []models.AllOutOfWarentyUnitsModel{}, models.PageModel{}, err
 	}
 
-	return status, warehouses, total, Sort.Limit, Sort.Page, nil
+	return status, units, page, nil
 }
 
-func (dr *DetailsRepo) GetAllOutOfWarentyUnitsInWarehouse(e echo.Context) (int, []models.AllOutOfWarentyWarehouseModel, int, int, int, error) {
+func (dr *DetailsRepo) GetAllOutOfWarentyUnitsInWarehouse(e echo.Context) (int, []models.AllOutOfWarentyWarehouseModel, models.PageModel, error) {
+	spec, err := queryspec.Parse(e.QueryParams(), database.OutOfWarrantyUnitsResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.AllOutOfWarentyWarehouseModel{}, models.PageModel{}, err
+	}
+
 	status, claims, err := utils.VerifyUserToken(e, "warehouses", dr.db)
 	if err != nil {
-		return status, []models.AllOutOfWarentyWarehouseModel{}, -1, -1, -1, err
+		return status, []models.AllOutOfWarentyWarehouseModel{}, models.PageModel{}, err
 	}
 
 	query := database.NewDBinstance(dr.db)
@@ -629,54 +414,42 @@ func (dr *DetailsRepo) GetAllOutOfWarentyUnitsInWarehouse(e echo.Context) (int,
 	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
 	if err != nil {
 		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, -1, -1, -1, fmt.Errorf("database error")
+		return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, models.PageModel{}, fmt.Errorf("database error")
 	} else if !ok {
 		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, []models.AllOutOfWarentyWarehouseModel{}, -1, -1, -1, fmt.Errorf("invalid user details")
+		return http.StatusUnauthorized, []models.AllOutOfWarentyWarehouseModel{}, models.PageModel{}, fmt.Errorf("invalid user details")
 	}
 
-	var Sort models.SortModel
-	Sort.Limit, _ = strconv.Atoi(e.QueryParam("limit"))
-	if Sort.Limit <= 0 || Sort.Limit > 100 {
-		Sort.Limit = 10
-	}
-	Sort.Page, _ = strconv.Atoi(e.QueryParam("page"))
-	if Sort.Page <= 0 {
-		Sort.Page = 1
-	}
-	Sort.Offset = (Sort.Page - 1) * Sort.Limit
-
 	ComponentID, err := strconv.Atoi(e.QueryParam("component_id"))
 	if err != nil {
 		log.Printf("error while parsing component id: %v", err)
-		return http.StatusBadRequest, []models.AllOutOfWarentyWarehouseModel{}, -1, -1, -1, fmt.Errorf("invalid component id")
+		return http.StatusBadRequest, []models.AllOutOfWarentyWarehouseModel{}, models.PageModel{}, fmt.Errorf("invalid component id")
 	}
 
 	if ComponentID <= 0 {
 		log.Printf("invalid component id")
-		return http.StatusBadRequest, []models.AllOutOfWarentyWarehouseModel{}, -1, -1, -1, fmt.Errorf("invalid component id")
+		return http.StatusBadRequest, []models.AllOutOfWarentyWarehouseModel{}, models.PageModel{}, fmt.Errorf("invalid component id")
 	}
 
 	_, prefix, err := query.GetComponentNameAndPrefix(ComponentID)
 	if err != nil {
 		log.Printf("error while fetching assigned units: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, -1, -1, -1, fmt.Errorf("database error")
+		return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, models.PageModel{}, fmt.Errorf("database error")
 	}
 
 	exists, err := query.CheckIfComponentBelongsToWarehouse(ComponentID, claims.UserID)
 	if err != nil {
 		log.Printf("error while fetching assigned units: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, -1, -1, -1, fmt.Errorf("database error")
+		return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, models.PageModel{}, fmt.Errorf("database error")
 	} else if !exists {
 		log.Printf("invalid component id")
-		return http.StatusBadRequest, []models.AllOutOfWarentyWarehouseModel{}, -1, -1, -1, fmt.Errorf("component not found")
+		return http.StatusBadRequest, []models.AllOutOfWarentyWarehouseModel{}, models.PageModel{}, fmt.Errorf("component not found")
 	}
 
-	status, units, total, err := query.GetAllOutOfWarehouseUnitsInWarehouse(claims.UserID, Sort.Limit, Sort.Offset, prefix)
+	status, units, page, err := query.GetAllOutOfWarehouseUnitsInWarehouse(claims.UserID, ComponentID, prefix, spec)
 	if err != nil {
-		log.Printf("error while fetching assigned units: %v", err)
-		return http.StatusInternalServerError, []models.AllOutOfWarentyWarehouseModel{}, total, -1, -1, fmt.Errorf("database error")
+		return status, []models.AllOutOfWarentyWarehouseModel{}, models.PageModel{}, err
 	}
 
-	return status, units, total, Sort.Limit, Sort.Page, nil
+	return status, units, page, nil
 }
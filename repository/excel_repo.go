@@ -23,14 +23,14 @@ func NewExcelRepo(db *sql.DB) *ExcelRepo {
 }
 
 func (r *ExcelRepo) DownloadComponentMaintainanceReport(e echo.Context) (int, *excelize.File, error) {
-	status, claims, err := utils.VerifyUserToken(e, "warehouse", r.DB)
+	status, claims, err := utils.VerifyUserToken(e, "warehouses", r.DB)
 	if err != nil {
 		return status, nil, err
 	}
 
 	query := database.NewDBinstance(r.DB)
 
-	ok, err := query.VerifyUser(claims.UserEmail, "warehouse", claims.UserID)
+	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
 	if err != nil {
 		log.Printf("Error checking user details: %v", err)
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
@@ -39,6 +39,10 @@ func (r *ExcelRepo) DownloadComponentMaintainanceReport(e echo.Context) (int, *e
 		return http.StatusUnauthorized, nil, fmt.Errorf("invalid user details")
 	}
 
+	if status, err := checkPermission(r.DB, claims, "reports.export"); err != nil {
+		return status, nil, err
+	}
+
 	var request models.DownloadComponentMaintainanceReportRequest
 
 	if err := e.Bind(&request); err != nil {
@@ -72,6 +76,29 @@ func (r *ExcelRepo) DownloadComponentMaintainanceReport(e echo.Context) (int, *e
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 
+	definitions, err := query.GetComponentAttributeDefinitions(request.ComponentID)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	headers := []string{
+		"Unit ID",
+		"Cost",
+		"Maintenance Cost",
+		"Last Maintenance Date",
+		"Status",
+		"Warranty",
+		"Department ID",
+		"Workspace ID",
+	}
+	for _, definition := range definitions {
+		headers = append(headers, definition.Label)
+	}
+
+	lastCol, _ := excelize.ColumnNumberToName(len(headers))
+	midCol, _ := excelize.ColumnNumberToName(len(headers) / 2)
+	nextCol, _ := excelize.ColumnNumberToName(len(headers)/2 + 1)
+
 	file := excelize.NewFile()
 	sheet := "Component Maintenance Report"
 	_, err = file.NewSheet(sheet)
@@ -80,35 +107,35 @@ func (r *ExcelRepo) DownloadComponentMaintainanceReport(e echo.Context) (int, *e
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 
-	if err := file.MergeCell(sheet, "A1", "H1"); err != nil {
+	if err := file.MergeCell(sheet, "A1", lastCol+"1"); err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 	if err := file.SetCellValue(sheet, "A1", "Maintenance Report"); err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 
-	if err := file.MergeCell(sheet, "A2", "H2"); err != nil {
+	if err := file.MergeCell(sheet, "A2", lastCol+"2"); err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 	if err := file.SetCellValue(sheet, "A2", fmt.Sprintf("Warehouse ID: %d", warehouseID)); err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 
-	if err := file.MergeCell(sheet, "A3", "D3"); err != nil {
+	if err := file.MergeCell(sheet, "A3", midCol+"3"); err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 	if err := file.SetCellValue(sheet, "A3", fmt.Sprintf("Component Name: %s", componentName)); err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 
-	if err := file.MergeCell(sheet, "E3", "H3"); err != nil {
+	if err := file.MergeCell(sheet, nextCol+"3", lastCol+"3"); err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
-	if err := file.SetCellValue(sheet, "E3", fmt.Sprintf("Component Prefix: %s", componentPrefix)); err != nil {
+	if err := file.SetCellValue(sheet, nextCol+"3", fmt.Sprintf("Component Prefix: %s", componentPrefix)); err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 
-	if err := file.MergeCell(sheet, "A4", "D4"); err != nil {
+	if err := file.MergeCell(sheet, "A4", midCol+"4"); err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 	if err := file.SetCellValue(sheet, "A4", fmt.Sprintf("Component ID: %d", request.ComponentID)); err != nil {
@@ -118,24 +145,13 @@ func (r *ExcelRepo) DownloadComponentMaintainanceReport(e echo.Context) (int, *e
 	now := time.Now()
 	today := now.Format("02-01-2006")
 
-	if err := file.MergeCell(sheet, "E4", "H4"); err != nil {
+	if err := file.MergeCell(sheet, nextCol+"4", lastCol+"4"); err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
-	if err := file.SetCellValue(sheet, "E4", today); err != nil {
+	if err := file.SetCellValue(sheet, nextCol+"4", today); err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 
-	headers := []string{
-		"Unit ID",
-		"Cost",
-		"Maintenance Cost",
-		"Last Maintenance Date",
-		"Status",
-		"Warranty",
-		"Department ID",
-		"Workspace ID",
-	}
-
 	for i, header := range headers {
 		cell, err := excelize.CoordinatesToCellName(i+1, 5)
 		if err != nil {
@@ -146,21 +162,34 @@ func (r *ExcelRepo) DownloadComponentMaintainanceReport(e echo.Context) (int, *e
 		}
 	}
 
-	units, err := query.GetAllComponentUnits(componentPrefix)
+	units, err := query.GetAllComponentUnits(componentPrefix, request.ComponentID)
 	if err != nil {
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
 	}
 
 	for i, unit := range units {
 		row := i + 6
-		_ = file.SetCellValue(sheet, fmt.Sprintf("A%d", row), unit.UnitID)
-		_ = file.SetCellValue(sheet, fmt.Sprintf("B%d", row), unit.Cost)
-		_ = file.SetCellValue(sheet, fmt.Sprintf("C%d", row), unit.MaintenanceCost)
-		_ = file.SetCellValue(sheet, fmt.Sprintf("D%d", row), time.Unix(unit.LastMaintenanceDate, 0).Format("02-01-2006"))
-		_ = file.SetCellValue(sheet, fmt.Sprintf("E%d", row), unit.Status)
-		_ = file.SetCellValue(sheet, fmt.Sprintf("F%d", row), time.Unix(unit.WarrantyDate, 0).Format("02-01-2006"))
-		_ = file.SetCellValue(sheet, fmt.Sprintf("G%d", row), unit.DepartmentID)
-		_ = file.SetCellValue(sheet, fmt.Sprintf("H%d", row), unit.WorkspaceID)
+		lastMaintenanceDate := "N/A"
+		if unit.LastMaintenanceDate != nil {
+			lastMaintenanceDate = unit.LastMaintenanceDate.Format("02-01-2006")
+		}
+		values := []interface{}{
+			unit.UnitID,
+			unit.Cost,
+			unit.MaintenanceCost,
+			lastMaintenanceDate,
+			unit.Status,
+			unit.WarrantyDate.Format("02-01-2006"),
+			unit.DepartmentID,
+			unit.WorkspaceID,
+		}
+		for _, definition := range definitions {
+			values = append(values, unit.Attributes[definition.Key])
+		}
+		for j, value := range values {
+			cell, _ := excelize.CoordinatesToCellName(j+1, row)
+			_ = file.SetCellValue(sheet, cell, value)
+		}
 	}
 
 	titleStyle, _ := file.NewStyle(&excelize.Style{
@@ -202,18 +231,18 @@ func (r *ExcelRepo) DownloadComponentMaintainanceReport(e echo.Context) (int, *e
 		},
 	})
 
-	_ = file.SetCellStyle(sheet, "A1", "H1", titleStyle)
-	_ = file.SetCellStyle(sheet, "A2", "H2", infoStyle)
-	_ = file.SetCellStyle(sheet, "A3", "D3", infoStyle)
-	_ = file.SetCellStyle(sheet, "E3", "H3", infoStyle)
-	_ = file.SetCellStyle(sheet, "A4", "D4", infoStyle)
-	_ = file.SetCellStyle(sheet, "E4", "H4", infoStyle)
-	_ = file.SetCellStyle(sheet, "A5", "H5", headerStyle)
+	_ = file.SetCellStyle(sheet, "A1", lastCol+"1", titleStyle)
+	_ = file.SetCellStyle(sheet, "A2", lastCol+"2", infoStyle)
+	_ = file.SetCellStyle(sheet, "A3", midCol+"3", infoStyle)
+	_ = file.SetCellStyle(sheet, nextCol+"3", lastCol+"3", infoStyle)
+	_ = file.SetCellStyle(sheet, "A4", midCol+"4", infoStyle)
+	_ = file.SetCellStyle(sheet, nextCol+"4", lastCol+"4", infoStyle)
+	_ = file.SetCellStyle(sheet, "A5", lastCol+"5", headerStyle)
 
 	lastRow := len(units) + 5
-	_ = file.SetCellStyle(sheet, "A6", fmt.Sprintf("H%d", lastRow), bodyStyle)
+	_ = file.SetCellStyle(sheet, "A6", fmt.Sprintf("%s%d", lastCol, lastRow), bodyStyle)
 
-	for col := 1; col <= 8; col++ {
+	for col := 1; col <= len(headers); col++ {
 		maxLen := 0
 		for row := 1; row <= lastRow; row++ {
 			cell, _ := excelize.CoordinatesToCellName(col, row)
@@ -231,14 +260,14 @@ func (r *ExcelRepo) DownloadComponentMaintainanceReport(e echo.Context) (int, *e
 }
 
 func (r *ExcelRepo) DownloadComponentPrefixReport(e echo.Context) (int, *excelize.File, error) {
-	status, claims, err := utils.VerifyUserToken(e, "warehouse", r.DB)
+	status, claims, err := utils.VerifyUserToken(e, "warehouses", r.DB)
 	if err != nil {
 		return status, nil, err
 	}
 
 	query := database.NewDBinstance(r.DB)
 
-	ok, err := query.VerifyUser(claims.UserEmail, "warehouse", claims.UserID)
+	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
 	if err != nil {
 		log.Printf("Error checking user details: %v", err)
 		return http.StatusInternalServerError, nil, fmt.Errorf("database error")
@@ -247,6 +276,10 @@ func (r *ExcelRepo) DownloadComponentPrefixReport(e echo.Context) (int, *exceliz
 		return http.StatusUnauthorized, nil, fmt.Errorf("invalid user details")
 	}
 
+	if status, err := checkPermission(r.DB, claims, "reports.export"); err != nil {
+		return status, nil, err
+	}
+
 	var request models.DownloadComponentPrefixReportRequest
 
 	if err := e.Bind(&request); err != nil {
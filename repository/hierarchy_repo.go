@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+type HierarchyRepo struct {
+	db *sql.DB
+}
+
+func NewHierarchyRepo(db *sql.DB) *HierarchyRepo {
+	return &HierarchyRepo{db: db}
+}
+
+func (hr *HierarchyRepo) GetHierarchyTree(e echo.Context) (int, models.HierarchyNodeModel, []models.HierarchyNodeModel, error) {
+	depth := -1
+	if param := e.QueryParam("depth"); param != "" {
+		var err error
+		depth, err = strconv.Atoi(param)
+		if err != nil || depth < 0 {
+			log.Printf("invalid depth: %v", param)
+			return http.StatusBadRequest, models.HierarchyNodeModel{}, nil, fmt.Errorf("invalid depth")
+		}
+	}
+
+	status, scope, err := getUserScope(e, hr.db)
+	if err != nil {
+		return status, models.HierarchyNodeModel{}, nil, err
+	}
+
+	query := database.NewDBinstance(hr.db)
+
+	return query.GetHierarchyTree(scope, depth)
+}
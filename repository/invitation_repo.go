@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/Hacfy/IT_INVENTORY/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type InvitationRepo struct {
+	db *sql.DB
+}
+
+func NewInvitationRepo(db *sql.DB) *InvitationRepo {
+	return &InvitationRepo{db: db}
+}
+
+// unusablePassword is stored for invited accounts until they set their own, nobody knows the value it hashes
+func unusablePassword() (string, error) {
+	secret, err := utils.GenerateToken()
+	if err != nil {
+		log.Printf("error while generating password: %v", err)
+		return "", fmt.Errorf("failed to generate password, please try again later")
+	}
+
+	hash, err := utils.HashPassword(secret)
+	if err != nil {
+		log.Printf("error while hashing password: %v", err)
+		return "", fmt.Errorf("failed to secure your password, please try again")
+	}
+
+	return hash, nil
+}
+
+// newInvitation generates the token of an account about to be created with an unusable password, the invitation
+// is stored with the account and the token is emailed by sendInvitation once both are committed
+func newInvitation(name, user_level, created_by_role string, created_by int) (string, models.NewInvitationModel, error) {
+	token, err := utils.GenerateToken()
+	if err != nil {
+		log.Printf("error while generating invitation token: %v", err)
+		return "", models.NewInvitationModel{}, fmt.Errorf("failed to generate the invitation, please try again later")
+	}
+
+	return token, models.NewInvitationModel{
+		Name:          name,
+		UserLevel:     user_level,
+		CreatedByRole: created_by_role,
+		CreatedBy:     created_by,
+		TokenHash:     utils.HashToken(token),
+		ExpiresAt:     time.Now().Add(utils.InvitationValidity).UTC(),
+	}, nil
+}
+
+func sendInvitation(email, name, user_level, token string, expires_at time.Time) {
+	log.Printf("sending invitation to %v", email)
+	if err := utils.SendInvitation(email, name, strings.ReplaceAll(user_level, "_", " "), token, expires_at); err != nil {
+		log.Printf("error while sending invitation to %v: %v", email, err)
+		return
+	}
+	log.Printf("invitation sent to %v", email)
+}
+
+func (ir *InvitationRepo) AcceptInvitation(e echo.Context) (int, error) {
+	var accept models.AcceptInvitationModel
+
+	if err := e.Bind(&accept); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	accept.Token = strings.TrimSpace(accept.Token)
+
+	if err := validate.Struct(accept); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	if !utils.StrongPasswordValidator(accept.Password) {
+		return http.StatusBadRequest, fmt.Errorf("password is not strong enough")
+	}
+
+	hash, err := utils.HashPassword(accept.Password)
+	if err != nil {
+		log.Printf("error while hashing password: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("failed to secure your password, please try again")
+	}
+
+	query := database.NewDBinstance(ir.db)
+
+	return query.AcceptInvitation(utils.HashToken(accept.Token), hash)
+}
+
+func (ir *InvitationRepo) ResendInvitation(e echo.Context) (int, error) {
+	status, scope, err := getUserScope(e, ir.db)
+	if err != nil {
+		return status, err
+	}
+
+	var resend models.ResendInvitationModel
+
+	if err := e.Bind(&resend); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(resend); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		log.Printf("error while generating invitation token: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("failed to generate invitation, please try again later")
+	}
+
+	query := database.NewDBinstance(ir.db)
+
+	status, invitation, err := query.ResendInvitation(scope.Role, scope.UserID, resend.InvitationID, utils.HashToken(token), time.Now().Add(utils.InvitationValidity).UTC())
+	if err != nil {
+		return status, err
+	}
+
+	go sendInvitation(invitation.UserEmail, invitation.Name, invitation.UserLevel, token, invitation.ExpiresAt)
+
+	return status, nil
+}
+
+func (ir *InvitationRepo) RevokeInvitation(e echo.Context) (int, error) {
+	status, scope, err := getUserScope(e, ir.db)
+	if err != nil {
+		return status, err
+	}
+
+	var revoke models.RevokeInvitationModel
+
+	if err := e.Bind(&revoke); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(revoke); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(ir.db)
+
+	return query.RevokeInvitation(scope.Role, scope.UserID, revoke.InvitationID)
+}
+
+func (ir *InvitationRepo) GetPendingInvitations(e echo.Context) (int, []models.InvitationModel, models.PageModel, error) {
+	status, scope, err := getUserScope(e, ir.db)
+	if err != nil {
+		return status, []models.InvitationModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.InvitationsResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.InvitationModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(ir.db)
+
+	return query.GetPendingInvitations(scope.Role, scope.UserID, spec)
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/jobs"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+type JobsRepo struct {
+	db     *sql.DB
+	runner *jobs.Runner
+}
+
+func NewJobsRepo(db *sql.DB, runner *jobs.Runner) *JobsRepo {
+	return &JobsRepo{
+		db:     db,
+		runner: runner,
+	}
+}
+
+func (jr *JobsRepo) verifyMainAdmin(e echo.Context) (int, error) {
+	var tokenModel models.MainAdminTokenModel
+
+	tokenString := e.Request().Header.Get("Authorization")
+	if tokenString == "" {
+		log.Printf("missgin token")
+		return http.StatusUnauthorized, fmt.Errorf("missing token")
+	}
+
+	jwtSecret := os.Getenv("JWT_SECRET")
+
+	token, err := jwt.ParseWithClaims(tokenString, &tokenModel, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	})
+
+	if err != nil {
+		log.Printf("invalid token: %v", err)
+		return http.StatusUnauthorized, fmt.Errorf("invalid token")
+	}
+
+	claims, ok := token.Claims.(*models.MainAdminTokenModel)
+	if !(ok && token.Valid) {
+		log.Printf("token expired or not of MainAdminTokenModel")
+		return http.StatusUnauthorized, fmt.Errorf("invalid token")
+	}
+
+	query := database.NewDBinstance(jr.db)
+
+	ok, err = query.VerifyMainAdmin(claims.MainAdminEmail, claims.MainAdminID)
+	if err != nil {
+		log.Printf("Error checking main admin details: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if !ok {
+		log.Printf("Invalid main admin details")
+		return http.StatusUnauthorized, fmt.Errorf("invalid main admin details")
+	}
+
+	return http.StatusOK, nil
+}
+
+func (jr *JobsRepo) RunJobs(e echo.Context) (int, []models.JobRunModel, error) {
+	if status, err := jr.verifyMainAdmin(e); err != nil {
+		return status, nil, err
+	}
+
+	runs, err := jr.runner.RunNow(e.Request().Context(), e.QueryParam("job"), "manual")
+	if err != nil {
+		log.Printf("error while running jobs: %v", err)
+		return http.StatusBadRequest, nil, fmt.Errorf("invalid job")
+	}
+
+	for _, run := range runs {
+		if run.Error != "" {
+			return http.StatusInternalServerError, runs, fmt.Errorf("one or more jobs failed")
+		}
+	}
+
+	return http.StatusOK, runs, nil
+}
+
+func (jr *JobsRepo) GetJobRuns(e echo.Context) (int, []models.JobRunModel, error) {
+	if status, err := jr.verifyMainAdmin(e); err != nil {
+		return status, nil, err
+	}
+
+	limit := 50
+	if value := e.QueryParam("limit"); value != "" {
+		l, err := strconv.Atoi(value)
+		if err != nil || l <= 0 || l > 500 {
+			return http.StatusBadRequest, nil, fmt.Errorf("invalid limit")
+		}
+		limit = l
+	}
+
+	query := database.NewDBinstance(jr.db)
+
+	return query.GetJobRuns(e.QueryParam("job"), limit)
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/labstack/echo/v4"
+)
+
+// maxCalendarDays bounds the range of the availability calendar
+const maxCalendarDays = 93
+
+type LoanRepo struct {
+	db *sql.DB
+}
+
+func NewLoanRepo(db *sql.DB) *LoanRepo {
+	return &LoanRepo{db: db}
+}
+
+func (lr *LoanRepo) CreateReservation(e echo.Context) (int, models.LoanModel, error) {
+	status, scope, err := authorize(e, lr.db, "loans.reserve")
+	if err != nil {
+		return status, models.LoanModel{}, err
+	}
+
+	var reservation models.CreateReservationModel
+
+	if err := e.Bind(&reservation); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.LoanModel{}, fmt.Errorf("invalid request format")
+	}
+
+	reservation.Note = strings.TrimSpace(reservation.Note)
+	if reservation.Quantity == 0 {
+		reservation.Quantity = 1
+	}
+
+	if err := validate.Struct(reservation); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.LoanModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	if reservation.StartsAt.IsZero() || reservation.EndsAt.IsZero() {
+		return http.StatusBadRequest, models.LoanModel{}, fmt.Errorf("starts_at and ends_at are required")
+	} else if !reservation.EndsAt.After(reservation.StartsAt) {
+		return http.StatusBadRequest, models.LoanModel{}, fmt.Errorf("ends_at must be after starts_at")
+	} else if !reservation.EndsAt.After(time.Now()) {
+		return http.StatusBadRequest, models.LoanModel{}, fmt.Errorf("reservation cannot end in the past")
+	} else if reservation.UnitID > 0 && reservation.Quantity != 1 {
+		return http.StatusBadRequest, models.LoanModel{}, fmt.Errorf("a reservation of a specific unit has a quantity of 1")
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.CreateReservation(scope, reservation)
+}
+
+func (lr *LoanRepo) CheckoutLoan(e echo.Context) (int, models.LoanModel, error) {
+	status, scope, err := authorize(e, lr.db, "loans.checkout")
+	if err != nil {
+		return status, models.LoanModel{}, err
+	}
+
+	var checkout models.CheckoutLoanModel
+
+	if err := e.Bind(&checkout); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.LoanModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(checkout); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.LoanModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.CheckoutLoan(scope, checkout)
+}
+
+func (lr *LoanRepo) ReturnLoan(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, lr.db, "loans.checkout")
+	if err != nil {
+		return status, err
+	}
+
+	var loan models.LoanActionModel
+
+	if err := e.Bind(&loan); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(loan); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.ReturnLoan(scope, loan.LoanID)
+}
+
+func (lr *LoanRepo) CancelLoan(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, lr.db, "loans.reserve")
+	if err != nil {
+		return status, err
+	}
+
+	var loan models.LoanActionModel
+
+	if err := e.Bind(&loan); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(loan); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.CancelLoan(scope, loan.LoanID)
+}
+
+func (lr *LoanRepo) GetLoans(e echo.Context) (int, []models.LoanModel, models.PageModel, error) {
+	status, scope, err := getUserScope(e, lr.db)
+	if err != nil {
+		return status, []models.LoanModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.LoansResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.LoanModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.GetLoans(scope, spec)
+}
+
+func (lr *LoanRepo) GetLoanCalendar(e echo.Context) (int, []models.LoanCalendarComponentModel, error) {
+	warehouse_id, err := strconv.Atoi(e.QueryParam("warehouse_id"))
+	if err != nil || warehouse_id <= 0 {
+		log.Printf("invalid warehouse id: %v", e.QueryParam("warehouse_id"))
+		return http.StatusBadRequest, []models.LoanCalendarComponentModel{}, fmt.Errorf("invalid warehouse id")
+	}
+
+	component_id := 0
+	if param := e.QueryParam("component_id"); param != "" {
+		component_id, err = strconv.Atoi(param)
+		if err != nil || component_id <= 0 {
+			log.Printf("invalid component id: %v", param)
+			return http.StatusBadRequest, []models.LoanCalendarComponentModel{}, fmt.Errorf("invalid component id")
+		}
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if param := e.QueryParam("from"); param != "" {
+		date, err := time.Parse("2006-01-02", param)
+		if err != nil {
+			log.Printf("error while parsing from date: %v", err)
+			return http.StatusBadRequest, []models.LoanCalendarComponentModel{}, fmt.Errorf("invalid request format")
+		}
+		from = date
+	}
+
+	to := from.AddDate(0, 0, 30)
+	if param := e.QueryParam("to"); param != "" {
+		date, err := time.Parse("2006-01-02", param)
+		if err != nil {
+			log.Printf("error while parsing to date: %v", err)
+			return http.StatusBadRequest, []models.LoanCalendarComponentModel{}, fmt.Errorf("invalid request format")
+		}
+		to = date.AddDate(0, 0, 1)
+	}
+
+	if !from.Before(to) {
+		return http.StatusBadRequest, []models.LoanCalendarComponentModel{}, fmt.Errorf("from date must be before to date")
+	} else if to.Sub(from) > maxCalendarDays*24*time.Hour {
+		return http.StatusBadRequest, []models.LoanCalendarComponentModel{}, fmt.Errorf("calendar cannot span more than %v days", maxCalendarDays)
+	}
+
+	status, scope, err := getUserScope(e, lr.db)
+	if err != nil {
+		return status, []models.LoanCalendarComponentModel{}, err
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.GetLoanCalendar(scope, warehouse_id, component_id, from, to)
+}
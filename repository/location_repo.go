@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/labstack/echo/v4"
+)
+
+type LocationRepo struct {
+	db *sql.DB
+}
+
+func NewLocationRepo(db *sql.DB) *LocationRepo {
+	return &LocationRepo{db: db}
+}
+
+func (lr *LocationRepo) CreateLocation(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, lr.db, "locations.manage")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var location models.CreateLocationModel
+
+	if err := e.Bind(&location); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	location.Name = strings.TrimSpace(location.Name)
+
+	if err := validate.Struct(location); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.CreateLocation(scope, location)
+}
+
+func (lr *LocationRepo) DeleteLocation(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, lr.db, "locations.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var location models.DeleteLocationModel
+
+	if err := e.Bind(&location); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(location); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.DeleteLocation(scope, location.LocationID)
+}
+
+func (lr *LocationRepo) GetLocationTree(e echo.Context) (int, models.LocationTreeModel, error) {
+	branch_id, err := strconv.Atoi(e.QueryParam("branch_id"))
+	if err != nil || branch_id <= 0 {
+		log.Printf("invalid branch id: %v", e.QueryParam("branch_id"))
+		return http.StatusBadRequest, models.LocationTreeModel{}, fmt.Errorf("invalid branch id")
+	}
+
+	status, scope, err := getUserScope(e, lr.db)
+	if err != nil {
+		return status, models.LocationTreeModel{}, err
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.GetLocationTree(scope, branch_id)
+}
+
+func (lr *LocationRepo) AttachWorkspaceLocation(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, lr.db, "locations.attach")
+	if err != nil {
+		return status, err
+	}
+
+	var attach models.AttachWorkspaceLocationModel
+
+	if err := e.Bind(&attach); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(attach); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.AttachWorkspaceLocation(scope, attach)
+}
+
+func (lr *LocationRepo) CreateStorageBin(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, lr.db, "units.store")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var bin models.CreateStorageBinModel
+
+	if err := e.Bind(&bin); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	bin.Name = strings.TrimSpace(bin.Name)
+	if bin.WarehouseID == 0 && scope.Role == "warehouses" {
+		bin.WarehouseID = scope.UserID
+	}
+
+	if err := validate.Struct(bin); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.CreateStorageBin(scope, bin)
+}
+
+func (lr *LocationRepo) GetStorageBins(e echo.Context) (int, []models.StorageBinModel, error) {
+	status, scope, err := getUserScope(e, lr.db)
+	if err != nil {
+		return status, []models.StorageBinModel{}, err
+	}
+
+	warehouse_id, err := strconv.Atoi(e.QueryParam("warehouse_id"))
+	if scope.Role == "warehouses" && e.QueryParam("warehouse_id") == "" {
+		warehouse_id, err = scope.UserID, nil
+	}
+	if err != nil || warehouse_id <= 0 {
+		log.Printf("invalid warehouse id: %v", e.QueryParam("warehouse_id"))
+		return http.StatusBadRequest, []models.StorageBinModel{}, fmt.Errorf("invalid warehouse id")
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.GetStorageBins(scope, warehouse_id)
+}
+
+func (lr *LocationRepo) StoreUnits(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, lr.db, "units.store")
+	if err != nil {
+		return status, err
+	}
+
+	var store models.StoreUnitsModel
+
+	if err := e.Bind(&store); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(store); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.StoreUnits(scope, store)
+}
+
+func (lr *LocationRepo) GetUnitLocations(e echo.Context) (int, []models.UnitLocationModel, models.PageModel, error) {
+	location_id := 0
+	if param := e.QueryParam("within"); param != "" {
+		var err error
+		location_id, err = strconv.Atoi(param)
+		if err != nil || location_id <= 0 {
+			log.Printf("invalid location id: %v", param)
+			return http.StatusBadRequest, []models.UnitLocationModel{}, models.PageModel{}, fmt.Errorf("invalid location id")
+		}
+	}
+
+	status, scope, err := getUserScope(e, lr.db)
+	if err != nil {
+		return status, []models.UnitLocationModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.UnitLocationsResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.UnitLocationModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(lr.db)
+
+	return query.GetUnitLocations(scope, location_id, spec)
+}
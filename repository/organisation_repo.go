@@ -40,6 +40,10 @@ func (or *OrgRepo) CreateSuperAdmin(e echo.Context) (int, error) {
 		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
 	}
 
+	if status, err := checkPermission(or.db, claims, "super_admins.manage"); err != nil {
+		return status, err
+	}
+
 	var new_sa models.CreateSuperAdminModel
 
 	if err := e.Bind(&new_sa); err != nil {
@@ -52,24 +56,9 @@ func (or *OrgRepo) CreateSuperAdmin(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
-	password, err := utils.GeneratePassword()
+	hash, err := unusablePassword()
 	if err != nil {
-		log.Printf("error while generating password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
-	}
-
-	for !utils.StrongPasswordValidator(password) {
-		password, err = utils.GeneratePassword()
-		if err != nil {
-			log.Printf("error while generating password: %v", err)
-			return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
-		}
-	}
-
-	hash, err := utils.HashPassword(password)
-	if err != nil {
-		log.Printf("error while hashing password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to secure your password, please try again")
+		return http.StatusInternalServerError, err
 	}
 
 	var SuperAdmin models.SuperAdminModel
@@ -82,19 +71,18 @@ func (or *OrgRepo) CreateSuperAdmin(e echo.Context) (int, error) {
 
 	SuperAdmin.SuperAdminEmail = new_sa.SuperAdminEmail
 
-	SuperAdmin.SuperAdminID, err = query.CreateSuperAdmin(SuperAdmin)
+	token, invitation, err := newInvitation(SuperAdmin.SuperAdminName, "super_admin", claims.UserType, claims.UserID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	SuperAdmin.SuperAdminID, err = query.CreateSuperAdmin(SuperAdmin, invitation)
 	if err != nil {
 		log.Printf("error while storing SuperAdmin data in DB: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("unable to register SuperAdmin at the moment, please try again later")
 	}
 
-	go func() {
-		log.Printf("sending login credentials to %s", SuperAdmin.SuperAdminEmail)
-		if err := utils.SendLoginCredentials(SuperAdmin.SuperAdminEmail, password); err != nil {
-			log.Printf("error while sending login credentials to %s: %v", SuperAdmin.SuperAdminEmail, err)
-		}
-		log.Printf("credentials sent to %s", SuperAdmin.SuperAdminEmail)
-	}()
+	go sendInvitation(SuperAdmin.SuperAdminEmail, SuperAdmin.SuperAdminName, "super_admin", token, invitation.ExpiresAt)
 
 	return http.StatusCreated, nil
 }
@@ -116,6 +104,10 @@ func (or *OrgRepo) DeleteSuperAdmin(e echo.Context) (int, error) {
 		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
 	}
 
+	if status, err := checkPermission(or.db, claims, "super_admins.manage"); err != nil {
+		return status, err
+	}
+
 	var del_sa models.DeleteSuperAdminModel
 
 	if err := e.Bind(&del_sa); err != nil {
@@ -194,6 +186,10 @@ func (or *OrgRepo) ReassignSuperAdmin(e echo.Context) (int, error) {
 		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
 	}
 
+	if status, err := checkPermission(or.db, claims, "super_admins.manage"); err != nil {
+		return status, err
+	}
+
 	var reassignSuperAdmin models.ReassignSuperAdminModel
 
 	err = e.Bind(&reassignSuperAdmin)
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type RestoreRepo struct {
+	db *sql.DB
+}
+
+func NewRestoreRepo(db *sql.DB) *RestoreRepo {
+	return &RestoreRepo{db: db}
+}
+
+func (rr *RestoreRepo) GetArchivedEntities(e echo.Context) (int, []models.ArchivedEntityModel, error) {
+	status, scope, err := getUserScope(e, rr.db)
+	if err != nil {
+		return status, []models.ArchivedEntityModel{}, err
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.GetArchivedEntities(e.QueryParam("type"), scope, utils.ArchiveRetentionDays())
+}
+
+func (rr *RestoreRepo) Restore(e echo.Context, entity_type string) (int, models.RestoreResultModel, error) {
+	var restore models.RestoreModel
+
+	if err := e.Bind(&restore); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.RestoreResultModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(restore); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.RestoreResultModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	if entity_type == "unit" {
+		var ok bool
+		if restore.Prefix, ok = utils.NormalisePrefix(restore.Prefix); !ok {
+			log.Printf("invalid prefix %v", restore.Prefix)
+			return http.StatusBadRequest, models.RestoreResultModel{}, fmt.Errorf("invalid prefix")
+		}
+	}
+
+	status, scope, err := authorize(e, rr.db, "records.restore")
+	if err != nil {
+		return status, models.RestoreResultModel{}, err
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.Restore(entity_type, restore.ID, restore.Prefix, scope, utils.ArchiveRetentionDays())
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/labstack/echo/v4"
+)
+
+type RestructureRepo struct {
+	db *sql.DB
+}
+
+func NewRestructureRepo(db *sql.DB) *RestructureRepo {
+	return &RestructureRepo{db: db}
+}
+
+func (rr *RestructureRepo) MoveWorkspace(e echo.Context) (int, models.MoveModel, error) {
+	status, scope, err := authorize(e, rr.db, "hierarchy.move_workspace")
+	if err != nil {
+		return status, models.MoveModel{}, err
+	}
+
+	var move models.MoveWorkspaceModel
+
+	if err := e.Bind(&move); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.MoveModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(move); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.MoveModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.MoveWorkspace(scope, move)
+}
+
+func (rr *RestructureRepo) MoveDepartment(e echo.Context) (int, models.MoveModel, error) {
+	status, scope, err := authorize(e, rr.db, "hierarchy.move_department")
+	if err != nil {
+		return status, models.MoveModel{}, err
+	}
+
+	var move models.MoveDepartmentModel
+
+	if err := e.Bind(&move); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, models.MoveModel{}, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(move); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, models.MoveModel{}, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.MoveDepartment(scope, move)
+}
+
+func (rr *RestructureRepo) GetMoves(e echo.Context) (int, []models.MoveModel, models.PageModel, error) {
+	status, scope, err := getUserScope(e, rr.db)
+	if err != nil {
+		return status, []models.MoveModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.MovesResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.MoveModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.GetMoves(scope, spec)
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/labstack/echo/v4"
+)
+
+type RoleRepo struct {
+	db *sql.DB
+}
+
+func NewRoleRepo(db *sql.DB) *RoleRepo {
+	return &RoleRepo{db: db}
+}
+
+func (rr *RoleRepo) GetPermissions(e echo.Context) (int, []models.PermissionModel, error) {
+	if status, _, err := getUserScope(e, rr.db); err != nil {
+		return status, []models.PermissionModel{}, err
+	}
+
+	return http.StatusOK, database.Permissions, nil
+}
+
+func (rr *RoleRepo) GetRoles(e echo.Context) (int, []models.RoleModel, error) {
+	status, scope, err := getUserScope(e, rr.db)
+	if err != nil {
+		return status, []models.RoleModel{}, err
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.GetRoles(scope)
+}
+
+func (rr *RoleRepo) CreateRole(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, rr.db, "roles.manage")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var role models.CreateRoleModel
+
+	if err := e.Bind(&role); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	role.Name = strings.TrimSpace(role.Name)
+
+	if err := validate.Struct(role); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.CreateRole(scope, role)
+}
+
+func (rr *RoleRepo) UpdateRolePermissions(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, rr.db, "roles.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var update models.UpdateRolePermissionsModel
+
+	if err := e.Bind(&update); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(update); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.UpdateRolePermissions(scope, update)
+}
+
+func (rr *RoleRepo) DeleteRole(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, rr.db, "roles.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var role models.DeleteRoleModel
+
+	if err := e.Bind(&role); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(role); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.DeleteRole(scope, role.RoleID)
+}
+
+func (rr *RoleRepo) BindRole(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, rr.db, "roles.manage")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var bind models.BindRoleModel
+
+	if err := e.Bind(&bind); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	bind.UserEmail = strings.ToLower(strings.TrimSpace(bind.UserEmail))
+
+	if err := validate.Struct(bind); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	status, target_org, err := getUserOrg(query, bind.UserEmail)
+	if err != nil {
+		return status, -1, err
+	}
+
+	return query.BindRole(scope, bind, target_org)
+}
+
+func (rr *RoleRepo) UnbindRole(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, rr.db, "roles.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var unbind models.UnbindRoleModel
+
+	if err := e.Bind(&unbind); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(unbind); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.UnbindRole(scope, unbind.BindingID)
+}
+
+func (rr *RoleRepo) GetRoleBindings(e echo.Context) (int, []models.RoleBindingModel, models.PageModel, error) {
+	status, scope, err := authorize(e, rr.db, "roles.manage")
+	if err != nil {
+		return status, []models.RoleBindingModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.RoleBindingsResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.RoleBindingModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.GetRoleBindings(scope, spec)
+}
+
+func (rr *RoleRepo) GetMyPermissions(e echo.Context) (int, models.EffectivePermissionsModel, error) {
+	status, scope, err := getUserScope(e, rr.db)
+	if err != nil {
+		return status, models.EffectivePermissionsModel{}, err
+	}
+
+	query := database.NewDBinstance(rr.db)
+
+	return query.GetEffectivePermissions(scope)
+}
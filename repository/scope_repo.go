@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// getUserScope verifies the user set by AuthMiddleware and resolves what they are allowed to see
+func getUserScope(e echo.Context, db *sql.DB) (int, models.ScopeModel, error) {
+	role, ok := e.Get("userType").(string)
+	if !ok {
+		return http.StatusUnauthorized, models.ScopeModel{}, fmt.Errorf("invalid user credentials")
+	}
+	userID, ok := e.Get("userID").(int)
+	if !ok {
+		return http.StatusUnauthorized, models.ScopeModel{}, fmt.Errorf("invalid user credentials")
+	}
+	userEmail, ok := e.Get("userEmail").(string)
+	if !ok {
+		return http.StatusUnauthorized, models.ScopeModel{}, fmt.Errorf("invalid user credentials")
+	}
+
+	query := database.NewDBinstance(db)
+
+	ok, err := query.VerifyUser(userEmail, role, userID)
+	if err != nil {
+		log.Printf("Error checking user details: %v", err)
+		return http.StatusUnauthorized, models.ScopeModel{}, fmt.Errorf("database error")
+	} else if !ok {
+		log.Printf("Invalid user details")
+		return http.StatusUnauthorized, models.ScopeModel{}, fmt.Errorf("invalid user details")
+	}
+
+	scope, err := query.GetUserScope(role, userID)
+	if err != nil {
+		log.Printf("error while resolving user scope: %v", err)
+		return http.StatusInternalServerError, models.ScopeModel{}, err
+	}
+
+	scope.UserEmail = userEmail
+	scope.StaffID, _ = e.Get("staffID").(int)
+	scope.StaffRole, _ = e.Get("staffRole").(string)
+
+	if err := query.ApplyDelegations(&scope); err != nil {
+		return http.StatusInternalServerError, models.ScopeModel{}, err
+	}
+
+	return http.StatusOK, scope, nil
+}
+
+// authorize is the authorizer of the repositories, it resolves the user's scope narrowed to the nodes where
+// they hold the permission
+func authorize(e echo.Context, db *sql.DB, permission string) (int, models.ScopeModel, error) {
+	status, scope, err := getUserScope(e, db)
+	if err != nil {
+		return status, models.ScopeModel{}, err
+	}
+
+	status, authorized, err := authorizeScope(db, scope, permission)
+	if err != nil {
+		return status, models.ScopeModel{}, err
+	}
+
+	if len(authorized.OnBehalfOf) > 0 {
+		e.Set("delegationIDs", authorized.OnBehalfOf)
+	}
+
+	return status, authorized, nil
+}
+
+// authorizeHandover authorizes actions shared by a department and the warehouse receiving its units, departments
+// are narrowed by department_permission and warehouses by warehouse_permission
+func authorizeHandover(e echo.Context, db *sql.DB, department_permission, warehouse_permission string) (int, models.ScopeModel, error) {
+	status, scope, err := getUserScope(e, db)
+	if err != nil {
+		return status, models.ScopeModel{}, err
+	}
+
+	query := database.NewDBinstance(db)
+
+	departments, ok, err := query.AuthorizeScope(scope, department_permission)
+	if err != nil {
+		return http.StatusInternalServerError, models.ScopeModel{}, err
+	} else if !ok {
+		departments.DepartmentIDs, departments.OnBehalfOf = []int{}, []int{}
+	}
+
+	warehouses, ok, err := query.AuthorizeScope(scope, warehouse_permission)
+	if err != nil {
+		return http.StatusInternalServerError, models.ScopeModel{}, err
+	} else if !ok {
+		warehouses.WarehouseIDs, warehouses.OnBehalfOf = []int{}, []int{}
+	}
+
+	if len(departments.DepartmentIDs) == 0 && len(warehouses.WarehouseIDs) == 0 {
+		log.Printf("%v %v lacks permission %v and %v", scope.Role, scope.UserEmail, department_permission, warehouse_permission)
+		return http.StatusForbidden, models.ScopeModel{}, fmt.Errorf("missing permission %v", department_permission)
+	}
+
+	authorized := departments
+	authorized.WarehouseIDs = warehouses.WarehouseIDs
+	for _, id := range warehouses.OnBehalfOf {
+		if !slices.Contains(authorized.OnBehalfOf, id) {
+			authorized.OnBehalfOf = append(authorized.OnBehalfOf, id)
+		}
+	}
+
+	if len(authorized.OnBehalfOf) > 0 {
+		e.Set("delegationIDs", authorized.OnBehalfOf)
+	}
+
+	return http.StatusOK, authorized, nil
+}
+
+// targetNode checks the branch, department or warehouse a request acts on against the authorized scope, the id may
+// be left out when the scope holds exactly one node of that kind. Only the delegations covering the node are
+// recorded for the request
+func targetNode(e echo.Context, db *sql.DB, scope models.ScopeModel, node_type string, id int) (int, int, error) {
+	var ids []int
+	switch node_type {
+	case "branch":
+		ids = scope.BranchIDs
+	case "department":
+		ids = scope.DepartmentIDs
+	case "warehouse":
+		ids = scope.WarehouseIDs
+	}
+
+	if id == 0 {
+		if len(ids) != 1 {
+			log.Printf("%v_id is required", node_type)
+			return http.StatusBadRequest, -1, fmt.Errorf("%v_id is required", node_type)
+		}
+		id = ids[0]
+	} else if !slices.Contains(ids, id) {
+		log.Printf("%v %v is not in the authorized scope", node_type, id)
+		return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+	}
+
+	if len(scope.OnBehalfOf) > 0 {
+		delegations, err := database.NewDBinstance(db).DelegationsOnNode(scope, node_type, id)
+		if err != nil {
+			return http.StatusInternalServerError, -1, err
+		}
+		e.Set("delegationIDs", delegations)
+	}
+
+	return http.StatusOK, id, nil
+}
+
+func authorizeScope(db *sql.DB, scope models.ScopeModel, permission string) (int, models.ScopeModel, error) {
+	query := database.NewDBinstance(db)
+
+	authorized, ok, err := query.AuthorizeScope(scope, permission)
+	if err != nil {
+		return http.StatusInternalServerError, models.ScopeModel{}, err
+	} else if !ok {
+		log.Printf("%v %v lacks permission %v", scope.Role, scope.UserEmail, permission)
+		return http.StatusForbidden, models.ScopeModel{}, fmt.Errorf("missing permission %v", permission)
+	}
+
+	return http.StatusOK, authorized, nil
+}
+
+// checkPermission authorizes handlers that verify the token themselves, they act on the account's own department
+// or warehouse so the permission has to cover it
+func checkPermission(db *sql.DB, claims models.UserTokenModel, permission string) (int, error) {
+	query := database.NewDBinstance(db)
+
+	scope, err := query.GetUserScope(claims.UserType, claims.UserID)
+	if err != nil {
+		log.Printf("error while resolving user scope: %v", err)
+		return http.StatusInternalServerError, err
+	}
+	scope.UserEmail, scope.StaffID, scope.StaffRole = claims.UserEmail, claims.StaffID, claims.StaffRole
+
+	status, authorized, err := authorizeScope(db, scope, permission)
+	if err != nil {
+		return status, err
+	}
+
+	for _, id := range scope.DepartmentIDs {
+		if !slices.Contains(authorized.DepartmentIDs, id) {
+			return http.StatusForbidden, fmt.Errorf("missing permission %v", permission)
+		}
+	}
+	for _, id := range scope.WarehouseIDs {
+		if !slices.Contains(authorized.WarehouseIDs, id) {
+			return http.StatusForbidden, fmt.Errorf("missing permission %v", permission)
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// getUserOrg resolves the organization of any account by its email
+func getUserOrg(query *database.Query, email string) (int, int, error) {
+	userType, ok, err := query.GetUserType(email)
+	if err != nil {
+		log.Printf("Error checking user type: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	} else if !ok {
+		return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+	}
+
+	_, _, userID, ok, err := query.GetUserPasswordID(email, userType)
+	if err != nil {
+		log.Printf("Error checking user details: %v", err)
+		return http.StatusInternalServerError, -1, fmt.Errorf("database error")
+	} else if !ok {
+		return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+	}
+
+	scope, err := query.GetUserScope(userType, userID)
+	if err != nil {
+		return http.StatusNotFound, -1, fmt.Errorf("no matching data found")
+	}
+
+	return http.StatusOK, scope.OrgID, nil
+}
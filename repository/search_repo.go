@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+type SearchRepo struct {
+	db *sql.DB
+}
+
+func NewSearchRepo(db *sql.DB) *SearchRepo {
+	return &SearchRepo{db: db}
+}
+
+func (sr *SearchRepo) Search(e echo.Context) (int, []models.SearchResultModel, error) {
+	term := strings.TrimSpace(e.QueryParam("q"))
+	if len(term) < 2 || len(term) > 100 {
+		log.Printf("invalid search term %q", term)
+		return http.StatusBadRequest, []models.SearchResultModel{}, fmt.Errorf("search term must be between 2 and 100 characters")
+	}
+
+	limit, _ := strconv.Atoi(e.QueryParam("limit"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	allowed := map[string]bool{"branch": true, "department": true, "workspace": true, "component": true, "unit": true, "issue": true, "user": true}
+	types := allowed
+
+	if param := e.QueryParam("types"); param != "" {
+		types = map[string]bool{}
+		for _, t := range strings.Split(param, ",") {
+			t = strings.TrimSpace(t)
+			if !allowed[t] {
+				log.Printf("invalid search type %v", t)
+				return http.StatusBadRequest, []models.SearchResultModel{}, fmt.Errorf("invalid search type %v", t)
+			}
+			types[t] = true
+		}
+	}
+
+	status, scope, err := authorize(e, sr.db, "details.view")
+	if err != nil {
+		return status, []models.SearchResultModel{}, err
+	}
+
+	query := database.NewDBinstance(sr.db)
+
+	return query.Search(scope, term, types, limit)
+}
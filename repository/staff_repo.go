@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Hacfy/IT_INVENTORY/internals/models"
+	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
+	"github.com/labstack/echo/v4"
+)
+
+type StaffRepo struct {
+	db *sql.DB
+}
+
+func NewStaffRepo(db *sql.DB) *StaffRepo {
+	return &StaffRepo{db: db}
+}
+
+func (sr *StaffRepo) CreateStaff(e echo.Context) (int, int, error) {
+	status, scope, err := authorize(e, sr.db, "staff.manage")
+	if err != nil {
+		return status, -1, err
+	}
+
+	var staff models.CreateStaffModel
+
+	if err := e.Bind(&staff); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("invalid request format")
+	}
+
+	staff.Name = strings.TrimSpace(staff.Name)
+	staff.Email = strings.ToLower(strings.TrimSpace(staff.Email))
+
+	if err := validate.Struct(staff); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, -1, fmt.Errorf("failed to validate request")
+	}
+
+	if staff.DepartmentID == 0 && staff.WarehouseID == 0 {
+		switch {
+		case scope.Role == "department_head" && len(scope.DepartmentIDs) == 1:
+			staff.DepartmentID = scope.DepartmentIDs[0]
+		case scope.Role == "warehouses":
+			staff.WarehouseID = scope.UserID
+		}
+	}
+
+	if (staff.DepartmentID == 0) == (staff.WarehouseID == 0) {
+		log.Printf("staff needs exactly one of department and warehouse")
+		return http.StatusBadRequest, -1, fmt.Errorf("either department_id or warehouse_id is required")
+	}
+
+	hash, err := unusablePassword()
+	if err != nil {
+		return http.StatusInternalServerError, -1, err
+	}
+
+	account_type := "warehouses"
+	if staff.DepartmentID != 0 {
+		account_type = "department_head"
+	}
+
+	token, invitation, err := newInvitation(staff.Name, account_type, scope.Role, scope.UserID)
+	if err != nil {
+		return http.StatusInternalServerError, -1, err
+	}
+
+	query := database.NewDBinstance(sr.db)
+
+	status, staff_id, err := query.CreateStaff(scope, staff, hash, invitation)
+	if err != nil {
+		return status, -1, err
+	}
+
+	go sendInvitation(staff.Email, staff.Name, account_type, token, invitation.ExpiresAt)
+
+	return status, staff_id, nil
+}
+
+func (sr *StaffRepo) GetStaff(e echo.Context) (int, []models.StaffModel, models.PageModel, error) {
+	status, scope, err := getUserScope(e, sr.db)
+	if err != nil {
+		return status, []models.StaffModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.StaffResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.StaffModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(sr.db)
+
+	return query.GetStaff(scope, spec)
+}
+
+func (sr *StaffRepo) DeactivateStaff(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, sr.db, "staff.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var staff models.DeactivateStaffModel
+
+	if err := e.Bind(&staff); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(staff); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(sr.db)
+
+	return query.DeactivateStaff(scope, staff.StaffID)
+}
+
+func (sr *StaffRepo) AssignIssue(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, sr.db, "issues.assign")
+	if err != nil {
+		return status, err
+	}
+
+	var assign models.AssignIssueModel
+
+	if err := e.Bind(&assign); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(assign); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	query := database.NewDBinstance(sr.db)
+
+	return query.AssignIssue(scope, assign)
+}
+
+func (sr *StaffRepo) GetStaffIssues(e echo.Context) (int, []models.StaffIssueModel, models.PageModel, error) {
+	status, scope, err := getUserScope(e, sr.db)
+	if err != nil {
+		return status, []models.StaffIssueModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.StaffIssuesResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.StaffIssueModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(sr.db)
+
+	return query.GetStaffIssues(scope, spec)
+}
+
+func (sr *StaffRepo) GetStaffActivity(e echo.Context) (int, []models.StaffActivityModel, models.PageModel, error) {
+	status, scope, err := authorize(e, sr.db, "staff.manage")
+	if err != nil {
+		return status, []models.StaffActivityModel{}, models.PageModel{}, err
+	}
+
+	spec, err := queryspec.Parse(e.QueryParams(), database.StaffActivityResource)
+	if err != nil {
+		return http.StatusBadRequest, []models.StaffActivityModel{}, models.PageModel{}, err
+	}
+
+	query := database.NewDBinstance(sr.db)
+
+	return query.GetStaffActivity(scope, spec)
+}
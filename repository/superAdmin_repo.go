@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
@@ -67,6 +68,10 @@ func (sa *SuperAdminRepo) CreateBranch(e echo.Context) (int, error) {
 		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
 	}
 
+	if status, err := checkPermission(sa.db, *claims, "branches.manage"); err != nil {
+		return status, err
+	}
+
 	var branch models.CreateBranchModel
 
 	if err := e.Bind(&branch); err != nil {
@@ -81,38 +86,22 @@ func (sa *SuperAdminRepo) CreateBranch(e echo.Context) (int, error) {
 
 	branch.BranchHeadName = strings.ToLower(branch.BranchHeadName)
 
-	password, err := utils.GeneratePassword()
+	hash, err := unusablePassword()
 	if err != nil {
-		log.Printf("error while generating password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
+		return http.StatusInternalServerError, err
 	}
 
-	for !utils.StrongPasswordValidator(password) {
-		password, err = utils.GeneratePassword()
-		if err != nil {
-			log.Printf("error while generating password: %v", err)
-			return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
-		}
-	}
-
-	hash, err := utils.HashPassword(password)
+	invitation_token, invitation, err := newInvitation(branch.BranchHeadName, "branch_head", claims.UserType, claims.UserID)
 	if err != nil {
-		log.Printf("error while hashing password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to secure your password, please try again")
+		return http.StatusInternalServerError, err
 	}
 
-	if err := query.CreateBranch(branch, claims.UserID, hash); err != nil {
+	if err := query.CreateBranch(branch, claims.UserID, hash, invitation); err != nil {
 		log.Printf("error while storing Branch Data in DB :%v", err)
 		return http.StatusInternalServerError, fmt.Errorf("unable to create branch at the moment, please try again later")
 	}
 
-	go func() {
-		log.Printf("sending login credentials to %v", branch.BranchHeadEmail)
-		if err := utils.SendLoginCredentials(branch.BranchHeadEmail, password); err != nil {
-			log.Printf("error while sending login credentials to %v: %v", branch.BranchHeadEmail, err)
-		}
-		log.Printf("credentials sent to %v", branch.BranchHeadEmail)
-	}()
+	go sendInvitation(branch.BranchHeadEmail, branch.BranchHeadName, "branch_head", invitation_token, invitation.ExpiresAt)
 
 	return http.StatusCreated, nil
 }
@@ -134,6 +123,10 @@ func (sa *SuperAdminRepo) DeleteBranch(e echo.Context) (int, error) {
 		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
 	}
 
+	if status, err := checkPermission(sa.db, claims, "branches.manage"); err != nil {
+		return status, err
+	}
+
 	var branch models.DeleteBranchModel
 
 	if err := e.Bind(&branch); err != nil {
@@ -148,8 +141,20 @@ func (sa *SuperAdminRepo) DeleteBranch(e echo.Context) (int, error) {
 
 	branch.BrachName = strings.ToLower(branch.BrachName)
 
+	ok, err = query.CheckIfBranchUnderSuperAdmin(branch.BranchID, claims.UserID)
+	if err != nil {
+		log.Printf("Error checking user details: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if !ok {
+		log.Printf("Invalid user details")
+		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
+	}
+
 	if status, err := query.DeleteBranch(branch, claims.UserID); err != nil {
 		log.Printf("error while deleting the branch %v: %v", branch.BranchID, err)
+		if status == http.StatusConflict || status == http.StatusNotFound || status == http.StatusPreconditionRequired {
+			return status, err
+		}
 		return status, fmt.Errorf("error while deleting the branch")
 	}
 
@@ -198,6 +203,10 @@ func (sa *SuperAdminRepo) UpdateBranchHead(e echo.Context) (int, error) {
 		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
 	}
 
+	if status, err := checkPermission(sa.db, *claims, "branches.manage"); err != nil {
+		return status, err
+	}
+
 	var branchHead models.UpdateBranchHeadModel
 
 	if err := e.Bind(&branchHead); err != nil {
@@ -212,39 +221,58 @@ func (sa *SuperAdminRepo) UpdateBranchHead(e echo.Context) (int, error) {
 
 	branchHead.NewBranchHeadName = strings.ToLower(branchHead.NewBranchHeadName)
 
-	password, err := utils.GeneratePassword()
+	hash, err := unusablePassword()
 	if err != nil {
-		log.Printf("error while generating password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
-	}
-
-	for !utils.StrongPasswordValidator(password) {
-		password, err = utils.GeneratePassword()
-		if err != nil {
-			log.Printf("error while generating password: %v", err)
-			return http.StatusInternalServerError, fmt.Errorf("failed to generate password, please try again later")
-		}
+		return http.StatusInternalServerError, err
 	}
 
-	hash, err := utils.HashPassword(password)
+	invitation_token, invitation, err := newInvitation(branchHead.NewBranchHeadName, "branch_head", claims.UserType, claims.UserID)
 	if err != nil {
-		log.Printf("error while hashing password: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("failed to secure your password, please try again")
+		return http.StatusInternalServerError, err
 	}
 
-	status, err := query.UpdateBranchHead(branchHead, claims.UserID, hash)
+	status, err := query.UpdateBranchHead(branchHead, claims.UserID, hash, invitation)
 	if err != nil {
 		log.Printf("error while deleting branchHead %v: %v", branchHead.BranchHeadID, err)
 		return status, fmt.Errorf("unable to delete the branch head at the moment, please try again later")
 	}
 
-	go func() {
-		log.Printf("sending login credentials to %v", branchHead.NewBranchHeadEmail)
-		if err := utils.SendLoginCredentials(branchHead.NewBranchHeadEmail, password); err != nil {
-			log.Printf("error while sending login credentials to %v: %v", branchHead.NewBranchHeadEmail, err)
-		}
-		log.Printf("credentials sent to %v", branchHead.NewBranchHeadEmail)
-	}()
+	go sendInvitation(branchHead.NewBranchHeadEmail, branchHead.NewBranchHeadName, "branch_head", invitation_token, invitation.ExpiresAt)
 
 	return http.StatusCreated, nil
 }
+
+func (sa *SuperAdminRepo) PreviewDeleteBranch(e echo.Context) (int, models.DeletionImpactModel, error) {
+	status, claims, err := utils.VerifyUserToken(e, "super_admin", sa.db)
+	if err != nil {
+		return status, models.DeletionImpactModel{}, err
+	}
+
+	query := database.NewDBinstance(sa.db)
+
+	ok, err := query.VerifyUser(claims.UserEmail, "super_admin", claims.UserID)
+	if err != nil {
+		log.Printf("Error checking user details: %v", err)
+		return http.StatusInternalServerError, models.DeletionImpactModel{}, fmt.Errorf("database error")
+	} else if !ok {
+		log.Printf("Invalid user details")
+		return http.StatusUnauthorized, models.DeletionImpactModel{}, fmt.Errorf("invalid user details")
+	}
+
+	BranchID, err := strconv.Atoi(e.QueryParam("branch_id"))
+	if err != nil {
+		log.Printf("error while parsing branch id: %v", err)
+		return http.StatusBadRequest, models.DeletionImpactModel{}, fmt.Errorf("invalid request format")
+	}
+
+	ok, err = query.CheckIfBranchUnderSuperAdmin(BranchID, claims.UserID)
+	if err != nil {
+		log.Printf("Error checking user details: %v", err)
+		return http.StatusInternalServerError, models.DeletionImpactModel{}, fmt.Errorf("database error")
+	} else if !ok {
+		log.Printf("Invalid user details")
+		return http.StatusUnauthorized, models.DeletionImpactModel{}, fmt.Errorf("invalid user details")
+	}
+
+	return query.GetDeletionImpact("branch", BranchID)
+}
@@ -2,16 +2,17 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 	"unicode"
 
 	"github.com/Hacfy/IT_INVENTORY/internals/models"
 	"github.com/Hacfy/IT_INVENTORY/pkg/database"
+	"github.com/Hacfy/IT_INVENTORY/pkg/queryspec"
 	"github.com/Hacfy/IT_INVENTORY/pkg/utils"
 	"github.com/labstack/echo/v4"
 )
@@ -26,10 +27,10 @@ func NewWarehouseRepo(db *sql.DB) *WarehouseRepo {
 	}
 }
 
-func (wr *WarehouseRepo) generateUniquePrefix(name string) (string, error) {
-
-	query := database.NewDBinstance(wr.db)
+const prefixFiller = "zyxwvutsrqponmlkjihgfedcba"
 
+// basePrefix is the first prefix tried for a component, built from the first distinct letters of its name
+func basePrefix(name string) (string, error) {
 	if len(strings.TrimSpace(name)) == 0 {
 		return "", fmt.Errorf("product name cannot be empty")
 	}
@@ -39,7 +40,7 @@ func (wr *WarehouseRepo) generateUniquePrefix(name string) (string, error) {
 	seen := make(map[rune]bool)
 
 	for _, ch := range name {
-		if unicode.IsLetter(ch) && !seen[ch] {
+		if unicode.IsLetter(ch) && ch < unicode.MaxASCII && !seen[ch] {
 			letters += string(ch)
 			seen[ch] = true
 		}
@@ -48,72 +49,44 @@ func (wr *WarehouseRepo) generateUniquePrefix(name string) (string, error) {
 		}
 	}
 
-	filler := "zyxwvutsrqponmlkjihgfedcba"
-
 	for len(letters) < 3 {
-		letters += string(filler[0])
+		letters += string(prefixFiller[0])
 	}
 
-	prefix := letters
-	maxAttempts := 10000
-	attempts := 0
+	return letters, nil
+}
 
-	for {
-		if !query.IfPrefixExists(prefix) {
-			break
-		}
+// nextPrefix gives the prefix to try after prefix is taken
+func nextPrefix(prefix string) (string, error) {
+	runes := []rune(prefix)
 
-		attempts++
-		if attempts > maxAttempts {
-			return "", fmt.Errorf("failed to generate unique prefix")
+	for i := len(runes) - 1; i >= 0; i-- {
+		index := strings.IndexRune(prefixFiller, runes[i])
+		if index == -1 {
+			return "", fmt.Errorf("invalid character in prefix: %c", runes[i])
 		}
-
-		runes := []rune(prefix)
-		changed := false
-
-		for i := len(runes) - 1; i >= 0; i-- {
-			index := strings.IndexRune(filler, runes[i])
-			if index == -1 {
-				return "", fmt.Errorf("invalid character in prefix: %c", runes[i])
-			}
-			if index < len(filler)-1 {
-				runes[i] = rune(filler[index+1])
-				for j := i + 1; j < len(runes); j++ {
-					runes[j] = rune(filler[0])
-				}
-				changed = true
-				break
+		if index < len(prefixFiller)-1 {
+			runes[i] = rune(prefixFiller[index+1])
+			for j := i + 1; j < len(runes); j++ {
+				runes[j] = rune(prefixFiller[0])
 			}
-		}
-
-		if !changed {
-			prefix = string(filler[0]) + string(runes)
-		} else {
-			prefix = string(runes)
+			return string(runes), nil
 		}
 	}
 
-	return prefix, nil
+	if len(runes)+1 > utils.MaxPrefixLength {
+		return "", fmt.Errorf("prefixes exhausted for %v", prefix)
+	}
 
+	return string(prefixFiller[0]) + string(runes), nil
 }
 
 func (wr *WarehouseRepo) CreateComponent(e echo.Context) (int, string, error) {
-	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
+	status, scope, err := authorize(e, wr.db, "components.manage")
 	if err != nil {
 		return status, "", err
 	}
 
-	query := database.NewDBinstance(wr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, "", fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, "", fmt.Errorf("invalid user details")
-	}
-
 	var new_component models.CreateComponentModel
 
 	if err := e.Bind(&new_component); err != nil {
@@ -126,35 +99,35 @@ func (wr *WarehouseRepo) CreateComponent(e echo.Context) (int, string, error) {
 		return http.StatusBadRequest, "", fmt.Errorf("failed to validate request")
 	}
 
-	exists, err := query.IfComponentExists(new_component.ComponentName, claims.UserID)
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", new_component.WarehouseID)
 	if err != nil {
-		log.Printf("Error checking if component exists: %v", err)
-		return http.StatusInternalServerError, "", fmt.Errorf("database error")
+		return status, "", err
 	}
 
-	if exists {
-		log.Printf("component %v already exists", new_component.ComponentName)
-		return http.StatusConflict, "", fmt.Errorf("component already exists")
+	query := database.NewDBinstance(wr.db)
+
+	if new_component.Kind == "" {
+		new_component.Kind = "unit"
 	}
 
-	// Component doesn't exist — proceed to create
+	var Prefix string
+	next := nextPrefix
 
-	Prefix, err := wr.generateUniquePrefix(new_component.ComponentName)
-	if err != nil {
+	if new_component.Prefix != "" {
+		var ok bool
+		if Prefix, ok = utils.NormalisePrefix(new_component.Prefix); !ok {
+			log.Printf("invalid prefix %v", new_component.Prefix)
+			return http.StatusBadRequest, "", fmt.Errorf("invalid prefix, use 2 to %v letters or digits starting with a letter", utils.MaxPrefixLength)
+		}
+		next = nil
+	} else if Prefix, err = basePrefix(new_component.ComponentName); err != nil {
 		log.Printf("error while generating prefix: %v", err)
-		return http.StatusInternalServerError, "", fmt.Errorf("failed to generate prefix, please try again later")
+		return http.StatusBadRequest, "", fmt.Errorf("invalid component name")
 	}
 
-	component_id, err := query.CreateComponent(new_component.ComponentName, Prefix, claims.UserID)
+	status, component_id, Prefix, err := query.CreateComponent(new_component.ComponentName, new_component.Kind, warehouse_id, new_component.CatalogueID, Prefix, next)
 	if err != nil {
-		log.Printf("error while storing Component data in DB: %v", err)
-		return http.StatusInternalServerError, "", fmt.Errorf("unable to create component at the moment, please try again later")
-	}
-	time.Sleep(100 * time.Millisecond)
-	if err := query.CreateAssignedAndDeleteUnitTable(Prefix); err != nil {
-		query.UndoCreateComponent(Prefix)
-		log.Printf("error while creating assigned and deleted units table %s", err.Error())
-		return http.StatusInternalServerError, "", fmt.Errorf("unable to create component at the moment, please try again later")
+		return status, "", err
 	}
 	log.Printf("Creating token for component_id=%d, name=%s, prefix=%s", component_id, new_component.ComponentName, Prefix)
 	token, err := utils.GenerateComponentToken(component_id, new_component.ComponentName, Prefix)
@@ -168,22 +141,11 @@ func (wr *WarehouseRepo) CreateComponent(e echo.Context) (int, string, error) {
 }
 
 func (wr *WarehouseRepo) DeleteComponent(e echo.Context) (int, error) {
-	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
+	status, scope, err := authorize(e, wr.db, "components.manage")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(wr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var del_component models.DeleteComponentModel
 
 	if err := e.Bind(&del_component); err != nil {
@@ -196,7 +158,14 @@ func (wr *WarehouseRepo) DeleteComponent(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
-	if status, err := query.DeleteComponent(del_component, claims.UserID); err != nil {
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", del_component.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
+	if status, err := query.DeleteComponent(del_component, warehouse_id); err != nil {
 		log.Printf("error while deleting the component %v: %v", del_component.ComponentID, err)
 		return status, fmt.Errorf("error while deleting the component")
 	}
@@ -206,22 +175,11 @@ func (wr *WarehouseRepo) DeleteComponent(e echo.Context) (int, error) {
 
 func (wr *WarehouseRepo) AddComponentUnits(e echo.Context) (int, error) {
 
-	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
+	status, scope, err := authorize(e, wr.db, "units.manage")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(wr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var new_component_unit models.AddUnitModel
 
 	if err := e.Bind(&new_component_unit); err != nil {
@@ -234,16 +192,46 @@ func (wr *WarehouseRepo) AddComponentUnits(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
-	prefix, exists, err := query.CheckIfComponentIDExists(new_component_unit.ComponentID, claims.UserID)
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", new_component_unit.WarehouseID)
 	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
+	prefix, exists, err := query.CheckIfComponentIDExists(new_component_unit.ComponentID, warehouse_id)
+	if err != nil && err != sql.ErrNoRows {
 		log.Printf("error while checking if component exists: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if exists {
+	} else if !exists {
 		log.Printf("component with id %v does not exist", new_component_unit.ComponentID)
 		return http.StatusBadRequest, fmt.Errorf("component with id %v does not exist", new_component_unit.ComponentID)
 	}
 
-	status, err = query.CreateComponentUnit(new_component_unit.Warenty_Date, float32(new_component_unit.Cost), prefix, claims.UserID, new_component_unit.Number_of_units, new_component_unit.ComponentID)
+	if kind, err := query.GetComponentKind(new_component_unit.ComponentID); err != nil {
+		log.Printf("error while getting component kind: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if kind == "consumable" {
+		return http.StatusBadRequest, fmt.Errorf("component %v is a consumable, use stock in instead", new_component_unit.ComponentID)
+	}
+
+	definitions, err := query.GetComponentAttributeDefinitions(new_component_unit.ComponentID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	values, err := utils.ValidateAttributes(definitions, new_component_unit.Attributes, nil)
+	if err != nil {
+		log.Printf("invalid attributes: %v", err)
+		return http.StatusBadRequest, err
+	}
+
+	attributes, err := json.Marshal(values)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	status, err = query.CreateComponentUnit(new_component_unit.Warenty_Date, float32(new_component_unit.Cost), prefix, warehouse_id, new_component_unit.Number_of_units, new_component_unit.ComponentID, attributes)
 	if err != nil {
 		log.Printf("error while creating units of %v: %v", new_component_unit.ComponentID, err)
 		return status, fmt.Errorf("database error")
@@ -254,22 +242,11 @@ func (wr *WarehouseRepo) AddComponentUnits(e echo.Context) (int, error) {
 
 func (wr *WarehouseRepo) AssignUnits(e echo.Context) (int, error) {
 
-	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
+	status, scope, err := authorize(e, wr.db, "units.assign")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(wr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var new_unit models.AssignUnitModel
 
 	if err := e.Bind(&new_unit); err != nil {
@@ -282,7 +259,14 @@ func (wr *WarehouseRepo) AssignUnits(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
-	prefix, exists, err := query.CheckIfComponentIDExists(new_unit.ComponentID, claims.UserID)
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", new_unit.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
+	prefix, exists, err := query.CheckIfComponentIDExists(new_unit.ComponentID, warehouse_id)
 	if err != nil {
 		log.Printf("error while checking if component exists: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
@@ -293,20 +277,27 @@ func (wr *WarehouseRepo) AssignUnits(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("component with id %v does not exist", new_unit.ComponentID)
 	}
 
-	status, err = query.AssignUnitWorkspace(new_unit.WorkspaceID, new_unit.UnitIDs, prefix)
+	if kind, err := query.GetComponentKind(new_unit.ComponentID); err != nil {
+		log.Printf("error while getting component kind: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	} else if kind == "consumable" {
+		return http.StatusBadRequest, fmt.Errorf("component %v is a consumable, issue it instead", new_unit.ComponentID)
+	}
+
+	status, err = query.AssignUnitWorkspace(new_unit.WorkspaceID, new_unit.ComponentID, new_unit.UnitIDs, prefix, warehouse_id)
 	if err != nil {
 		log.Printf("error while assigning units to workspace: %v", err)
-		return status, fmt.Errorf("database error")
+		return status, err
 	}
 
 	return status, nil
 
 }
 
-func (wr *WarehouseRepo) GetAllWarehouseIssues(e echo.Context) (int, []models.IssueModel, int, int, int, error) {
+func (wr *WarehouseRepo) GetAllWarehouseIssues(e echo.Context) (int, []models.IssueModel, models.PageModel, error) {
 	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
 	if err != nil {
-		return status, []models.IssueModel{}, 0, 0, 0, err
+		return status, []models.IssueModel{}, models.PageModel{}, err
 	}
 
 	query := database.NewDBinstance(wr.db)
@@ -314,58 +305,18 @@ func (wr *WarehouseRepo) GetAllWarehouseIssues(e echo.Context) (int, []models.Is
 	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
 	if err != nil {
 		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, []models.IssueModel{}, 0, 0, 0, fmt.Errorf("database error")
+		return http.StatusInternalServerError, []models.IssueModel{}, models.PageModel{}, fmt.Errorf("database error")
 	} else if !ok {
 		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, []models.IssueModel{}, 0, 0, 0, fmt.Errorf("invalid user details")
-	}
-
-	var Sort models.SortModel
-
-	Sort.Limit, err = strconv.Atoi(e.QueryParam("limit"))
-	if err != nil {
-		return http.StatusBadRequest, []models.IssueModel{}, 0, 0, 0, fmt.Errorf("invalid request format")
-	}
-
-	if Sort.Limit <= 0 || Sort.Limit > 100 {
-		Sort.Limit = 10
-	}
-
-	Sort.Page, err = strconv.Atoi(e.QueryParam("page"))
-	if err != nil {
-		return http.StatusBadRequest, []models.IssueModel{}, 0, 0, 0, fmt.Errorf("invalid request format")
-	}
-
-	if Sort.Page <= 0 {
-		Sort.Page = 1
+		return http.StatusUnauthorized, []models.IssueModel{}, models.PageModel{}, fmt.Errorf("invalid user details")
 	}
-	Sort.Offset = (Sort.Page - 1) * Sort.Limit
 
-	Sort.Order = e.QueryParam("order")
-	if Sort.Order != "asc" && Sort.Order != "desc" {
-		Sort.Order = "asc"
-	}
-
-	Sort.SortBy = e.QueryParam("sortBy")
-	if Sort.SortBy == "" {
-		Sort.SortBy = "created_at"
-	}
-
-	allowed := map[string]bool{"name": true, "created_at": true}
-	if !allowed[Sort.SortBy] {
-		Sort.SortBy = "created_at"
-	}
-
-	Sort.Search = e.QueryParam("search")
-
-	status, issues, total, err := query.GetAllIssues(claims.UserID, Sort)
+	spec, err := queryspec.Parse(e.QueryParams(), database.IssuesResource)
 	if err != nil {
-		log.Printf("Error while fetching issues: %v", err)
-		return http.StatusInternalServerError, []models.IssueModel{}, 0, 0, 0, fmt.Errorf("database error")
+		return http.StatusBadRequest, []models.IssueModel{}, models.PageModel{}, err
 	}
 
-	return status, issues, total, Sort.Page, Sort.Limit, nil
-
+	return query.GetAllIssues(claims.UserID, spec)
 }
 
 func (wr *WarehouseRepo) GetAllWarehouseComponents(e echo.Context) (int, []models.AllWarehouseComponentsModel, error) {
@@ -395,10 +346,10 @@ func (wr *WarehouseRepo) GetAllWarehouseComponents(e echo.Context) (int, []model
 
 }
 
-func (wr *WarehouseRepo) GetAllWarehouseComponentUnits(e echo.Context) (int, []models.AllComponentUnitsModel, error) {
+func (wr *WarehouseRepo) GetAllWarehouseComponentUnits(e echo.Context) (int, []models.AllComponentUnitsModel, models.PageModel, error) {
 	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
 	if err != nil {
-		return status, []models.AllComponentUnitsModel{}, err
+		return status, []models.AllComponentUnitsModel{}, models.PageModel{}, err
 	}
 
 	query := database.NewDBinstance(wr.db)
@@ -406,33 +357,37 @@ func (wr *WarehouseRepo) GetAllWarehouseComponentUnits(e echo.Context) (int, []m
 	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
 	if err != nil {
 		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, []models.AllComponentUnitsModel{}, fmt.Errorf("database error")
+		return http.StatusInternalServerError, []models.AllComponentUnitsModel{}, models.PageModel{}, fmt.Errorf("database error")
 	} else if !ok {
 		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, []models.AllComponentUnitsModel{}, fmt.Errorf("invalid user details")
+		return http.StatusUnauthorized, []models.AllComponentUnitsModel{}, models.PageModel{}, fmt.Errorf("invalid user details")
 	}
 
-	var getAllComponentUnitsModel models.GetAllComponentUnitsModel
-
-	err = e.Bind(&getAllComponentUnitsModel)
-	if err != nil {
-		log.Printf("failed to decode request: %v", err)
-		return http.StatusBadRequest, []models.AllComponentUnitsModel{}, fmt.Errorf("invalid request format")
+	component_id, err := strconv.Atoi(e.QueryParam("component_id"))
+	if err != nil || component_id <= 0 {
+		log.Printf("invalid component id")
+		return http.StatusBadRequest, []models.AllComponentUnitsModel{}, models.PageModel{}, fmt.Errorf("invalid component id")
 	}
 
-	if getAllComponentUnitsModel.ComponentID <= 0 {
-		log.Printf("invalid component id")
-		return http.StatusBadRequest, []models.AllComponentUnitsModel{}, fmt.Errorf("invalid component id")
+	prefix, _, err := query.CheckIfComponentIDExists(component_id, claims.UserID)
+	if err == sql.ErrNoRows {
+		return http.StatusNotFound, []models.AllComponentUnitsModel{}, models.PageModel{}, fmt.Errorf("no components found")
+	} else if err != nil {
+		log.Printf("error while checking if component exists: %v", err)
+		return http.StatusInternalServerError, []models.AllComponentUnitsModel{}, models.PageModel{}, fmt.Errorf("database error")
 	}
 
-	units, err := query.GetAllWarehouseComponentUnits(getAllComponentUnitsModel.ComponentID)
+	definitions, err := query.GetComponentAttributeDefinitions(component_id)
 	if err != nil {
-		log.Printf("error while fetching components: %v", err)
-		return http.StatusInternalServerError, []models.AllComponentUnitsModel{}, fmt.Errorf("database error")
+		return http.StatusInternalServerError, []models.AllComponentUnitsModel{}, models.PageModel{}, err
 	}
 
-	return http.StatusOK, units, nil
+	spec, err := queryspec.Parse(e.QueryParams(), database.UnitsResource(definitions))
+	if err != nil {
+		return http.StatusBadRequest, []models.AllComponentUnitsModel{}, models.PageModel{}, err
+	}
 
+	return query.GetAllWarehouseComponentUnits(prefix, component_id, spec)
 }
 
 func (wr *WarehouseRepo) GetIssueDetails(e echo.Context) (int, models.IssueDetailsModel, error) {
@@ -520,22 +475,11 @@ func (wr *WarehouseRepo) GetUnitAssignmentHistory(e echo.Context) (int, models.U
 }
 
 func (wr *WarehouseRepo) UpdateIssueStatus(e echo.Context) (int, error) {
-	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
+	status, scope, err := authorize(e, wr.db, "issues.resolve")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(wr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var updateIssueStatusModel models.UpdateIssueStatusModel
 
 	err = e.Bind(&updateIssueStatusModel)
@@ -544,6 +488,13 @@ func (wr *WarehouseRepo) UpdateIssueStatus(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("invalid request format")
 	}
 
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", updateIssueStatusModel.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
 	if updateIssueStatusModel.IssueID <= 0 {
 		log.Printf("invalid issue id")
 		return http.StatusBadRequest, fmt.Errorf("invalid issue id")
@@ -559,10 +510,10 @@ func (wr *WarehouseRepo) UpdateIssueStatus(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("invalid issue status")
 	}
 
-	status, err = query.UpdateIssueStatus(updateIssueStatusModel.IssueID, updateIssueStatusModel.Status)
+	status, err = query.UpdateIssueStatus(updateIssueStatusModel.IssueID, warehouse_id, updateIssueStatusModel.Status)
 	if err != nil {
 		log.Printf("error while updating issue status: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
+		return status, err
 	}
 
 	return status, nil
@@ -570,22 +521,11 @@ func (wr *WarehouseRepo) UpdateIssueStatus(e echo.Context) (int, error) {
 }
 
 func (wr *WarehouseRepo) UpdateComponentName(e echo.Context) (int, error) {
-	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
+	status, scope, err := authorize(e, wr.db, "components.manage")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(wr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var updateComponentNameModel models.UpdateComponentNameModel
 
 	err = e.Bind(&updateComponentNameModel)
@@ -594,6 +534,13 @@ func (wr *WarehouseRepo) UpdateComponentName(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("invalid request format")
 	}
 
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", updateComponentNameModel.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
 	if updateComponentNameModel.ComponentID <= 0 {
 		log.Printf("invalid component id")
 		return http.StatusBadRequest, fmt.Errorf("invalid component id")
@@ -604,16 +551,229 @@ func (wr *WarehouseRepo) UpdateComponentName(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("invalid component name")
 	}
 
-	status, err = query.UpdateComponentName(updateComponentNameModel.ComponentID, updateComponentNameModel.ComponentName)
+	status, err = query.UpdateComponentName(updateComponentNameModel.ComponentID, warehouse_id, updateComponentNameModel.ComponentName)
 	if err != nil {
 		log.Printf("error while updating component name: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
+		return status, err
 	}
 
 	return status, nil
 
 }
 
+func (wr *WarehouseRepo) UpdateComponentPrefix(e echo.Context) (int, string, error) {
+	status, scope, err := authorize(e, wr.db, "components.manage")
+	if err != nil {
+		return status, "", err
+	}
+
+	var updateComponentPrefixModel models.UpdateComponentPrefixModel
+
+	if err := e.Bind(&updateComponentPrefixModel); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, "", fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(updateComponentPrefixModel); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, "", fmt.Errorf("failed to validate request")
+	}
+
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", updateComponentPrefixModel.WarehouseID)
+	if err != nil {
+		return status, "", err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
+	prefix, ok := utils.NormalisePrefix(updateComponentPrefixModel.Prefix)
+	if !ok {
+		log.Printf("invalid prefix %v", updateComponentPrefixModel.Prefix)
+		return http.StatusBadRequest, "", fmt.Errorf("invalid prefix, use 2 to %v letters or digits starting with a letter", utils.MaxPrefixLength)
+	}
+
+	status, name, err := query.RenameComponentPrefix(updateComponentPrefixModel.ComponentID, warehouse_id, prefix)
+	if err != nil {
+		return status, "", err
+	}
+
+	token, err := utils.GenerateComponentToken(updateComponentPrefixModel.ComponentID, name, prefix)
+	if err != nil {
+		log.Printf("error while generating token: %v", err)
+		return http.StatusInternalServerError, "", fmt.Errorf("failed to generate token, please try again later")
+	}
+
+	return http.StatusOK, token, nil
+}
+
+func (wr *WarehouseRepo) UpdateComponentCatalogue(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, wr.db, "components.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var updateComponentCatalogueModel models.UpdateComponentCatalogueModel
+
+	if err := e.Bind(&updateComponentCatalogueModel); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(updateComponentCatalogueModel); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", updateComponentCatalogueModel.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
+	if updateComponentCatalogueModel.CatalogueID < 0 {
+		log.Printf("invalid catalogue id")
+		return http.StatusBadRequest, fmt.Errorf("invalid catalogue id")
+	}
+
+	return query.UpdateComponentCatalogue(updateComponentCatalogueModel.ComponentID, warehouse_id, updateComponentCatalogueModel.CatalogueID)
+}
+
+func (wr *WarehouseRepo) UpdateUnitAttributes(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, wr.db, "units.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var updateUnitAttributesModel models.UpdateUnitAttributesModel
+
+	if err := e.Bind(&updateUnitAttributesModel); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(updateUnitAttributesModel); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", updateUnitAttributesModel.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
+	prefix, _, err := query.CheckIfComponentIDExists(updateUnitAttributesModel.ComponentID, warehouse_id)
+	if err == sql.ErrNoRows {
+		return http.StatusNotFound, fmt.Errorf("no components found")
+	} else if err != nil {
+		log.Printf("error while checking if component exists: %v", err)
+		return http.StatusInternalServerError, fmt.Errorf("database error")
+	}
+
+	definitions, err := query.GetComponentAttributeDefinitions(updateUnitAttributesModel.ComponentID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return query.UpdateUnitAttributes(prefix, updateUnitAttributesModel.UnitID, updateUnitAttributesModel.ComponentID, warehouse_id, func(current map[string]interface{}) (map[string]interface{}, error) {
+		return utils.ValidateAttributes(definitions, updateUnitAttributesModel.Attributes, current)
+	})
+}
+
+func (wr *WarehouseRepo) LinkUnits(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, wr.db, "units.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var linkUnitsModel models.LinkUnitsModel
+
+	if err := e.Bind(&linkUnitsModel); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(linkUnitsModel); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", linkUnitsModel.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
+	if linkUnitsModel.ParentComponentID == linkUnitsModel.ChildComponentID && linkUnitsModel.ParentUnitID == linkUnitsModel.ChildUnitID {
+		return http.StatusBadRequest, fmt.Errorf("a unit cannot be linked to itself")
+	}
+
+	return query.LinkUnits(linkUnitsModel, warehouse_id)
+}
+
+func (wr *WarehouseRepo) UnlinkUnit(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, wr.db, "units.manage")
+	if err != nil {
+		return status, err
+	}
+
+	var unlinkUnitModel models.UnlinkUnitModel
+
+	if err := e.Bind(&unlinkUnitModel); err != nil {
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(unlinkUnitModel); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", unlinkUnitModel.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
+	return query.UnlinkUnit(unlinkUnitModel.ComponentID, unlinkUnitModel.UnitID, warehouse_id)
+}
+
+func (wr *WarehouseRepo) GetUnitComposition(e echo.Context) (int, models.UnitCompositionModel, error) {
+	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
+	if err != nil {
+		return status, models.UnitCompositionModel{}, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
+	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
+	if err != nil {
+		log.Printf("Error checking user details: %v", err)
+		return http.StatusInternalServerError, models.UnitCompositionModel{}, fmt.Errorf("database error")
+	} else if !ok {
+		log.Printf("Invalid user details")
+		return http.StatusUnauthorized, models.UnitCompositionModel{}, fmt.Errorf("invalid user details")
+	}
+
+	component_id, err := strconv.Atoi(e.QueryParam("component_id"))
+	if err != nil || component_id <= 0 {
+		log.Printf("invalid component id")
+		return http.StatusBadRequest, models.UnitCompositionModel{}, fmt.Errorf("invalid component id")
+	}
+
+	unit_id, err := strconv.Atoi(e.QueryParam("unit_id"))
+	if err != nil || unit_id <= 0 {
+		log.Printf("invalid unit id")
+		return http.StatusBadRequest, models.UnitCompositionModel{}, fmt.Errorf("invalid unit id")
+	}
+
+	return query.GetUnitComposition(component_id, unit_id, claims.UserID)
+}
+
 func (wr *WarehouseRepo) GetAssignedUnits(e echo.Context) (int, []models.AssignedUnitsModel, int, int, int, error) {
 
 	page, err := strconv.Atoi(e.QueryParam("page"))
@@ -676,22 +836,11 @@ func (wr *WarehouseRepo) GetAssignedUnits(e echo.Context) (int, []models.Assigne
 }
 
 func (wr *WarehouseRepo) UpdateMaintenanceCost(e echo.Context) (int, error) {
-	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
+	status, scope, err := authorize(e, wr.db, "units.maintain")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(wr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var updateMaintenanceCostModel models.UpdateMaintenanceCostModel
 
 	err = e.Bind(&updateMaintenanceCostModel)
@@ -700,12 +849,19 @@ func (wr *WarehouseRepo) UpdateMaintenanceCost(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("invalid request format")
 	}
 
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", updateMaintenanceCostModel.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
 	if updateMaintenanceCostModel.UnitID <= 0 {
 		log.Printf("invalid unit id")
 		return http.StatusBadRequest, fmt.Errorf("invalid unit id")
 	}
 
-	prefix, ok, err := query.CheckIfUnitIDExists(updateMaintenanceCostModel.UnitID, updateMaintenanceCostModel.ComponentID, claims.UserID)
+	prefix, ok, err := query.CheckIfUnitIDExists(updateMaintenanceCostModel.UnitID, updateMaintenanceCostModel.ComponentID, warehouse_id)
 	if err != nil {
 		log.Printf("error while checking if component exists: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
@@ -729,22 +885,11 @@ func (wr *WarehouseRepo) UpdateMaintenanceCost(e echo.Context) (int, error) {
 }
 
 func (wr *WarehouseRepo) UpdateUnitStatus(e echo.Context) (int, error) {
-	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
+	status, scope, err := authorize(e, wr.db, "units.maintain")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(wr.db)
-
-	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
-	if err != nil {
-		log.Printf("Error checking user details: %v", err)
-		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
-	}
-
 	var updateUnitStatusModel models.UpdateUnitStatusModel
 
 	err = e.Bind(&updateUnitStatusModel)
@@ -758,12 +903,19 @@ func (wr *WarehouseRepo) UpdateUnitStatus(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", updateUnitStatusModel.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
 	if updateUnitStatusModel.UnitID <= 0 {
 		log.Printf("invalid unit id")
 		return http.StatusBadRequest, fmt.Errorf("invalid unit id")
 	}
 
-	if exists, err := query.CheckIfUnitExists(updateUnitStatusModel.UnitID, updateUnitStatusModel.Prefix, claims.UserID); err != nil {
+	if exists, err := query.CheckIfUnitExists(updateUnitStatusModel.UnitID, updateUnitStatusModel.Prefix, warehouse_id); err != nil {
 		log.Printf("error while checking if unit exists: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
 	} else if !exists {
@@ -780,21 +932,52 @@ func (wr *WarehouseRepo) UpdateUnitStatus(e echo.Context) (int, error) {
 	return http.StatusOK, nil
 }
 
-func (wr *WarehouseRepo) DeleteUnit(e echo.Context) (int, error) {
-	status, claims, err := utils.VerifyUserToken(e, "warehouses", wr.db)
+func (wr *WarehouseRepo) UpdateUnitIdentifiers(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, wr.db, "units.manage")
 	if err != nil {
 		return status, err
 	}
 
-	query := database.NewDBinstance(wr.db)
+	var updateUnitIdentifiersModel models.UpdateUnitIdentifiersModel
 
-	ok, err := query.VerifyUser(claims.UserEmail, "warehouses", claims.UserID)
+	err = e.Bind(&updateUnitIdentifiersModel)
 	if err != nil {
-		log.Printf("Error checking user details: %v", err)
+		log.Printf("failed to decode request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("invalid request format")
+	}
+
+	if err := validate.Struct(updateUnitIdentifiersModel); err != nil {
+		log.Printf("failed to validate request: %v", err)
+		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
+	}
+
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", updateUnitIdentifiersModel.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
+	if !query.IfPrefixExists(updateUnitIdentifiersModel.Prefix) {
+		log.Printf("prefix %v does not exist", updateUnitIdentifiersModel.Prefix)
+		return http.StatusBadRequest, fmt.Errorf("invalid prefix")
+	}
+
+	if exists, err := query.CheckIfUnitExists(updateUnitIdentifiersModel.UnitID, updateUnitIdentifiersModel.Prefix, warehouse_id); err != nil {
+		log.Printf("error while checking if unit exists: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
-	} else if !ok {
-		log.Printf("Invalid user details")
-		return http.StatusUnauthorized, fmt.Errorf("invalid user details")
+	} else if !exists {
+		log.Printf("unit with id %v does not exist", updateUnitIdentifiersModel.UnitID)
+		return http.StatusBadRequest, fmt.Errorf("unit with id %v does not exist", updateUnitIdentifiersModel.UnitID)
+	}
+
+	return query.UpdateUnitIdentifiers(updateUnitIdentifiersModel.UnitID, updateUnitIdentifiersModel.Prefix, updateUnitIdentifiersModel.SerialNumber, updateUnitIdentifiersModel.AssetTag)
+}
+
+func (wr *WarehouseRepo) DeleteUnit(e echo.Context) (int, error) {
+	status, scope, err := authorize(e, wr.db, "units.manage")
+	if err != nil {
+		return status, err
 	}
 
 	var deleteUnitModel models.DeleteUnitModel
@@ -810,12 +993,19 @@ func (wr *WarehouseRepo) DeleteUnit(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("failed to validate request")
 	}
 
+	status, warehouse_id, err := targetNode(e, wr.db, scope, "warehouse", deleteUnitModel.WarehouseID)
+	if err != nil {
+		return status, err
+	}
+
+	query := database.NewDBinstance(wr.db)
+
 	if deleteUnitModel.UnitID <= 0 {
 		log.Printf("invalid unit id")
 		return http.StatusBadRequest, fmt.Errorf("invalid unit id")
 	}
 
-	if exists, err := query.CheckIfUnitExists(deleteUnitModel.UnitID, deleteUnitModel.Prefix, claims.UserID); err != nil {
+	if exists, err := query.CheckIfUnitExists(deleteUnitModel.UnitID, deleteUnitModel.Prefix, warehouse_id); err != nil {
 		log.Printf("error while checking if unit exists: %v", err)
 		return http.StatusInternalServerError, fmt.Errorf("database error")
 	} else if !exists {
@@ -823,7 +1013,7 @@ func (wr *WarehouseRepo) DeleteUnit(e echo.Context) (int, error) {
 		return http.StatusBadRequest, fmt.Errorf("unit with id %v does not exist", deleteUnitModel.UnitID)
 	}
 
-	status, err = query.DeleteUnit(deleteUnitModel.UnitID, deleteUnitModel.Prefix, claims.UserID)
+	status, err = query.DeleteUnit(deleteUnitModel.UnitID, deleteUnitModel.Prefix, warehouse_id)
 	if err != nil {
 		log.Printf("error while deleting unit: %v", err)
 		return status, fmt.Errorf("database error")